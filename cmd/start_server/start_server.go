@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
@@ -16,6 +17,7 @@ func main() {
 	portRef := flag.Int("port", -1, "port number")
 	schemaRef := flag.String("schema", "", "Location of the schema")
 	versionRef := flag.Bool("version", false, "display version")
+	jsonSchemaRef := flag.Bool("json-schema", false, "display the JSON Schema of the configuration")
 	stdioRef := flag.Bool("stdio", false, "Use stdio instead of socket to communicate")
 	flag.Parse()
 
@@ -26,25 +28,26 @@ func main() {
 		return
 	}
 
-	// Parameter: schema
-	schema := *schemaRef
-	if schema == "" {
-		schema = os.Getenv("SCHEMA_LOCATION")
-
-		if schema == "" {
-			fmt.Print("No schema defined")
-			return
+	// Parameter: json-schema
+	if *jsonSchemaRef {
+		output, err := json.MarshalIndent(utils.GetConfigJSONSchema(), "", "  ")
+		if err != nil {
+			panic(err)
 		}
+		fmt.Println(string(output))
+		return
+	}
 
-		if !path.IsAbs(schema) {
-			cwd, err := os.Getwd()
+	// Command: validate
+	if flag.Arg(0) == "validate" {
+		os.Exit(validate(flag.Args()[1:], *schemaRef))
+	}
 
-			if err != nil {
-				fmt.Printf("Error while resolving schema path \"%s\"", schema)
-				panic(err)
-			}
-			schema = path.Join(cwd, schema)
-		}
+	// Parameter: schema
+	schema := getSchemaLocation(*schemaRef)
+	if schema == "" {
+		fmt.Print("No schema defined")
+		return
 	}
 
 	// Command: stdio
@@ -92,3 +95,24 @@ func main() {
 
 	lsp.StartServer(port, host, schema)
 }
+
+// The schema given with the flag, or else the one of the SCHEMA_LOCATION
+// environment variable relative to the working directory. Empty when none is
+// defined
+func getSchemaLocation(schema string) string {
+	if schema != "" {
+		return schema
+	}
+
+	schema = os.Getenv("SCHEMA_LOCATION")
+	if schema == "" || path.IsAbs(schema) {
+		return schema
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		fmt.Printf("Error while resolving schema path \"%s\"", schema)
+		panic(err)
+	}
+	return path.Join(cwd, schema)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	lsp "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/server"
+	languageservice "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/services"
+	"go.lsp.dev/protocol"
+)
+
+var severityNames = map[protocol.DiagnosticSeverity]string{
+	protocol.DiagnosticSeverityError:       "error",
+	protocol.DiagnosticSeverityWarning:     "warning",
+	protocol.DiagnosticSeverityInformation: "info",
+	protocol.DiagnosticSeverityHint:        "hint",
+}
+
+// Command: validate [-format text|json|sarif] [-no-network] <file>...
+//
+// Prints the diagnostics of the files, and returns the exit code: 1 when a
+// file has an error, 2 when the files could not be validated
+func validate(args []string, schemaFlag string) int {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	formatRef := flags.String("format", "text", "Format of the diagnostics: text, json or sarif")
+	jsonRef := flags.Bool("json", false, "Print the diagnostics as JSON, same as -format json")
+	noNetworkRef := flags.Bool("no-network", false, "Never reach the network, as in offline mode")
+	schemaRef := flags.String("schema", schemaFlag, "Location of the schema")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s validate [-format text|json|sarif] [-no-network] [-schema <location>] <file>...\n", filepath.Base(os.Args[0]))
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	format := *formatRef
+	if *jsonRef {
+		format = "json"
+	}
+
+	if flags.NArg() == 0 || (format != "text" && format != "json" && format != "sarif") {
+		flags.Usage()
+		return 2
+	}
+
+	schema := getSchemaLocation(*schemaRef)
+	if schema == "" {
+		fmt.Fprintln(os.Stderr, "No schema defined")
+		return 2
+	}
+
+	result, err := lsp.ValidateFiles(flags.Args(), schema, *noNetworkRef, os.Stderr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	switch format {
+	case "json":
+		if err := printJSON(result); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	case "sarif":
+		// The paths of the files are relative to the working directory, the
+		// root of the repository when run by CI
+		root, _ := os.Getwd()
+		if err := printJSON(languageservice.GetSARIFLog(result.Files, root)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	default:
+		printDiagnostics(result.Files)
+	}
+
+	if !result.Passed {
+		return 1
+	}
+	return 0
+}
+
+func printJSON(value any) error {
+	output, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(output))
+	return nil
+}
+
+// Prints the diagnostics like compilers do, one per line as
+// file:line:column: severity: message, with 1-based positions
+func printDiagnostics(files map[protocol.URI][]protocol.Diagnostic) {
+	fileURIs := make([]protocol.URI, 0, len(files))
+	for fileURI := range files {
+		fileURIs = append(fileURIs, fileURI)
+	}
+	sort.Slice(fileURIs, func(i, j int) bool { return fileURIs[i] < fileURIs[j] })
+
+	for _, fileURI := range fileURIs {
+		diagnostics := append([]protocol.Diagnostic{}, files[fileURI]...)
+		sort.SliceStable(diagnostics, func(i, j int) bool {
+			a, b := diagnostics[i].Range.Start, diagnostics[j].Range.Start
+			return a.Line < b.Line || (a.Line == b.Line && a.Character < b.Character)
+		})
+
+		for _, diagnostic := range diagnostics {
+			line := fmt.Sprintf("%s:%d:%d: %s: %s",
+				fileURI.Filename(),
+				diagnostic.Range.Start.Line+1,
+				diagnostic.Range.Start.Character+1,
+				severityNames[diagnostic.Severity],
+				diagnostic.Message,
+			)
+			if diagnostic.Code != nil {
+				line += fmt.Sprintf(" [%v]", diagnostic.Code)
+			}
+			fmt.Println(line)
+		}
+	}
+}
@@ -40,6 +40,24 @@ export async function activate(context: vscode.ExtensionContext) {
                 // Trigger completion again
                 vscode.commands.executeCommand('editor.action.triggerSuggest');
             },
+            'circleci-language-server.renameAtPosition': async (
+                uri: string,
+                position: { line: number; character: number },
+            ) => {
+                // Sent by the server once a refactoring introduced a
+                // placeholder name, such as when extracting a command
+                const document = await vscode.workspace.openTextDocument(
+                    vscode.Uri.parse(uri),
+                );
+                const editor = await vscode.window.showTextDocument(document);
+                const cursor = new vscode.Position(
+                    position.line,
+                    position.character,
+                );
+
+                editor.selections = [new vscode.Selection(cursor, cursor)];
+                vscode.commands.executeCommand('editor.action.rename');
+            },
         };
         const wrap = (
             name: CommandName,
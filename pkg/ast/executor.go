@@ -18,6 +18,8 @@ type Executor interface {
 	GetParametersRange() protocol.Range
 
 	GetEnvs() Environment
+
+	GetWorkingDirectory() string
 }
 
 type BaseExecutor struct {
@@ -71,6 +73,10 @@ func (e BaseExecutor) GetEnvs() Environment {
 	return e.Environment
 }
 
+func (e BaseExecutor) GetWorkingDirectory() string {
+	return e.BuiltInParameters.WorkingDirectory
+}
+
 type DockerExecutor struct {
 	BaseExecutor
 	Image         []DockerImage
@@ -123,6 +129,8 @@ type DockerImage struct {
 }
 
 type DockerImageInfo struct {
+	// Host of the registry, empty for Docker Hub
+	Registry  string
 	Namespace string
 	Name      string
 	Tag       string
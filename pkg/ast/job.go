@@ -1,10 +1,6 @@
 package ast
 
-import (
-	"strings"
-
-	"go.lsp.dev/protocol"
-)
+import "go.lsp.dev/protocol"
 
 type Job struct {
 	Range protocol.Range
@@ -16,6 +12,7 @@ type Job struct {
 	WorkingDirectory string
 	Parallelism      int
 	ParallelismRange protocol.Range
+	RawParallelism   string
 
 	ResourceClass      string
 	ResourceClassRange protocol.Range
@@ -44,14 +41,4 @@ type Job struct {
 
 	MacOS      MacOSExecutor
 	MacOSRange protocol.Range
-
-	CompletionItem *[]protocol.CompletionItem
-}
-
-func (job *Job) AddCompletionItem(label string, commitCharacters []string) {
-	*job.CompletionItem = append(*job.CompletionItem, protocol.CompletionItem{
-		Label:      label,
-		Kind:       protocol.CompletionItemKindProperty,
-		InsertText: label + strings.Join(commitCharacters, ""),
-	})
 }
@@ -2,6 +2,7 @@ package ast
 
 import (
 	"fmt"
+	"strings"
 
 	sitter "github.com/smacker/go-tree-sitter"
 	"go.lsp.dev/protocol"
@@ -33,7 +34,13 @@ func (orb *OrbURL) GetOrbID() string {
 	if orb.IsLocal {
 		return orb.Name
 	}
-	return fmt.Sprintf("%s@%s", orb.Name, orb.Version)
+	return FormatOrbID(orb.Name, orb.Version)
+}
+
+// Build the identifier of a remote orb version, as used by the registry and
+// as key of the orb cache. The name is the "namespace/orb" slug
+func FormatOrbID(name string, version string) string {
+	return fmt.Sprintf("%s@%s", strings.TrimSpace(name), strings.TrimSpace(version))
 }
 
 type OrbInfo struct {
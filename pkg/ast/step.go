@@ -57,6 +57,7 @@ type Run struct {
 	RawCommand       string
 	Name             string
 	Shell            string
+	ShellRange       protocol.Range
 	Background       bool
 	WorkingDirectory string
 	NoOutputTimeout  string
@@ -64,6 +65,10 @@ type Run struct {
 	WhenRange        protocol.Range
 	Environment      map[string]string
 	IsDeployStep     bool
+
+	// Keys written next to `run` in the step instead of under it, such as a
+	// misindented `command` or `name`
+	MisplacedKeys []TextAndRange
 }
 
 func (step Run) GetRange() protocol.Range {
@@ -138,8 +143,10 @@ func (step RestoreCache) GetName() string {
 
 type StoreArtifacts struct {
 	protocol.Range
-	Path        string
-	Destination string
+	Path             string
+	PathRange        protocol.Range
+	Destination      string
+	DestinationRange protocol.Range
 }
 
 func (step StoreArtifacts) GetRange() protocol.Range {
@@ -152,7 +159,8 @@ func (step StoreArtifacts) GetName() string {
 
 type StoreTestResults struct {
 	protocol.Range
-	Path string
+	Path      string
+	PathRange protocol.Range
 }
 
 func (step StoreTestResults) GetRange() protocol.Range {
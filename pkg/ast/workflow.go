@@ -41,6 +41,27 @@ type JobRef struct {
 
 	HasMatrix    bool
 	MatrixParams map[string][]ParameterValue
+
+	Filters WorkflowFilters
+
+	// MatrixAlias is the name given with `matrix.alias`, empty when the alias
+	// of the matrix defaults to the job name
+	MatrixAlias      string
+	MatrixAliasRange protocol.Range
+}
+
+// Name the other jobs of the workflow require this one with. All the jobs of
+// a matrix are required at once with its alias
+func (jobRef JobRef) GetRequireName() string {
+	if !jobRef.HasMatrix {
+		return jobRef.StepName
+	}
+
+	if jobRef.MatrixAlias != "" {
+		return jobRef.MatrixAlias
+	}
+
+	return jobRef.JobName
 }
 
 type Require struct {
@@ -54,22 +75,26 @@ type WorkflowTrigger struct {
 }
 
 type ScheduleTrigger struct {
-	Cron    string
-	Filters WorkflowFilters
-	Range   protocol.Range
+	Cron      string
+	CronRange protocol.Range
+	Filters   WorkflowFilters
+	Range     protocol.Range
 }
 
 type WorkflowFilters struct {
 	Range    protocol.Range
 	Branches BranchesFilter
+	Tags     BranchesFilter // Tags are filtered the same way as branches
 }
 
+// Each entry is either the exact name of a branch, or a regular expression
+// between slashes
 type BranchesFilter struct {
 	Range protocol.Range
 
-	Only      []string
+	Only      []TextAndRange
 	OnlyRange protocol.Range
 
-	Ignore      []string
+	Ignore      []TextAndRange
 	IgnoreRange protocol.Range
 }
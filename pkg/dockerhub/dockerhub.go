@@ -1,9 +1,18 @@
 package dockerhub
 
-import "net/url"
+import (
+	"net/url"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+)
 
 type DockerHubAPI interface {
-	DoesImageExist(namespace, image string) bool
+	// Returns an error when the existence of the image could not be determined,
+	// for example when the registry is unreachable
+	DoesImageExist(namespace, image string) (bool, error)
+	// Same as DoesImageExist for the images of any registry, including
+	// private ones when given credentials
+	DoesRegistryImageExist(registry, repository string, credentials *utils.DockerRegistryCredentials) (bool, error)
 	GetImageTags(namespace, image string) ([]string, error)
 	ImageHasTag(namespace, image, tag string) bool
 }
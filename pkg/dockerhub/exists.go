@@ -7,7 +7,7 @@ import (
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
 )
 
-func (me *dockerHubAPI) DoesImageExist(namespace, image string) bool {
+func (me *dockerHubAPI) DoesImageExist(namespace, image string) (bool, error) {
 	// A quick win is to check locally first, just in case we already found the image
 	ns := hubNamespaces[namespace]
 
@@ -15,7 +15,7 @@ func (me *dockerHubAPI) DoesImageExist(namespace, image string) bool {
 		repo, _ := findFirstByName(&ns.allRepositories, image)
 
 		if repo != nil {
-			return true
+			return true, nil
 		}
 	}
 
@@ -27,13 +27,21 @@ func (me *dockerHubAPI) DoesImageExist(namespace, image string) bool {
 	req.Header.Set("User-Agent", utils.UserAgent)
 
 	if err != nil {
-		return false
+		return false, err
 	}
 
 	res, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return false
+		return false, err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("unexpected status %d while checking image %s/%s", res.StatusCode, namespace, image)
 	}
-
-	return res.StatusCode == 200
 }
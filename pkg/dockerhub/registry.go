@@ -0,0 +1,125 @@
+package dockerhub
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+)
+
+// Returned when a registry refuses to tell whether an image exists, the image
+// being private and the credentials missing or wrong
+var ErrMissingCredentials = errors.New("the registry requires valid credentials")
+
+const dockerHubRegistry = "registry-1.docker.io"
+
+var bearerChallengeParamRegex = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// Checks the existence of a repository through the Docker Registry HTTP API,
+// implemented by Docker Hub, ECR, GCR and most private registries. The
+// registry is the host of the image, empty for Docker Hub
+func (me *dockerHubAPI) DoesRegistryImageExist(registry, repository string, credentials *utils.DockerRegistryCredentials) (bool, error) {
+	if registry == "" {
+		registry = dockerHubRegistry
+	}
+
+	tagsURL := url.URL{Scheme: "https", Host: registry, Path: fmt.Sprintf("/v2/%s/tags/list", repository)}
+
+	res, err := getRegistry(tagsURL.String(), "", credentials)
+	if err != nil {
+		return false, err
+	}
+	res.Body.Close()
+
+	// Registries such as Docker Hub and GCR only accept tokens given by
+	// their authentication service, with the credentials of the user
+	if challenge := res.Header.Get("WWW-Authenticate"); res.StatusCode == http.StatusUnauthorized && challenge != "" {
+		token, err := getRegistryToken(challenge, credentials)
+		if err != nil {
+			return false, err
+		}
+
+		res, err = getRegistry(tagsURL.String(), token, nil)
+		if err != nil {
+			return false, err
+		}
+		res.Body.Close()
+	}
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return false, ErrMissingCredentials
+	default:
+		return false, fmt.Errorf("unexpected status %d while checking image %s/%s", res.StatusCode, registry, repository)
+	}
+}
+
+func getRegistry(url string, token string, credentials *utils.DockerRegistryCredentials) (*http.Response, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", utils.UserAgent)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if credentials != nil {
+		req.SetBasicAuth(credentials.Username, credentials.Password)
+	}
+
+	return http.DefaultClient.Do(req)
+}
+
+// Answers a challenge such as:
+// Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:cimg/go:pull"
+func getRegistryToken(challenge string, credentials *utils.DockerRegistryCredentials) (string, error) {
+	params := map[string]string{}
+	for _, match := range bearerChallengeParamRegex.FindAllStringSubmatch(challenge, -1) {
+		params[match[1]] = match[2]
+	}
+
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		// Registries asking for basic authentication
+		return "", ErrMissingCredentials
+	}
+
+	query := realm.Query()
+	for _, key := range []string{"service", "scope"} {
+		if params[key] != "" {
+			query.Set(key, params[key])
+		}
+	}
+	realm.RawQuery = query.Encode()
+
+	res, err := getRegistry(realm.String(), "", credentials)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", ErrMissingCredentials
+	}
+
+	body := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return "", err
+	}
+
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	return body.AccessToken, nil
+}
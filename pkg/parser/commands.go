@@ -3,7 +3,6 @@ package parser
 import (
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
 	sitter "github.com/smacker/go-tree-sitter"
-	"go.lsp.dev/protocol"
 )
 
 func (doc *YamlDocument) parseCommands(commandsNode *sitter.Node) {
@@ -15,20 +14,8 @@ func (doc *YamlDocument) parseCommands(commandsNode *sitter.Node) {
 
 	doc.iterateOnBlockMapping(blockMappingNode, func(child *sitter.Node) {
 		command := doc.parseSingleCommand(child)
-		if definedCommand, ok := doc.Commands[command.Name]; ok {
-			doc.addDiagnostic(protocol.Diagnostic{
-				Severity: protocol.DiagnosticSeverityWarning,
-				Range:    command.NameRange,
-				Message:  "Command already defined",
-				Source:   "cci-language-server",
-			})
-			doc.addDiagnostic(protocol.Diagnostic{
-				Severity: protocol.DiagnosticSeverityWarning,
-				Range:    definedCommand.NameRange,
-				Message:  "Command already defined",
-				Source:   "cci-language-server",
-			})
-
+		// Duplicate keys are reported by the validation
+		if _, ok := doc.Commands[command.Name]; ok {
 			return
 		}
 
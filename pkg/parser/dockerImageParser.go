@@ -2,6 +2,7 @@ package parser
 
 import (
 	"regexp"
+	"strings"
 
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
 )
@@ -9,8 +10,17 @@ import (
 var dockerImageRegex = regexp.MustCompile(`^([a-z0-9\-_]+\/)?([a-z0-9\-_]+)(:(.*))?$`)
 var aliasRemover = regexp.MustCompile(`^&[a-zA-Z0-9\-_]+\s*`)
 
+// Images of other registries start with their host, which includes a dot or
+// a port, or is localhost: gcr.io/project/image:tag
+var registryImageRegex = regexp.MustCompile(`^((?:[a-zA-Z0-9\-]+\.)+[a-zA-Z0-9\-]+(?::[0-9]+)?|[a-zA-Z0-9\-]+:[0-9]+|localhost)/((?:[a-z0-9\-_.]+/)*)([a-z0-9\-_.]+)(:(.*))?$`)
+
 func ParseDockerImageValue(value string) ast.DockerImageInfo {
 	value = aliasRemover.ReplaceAllString(value, "")
+
+	if registryImage := registryImageRegex.FindStringSubmatch(value); registryImage != nil {
+		return parseRegistryImageValue(value, registryImage)
+	}
+
 	imageName := dockerImageRegex.FindAllStringSubmatch(value, -1)
 
 	if len(imageName) < 1 {
@@ -45,3 +55,26 @@ func ParseDockerImageValue(value string) ast.DockerImageInfo {
 		FullPath:  value,
 	}
 }
+
+func parseRegistryImageValue(value string, match []string) ast.DockerImageInfo {
+	registry, namespace, repository, tag := match[1], match[2], match[3], match[4]
+
+	namespace = strings.TrimSuffix(namespace, "/")
+	tag = strings.TrimPrefix(tag, ":")
+
+	switch registry {
+	case "docker.io", "index.docker.io", "registry-1.docker.io":
+		registry = ""
+		if namespace == "" {
+			namespace = "library"
+		}
+	}
+
+	return ast.DockerImageInfo{
+		Registry:  registry,
+		Namespace: namespace,
+		Name:      repository,
+		Tag:       tag,
+		FullPath:  value,
+	}
+}
@@ -120,6 +120,47 @@ func Test_parseDockerImageValue(t *testing.T) {
 				FullPath:  "cimg/go:<<parameters.go_version>>",
 			},
 		},
+
+		{
+			name: "",
+			args: args{
+				value: "123456789012.dkr.ecr.us-east-1.amazonaws.com/team/app:1.0",
+			},
+			want: ast.DockerImageInfo{
+				Registry:  "123456789012.dkr.ecr.us-east-1.amazonaws.com",
+				Namespace: "team",
+				Tag:       "1.0",
+				Name:      "app",
+				FullPath:  "123456789012.dkr.ecr.us-east-1.amazonaws.com/team/app:1.0",
+			},
+		},
+
+		{
+			name: "",
+			args: args{
+				value: "localhost:5000/app",
+			},
+			want: ast.DockerImageInfo{
+				Registry:  "localhost:5000",
+				Namespace: "",
+				Tag:       "",
+				Name:      "app",
+				FullPath:  "localhost:5000/app",
+			},
+		},
+
+		{
+			name: "",
+			args: args{
+				value: "docker.io/node:18",
+			},
+			want: ast.DockerImageInfo{
+				Namespace: "library",
+				Tag:       "18",
+				Name:      "node",
+				FullPath:  "docker.io/node:18",
+			},
+		},
 	}
 
 	for _, tt := range tests {
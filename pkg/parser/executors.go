@@ -30,20 +30,8 @@ func (doc *YamlDocument) parseSingleExecutor(executorNode *sitter.Node) {
 		return
 	}
 
-	if definedExecutor, ok := doc.Executors[executorName]; ok {
-		doc.addDiagnostic(protocol.Diagnostic{
-			Severity: protocol.DiagnosticSeverityWarning,
-			Range:    doc.NodeToRange(executorNameNode),
-			Message:  "Executor already defined",
-			Source:   "cci-language-server",
-		})
-		doc.addDiagnostic(protocol.Diagnostic{
-			Severity: protocol.DiagnosticSeverityWarning,
-			Range:    definedExecutor.GetNameRange(),
-			Message:  "Executor already defined",
-			Source:   "cci-language-server",
-		})
-
+	// Duplicate keys are reported by the validation
+	if _, ok := doc.Executors[executorName]; ok {
 		return
 	}
 
@@ -293,9 +281,17 @@ func (doc *YamlDocument) parseDockerImage(imageNode *sitter.Node) ast.DockerImag
 			}
 		case "aws_auth":
 			dict := doc.parseDictionary(GetChildOfType(valueNode, "block_mapping"))
+			// The keys are documented in lower case, the upper case ones are
+			// still accepted
 			dockerImg.AwsAuth = ast.DockerImageAWSAuth{
-				AWSAccessKeyID:     dict["AWS_ACCESS_KEY_ID"],
-				AWSSecretAccessKey: dict["AWS_SECRET_ACCESS_KEY"],
+				AWSAccessKeyID:     dict["aws_access_key_id"],
+				AWSSecretAccessKey: dict["aws_secret_access_key"],
+			}
+			if dockerImg.AwsAuth.AWSAccessKeyID == "" {
+				dockerImg.AwsAuth.AWSAccessKeyID = dict["AWS_ACCESS_KEY_ID"]
+			}
+			if dockerImg.AwsAuth.AWSSecretAccessKey == "" {
+				dockerImg.AwsAuth.AWSSecretAccessKey = dict["AWS_SECRET_ACCESS_KEY"]
 			}
 		}
 	})
@@ -401,7 +397,7 @@ func (doc *YamlDocument) machineTrueFix(machineRange protocol.Range) {
 	diagnostic.Tags = []protocol.DiagnosticTag{
 		protocol.DiagnosticTagDeprecated,
 	}
-	doc.addDiagnostic(diagnostic)
+	doc.addDiagnostic(utils.RuleDeprecatedImage, diagnostic)
 
 }
 
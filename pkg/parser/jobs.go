@@ -2,10 +2,11 @@ package parser
 
 import (
 	"strconv"
+	"strings"
 
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
 	sitter "github.com/smacker/go-tree-sitter"
-	"go.lsp.dev/protocol"
 )
 
 func (doc *YamlDocument) parseJobs(jobsNode *sitter.Node) {
@@ -17,19 +18,8 @@ func (doc *YamlDocument) parseJobs(jobsNode *sitter.Node) {
 
 	doc.iterateOnBlockMapping(blockMappingNode, func(child *sitter.Node) {
 		job := doc.parseSingleJob(child)
-		if definedJob, ok := doc.Jobs[job.Name]; ok {
-			doc.addDiagnostic(protocol.Diagnostic{
-				Severity: protocol.DiagnosticSeverityWarning,
-				Range:    job.NameRange,
-				Message:  "Job already defined",
-				Source:   "cci-language-server",
-			})
-			doc.addDiagnostic(protocol.Diagnostic{
-				Severity: protocol.DiagnosticSeverityWarning,
-				Range:    definedJob.NameRange,
-				Message:  "Job already defined",
-				Source:   "cci-language-server",
-			})
+		// Duplicate keys are reported by the validation
+		if _, ok := doc.Jobs[job.Name]; ok {
 			return
 		}
 
@@ -40,7 +30,7 @@ func (doc *YamlDocument) parseJobs(jobsNode *sitter.Node) {
 func (doc *YamlDocument) parseSingleJob(jobNode *sitter.Node) ast.Job {
 	// jobNode is a block_mapping_pair
 	jobNameNode, valueNode := doc.GetKeyValueNodes(jobNode)
-	res := ast.Job{CompletionItem: &[]protocol.CompletionItem{}, Parallelism: -1, Contexts: &[]string{}, Parameters: map[string]ast.Parameter{}}
+	res := ast.Job{Parallelism: -1, Contexts: &[]string{}, Parameters: map[string]ast.Parameter{}}
 
 	if jobNameNode == nil || valueNode == nil {
 		return res
@@ -77,15 +67,20 @@ func (doc *YamlDocument) parseSingleJob(jobNode *sitter.Node) ast.Job {
 				res.Description = doc.GetNodeText(valueNode)
 
 			case "parallelism":
-				parsedInt, err := strconv.ParseInt(doc.GetNodeText(valueNode), 10, 8)
-				if err != nil {
-					return
-				}
-
-				res.Parallelism = int(parsedInt)
+				res.RawParallelism = doc.GetNodeText(valueNode)
 				res.ParallelismRange = doc.NodeToRange(child)
+
+				// Invalid values are kept raw, Parallelism is left to -1
+				parsedInt, err := strconv.ParseInt(res.RawParallelism, 10, 0)
+				if err == nil {
+					res.Parallelism = int(parsedInt)
+				}
 			case "resource_class":
 				res.ResourceClass = doc.GetNodeText(valueNode)
+				res.ResourceClassRange = doc.NodeToRange(child)
+				if res.ResourceClass == "" {
+					res.ResourceClassRange.End.Character = 999
+				}
 
 			case "steps":
 				res.StepsRange = doc.NodeToRange(child)
@@ -124,34 +119,45 @@ func (doc *YamlDocument) parseSingleJob(jobNode *sitter.Node) ast.Job {
 	if machineNodeFound {
 		doc.addedMachineTrueDeprecatedDiag(machineNode, res.ResourceClass)
 	}
-	doc.jobCompletionItem(res)
 
 	return res
 }
 
-func (doc *YamlDocument) jobCompletionItem(job ast.Job) {
-	if job.Steps == nil {
-		job.AddCompletionItem("steps", []string{":", "\n", "\t"})
-	}
-	if job.Description == "" {
-		job.AddCompletionItem("description", []string{":", " "})
-	}
-	if job.Executor == "" {
-		job.AddCompletionItem("executor", []string{":", " "})
-		if job.ResourceClass == "" {
-			job.AddCompletionItem("resource_class", []string{":", " "})
+// Executor the job runs with: the one it references, declared in the
+// document or provided by an orb, or else the one it defines. Returns nil when
+// it can not be determined, such as when the executor is a parameter or its
+// orb is not available
+func (doc *YamlDocument) GetJobExecutor(job ast.Job, cache *utils.Cache) ast.Executor {
+	if job.Executor != "" {
+		if utils.CheckIfOnlyParamUsed(job.Executor) {
+			return nil
 		}
-		if job.Shell == "" {
-			job.AddCompletionItem("shell", []string{":", " "})
+
+		if executor, ok := doc.Executors[job.Executor]; ok {
+			return executor
 		}
-		if job.WorkingDirectory == "" {
-			job.AddCompletionItem("working_directory", []string{":", " "})
+
+		orbName, isOrbExecutor := doc.CouldBeOrbReference(job.Executor)
+		if !isOrbExecutor {
+			return nil
 		}
+
+		orbInfo, err := doc.GetOrbInfoFromName(orbName, cache)
+		if err != nil || orbInfo == nil {
+			return nil
+		}
+
+		return orbInfo.Executors[strings.SplitN(job.Executor, "/", 2)[1]]
 	}
-	if job.Parameters == nil {
-		job.AddCompletionItem("parameters", []string{":", "\n", " "})
-	}
-	if job.Parallelism == 0 {
-		job.AddCompletionItem("parallelism", []string{":", " "})
+
+	switch {
+	case !utils.IsDefaultRange(job.DockerRange):
+		return job.Docker
+	case !utils.IsDefaultRange(job.MachineRange):
+		return job.Machine
+	case !utils.IsDefaultRange(job.MacOSRange):
+		return job.MacOS
 	}
+
+	return nil
 }
@@ -122,7 +122,8 @@ func handleYAMLErrors(err string, content []byte, rootNode *sitter.Node) ([]prot
 	for i, lineContentRange := range lineContentRanges {
 		lineError := lineErrors[i]
 
-		if duplicateMergeKeyErrorRegex.MatchString(lineError) {
+		// Duplicate keys are reported by the validation, on the key itself
+		if duplicateKeyErrorRegex.MatchString(lineError) {
 			continue
 		}
 
@@ -144,10 +145,14 @@ func (validator *JSONSchemaValidator) ValidateWithJSONSchema(rootNode *sitter.No
 	var file interface{}
 	diagnostics := make([]protocol.Diagnostic, 0)
 
-	if err := yaml.Unmarshal(content, &file); err != nil {
+	// The unknown anchors are reported at each of their aliases by the
+	// validation of the anchors
+	if err := yaml.Unmarshal(content, &file); err != nil && !strings.Contains(err.Error(), "yaml: unknown anchor") {
 		// Can only happen if anchor or alias are not properly defined and/or referenced
 		yamlError, _ := handleYAMLErrors(err.Error(), content, rootNode)
-		diagnostics = append(diagnostics, yamlError...)
+		for _, diagnostic := range yamlError {
+			diagnostics = append(diagnostics, utils.WithDiagnosticRule(utils.RuleYAMLSyntax, diagnostic))
+		}
 	}
 
 	yamlLoader := gojsonschema.NewGoLoader(file)
@@ -156,13 +161,17 @@ func (validator *JSONSchemaValidator) ValidateWithJSONSchema(rootNode *sitter.No
 
 	if err != nil {
 		// Should never happen
-		return []protocol.Diagnostic{utils.CreateErrorDiagnosticFromNode(rootNode, err.Error())}
+		return []protocol.Diagnostic{utils.WithDiagnosticRule(utils.RuleSchema, utils.CreateErrorDiagnosticFromNode(rootNode, err.Error()))}
 	}
 
 	jsonSchemaDiags := []protocol.Diagnostic{}
 
 	if !result.Valid() {
 		for _, resErr := range result.Errors() {
+			if isVersionError(resErr) {
+				continue
+			}
+
 			fields := strings.Split(resErr.Field(), ".")
 			if len(fields) == 1 && fields[0] == "(root)" {
 				diagnostic := utils.CreateErrorDiagnosticFromNode(rootNode, resErr.Description())
@@ -183,12 +192,22 @@ func (validator *JSONSchemaValidator) ValidateWithJSONSchema(rootNode *sitter.No
 		}
 	}
 
-	jsonSchemaDiags = removeUselessMustValidateError(jsonSchemaDiags)
-	diagnostics = append(diagnostics, jsonSchemaDiags...)
+	for _, diagnostic := range removeUselessMustValidateError(jsonSchemaDiags) {
+		diagnostics = append(diagnostics, utils.WithDiagnosticRule(utils.RuleSchema, diagnostic))
+	}
 
 	return diagnostics
 }
 
+// The version of the configuration is checked by the validation, which
+// reports it more precisely
+func isVersionError(resErr gojsonschema.ResultError) bool {
+	if resErr.Type() == "required" {
+		return resErr.Details()["property"] == "version"
+	}
+	return resErr.Field() == "version"
+}
+
 // Keys that can have only a parameter inside it,
 // and therefore the JSON Schema validation is not necessary for these keys.
 //
@@ -250,4 +269,4 @@ func hasAnotherDiagInsideRange(diags []protocol.Diagnostic, rangeToCheck protoco
 	return false
 }
 
-var duplicateMergeKeyErrorRegex = regexp.MustCompile(`mapping key "<<" already defined at line \d+\n?`)
+var duplicateKeyErrorRegex = regexp.MustCompile(`mapping key ".*" already defined at line \d+\n?`)
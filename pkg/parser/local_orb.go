@@ -86,10 +86,8 @@ func (doc *YamlDocument) parseLocalOrb(name string, orbNode *sitter.Node, adding
 
 	doc.LocalOrbInfo[name] = orbInfo
 
-	// Diagnostics
-	for _, diagnostic := range *orbDoc.Diagnostics {
-		doc.addDiagnostic(diagnostic)
-	}
+	// Diagnostics, already identified by their rule
+	*doc.Diagnostics = append(*doc.Diagnostics, *orbDoc.Diagnostics...)
 
 	return &orb, nil
 }
@@ -0,0 +1,154 @@
+package parser
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+)
+
+const DefaultOrbResolverWorkers = 8
+
+// Fetches the remote orbs missing from the orb cache concurrently
+type OrbResolver struct {
+	Cache *utils.Cache
+
+	// Maximum number of orbs fetched at the same time
+	Workers int
+
+	// Retrieves a single orb, the result is stored in the cache by the resolver
+	Fetch func(orbID string) (*ast.OrbInfo, error)
+
+	// Reports the progress of the orbs being fetched, nothing is reported
+	// when nil
+	Progress utils.ProgressReporter
+
+	// Logs the orbs being resolved, nothing is logged when nil
+	Logger *slog.Logger
+}
+
+// Resolver fetching the orbs with the fetcher of the cache, or the registry
+// of the context's host when the cache has none
+func NewOrbResolver(cache *utils.Cache, context *utils.LsContext) *OrbResolver {
+	return &OrbResolver{
+		Cache:    cache,
+		Workers:  DefaultOrbResolverWorkers,
+		Fetch:    getOrbFetcher(cache, context).FetchOrb,
+		Progress: context.Progress,
+		Logger:   context.GetLogger(),
+	}
+}
+
+// Fetch and cache every orb that is not already in the cache. The
+// `@volatile` orbs are fetched again even when cached, as the version they
+// point to changes, the cached one is kept when the fetch fails. Each orb is
+// resolved independently, the errors of the orbs that could not be fetched
+// are returned by orb ID
+func (resolver *OrbResolver) Resolve(orbIDs []string) map[string]error {
+	errs := make(map[string]error)
+
+	pending := []string{}
+	seen := make(map[string]bool, len(orbIDs))
+	for _, orbID := range orbIDs {
+		if seen[orbID] || (!isVolatileOrbID(orbID) && resolver.Cache.OrbCache.HasOrb(orbID)) {
+			continue
+		}
+		seen[orbID] = true
+		pending = append(pending, orbID)
+	}
+
+	if len(pending) == 0 {
+		return errs
+	}
+
+	logger := resolver.Logger
+	if logger == nil {
+		logger = utils.DiscardLogger
+	}
+	logger.Debug("Resolving orbs", "orbs", strings.Join(pending, ","))
+
+	progress := utils.BeginProgress(resolver.Progress, "Resolving orbs")
+	resolved := 0
+
+	// Guards the errors and the progress
+	mutex := sync.Mutex{}
+
+	toFetch := make(chan string)
+	wg := sync.WaitGroup{}
+
+	workers := resolver.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for orbID := range toFetch {
+				var err error
+				if isVolatileOrbID(orbID) {
+					err = resolver.refresh(orbID)
+				} else {
+					// Waits for the fetch of another lookup of the same orb
+					_, err = resolver.Cache.OrbCache.GetOrSet(orbID, func() (*ast.OrbInfo, error) {
+						orb, err := resolver.Fetch(orbID)
+						if err == nil {
+							storeResolvedOrbVersion(orbID, orb, resolver.Cache)
+						}
+						return orb, err
+					})
+				}
+
+				mutex.Lock()
+				if err != nil {
+					errs[orbID] = err
+				}
+				resolved++
+				progress.Report(
+					fmt.Sprintf("%d/%d: %s", resolved, len(pending), orbID),
+					uint32(resolved*100/len(pending)),
+				)
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	for _, orbID := range pending {
+		toFetch <- orbID
+	}
+	close(toFetch)
+
+	wg.Wait()
+
+	logger.Debug("Orbs resolved", "resolved", len(pending)-len(errs), "failed", len(errs))
+
+	if len(errs) > 0 {
+		progress.End(fmt.Sprintf("%d of %d orbs could not be resolved", len(errs), len(pending)))
+	} else {
+		progress.End("")
+	}
+
+	return errs
+}
+
+// Replace the cached orb with the one fetched, if any
+func (resolver *OrbResolver) refresh(orbID string) error {
+	orb, err := resolver.Fetch(orbID)
+	if err != nil {
+		return err
+	}
+
+	resolver.Cache.OrbCache.SetOrb(orb, orbID)
+	storeResolvedOrbVersion(orbID, orb, resolver.Cache)
+	return nil
+}
+
+func isVolatileOrbID(orbID string) bool {
+	_, version, _ := strings.Cut(orbID, "@")
+	return version == "volatile"
+}
@@ -0,0 +1,203 @@
+package parser
+
+import (
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrbResolver(t *testing.T) {
+	cache := utils.CreateCache()
+	cache.OrbCache.SetOrb(&ast.OrbInfo{}, "circleci/cached@1.0.0")
+
+	fetched := atomic.Int32{}
+	resolver := &OrbResolver{
+		Cache:   cache,
+		Workers: 4,
+		Fetch: func(orbID string) (*ast.OrbInfo, error) {
+			fetched.Add(1)
+
+			switch orbID {
+			case "circleci/slow@1.0.0":
+				time.Sleep(200 * time.Millisecond)
+			case "circleci/broken@1.0.0":
+				return nil, errors.New("could not find orb")
+			default:
+				time.Sleep(20 * time.Millisecond)
+			}
+
+			_, version, _ := strings.Cut(orbID, "@")
+			return &ast.OrbInfo{RemoteInfo: ast.RemoteOrbInfo{Version: version}}, nil
+		},
+	}
+
+	start := time.Now()
+	errs := resolver.Resolve([]string{
+		"circleci/slow@1.0.0",
+		"circleci/node@1.0.0",
+		"circleci/go@1.0.0",
+		"circleci/go@1.0.0",
+		"circleci/rust@1.0.0",
+		"circleci/broken@1.0.0",
+		"circleci/cached@1.0.0",
+	})
+	elapsed := time.Since(start)
+
+	assert.Len(t, errs, 1)
+	assert.Error(t, errs["circleci/broken@1.0.0"])
+	assert.Equal(t, int32(5), fetched.Load())
+	assert.Less(t, elapsed, 400*time.Millisecond)
+
+	for _, orbID := range []string{"circleci/slow@1.0.0", "circleci/node@1.0.0", "circleci/go@1.0.0", "circleci/rust@1.0.0"} {
+		assert.True(t, cache.OrbCache.HasOrb(orbID), orbID)
+	}
+}
+
+func TestOrbResolverStoresResolvedVersion(t *testing.T) {
+	cache := utils.CreateCache()
+	resolver := &OrbResolver{
+		Cache:   cache,
+		Workers: 1,
+		Fetch: func(orbID string) (*ast.OrbInfo, error) {
+			return &ast.OrbInfo{RemoteInfo: ast.RemoteOrbInfo{Version: "1.4.2"}}, nil
+		},
+	}
+
+	resolver.Resolve([]string{"circleci/node@1"})
+
+	assert.True(t, cache.OrbCache.HasOrb("circleci/node@1"))
+	assert.True(t, cache.OrbCache.HasOrb("circleci/node@1.4.2"))
+}
+
+func TestOrbResolverRefreshesVolatileOrbs(t *testing.T) {
+	cache := utils.CreateCache()
+	cache.OrbCache.SetOrb(&ast.OrbInfo{RemoteInfo: ast.RemoteOrbInfo{Version: "1.0.0"}}, "circleci/node@volatile")
+	cache.OrbCache.SetOrb(&ast.OrbInfo{RemoteInfo: ast.RemoteOrbInfo{Version: "5.1.0"}}, "circleci/node@5.1.0")
+	cache.OrbCache.SetOrb(&ast.OrbInfo{RemoteInfo: ast.RemoteOrbInfo{Version: "1.0.0"}}, "circleci/go@volatile")
+
+	fetcher := &fakeOrbFetcher{
+		orbs: map[string]*ast.OrbInfo{
+			"circleci/node@volatile": {RemoteInfo: ast.RemoteOrbInfo{Version: "5.2.0"}},
+		},
+		fetched: map[string]int{},
+	}
+	resolver := &OrbResolver{Cache: cache, Workers: 2, Fetch: fetcher.FetchOrb}
+
+	errs := resolver.Resolve([]string{"circleci/node@volatile", "circleci/node@5.1.0", "circleci/go@volatile"})
+
+	assert.Equal(t, map[string]int{"circleci/node@volatile": 1, "circleci/go@volatile": 1}, fetcher.fetched)
+	assert.Equal(t, "5.2.0", cache.OrbCache.GetOrb("circleci/node@volatile").RemoteInfo.Version)
+	assert.True(t, cache.OrbCache.HasOrb("circleci/node@5.2.0"))
+
+	// The cached orb is kept when it can not be fetched again
+	assert.Contains(t, errs, "circleci/go@volatile")
+	assert.Equal(t, "1.0.0", cache.OrbCache.GetOrb("circleci/go@volatile").RemoteInfo.Version)
+}
+
+type fakeOrbFetcher struct {
+	orbs    map[string]*ast.OrbInfo
+	fetched map[string]int
+}
+
+func (fetcher *fakeOrbFetcher) FetchOrb(orbID string) (*ast.OrbInfo, error) {
+	fetcher.fetched[orbID]++
+
+	orb, ok := fetcher.orbs[orbID]
+	if !ok {
+		return nil, errors.New("could not find orb " + orbID)
+	}
+	return orb, nil
+}
+
+func TestOrbResolverWithOrbFetcher(t *testing.T) {
+	fetcher := &fakeOrbFetcher{
+		orbs: map[string]*ast.OrbInfo{
+			"acme/deploy@1": {
+				Description: "Deploys to the acme cloud",
+				RemoteInfo:  ast.RemoteOrbInfo{Version: "1.2.0"},
+			},
+			"acme/test@2.0.0": {
+				RemoteInfo: ast.RemoteOrbInfo{Version: "2.0.0"},
+			},
+		},
+		fetched: map[string]int{},
+	}
+	cache := utils.CreateCache(utils.WithOrbFetcher(fetcher))
+	context := &utils.LsContext{}
+
+	errs := NewOrbResolver(cache, context).Resolve([]string{"acme/deploy@1", "acme/test@2.0.0", "acme/missing@1.0.0"})
+
+	assert.Len(t, errs, 1)
+	assert.Error(t, errs["acme/missing@1.0.0"])
+	assert.True(t, cache.OrbCache.HasOrb("acme/deploy@1.2.0"))
+	assert.True(t, cache.OrbCache.HasOrb("acme/test@2.0.0"))
+
+	orb, err := GetOrbInfo("acme/deploy@1", cache, context)
+	assert.NoError(t, err)
+	assert.Equal(t, "Deploys to the acme cloud", orb.Description)
+	assert.Equal(t, 1, fetcher.fetched["acme/deploy@1"])
+
+	orb, err = RefreshOrbInfo("acme/test@2.0.0", cache, context)
+	assert.NoError(t, err)
+	assert.Equal(t, "2.0.0", orb.RemoteInfo.Version)
+	assert.Equal(t, 2, fetcher.fetched["acme/test@2.0.0"])
+}
+
+type fakeProgressReporter struct {
+	titles      []string
+	percentages []uint32
+	ended       []string
+}
+
+func (reporter *fakeProgressReporter) Begin(title string) utils.Progress {
+	reporter.titles = append(reporter.titles, title)
+	return reporter
+}
+
+func (reporter *fakeProgressReporter) Report(message string, percentage uint32) {
+	reporter.percentages = append(reporter.percentages, percentage)
+}
+
+func (reporter *fakeProgressReporter) End(message string) {
+	reporter.ended = append(reporter.ended, message)
+}
+
+func TestOrbResolverProgress(t *testing.T) {
+	cache := utils.CreateCache()
+	cache.OrbCache.SetOrb(&ast.OrbInfo{}, "circleci/cached@1.0.0")
+
+	reporter := &fakeProgressReporter{}
+	resolver := &OrbResolver{
+		Cache:    cache,
+		Workers:  4,
+		Progress: reporter,
+		Fetch: func(orbID string) (*ast.OrbInfo, error) {
+			if orbID == "circleci/broken@1.0.0" {
+				return nil, errors.New("could not find orb")
+			}
+			return &ast.OrbInfo{}, nil
+		},
+	}
+
+	resolver.Resolve([]string{
+		"circleci/node@1.0.0",
+		"circleci/go@1.0.0",
+		"circleci/go@1.0.0",
+		"circleci/broken@1.0.0",
+		"circleci/cached@1.0.0",
+	})
+
+	assert.Equal(t, []string{"Resolving orbs"}, reporter.titles)
+	assert.Equal(t, []uint32{33, 66, 100}, reporter.percentages)
+	assert.Equal(t, []string{"1 of 3 orbs could not be resolved"}, reporter.ended)
+
+	// Nothing is reported when every orb is cached
+	resolver.Resolve([]string{"circleci/node@1.0.0", "circleci/cached@1.0.0"})
+	assert.Len(t, reporter.titles, 1)
+}
@@ -27,6 +27,32 @@ func (doc *YamlDocument) GetOrbInfoFromName(name string, cache *utils.Cache) (*a
 	return doc.GetOrFetchOrbInfo(orb, cache)
 }
 
+// Identifier of the remote orb a reference such as `node/install` resolves
+// to. References use the alias the orb is imported with in the `orbs`
+// section, `node: circleci/node@5.1.0`, while the orb cache and the registry
+// use the identifier, circleci/node@5.1.0. Local orbs have no identifier
+func (doc *YamlDocument) GetOrbIDFromReference(reference string) (string, bool) {
+	orb, ok := doc.Orbs[strings.SplitN(reference, "/", 2)[0]]
+	if !ok || orb.Url.IsLocal {
+		return "", false
+	}
+
+	return orb.Url.GetOrbID(), true
+}
+
+// Orb a reference such as `node/install` resolves to, following the alias it
+// is imported with, along with the name of the member. The orb is nil when
+// the reference is not to a declared orb
+func (doc *YamlDocument) GetOrbInfoFromReference(reference string, cache *utils.Cache) (*ast.OrbInfo, string, error) {
+	components := strings.SplitN(reference, "/", 2)
+	if len(components) != 2 {
+		return nil, "", nil
+	}
+
+	orbInfo, err := doc.GetOrbInfoFromName(components[0], cache)
+	return orbInfo, components[1], err
+}
+
 func (doc *YamlDocument) GetOrFetchOrbInfo(orb ast.Orb, cache *utils.Cache) (*ast.OrbInfo, error) {
 	// Searching within local orbs
 	orbInfo, ok := doc.LocalOrbInfo[orb.Name]
@@ -61,6 +87,11 @@ func (doc *YamlDocument) DoesOrbExist(orb ast.Orb, cache *utils.Cache) bool {
 		return exists
 	}
 
+	// Whether the orb exists can not be known offline
+	if doc.Context.IsOffline() {
+		return true
+	}
+
 	fetchedOrb, err := GetOrbByName(lookup, doc.Context)
 	simpleOrbExistanceCache[lookup] = err == nil && fetchedOrb.Name != ""
 
@@ -211,7 +211,7 @@ func (doc *YamlDocument) parseEnumParameter(paramName string, paramNode *sitter.
 	})
 
 	if enumParam.HasDefault && utils.FindInArray(enumParam.Enum, enumParam.Default) == -1 {
-		doc.addDiagnostic(utils.CreateErrorDiagnosticFromRange(enumParam.DefaultRange, "Default value is not in enum"))
+		doc.addDiagnostic(utils.RuleParameterDefault, utils.CreateErrorDiagnosticFromRange(enumParam.DefaultRange, "Default value is not in enum"))
 	}
 
 	return enumParam
@@ -257,7 +257,7 @@ func (doc *YamlDocument) parseStepsParameter(paramName string, paramNode *sitter
 			stepsParam.HasDefault = true
 			for _, step := range stepsParam.Default.Value.([]ast.ParameterValue) {
 				if step.Type != "steps" {
-					doc.addDiagnostic(utils.CreateErrorDiagnosticFromRange(step.Range, "Not a valid step"))
+					doc.addDiagnostic(utils.RuleParameterDefault, utils.CreateErrorDiagnosticFromRange(step.Range, "Not a valid step"))
 				}
 			}
 		case "description":
@@ -311,7 +311,7 @@ func (doc *YamlDocument) parseParameterValue(child *sitter.Node) (ast.ParameterV
 			keyNode,
 			"No value defined for the parameter",
 		)
-		doc.addDiagnostic(diag)
+		doc.addDiagnostic(utils.RuleParameter, diag)
 		return ast.ParameterValue{}, fmt.Errorf("no parameter value")
 	}
 
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
@@ -38,17 +40,20 @@ type OrbQuery struct {
 }
 
 func GetOrbInfo(orbVersionCode string, cache *utils.Cache, context *utils.LsContext) (*ast.OrbInfo, error) {
-	// Returning cache if exists
-	if !cache.OrbCache.HasOrb(orbVersionCode) {
-
-		orb, err := fetchOrbInfo(orbVersionCode, cache, context)
+	// Concurrent lookups of an orb missing from the cache share a single fetch
+	return cache.OrbCache.GetOrSet(orbVersionCode, func() (*ast.OrbInfo, error) {
+		orb, err := getOrbFetcher(cache, context).FetchOrb(orbVersionCode)
+		if err == nil {
+			storeResolvedOrbVersion(orbVersionCode, orb, cache)
+		}
 		return orb, err
-	}
-
-	return cache.OrbCache.GetOrb(orbVersionCode), nil
+	})
 }
 
 func GetOrbByName(orbName string, context *utils.LsContext) (OrbGQLData, error) {
+	if context.IsOffline() {
+		return OrbGQLData{}, utils.ErrOffline
+	}
 	if context.Api.HostUrl == "" {
 		return OrbGQLData{}, errors.New("host URL not defined")
 	}
@@ -83,12 +88,18 @@ func GetOrbByName(orbName string, context *utils.LsContext) (OrbGQLData, error)
 }
 
 func ParseRemoteOrbs(orbs map[string]ast.Orb, cache *utils.Cache, context *utils.LsContext) {
+	toFetch := []string{}
+
 	for _, orb := range orbs {
 		if orb.Url.IsLocal {
 			continue
 		}
 
-		if orb.Url.Version != "volatile" && checkIfRemoteOrbAlreadyExistsInFSCache(orb.Url.GetOrbID()) {
+		if orb.Url.Version != "volatile" && cache.OrbCache.HasOrb(orb.Url.GetOrbID()) {
+			continue
+		}
+
+		if orb.Url.Version != "volatile" && !cache.OrbCache.IsInMemory() && checkIfRemoteOrbAlreadyExistsInFSCache(orb.Url.GetOrbID()) {
 			err := addAlreadyExistingRemoteOrbsToFSCache(orb, cache, context)
 
 			// If no error, we continue
@@ -98,29 +109,100 @@ func ParseRemoteOrbs(orbs map[string]ast.Orb, cache *utils.Cache, context *utils
 			}
 		}
 
-		fetchOrbInfo(orb.Url.GetOrbID(), cache, context)
+		toFetch = append(toFetch, orb.Url.GetOrbID())
 	}
+
+	NewOrbResolver(cache, context).Resolve(toFetch)
 }
 
-func fetchOrbInfo(orbVersionCode string, cache *utils.Cache, context *utils.LsContext) (*ast.OrbInfo, error) {
-	orbQuery, err := GetRemoteOrb(orbVersionCode, context.Api.Token, context.Api.HostUrl, context.UserIdForTelemetry)
+// Load the orbs persisted on disk by a previous session into the cache
+func LoadPersistedOrbs(cache *utils.Cache, context *utils.LsContext) int {
+	return cache.LoadPersistedOrbs(func(source []byte, filePath string) (ast.OrbParsedAttributes, error) {
+		parsedOrbSource, err := ParseFromContent(source, context, uri.File(filePath), protocol.Position{})
+		if err != nil {
+			return ast.OrbParsedAttributes{}, err
+		}
 
+		return parsedOrbSource.ToOrbParsedAttributes(), nil
+	})
+}
+
+// Fetch the orb again from the registry, replacing the cached one even when
+// it has not expired
+func RefreshOrbInfo(orbVersionCode string, cache *utils.Cache, context *utils.LsContext) (*ast.OrbInfo, error) {
+	cache.OrbCache.RemoveOrbWithFile(orbVersionCode)
+
+	orb, err := GetOrbInfo(orbVersionCode, cache, context)
 	if err != nil {
-		return &ast.OrbInfo{}, err
+		return nil, fmt.Errorf("orb %s could not be refreshed: %w", orbVersionCode, err)
 	}
 
-	parsedOrbSource, err := ParseFromContent([]byte(orbQuery.Source), context, uri.File(""), protocol.Position{})
+	return orb, nil
+}
+
+// Orb fetcher querying the orb registry of a CircleCI host, the one of the
+// context unless HostUrl is set
+type RegistryOrbFetcher struct {
+	Context *utils.LsContext
+	HostUrl string
+
+	// Do not write the sources of the orbs in the FS cache, the orbs then have
+	// no file
+	InMemory bool
+}
+
+// Fetcher set on the cache, the registry of the context's host otherwise
+func getOrbFetcher(cache *utils.Cache, context *utils.LsContext) utils.OrbFetcher {
+	if fetcher := cache.OrbCache.Fetcher(); fetcher != nil {
+		return fetcher
+	}
+
+	return RegistryOrbFetcher{Context: context, InMemory: cache.OrbCache.IsInMemory()}
+}
+
+// Fetch an orb from the registry and write its source in the FS cache,
+// without storing it in the orb cache
+func (fetcher RegistryOrbFetcher) FetchOrb(orbVersionCode string) (*ast.OrbInfo, error) {
+	context := fetcher.Context
+	if context.IsOffline() {
+		return &ast.OrbInfo{}, utils.ErrOffline
+	}
+
+	hostUrl := fetcher.HostUrl
+	if hostUrl == "" {
+		hostUrl = context.Api.HostUrl
+	}
+
+	logger := context.GetLogger().With("orb", orbVersionCode, "host", hostUrl)
+	logger.Debug("Fetching orb")
+	start := time.Now()
+
+	orbQuery, err := GetRemoteOrb(orbVersionCode, context.Api.Token, hostUrl, context.UserIdForTelemetry)
 
 	if err != nil {
+		logger.Warn("Orb could not be fetched", "error", err)
 		return &ast.OrbInfo{}, err
 	}
 
-	filePath, err := writeRemoteOrbSourceInFSCache(orbVersionCode, orbQuery.Source)
+	parsedOrbSource, err := ParseFromContent([]byte(orbQuery.Source), context, uri.File(""), protocol.Position{})
 
 	if err != nil {
+		logger.Error("Orb source could not be parsed", "error", err)
 		return &ast.OrbInfo{}, err
 	}
 
+	filePath := ""
+	if !fetcher.InMemory {
+		filePath, err = writeRemoteOrbSourceInFSCache(orbVersionCode, orbQuery.Source)
+
+		if err != nil {
+			logger.Error("Orb source could not be written in the orb cache directory", "error", err)
+			return &ast.OrbInfo{}, err
+		}
+	}
+
+	logger.Debug("Orb fetched", "version", orbQuery.Version, "duration", time.Since(start))
+
 	latest, latestMinor, latestPatch := GetVersionInfo(
 		orbQuery.Orb.Versions,
 		"v"+orbQuery.Version,
@@ -142,11 +224,18 @@ func fetchOrbInfo(orbVersionCode string, cache *utils.Cache, context *utils.LsCo
 		},
 	}
 
-	cache.OrbCache.SetOrb(orb, orbVersionCode)
-
 	return orb, nil
 }
 
+// Floating versions such as @1 or @volatile are also stored under the
+// version they resolved to, so that an exact reference is a hit
+func storeResolvedOrbVersion(orbVersionCode string, orb *ast.OrbInfo, cache *utils.Cache) {
+	orbName, _, _ := strings.Cut(orbVersionCode, "@")
+	if resolvedOrbID := ast.FormatOrbID(orbName, orb.RemoteInfo.Version); resolvedOrbID != orbVersionCode {
+		cache.OrbCache.SetOrb(orb, resolvedOrbID)
+	}
+}
+
 /**
  * List all versions provided and return the latest minor, patch and major corresponding
  * to the current version.
@@ -267,8 +356,6 @@ func writeRemoteOrbSourceInFSCache(orbYaml string, source string) (string, error
 	_, err := os.Stat(filePath)
 
 	if errors.Is(err, os.ErrNotExist) {
-		fmt.Println("Writing remote orb source in cache:", filePath)
-
 		err = os.WriteFile(filePath, []byte(source), 0644)
 		return filePath, err
 	}
@@ -305,10 +392,14 @@ func AddOrbToCacheWithContent(orb ast.Orb, uri protocol.URI, content []byte, con
 		return err
 	}
 
-	versions, err := GetOrbVersions(orb.Url.GetOrbID(), context.Api.Token, context.Api.HostUrl, context.UserIdForTelemetry)
+	// Offline, the orb is stored without checking for newer versions
+	versions := []struct{ Version string }{}
+	if !context.IsOffline() {
+		versions, err = GetOrbVersions(orb.Url.GetOrbID(), context.Api.Token, context.Api.HostUrl, context.UserIdForTelemetry)
 
-	if err != nil {
-		return nil
+		if err != nil {
+			return nil
+		}
 	}
 
 	latest, latestMinor, latestPatch := GetVersionInfo(versions, "v"+orb.Url.Version)
@@ -90,9 +90,13 @@ func (doc *YamlDocument) parseStep(blockMapping *sitter.Node) []ast.Step {
 	}
 	switch keyName {
 	case "deploy":
-		return []ast.Step{doc.parseDeployStep(valueNode)}
+		runStep := doc.parseDeployStep(valueNode)
+		runStep.MisplacedKeys = doc.getOtherStepKeys(blockMapping, blockMappingPair)
+		return []ast.Step{runStep}
 	case "run":
-		return []ast.Step{doc.parseRunStep(valueNode)}
+		runStep := doc.parseRunStep(valueNode)
+		runStep.MisplacedKeys = doc.getOtherStepKeys(blockMapping, blockMappingPair)
+		return []ast.Step{runStep}
 	case "checkout":
 		return []ast.Step{doc.parseCheckoutStep(valueNode)}
 	case "setup_remote_docker":
@@ -126,6 +130,24 @@ func (doc *YamlDocument) parseStep(blockMapping *sitter.Node) []ast.Step {
 	}
 }
 
+// Keys of the step other than the one of its type, a step has a single key
+func (doc *YamlDocument) getOtherStepKeys(blockMapping *sitter.Node, stepPair *sitter.Node) []ast.TextAndRange {
+	keys := []ast.TextAndRange{}
+	for i := 0; i < int(blockMapping.NamedChildCount()); i++ {
+		child := blockMapping.NamedChild(i)
+		if child.Type() != "block_mapping_pair" || child.Equal(stepPair) {
+			continue
+		}
+
+		keyNode := child.ChildByFieldName("key")
+		if keyNode == nil {
+			continue
+		}
+		keys = append(keys, ast.TextAndRange{Text: doc.GetNodeText(keyNode), Range: doc.NodeToRange(keyNode)})
+	}
+	return keys
+}
+
 func (doc *YamlDocument) parseAnchorStep(blockNode *sitter.Node) []ast.Step {
 	blockMapping := GetChildOfType(blockNode, "block_mapping")
 	blockSequence := GetChildSequence(blockNode)
@@ -265,6 +287,7 @@ func (doc *YamlDocument) parseRunStep(runNode *sitter.Node) ast.Run {
 				res.RawCommand = doc.GetRawNodeText(valueNode)
 			case "shell":
 				res.Shell = doc.GetNodeText(valueNode)
+				res.ShellRange = doc.NodeToRange(valueNode)
 			case "background":
 				res.Background = (doc.GetNodeText(valueNode) == "true")
 			case "working_directory":
@@ -275,7 +298,7 @@ func (doc *YamlDocument) parseRunStep(runNode *sitter.Node) ast.Run {
 				res.When = doc.GetNodeText(valueNode)
 				res.WhenRange = doc.NodeToRange(valueNode)
 			case "environment":
-				res.Environment = doc.parseDictionary(valueNode)
+				res.Environment = doc.parseDictionary(GetChildMapping(valueNode))
 			}
 		})
 		return res
@@ -383,8 +406,10 @@ func (doc *YamlDocument) parseStoreArtifactsStep(storeArtifactsNode *sitter.Node
 		switch keyName {
 		case "path":
 			res.Path = doc.GetNodeText(valueNode)
+			res.PathRange = doc.NodeToRange(valueNode)
 		case "destination":
 			res.Destination = doc.GetNodeText(valueNode)
+			res.DestinationRange = doc.NodeToRange(valueNode)
 		}
 	})
 	return res
@@ -402,6 +427,7 @@ func (doc *YamlDocument) parseStoreTestResultsStep(storeTestResultsNode *sitter.
 		switch keyName {
 		case "path":
 			res.Path = doc.GetNodeText(valueNode)
+			res.PathRange = doc.NodeToRange(valueNode)
 		}
 	})
 	return res
@@ -1,6 +1,12 @@
 package validate
 
-import "go.lsp.dev/protocol"
+import (
+	"fmt"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	sitter "github.com/smacker/go-tree-sitter"
+)
 
 func (val Validate) ValidateAnchors() {
 
@@ -10,11 +16,93 @@ func (val Validate) ValidateAnchors() {
 			continue
 		}
 
-		val.addDiagnostic(protocol.Diagnostic{
-			Severity: protocol.DiagnosticSeverityInformation,
-			Range:    anchor.DefinitionRange,
-			Message:  "Anchor never used",
-			Source:   "cci-language-server",
-		})
+		val.addDiagnostic(utils.RuleUnusedAnchor, utils.CreateUnusedDiagnosticFromRange(anchor.DefinitionRange, "Anchor never used"))
 	}
+
+	val.validateAliases()
+}
+
+// Aliases are checked on the tree of the document, before they are expanded:
+// an alias must reference an anchor defined before it, and the aliases merged
+// with the `<<` key must reference maps
+func (val Validate) validateAliases() {
+	// Start of the definitions of each anchor, an anchor can be defined again
+	// for the next aliases
+	definitions := map[string][]uint32{}
+	names := []string{}
+	parser.ExecQuery(val.Doc.RootNode, "(anchor) @query", func(match *sitter.QueryMatch) {
+		for _, capture := range match.Captures {
+			name := val.Doc.GetNodeText(parser.GetChildOfType(capture.Node, "anchor_name"))
+			if _, ok := definitions[name]; !ok {
+				names = append(names, name)
+			}
+			definitions[name] = append(definitions[name], capture.Node.StartByte())
+		}
+	})
+
+	parser.ExecQuery(val.Doc.RootNode, "(alias) @query", func(match *sitter.QueryMatch) {
+		for _, capture := range match.Captures {
+			alias := capture.Node
+			name := val.Doc.GetNodeText(alias)[1:]
+			rng := val.Doc.NodeToRange(alias)
+
+			starts, ok := definitions[name]
+			if !ok {
+				message := fmt.Sprintf("Anchor %s is not defined", name)
+				if closest, found := utils.FindClosestMatch(name, names); found {
+					message += fmt.Sprintf(", did you mean %s?", closest)
+				}
+				val.addDiagnostic(utils.RuleUnknownAnchor, utils.CreateErrorDiagnosticFromRange(rng, message))
+				continue
+			}
+
+			if starts[0] > alias.StartByte() {
+				val.addDiagnostic(utils.RuleUnknownAnchor, utils.CreateErrorDiagnosticFromRange(
+					rng,
+					fmt.Sprintf("Anchor %s is defined after this alias, anchors must be defined before being referenced", name),
+				))
+				continue
+			}
+
+			if isMergedAlias(val.Doc, alias) && !val.isMappingAnchor(name) {
+				val.addDiagnostic(utils.RuleMergeKey, utils.CreateErrorDiagnosticFromRange(
+					rng,
+					fmt.Sprintf("Anchor %s is not a map, only maps can be merged with <<", name),
+				))
+			}
+		}
+	})
+}
+
+// Whether the alias is the value of a merge key, alone or in a list such as
+// `<<: [*a, *b]`
+func isMergedAlias(doc parser.YamlDocument, alias *sitter.Node) bool {
+	node := alias.Parent()
+	for node != nil && node.Type() != "block_mapping_pair" && node.Type() != "flow_pair" {
+		switch node.Type() {
+		case "flow_node", "block_node", "flow_sequence":
+			node = node.Parent()
+		default:
+			return false
+		}
+	}
+
+	if node == nil {
+		return false
+	}
+
+	key, _ := doc.GetKeyValueNodes(node)
+	return doc.GetNodeText(key) == "<<"
+}
+
+// Anchors defined several times are checked with their last definition, the
+// one recorded when parsing
+func (val Validate) isMappingAnchor(name string) bool {
+	anchor, ok := val.Doc.YamlAnchors[name]
+	if !ok || anchor.ValueNode == nil {
+		return true
+	}
+
+	return parser.GetChildOfType(anchor.ValueNode, "block_mapping") != nil ||
+		parser.GetChildOfType(anchor.ValueNode, "flow_mapping") != nil
 }
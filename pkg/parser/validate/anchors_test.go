@@ -0,0 +1,42 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"go.lsp.dev/protocol"
+)
+
+func TestValidateAnchors(t *testing.T) {
+	val := CreateValidateFromYAML(`version: 2.1
+
+defaults: &defaults
+  docker:
+    - image: cimg/base:2023.01
+unused: &unused
+  working_directory: ~/project
+tag: &tag cimg/base:2023.01
+
+jobs:
+  build:
+    <<: *defualts
+    steps:
+      - checkout
+  test:
+    <<: [*defaults, *tag]
+    environment: *later
+    steps:
+      - checkout
+
+env: &later
+  KEY: value
+`)
+	val.ValidateAnchors()
+
+	CompareDiagnostics(t, &[]protocol.Diagnostic{
+		utils.WithDiagnosticRule(utils.RuleUnusedAnchor, utils.CreateUnusedDiagnosticFromRange(createRange(5, 8, 15), "Anchor never used")),
+		utils.WithDiagnosticRule(utils.RuleUnknownAnchor, utils.CreateErrorDiagnosticFromRange(createRange(11, 8, 17), "Anchor defualts is not defined, did you mean defaults?")),
+		utils.WithDiagnosticRule(utils.RuleMergeKey, utils.CreateErrorDiagnosticFromRange(createRange(15, 20, 24), "Anchor tag is not a map, only maps can be merged with <<")),
+		utils.WithDiagnosticRule(utils.RuleUnknownAnchor, utils.CreateErrorDiagnosticFromRange(createRange(16, 17, 23), "Anchor later is defined after this alias, anchors must be defined before being referenced")),
+	}, val.Diagnostics)
+}
@@ -0,0 +1,186 @@
+package validate
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+var cacheKeyVariables = []string{".Branch", ".Revision", ".BuildNum"}
+
+var cacheKeyFunctions = []string{"checksum", "epoch", "arch"}
+
+// Validates the templates of the keys of the `save_cache` and `restore_cache`
+// steps, such as `v1-{{ .Branch }}-{{ checksum "package-lock.json" }}`
+func (val Validate) ValidateCacheKeys() {
+	val.findCacheSteps(val.Doc.RootNode)
+}
+
+func (val Validate) findCacheSteps(node *sitter.Node) {
+	if node.Type() == "block_mapping_pair" || node.Type() == "flow_pair" {
+		keyNode, valueNode := val.Doc.GetKeyValueNodes(node)
+		step := val.Doc.GetNodeText(keyNode)
+
+		if step == "save_cache" || step == "restore_cache" {
+			val.iterateOnMapping(parser.GetChildMapping(valueNode), func(key string, _ *sitter.Node, value *sitter.Node) {
+				switch {
+				case key == "key":
+					val.validateCacheKey(value)
+				case key == "keys" && step == "restore_cache":
+					for _, item := range val.getSequenceItems(value) {
+						val.validateCacheKey(item)
+					}
+				}
+			})
+			return
+		}
+	}
+
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		val.findCacheSteps(node.NamedChild(i))
+	}
+}
+
+func (val Validate) validateCacheKey(node *sitter.Node) {
+	if node == nil || val.getValueKind(node) != "string" {
+		return
+	}
+
+	text := val.Doc.GetRawNodeText(node)
+	addError := func(start int, end int, message string) {
+		val.addDiagnostic(utils.RuleCacheKey, utils.CreateErrorDiagnosticFromRange(getRangeInNode(node, text, start, end), message))
+	}
+
+	offset := 0
+	for offset < len(text) {
+		open := strings.Index(text[offset:], "{{")
+		stray := strings.Index(text[offset:], "}}")
+		if stray != -1 && (open == -1 || stray < open) {
+			addError(offset+stray, offset+stray+2, "Unexpected }}, templates must be opened with {{")
+			offset += stray + 2
+			continue
+		}
+		if open == -1 {
+			return
+		}
+
+		start := offset + open
+		end := strings.Index(text[start+2:], "}}")
+		if end == -1 {
+			addError(start, len(text), "Unterminated template, missing }}")
+			return
+		}
+		end += start + 4
+
+		if nested := strings.Index(text[start+2:end-2], "{{"); nested != -1 {
+			addError(start, end, "Templates can not be nested")
+		} else {
+			val.validateCacheKeyTemplate(node, text, start, end)
+		}
+		offset = end
+	}
+}
+
+// Checks a single template, the start and end offsets include the braces
+func (val Validate) validateCacheKeyTemplate(node *sitter.Node, text string, start int, end int) {
+	addDiagnostic := func(isWarning bool, message string) {
+		rng := getRangeInNode(node, text, start, end)
+		if isWarning {
+			val.addDiagnostic(utils.RuleCacheKey, utils.CreateWarningDiagnosticFromRange(rng, message))
+		} else {
+			val.addDiagnostic(utils.RuleCacheKey, utils.CreateErrorDiagnosticFromRange(rng, message))
+		}
+	}
+
+	content := strings.TrimSpace(text[start+2 : end-2])
+	// Parameters are replaced before the template is evaluated
+	if strings.Contains(content, "<<") {
+		return
+	}
+
+	if content == "" {
+		addDiagnostic(false, "Empty template")
+		return
+	}
+
+	fields := strings.Fields(content)
+	name := fields[0]
+
+	if strings.HasPrefix(name, ".") {
+		if len(fields) > 1 {
+			addDiagnostic(false, fmt.Sprintf("Unexpected arguments, %s is not a function", name))
+			return
+		}
+
+		if variable, found := strings.CutPrefix(name, ".Environment."); found {
+			if !envVarNameRegex.MatchString(variable) {
+				addDiagnostic(false, fmt.Sprintf("%s is not a valid environment variable name", variable))
+			}
+			return
+		}
+
+		if name == ".Environment" {
+			addDiagnostic(false, "Missing environment variable name, use .Environment.VARIABLE_NAME")
+			return
+		}
+
+		if utils.FindInArray(cacheKeyVariables, name) == -1 {
+			message := fmt.Sprintf("Unknown template variable %s", name)
+			if closest, found := utils.FindClosestMatch(name, cacheKeyVariables); found {
+				message += fmt.Sprintf(", did you mean %s?", closest)
+			}
+			addDiagnostic(false, message)
+		}
+		return
+	}
+
+	switch name {
+	case "epoch", "arch":
+		if len(fields) > 1 {
+			addDiagnostic(false, fmt.Sprintf("%s does not take any argument", name))
+		}
+
+	case "checksum":
+		argument := strings.TrimSpace(strings.TrimPrefix(content, name))
+		if parser.GetFirstChild(node).Type() == "double_quote_scalar" {
+			argument = strings.ReplaceAll(argument, `\"`, `"`)
+		}
+		path, err := strconv.Unquote(argument)
+		if argument == "" || err != nil {
+			addDiagnostic(false, "checksum expects a single quoted file path")
+			return
+		}
+
+		if message := checkChecksumPath(path); message != "" {
+			addDiagnostic(true, message)
+		}
+
+	default:
+		message := fmt.Sprintf("Unknown template function %s", name)
+		if closest, found := utils.FindClosestMatch(name, cacheKeyFunctions); found {
+			message += fmt.Sprintf(", did you mean %s?", closest)
+		}
+		addDiagnostic(false, message)
+	}
+}
+
+// The checksum is the one of a single file, resolved from the working
+// directory of the job
+func checkChecksumPath(path string) string {
+	switch {
+	case strings.TrimSpace(path) == "":
+		return "checksum expects a file path"
+	case strings.ContainsAny(path, "*?["):
+		return fmt.Sprintf("checksum does not expand glob patterns, %s must be the path of a file", path)
+	case strings.HasSuffix(path, "/"):
+		return fmt.Sprintf("checksum expects the path of a file, %s is a directory", path)
+	case path != strings.TrimSpace(path):
+		return fmt.Sprintf("The path %q has leading or trailing spaces", path)
+	}
+
+	return ""
+}
@@ -0,0 +1,115 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"go.lsp.dev/protocol"
+)
+
+func TestValidateCacheKeys(t *testing.T) {
+	testCases := []struct {
+		name        string
+		yamlContent string
+		diagnostics []protocol.Diagnostic
+	}{
+		{
+			name: "Valid templates",
+			yamlContent: `version: 2.1
+
+commands:
+  cached-install:
+    parameters:
+      lockfile:
+        type: string
+    steps:
+      - restore_cache:
+          keys:
+            - v1-{{ arch }}-{{ .Branch }}-{{ checksum "package-lock.json" }}
+            - v1-{{ .Environment.CACHE_VERSION }}-{{ checksum "<< parameters.lockfile >>" }}
+            - v1-
+      - save_cache:
+          key: "v1-{{ .Revision }}-{{ epoch }}-{{ checksum \"go.sum\" }}"
+          paths:
+            - node_modules
+`,
+			diagnostics: []protocol.Diagnostic{},
+		},
+		{
+			name: "Unknown variables and functions",
+			yamlContent: `version: 2.1
+
+jobs:
+  build:
+    docker:
+      - image: cimg/node:lts
+    steps:
+      - restore_cache:
+          key: v1-{{ .Brnch }}-{{ chcksum "package-lock.json" }}-{{ .Environment }}
+      - save_cache:
+          key: v1-{{ .Environment.1VAR }}-{{ epoch 1 }}
+          paths:
+            - node_modules
+`,
+			diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleCacheKey, utils.CreateErrorDiagnosticFromRange(createRange(8, 18, 30), "Unknown template variable .Brnch, did you mean .Branch?")),
+				utils.WithDiagnosticRule(utils.RuleCacheKey, utils.CreateErrorDiagnosticFromRange(createRange(8, 31, 64), "Unknown template function chcksum, did you mean checksum?")),
+				utils.WithDiagnosticRule(utils.RuleCacheKey, utils.CreateErrorDiagnosticFromRange(createRange(8, 65, 83), "Missing environment variable name, use .Environment.VARIABLE_NAME")),
+				utils.WithDiagnosticRule(utils.RuleCacheKey, utils.CreateErrorDiagnosticFromRange(createRange(10, 18, 41), "1VAR is not a valid environment variable name")),
+				utils.WithDiagnosticRule(utils.RuleCacheKey, utils.CreateErrorDiagnosticFromRange(createRange(10, 42, 55), "epoch does not take any argument")),
+			},
+		},
+		{
+			name: "Malformed templates",
+			yamlContent: `version: 2.1
+
+jobs:
+  build:
+    docker:
+      - image: cimg/node:lts
+    steps:
+      - restore_cache:
+          keys:
+            - v1-{{ .Branch }-{{ epoch }}
+            - v1-.Branch }}-{{ }}
+            - v1-{{ checksum package-lock.json }}-{{ .Revision
+`,
+			diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleCacheKey, utils.CreateErrorDiagnosticFromRange(createRange(9, 17, 41), "Templates can not be nested")),
+				utils.WithDiagnosticRule(utils.RuleCacheKey, utils.CreateErrorDiagnosticFromRange(createRange(10, 25, 27), "Unexpected }}, templates must be opened with {{")),
+				utils.WithDiagnosticRule(utils.RuleCacheKey, utils.CreateErrorDiagnosticFromRange(createRange(10, 28, 33), "Empty template")),
+				utils.WithDiagnosticRule(utils.RuleCacheKey, utils.CreateErrorDiagnosticFromRange(createRange(11, 17, 49), "checksum expects a single quoted file path")),
+				utils.WithDiagnosticRule(utils.RuleCacheKey, utils.CreateErrorDiagnosticFromRange(createRange(11, 50, 62), "Unterminated template, missing }}")),
+			},
+		},
+		{
+			name: "Suspicious checksum paths",
+			yamlContent: `version: 2.1
+
+jobs:
+  build:
+    docker:
+      - image: cimg/node:lts
+    steps:
+      - save_cache:
+          key: v1-{{ checksum "**/package-lock.json" }}-{{ checksum "vendor/" }}-{{ checksum "" }}
+          paths:
+            - node_modules
+`,
+			diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleCacheKey, utils.CreateWarningDiagnosticFromRange(createRange(8, 18, 55), "checksum does not expand glob patterns, **/package-lock.json must be the path of a file")),
+				utils.WithDiagnosticRule(utils.RuleCacheKey, utils.CreateWarningDiagnosticFromRange(createRange(8, 56, 80), "checksum expects the path of a file, vendor/ is a directory")),
+				utils.WithDiagnosticRule(utils.RuleCacheKey, utils.CreateWarningDiagnosticFromRange(createRange(8, 81, 98), "checksum expects a file path")),
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			val := CreateValidateFromYAML(tt.yamlContent)
+			val.ValidateCacheKeys()
+
+			CompareDiagnostics(t, &tt.diagnostics, val.Diagnostics)
+		})
+	}
+}
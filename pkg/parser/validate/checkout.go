@@ -0,0 +1,130 @@
+package validate
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"go.lsp.dev/protocol"
+)
+
+// Commands fetching the code without the checkout step
+var gitCheckoutCommand = regexp.MustCompile(`\bgit\s+(clone|init|fetch|checkout)\b`)
+
+// Suggests the checkout step for the jobs running commands that need the code
+// of the project, such as `npm ci`, without checking it out. Only the commands
+// of the `checkoutCommands` option are strong enough signals, and the jobs
+// that could get the code some other way are not reported
+func (val Validate) validateJobCheckout(job ast.Job) {
+	command, found := val.findCheckoutCommand(job.Steps)
+	if !found || val.stepsCheckOut(job.Steps, map[string]bool{}) || val.isCheckedOutByPreSteps(job.Name) {
+		return
+	}
+
+	diagnostic := utils.CreateHintDiagnosticFromRange(
+		job.NameRange,
+		fmt.Sprintf("Job %s runs `%s` without checking out the code, you may want to add the `checkout` step first", job.Name, command),
+	)
+	if edit, ok := val.getInsertCheckoutEdit(job); ok {
+		diagnostic.Data = []protocol.CodeAction{
+			utils.CreateCodeActionTextEdit("Add the checkout step", val.Doc.URI, []protocol.TextEdit{edit}, true),
+		}
+	}
+
+	val.addDiagnostic(utils.RuleMissingCheckout, diagnostic)
+}
+
+// The first command of the run steps that needs the code
+func (val Validate) findCheckoutCommand(steps []ast.Step) (string, bool) {
+	commands := val.Context.GetCheckoutCommands()
+	patterns := make([]*regexp.Regexp, len(commands))
+	for i, command := range commands {
+		// The command starts one of the statements of the script
+		patterns[i] = regexp.MustCompile(`(?m)(^|&&|\|\||[;|(])\s*(sudo\s+)?` + regexp.QuoteMeta(command) + `(\s|$)`)
+	}
+
+	for _, step := range steps {
+		run, ok := step.(ast.Run)
+		if !ok {
+			continue
+		}
+
+		for i, pattern := range patterns {
+			if pattern.MatchString(run.Command) {
+				return commands[i], true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// Whether the steps get the code, or may do so: the orb commands and the
+// steps given as parameters are not known
+func (val Validate) stepsCheckOut(steps []ast.Step, visited map[string]bool) bool {
+	for _, step := range steps {
+		switch step := step.(type) {
+		case ast.Checkout, ast.AttachWorkspace, ast.Steps:
+			return true
+		case ast.Run:
+			if gitCheckoutCommand.MatchString(step.Command) {
+				return true
+			}
+			continue
+		}
+
+		name := step.GetName()
+		if name == "checkout" || name == "attach_workspace" || strings.Contains(name, "<<") || val.Doc.IsOrbReference(name) {
+			return true
+		}
+
+		command, ok := val.Doc.Commands[name]
+		if ok && !visited[name] {
+			visited[name] = true
+			if val.stepsCheckOut(command.Steps, visited) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (val Validate) isCheckedOutByPreSteps(jobName string) bool {
+	for _, workflow := range val.Doc.Workflows {
+		for _, jobRef := range workflow.JobRefs {
+			if jobRef.JobName == jobName && val.stepsCheckOut(jobRef.PreSteps, map[string]bool{}) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Inserts the checkout step before the first step, with its indentation. The
+// steps written as a flow sequence are not edited
+func (val Validate) getInsertCheckoutEdit(job ast.Job) (protocol.TextEdit, bool) {
+	if len(job.Steps) == 0 {
+		return protocol.TextEdit{}, false
+	}
+
+	line := job.Steps[0].GetRange().Start.Line
+	lines := strings.Split(string(val.Doc.Content), "\n")
+	if int(line) >= len(lines) {
+		return protocol.TextEdit{}, false
+	}
+
+	indentation := len(lines[line]) - len(strings.TrimLeft(lines[line], " "))
+	if !strings.HasPrefix(lines[line][indentation:], "-") {
+		return protocol.TextEdit{}, false
+	}
+
+	position := protocol.Position{Line: line, Character: uint32(indentation)}
+	return protocol.TextEdit{
+		Range:   protocol.Range{Start: position, End: position},
+		NewText: "- checkout\n" + strings.Repeat(" ", indentation),
+	}, true
+}
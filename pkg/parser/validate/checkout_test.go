@@ -0,0 +1,95 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"go.lsp.dev/protocol"
+)
+
+const checkoutYAML = `version: 2.1
+
+commands:
+  setup:
+    steps:
+      - checkout
+
+jobs:
+  build:
+    docker:
+      - image: cimg/node:20.0
+    steps:
+      - run: echo "Building"
+      - run: npm ci && npm run build
+  test:
+    docker:
+      - image: cimg/node:20.0
+    steps:
+      - setup
+      - run: npm test
+  deploy:
+    docker:
+      - image: cimg/node:20.0
+    steps:
+      - attach_workspace:
+          at: .
+      - run: make deploy
+  lint:
+    docker:
+      - image: cimg/node:20.0
+    steps:
+      - run: npx eslint .
+
+workflows:
+  main:
+    jobs:
+      - build
+      - test
+      - deploy
+      - lint
+`
+
+func TestValidateJobCheckout(t *testing.T) {
+	val := CreateValidateFromYAML(checkoutYAML)
+	for _, job := range val.Doc.Jobs {
+		val.validateJobCheckout(job)
+	}
+
+	diagnostic := utils.CreateHintDiagnosticFromRange(
+		createRange(8, 2, 7),
+		"Job build runs `npm ci` without checking out the code, you may want to add the `checkout` step first",
+	)
+	position := protocol.Position{Line: 12, Character: 6}
+	diagnostic.Data = []protocol.CodeAction{
+		utils.CreateCodeActionTextEdit("Add the checkout step", val.Doc.URI, []protocol.TextEdit{{
+			Range:   protocol.Range{Start: position, End: position},
+			NewText: "- checkout\n      ",
+		}}, true),
+	}
+
+	CompareDiagnostics(t, &[]protocol.Diagnostic{
+		utils.WithDiagnosticRule(utils.RuleMissingCheckout, diagnostic),
+	}, val.Diagnostics)
+}
+
+func TestValidateJobCheckoutCommands(t *testing.T) {
+	val := CreateValidateFromYAML(checkoutYAML)
+	val.Context.CheckoutCommands = []string{}
+	for _, job := range val.Doc.Jobs {
+		val.validateJobCheckout(job)
+	}
+
+	CompareDiagnostics(t, &[]protocol.Diagnostic{}, val.Diagnostics)
+
+	val = CreateValidateFromYAML(checkoutYAML)
+	val.Context.CheckoutCommands = []string{"npx eslint"}
+	for _, job := range val.Doc.Jobs {
+		if job.Name == "lint" {
+			val.validateJobCheckout(job)
+		}
+	}
+
+	if len(*val.Diagnostics) != 1 || (*val.Diagnostics)[0].Code != utils.RuleMissingCheckout {
+		t.Errorf("Expected a %s diagnostic for the lint job, got %v", utils.RuleMissingCheckout, *val.Diagnostics)
+	}
+}
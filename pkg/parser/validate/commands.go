@@ -8,6 +8,7 @@ import (
 func (val Validate) ValidateCommands() {
 	if len(val.Doc.Commands) == 0 && !utils.IsDefaultRange(val.Doc.CommandsRange) {
 		val.addDiagnostic(
+			utils.RuleEmptySection,
 			utils.CreateEmptyAssignationWarning(val.Doc.CommandsRange),
 		)
 
@@ -51,9 +52,9 @@ func (val Validate) checkIfCommandIsUsed(command ast.Command) bool {
 		}
 	}
 
-	return false
+	return val.checkIfUsedThroughParameters(command.Name)
 }
 
 func (val Validate) commandIsUnused(command ast.Command) {
-	val.addDiagnostic(utils.CreateWarningDiagnosticFromRange(command.NameRange, "Command is unused"))
+	val.addUnusedDiagnostic(utils.RuleUnusedCommand, command.NameRange, "Command is unused")
 }
@@ -0,0 +1,329 @@
+package validate
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	sitter "github.com/smacker/go-tree-sitter"
+	"go.lsp.dev/protocol"
+)
+
+var logicOperators = []string{"and", "or", "not", "equal", "matches"}
+
+var interpolationRegex = regexp.MustCompile(`<<\s*([^<>]*?)\s*>>`)
+
+// Validates the logic statements of the `when` and `unless` keys of the
+// workflows and of the steps, along with the parameters and the pipeline
+// values they reference
+func (val Validate) ValidateConditions() {
+	workflowConditions := map[uint32]bool{}
+
+	val.iterateOnWorkflowConditions(func(keyNode *sitter.Node, condition *sitter.Node) {
+		workflowConditions[keyNode.StartByte()] = true
+		val.validateLogicStatement(keyNode, condition, true)
+	})
+
+	val.findStepConditions(val.Doc.RootNode, workflowConditions)
+}
+
+func (val Validate) iterateOnWorkflowConditions(fn func(keyNode *sitter.Node, condition *sitter.Node)) {
+	rootMapping := parser.GetBlockMappingNode(val.Doc.RootNode)
+
+	val.iterateOnMapping(rootMapping, func(key string, _ *sitter.Node, value *sitter.Node) {
+		if key != "workflows" {
+			return
+		}
+
+		val.iterateOnMapping(parser.GetChildMapping(value), func(_ string, _ *sitter.Node, workflow *sitter.Node) {
+			val.iterateOnMapping(parser.GetChildMapping(workflow), func(key string, keyNode *sitter.Node, condition *sitter.Node) {
+				if key == "when" || key == "unless" {
+					fn(keyNode, condition)
+				}
+			})
+		})
+	})
+}
+
+// Ranges of the logic statements of the workflows, their references are
+// checked with the workflow scope rules rather than with the other
+// interpolations of the document
+func (val Validate) getWorkflowConditionRanges() []protocol.Range {
+	ranges := []protocol.Range{}
+	val.iterateOnWorkflowConditions(func(_ *sitter.Node, condition *sitter.Node) {
+		if condition != nil {
+			ranges = append(ranges, val.Doc.NodeToRange(condition))
+		}
+	})
+	return ranges
+}
+
+// Conditional steps hold their logic statement in their `condition` key
+func (val Validate) findStepConditions(node *sitter.Node, workflowConditions map[uint32]bool) {
+	if node.Type() == "block_mapping_pair" || node.Type() == "flow_pair" {
+		keyNode, valueNode := val.Doc.GetKeyValueNodes(node)
+		key := val.Doc.GetNodeText(keyNode)
+
+		if (key == "when" || key == "unless") && !workflowConditions[keyNode.StartByte()] {
+			val.iterateOnMapping(parser.GetChildMapping(valueNode), func(key string, keyNode *sitter.Node, condition *sitter.Node) {
+				if key == "condition" {
+					val.validateLogicStatement(keyNode, condition, false)
+				}
+			})
+		}
+	}
+
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		val.findStepConditions(node.NamedChild(i), workflowConditions)
+	}
+}
+
+// A logic statement is either a literal or a mapping with a single
+// operator. The key node is the one holding the statement, used to anchor
+// the diagnostics of empty statements. Statements of the workflows are
+// evaluated before any job runs, they can only reference the pipeline
+func (val Validate) validateLogicStatement(keyNode *sitter.Node, statement *sitter.Node, inWorkflow bool) {
+	if statement == nil {
+		val.addDiagnostic(utils.RuleCondition, utils.CreateErrorDiagnosticFromRange(
+			val.Doc.NodeToRange(keyNode),
+			"Missing condition"))
+		return
+	}
+
+	mapping := parser.GetChildMapping(statement)
+	if mapping == nil {
+		if parser.GetChildSequence(statement) != nil {
+			val.addDiagnostic(utils.RuleCondition, utils.CreateErrorDiagnosticFromRange(
+				val.Doc.NodeToRange(statement),
+				"A condition can not be a list, use `and` or `or` to combine conditions"))
+			return
+		}
+
+		val.validateConditionReferences(statement, inWorkflow)
+		return
+	}
+
+	operators := 0
+	val.iterateOnMapping(mapping, func(operator string, operatorNode *sitter.Node, operand *sitter.Node) {
+		operators++
+		if operators == 2 {
+			val.addDiagnostic(utils.RuleCondition, utils.CreateErrorDiagnosticFromRange(
+				val.Doc.NodeToRange(operatorNode),
+				"A condition must have a single operator, use `and` or `or` to combine conditions"))
+		}
+
+		switch operator {
+		case "and", "or":
+			conditions := val.getSequenceItems(operand)
+			if len(conditions) == 0 {
+				val.addDiagnostic(utils.RuleCondition, utils.CreateErrorDiagnosticFromRange(
+					val.Doc.NodeToRange(operatorNode),
+					fmt.Sprintf("`%s` expects a list of conditions", operator)))
+				return
+			}
+
+			for _, condition := range conditions {
+				val.validateLogicStatement(operatorNode, condition, inWorkflow)
+			}
+
+		case "not":
+			if operand != nil && parser.GetChildSequence(operand) != nil {
+				val.addDiagnostic(utils.RuleCondition, utils.CreateErrorDiagnosticFromRange(
+					val.Doc.NodeToRange(operatorNode),
+					"`not` expects a single condition"))
+				return
+			}
+			val.validateLogicStatement(operatorNode, operand, inWorkflow)
+
+		case "equal":
+			values := val.getSequenceItems(operand)
+			if len(values) < 2 {
+				val.addDiagnostic(utils.RuleCondition, utils.CreateErrorDiagnosticFromRange(
+					val.Doc.NodeToRange(operatorNode),
+					"`equal` expects a list of at least two values"))
+				return
+			}
+
+			for _, value := range values {
+				val.validateConditionReferences(value, inWorkflow)
+			}
+
+		case "matches":
+			val.validateMatchesOperator(operatorNode, operand, inWorkflow)
+
+		default:
+			val.addDiagnostic(utils.RuleCondition, utils.CreateErrorDiagnosticFromRange(
+				val.Doc.NodeToRange(operatorNode),
+				fmt.Sprintf("Unknown logic operator %s, expected one of %s", operator, strings.Join(logicOperators, ", "))))
+		}
+	})
+}
+
+// `matches` expects a pattern and the value to match against it
+func (val Validate) validateMatchesOperator(operatorNode *sitter.Node, operand *sitter.Node, inWorkflow bool) {
+	var pattern, value *sitter.Node
+	val.iterateOnMapping(parser.GetChildMapping(operand), func(key string, keyNode *sitter.Node, valueNode *sitter.Node) {
+		switch key {
+		case "pattern":
+			pattern = valueNode
+		case "value":
+			value = valueNode
+		default:
+			val.addDiagnostic(utils.RuleCondition, utils.CreateErrorDiagnosticFromRange(
+				val.Doc.NodeToRange(keyNode),
+				fmt.Sprintf("Unknown key %s, `matches` expects a pattern and a value", key)))
+		}
+	})
+
+	if pattern == nil || value == nil {
+		val.addDiagnostic(utils.RuleCondition, utils.CreateErrorDiagnosticFromRange(
+			val.Doc.NodeToRange(operatorNode),
+			"`matches` expects a pattern and a value"))
+		return
+	}
+
+	if err := checkPatternSyntax(val.Doc.GetNodeText(pattern)); err != nil {
+		val.addDiagnostic(utils.RuleCondition, utils.CreateErrorDiagnosticFromRange(
+			val.Doc.NodeToRange(pattern),
+			fmt.Sprintf("Invalid pattern: %s", err.Error())))
+	}
+
+	val.validateConditionReferences(pattern, inWorkflow)
+	val.validateConditionReferences(value, inWorkflow)
+}
+
+// Patterns are Java regular expressions, only the errors that Go regular
+// expressions share with them are reported: the features Go does not support,
+// such as lookarounds, are not errors
+func checkPatternSyntax(pattern string) error {
+	if strings.Contains(pattern, "<<") {
+		return nil
+	}
+
+	_, err := syntax.Parse(pattern, syntax.Perl)
+	if err, ok := err.(*syntax.Error); ok {
+		switch err.Code {
+		case syntax.ErrMissingParen, syntax.ErrUnexpectedParen, syntax.ErrMissingBracket,
+			syntax.ErrMissingRepeatArgument, syntax.ErrTrailingBackslash, syntax.ErrInvalidCharRange:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// The parameters of the steps are checked along with the other
+// interpolations of the document. In the workflows, only the pipeline
+// parameters are in scope
+func (val Validate) validateConditionReferences(node *sitter.Node, inWorkflow bool) {
+	text := val.Doc.GetRawNodeText(node)
+
+	for _, match := range interpolationRegex.FindAllStringSubmatchIndex(text, -1) {
+		reference := text[match[2]:match[3]]
+		isParameter := strings.HasPrefix(reference, "parameters.")
+		pipelineParameter, isPipelineParameter := strings.CutPrefix(reference, "pipeline.parameters.")
+		if !inWorkflow && (isParameter || isPipelineParameter) {
+			continue
+		}
+
+		message := ""
+		if isPipelineParameter {
+			if _, ok := val.Doc.PipelineParameters[pipelineParameter]; ok {
+				continue
+			}
+
+			message = fmt.Sprintf("Pipeline parameter %s is not defined", pipelineParameter)
+			if closest, found := utils.FindClosestMatch(pipelineParameter, getParameterNames(val.Doc.PipelineParameters)); found {
+				message += fmt.Sprintf(", did you mean %s?", closest)
+			}
+		} else if isParameter {
+			name := strings.TrimPrefix(reference, "parameters.")
+			message = fmt.Sprintf("Parameter %s is not in scope, workflow conditions can only reference pipeline parameters and pipeline values", name)
+			if _, ok := val.Doc.PipelineParameters[name]; ok {
+				message += fmt.Sprintf(", did you mean pipeline.parameters.%s?", name)
+			}
+		} else if value, found := strings.CutPrefix(reference, "pipeline."); found {
+			if utils.IsPipelineValue(value) {
+				continue
+			}
+
+			message = fmt.Sprintf("Unknown pipeline value %s", reference)
+			if closest, found := utils.FindClosestMatch(value, utils.GetPipelineValueNames()); found {
+				message += fmt.Sprintf(", did you mean pipeline.%s?", closest)
+			}
+		} else if inWorkflow {
+			message = fmt.Sprintf("Unknown reference %s, workflow conditions can only reference pipeline parameters and pipeline values", reference)
+		} else {
+			message = fmt.Sprintf("Unknown reference %s, conditions can only reference parameters and pipeline values", reference)
+		}
+
+		val.addDiagnostic(utils.RuleCondition, utils.CreateErrorDiagnosticFromRange(
+			getRangeInNode(node, text, match[2], match[3]),
+			message))
+	}
+}
+
+func (val Validate) iterateOnMapping(mapping *sitter.Node, fn func(key string, keyNode *sitter.Node, valueNode *sitter.Node)) {
+	if mapping == nil {
+		return
+	}
+
+	for i := 0; i < int(mapping.NamedChildCount()); i++ {
+		child := mapping.NamedChild(i)
+		if child.Type() != "block_mapping_pair" && child.Type() != "flow_pair" {
+			continue
+		}
+
+		keyNode, valueNode := val.Doc.GetKeyValueNodes(child)
+		if keyNode == nil {
+			continue
+		}
+		fn(val.Doc.GetNodeText(keyNode), keyNode, valueNode)
+	}
+}
+
+// Items of a block or flow sequence, nil when the node is not a sequence
+func (val Validate) getSequenceItems(node *sitter.Node) []*sitter.Node {
+	sequence := parser.GetChildSequence(node)
+	if sequence == nil {
+		return nil
+	}
+
+	items := []*sitter.Node{}
+	for i := 0; i < int(sequence.NamedChildCount()); i++ {
+		item := sequence.NamedChild(i)
+		switch item.Type() {
+		case "block_sequence_item":
+			var value *sitter.Node
+			for j := 0; j < int(item.NamedChildCount()); j++ {
+				if item.NamedChild(j).Type() != "comment" {
+					value = item.NamedChild(j)
+					break
+				}
+			}
+			items = append(items, value)
+		case "flow_node":
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+func getRangeInNode(node *sitter.Node, text string, start int, end int) protocol.Range {
+	toDocumentPosition := func(pos protocol.Position) protocol.Position {
+		if pos.Line == 0 {
+			pos.Character += node.StartPoint().Column
+		}
+		pos.Line += node.StartPoint().Row
+		return pos
+	}
+
+	return protocol.Range{
+		Start: toDocumentPosition(utils.IndexToPos(start, []byte(text))),
+		End:   toDocumentPosition(utils.IndexToPos(end, []byte(text))),
+	}
+}
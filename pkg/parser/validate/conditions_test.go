@@ -0,0 +1,169 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"go.lsp.dev/protocol"
+)
+
+func TestValidateConditions(t *testing.T) {
+	createRange := func(line uint32, start uint32, end uint32) protocol.Range {
+		return protocol.Range{
+			Start: protocol.Position{Line: line, Character: start},
+			End:   protocol.Position{Line: line, Character: end},
+		}
+	}
+
+	testCases := []struct {
+		name        string
+		yamlContent string
+		diagnostics []protocol.Diagnostic
+	}{
+		{
+			name: "Nested logic operators",
+			yamlContent: `version: 2.1
+
+parameters:
+  deploy:
+    type: boolean
+    default: false
+
+jobs:
+  build:
+    machine: true
+    steps:
+      - when:
+          condition:
+            or:
+              - << pipeline.parameters.deploy >>
+              - { equal: [main, << pipeline.git.branch >>] }
+              - { equal: [main, << pipeline.trigger_parameters.gitlab.branch >>] }
+          steps:
+            - checkout
+
+workflows:
+  main:
+    when:
+      and:
+        - not:
+            equal: [scheduled_pipeline, << pipeline.trigger_source >>]
+        - or:
+            - matches: { pattern: "^release-.*$", value: << pipeline.git.branch >> }
+            - << pipeline.parameters.deploy >>
+            - equal: [main, << pipeline.trigger_parameters.gitlab.branch >>]
+            - equal: [schedule, << pipeline.trigger_parameters.circleci.trigger_type >>]
+            - equal: [push, << pipeline.event.name >>]
+    jobs:
+      - build
+`,
+			diagnostics: []protocol.Diagnostic{},
+		},
+		{
+			name: "Structurally invalid conditions",
+			yamlContent: `version: 2.1
+
+jobs:
+  build:
+    machine: true
+    steps:
+      - unless:
+          condition:
+            and:
+              - equal: [main]
+              - equals: [main, << pipeline.git.branch >>]
+              - matches:
+                  pattern: "(main"
+                  value: << pipeline.git.branch >>
+              - matches: { pattern: "main" }
+          steps:
+            - checkout
+
+workflows:
+  main:
+    when:
+      not: [true]
+      or: []
+    jobs:
+      - build
+`,
+			diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleCondition, utils.CreateErrorDiagnosticFromRange(createRange(9, 16, 21), "`equal` expects a list of at least two values")),
+				utils.WithDiagnosticRule(utils.RuleCondition, utils.CreateErrorDiagnosticFromRange(createRange(10, 16, 22), "Unknown logic operator equals, expected one of and, or, not, equal, matches")),
+				utils.WithDiagnosticRule(utils.RuleCondition, utils.CreateErrorDiagnosticFromRange(createRange(12, 27, 34), "Invalid pattern: error parsing regexp: missing closing ): `(main`")),
+				utils.WithDiagnosticRule(utils.RuleCondition, utils.CreateErrorDiagnosticFromRange(createRange(14, 16, 23), "`matches` expects a pattern and a value")),
+				utils.WithDiagnosticRule(utils.RuleCondition, utils.CreateErrorDiagnosticFromRange(createRange(21, 6, 9), "`not` expects a single condition")),
+				utils.WithDiagnosticRule(utils.RuleCondition, utils.CreateErrorDiagnosticFromRange(createRange(22, 6, 8), "A condition must have a single operator, use `and` or `or` to combine conditions")),
+				utils.WithDiagnosticRule(utils.RuleCondition, utils.CreateErrorDiagnosticFromRange(createRange(22, 6, 8), "`or` expects a list of conditions")),
+			},
+		},
+		{
+			name: "Invalid references",
+			yamlContent: `version: 2.1
+
+workflows:
+  main:
+    unless:
+      or:
+        - equal: [main, << pipeline.git.brnch >>]
+        - << parameter.deploy >>
+        - equal: [main, << pipeline.trigger_parameters >>]
+    jobs:
+      - build
+`,
+			diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleCondition, utils.CreateErrorDiagnosticFromRange(createRange(6, 27, 45), "Unknown pipeline value pipeline.git.brnch, did you mean pipeline.git.branch?")),
+				utils.WithDiagnosticRule(utils.RuleCondition, utils.CreateErrorDiagnosticFromRange(createRange(7, 13, 29), "Unknown reference parameter.deploy, workflow conditions can only reference pipeline parameters and pipeline values")),
+				utils.WithDiagnosticRule(utils.RuleCondition, utils.CreateErrorDiagnosticFromRange(createRange(8, 27, 54), "Unknown pipeline value pipeline.trigger_parameters")),
+			},
+		},
+		{
+			name: "References out of the workflow scope",
+			yamlContent: `version: 2.1
+
+parameters:
+  deploy:
+    type: boolean
+    default: false
+
+jobs:
+  build:
+    parameters:
+      target:
+        type: string
+    machine: true
+    steps:
+      - when:
+          condition: << parameters.target >>
+          steps:
+            - checkout
+
+workflows:
+  main:
+    when:
+      or:
+        - << pipeline.parameters.deploy >>
+        - << parameters.deploy >>
+        - << parameters.target >>
+        - << pipeline.parameters.deplyo >>
+    jobs:
+      - build:
+          target: linux
+`,
+			diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleCondition, utils.CreateErrorDiagnosticFromRange(createRange(24, 13, 30), "Parameter deploy is not in scope, workflow conditions can only reference pipeline parameters and pipeline values, did you mean pipeline.parameters.deploy?")),
+				utils.WithDiagnosticRule(utils.RuleCondition, utils.CreateErrorDiagnosticFromRange(createRange(25, 13, 30), "Parameter target is not in scope, workflow conditions can only reference pipeline parameters and pipeline values")),
+				utils.WithDiagnosticRule(utils.RuleCondition, utils.CreateErrorDiagnosticFromRange(createRange(26, 13, 39), "Pipeline parameter deplyo is not defined, did you mean deploy?")),
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			val := CreateValidateFromYAML(tt.yamlContent)
+			val.ValidateConditions()
+
+			CompareDiagnostics(t, &tt.diagnostics, val.Diagnostics)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package validate
+
+import (
+	"fmt"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"go.lsp.dev/protocol"
+)
+
+// Top-level keys only available from version 2.1, with the name of what they
+// define
+var version21Keys = map[string]string{
+	"orbs":       "Orbs",
+	"commands":   "Commands",
+	"executors":  "Executors",
+	"parameters": "Pipeline parameters",
+}
+
+type topLevelKey struct {
+	keyRange   protocol.Range
+	value      string
+	valueRange protocol.Range
+}
+
+// Checks that the configuration declares a supported version, and that
+// configurations of version 2 do not use what was introduced by 2.1
+func (val Validate) ValidateVersion() {
+	keys, ok := val.getTopLevelKeys()
+	if !ok {
+		return
+	}
+
+	version, ok := keys["version"]
+	if !ok {
+		val.addDiagnostic(utils.RuleVersion, utils.CreateErrorDiagnosticFromRange(
+			protocol.Range{},
+			"Missing version, add `version: 2.1` at the top of the configuration"))
+		return
+	}
+
+	switch version.value {
+	case "2.1":
+	case "2", "2.0":
+		val.validateVersion2Features(keys)
+	case "":
+		val.addDiagnostic(utils.RuleVersion, utils.CreateErrorDiagnosticFromRange(
+			version.keyRange,
+			"Missing version value, use 2.1"))
+	default:
+		val.addDiagnostic(utils.RuleVersion, utils.CreateErrorDiagnosticFromRange(
+			version.valueRange,
+			fmt.Sprintf("Unsupported version %s, use 2.1", version.value)))
+	}
+}
+
+func (val Validate) validateVersion2Features(keys map[string]topLevelKey) {
+	addWarning := func(rng protocol.Range, feature string) {
+		val.addDiagnostic(utils.RuleVersion, utils.CreateWarningDiagnosticFromRange(
+			rng,
+			fmt.Sprintf("%s are only supported from version 2.1, set `version: 2.1` to use them", feature)))
+	}
+
+	for name, feature := range version21Keys {
+		if key, ok := keys[name]; ok {
+			addWarning(key.keyRange, feature)
+		}
+	}
+
+	for _, job := range val.Doc.Jobs {
+		if !utils.IsDefaultRange(job.ParametersRange) {
+			addWarning(job.ParametersRange, "Job parameters")
+		}
+	}
+
+	for _, workflow := range val.Doc.Workflows {
+		for _, jobRef := range workflow.JobRefs {
+			if jobRef.HasMatrix {
+				addWarning(jobRef.JobNameRange, "Matrix jobs")
+			}
+		}
+	}
+}
+
+// Keys at the root of the document, false when the document is not a map
+func (val Validate) getTopLevelKeys() (map[string]topLevelKey, bool) {
+	mapping := parser.GetBlockMappingNode(val.Doc.RootNode)
+	if mapping == nil {
+		return nil, false
+	}
+
+	keys := map[string]topLevelKey{}
+	for i := 0; i < int(mapping.NamedChildCount()); i++ {
+		pair := mapping.NamedChild(i)
+		if pair.Type() != "block_mapping_pair" {
+			continue
+		}
+
+		keyNode, valueNode := val.Doc.GetKeyValueNodes(pair)
+		if keyNode == nil {
+			continue
+		}
+
+		key := topLevelKey{keyRange: val.Doc.NodeToRange(keyNode)}
+		if valueNode != nil {
+			key.value = val.Doc.GetNodeText(valueNode)
+			key.valueRange = val.Doc.NodeToRange(valueNode)
+		}
+		keys[val.Doc.GetNodeText(keyNode)] = key
+	}
+
+	return keys, true
+}
@@ -0,0 +1,121 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"go.lsp.dev/protocol"
+)
+
+func TestValidateVersion(t *testing.T) {
+	jobs := `jobs:
+  build:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - checkout
+
+workflows:
+  main:
+    jobs:
+      - build
+`
+
+	testCases := []struct {
+		name        string
+		yamlContent string
+		diagnostics []protocol.Diagnostic
+	}{
+		{
+			name:        "Valid 2.1 configuration",
+			yamlContent: "version: 2.1\n\norbs:\n  node: circleci/node@5.1.0\n\n" + jobs,
+			diagnostics: []protocol.Diagnostic{},
+		},
+		{
+			name:        "Quoted version",
+			yamlContent: "version: \"2.1\"\n\n" + jobs,
+			diagnostics: []protocol.Diagnostic{},
+		},
+		{
+			name:        "Version 2 without 2.1 features",
+			yamlContent: "version: 2\n\n" + jobs,
+			diagnostics: []protocol.Diagnostic{},
+		},
+		{
+			name:        "Missing version",
+			yamlContent: jobs,
+			diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleVersion, utils.CreateErrorDiagnosticFromRange(
+					createRange(0, 0, 0),
+					"Missing version, add `version: 2.1` at the top of the configuration")),
+			},
+		},
+		{
+			name:        "Missing version value",
+			yamlContent: "version:\n\n" + jobs,
+			diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleVersion, utils.CreateErrorDiagnosticFromRange(
+					createRange(0, 0, 7),
+					"Missing version value, use 2.1")),
+			},
+		},
+		{
+			name:        "Unsupported version",
+			yamlContent: "version: 3\n\n" + jobs,
+			diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleVersion, utils.CreateErrorDiagnosticFromRange(
+					createRange(0, 9, 10),
+					"Unsupported version 3, use 2.1")),
+			},
+		},
+		{
+			name: "Version 2 with orbs",
+			yamlContent: `version: 2
+
+orbs:
+  node: circleci/node@5.1.0
+
+jobs:
+  build:
+    parameters:
+      target:
+        type: string
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - checkout
+
+workflows:
+  main:
+    jobs:
+      - build:
+          matrix:
+            parameters:
+              target: [a, b]
+`,
+			diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleVersion, utils.CreateWarningDiagnosticFromRange(
+					createRange(2, 0, 4),
+					"Orbs are only supported from version 2.1, set `version: 2.1` to use them")),
+				utils.WithDiagnosticRule(utils.RuleVersion, utils.CreateWarningDiagnosticFromRange(
+					protocol.Range{
+						Start: protocol.Position{Line: 7, Character: 4},
+						End:   protocol.Position{Line: 9, Character: 20},
+					},
+					"Job parameters are only supported from version 2.1, set `version: 2.1` to use them")),
+				utils.WithDiagnosticRule(utils.RuleVersion, utils.CreateWarningDiagnosticFromRange(
+					createRange(18, 8, 13),
+					"Matrix jobs are only supported from version 2.1, set `version: 2.1` to use them")),
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			val := CreateValidateFromYAML(tt.yamlContent)
+			val.ValidateVersion()
+
+			CompareDiagnostics(t, &tt.diagnostics, val.Diagnostics)
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package validate
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type cronField struct {
+	name string
+	min  int
+	max  int
+}
+
+var cronFields = []cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 6},
+}
+
+type cronIssue struct {
+	// Offset and length of the faulty part of the expression
+	offset    int
+	length    int
+	message   string
+	isWarning bool
+}
+
+// Checks a cron expression against the POSIX crontab syntax used by CircleCI:
+// five fields separated by spaces, each one being a list of values, ranges
+// and steps. Names of months and days, macros such as @daily and the L, W, #
+// and ? characters are not supported
+func checkCron(expression string) []cronIssue {
+	fields, offsets := splitCronFields(expression)
+
+	if len(fields) != len(cronFields) {
+		return []cronIssue{{
+			offset: 0,
+			length: len(expression),
+			message: fmt.Sprintf(
+				"Cron expression must have %d fields (minute, hour, day of month, month, day of week), found %d",
+				len(cronFields),
+				len(fields),
+			),
+		}}
+	}
+
+	issues := []cronIssue{}
+	for i, field := range fields {
+		issues = append(issues, checkCronField(field, offsets[i], cronFields[i])...)
+	}
+
+	if len(issues) == 0 && fields[2] != "*" && fields[4] != "*" {
+		issues = append(issues, cronIssue{
+			offset:    0,
+			length:    len(expression),
+			message:   "Both day of month and day of week are set, the workflow will run when either of them matches",
+			isWarning: true,
+		})
+	}
+
+	return issues
+}
+
+func splitCronFields(expression string) ([]string, []int) {
+	fields := []string{}
+	offsets := []int{}
+
+	start := -1
+	for i, char := range expression + " " {
+		isSpace := char == ' ' || char == '\t'
+		if !isSpace && start == -1 {
+			start = i
+		} else if isSpace && start != -1 {
+			fields = append(fields, expression[start:i])
+			offsets = append(offsets, start)
+			start = -1
+		}
+	}
+
+	return fields, offsets
+}
+
+func checkCronField(field string, offset int, definition cronField) []cronIssue {
+	issues := []cronIssue{}
+
+	for _, item := range strings.Split(field, ",") {
+		if item == "" {
+			issues = append(issues, cronIssue{
+				offset:  offset,
+				length:  1,
+				message: fmt.Sprintf("Empty value in the %s list", definition.name),
+			})
+		} else if issue, found := checkCronItem(item, offset, definition); found {
+			issues = append(issues, issue)
+		}
+
+		offset += len(item) + 1
+	}
+
+	return issues
+}
+
+// An item is either `*`, a value or a range of values, optionally followed
+// by a step for `*` and ranges
+func checkCronItem(item string, offset int, definition cronField) (cronIssue, bool) {
+	base, step, hasStep := strings.Cut(item, "/")
+
+	if hasStep {
+		stepOffset := offset + len(base) + 1
+		value, err := strconv.Atoi(step)
+		if err != nil || value < 1 || value > definition.max {
+			return cronIssue{
+				offset:  stepOffset,
+				length:  len(step),
+				message: fmt.Sprintf("Invalid %s step %s, must be between 1 and %d", definition.name, step, definition.max),
+			}, true
+		}
+
+		if base != "*" && !strings.Contains(base, "-") {
+			return cronIssue{
+				offset:  offset,
+				length:  len(item),
+				message: fmt.Sprintf("Steps can only be applied to * or to a range, not to %s", base),
+			}, true
+		}
+	}
+
+	if base == "*" {
+		return cronIssue{}, false
+	}
+
+	start, end, isRange := strings.Cut(base, "-")
+	startValue, issue, found := checkCronValue(start, offset, definition)
+	if found || !isRange {
+		return issue, found
+	}
+
+	endValue, issue, found := checkCronValue(end, offset+len(start)+1, definition)
+	if found {
+		return issue, found
+	}
+
+	if startValue > endValue {
+		return cronIssue{
+			offset:  offset,
+			length:  len(base),
+			message: fmt.Sprintf("Invalid %s range %s, its start is greater than its end", definition.name, base),
+		}, true
+	}
+
+	return cronIssue{}, false
+}
+
+func checkCronValue(value string, offset int, definition cronField) (int, cronIssue, bool) {
+	number, err := strconv.Atoi(value)
+	if err != nil || number < definition.min || number > definition.max {
+		return 0, cronIssue{
+			offset:  offset,
+			length:  len(value),
+			message: fmt.Sprintf("Invalid %s %s, must be between %d and %d", definition.name, value, definition.min, definition.max),
+		}, true
+	}
+
+	return number, cronIssue{}, false
+}
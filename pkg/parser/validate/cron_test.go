@@ -0,0 +1,79 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckCron(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		want       []cronIssue
+	}{
+		{
+			name:       "Valid expression",
+			expression: "0 0 * * *",
+			want:       []cronIssue{},
+		},
+		{
+			name:       "Valid lists, ranges and steps",
+			expression: "*/15 9-17/2 * 1,6-8 1-5",
+			want:       []cronIssue{},
+		},
+		{
+			name:       "Wrong number of fields",
+			expression: "0 0 * *",
+			want: []cronIssue{
+				{0, 7, "Cron expression must have 5 fields (minute, hour, day of month, month, day of week), found 4", false},
+			},
+		},
+		{
+			name:       "Macros are not supported",
+			expression: "@daily",
+			want: []cronIssue{
+				{0, 6, "Cron expression must have 5 fields (minute, hour, day of month, month, day of week), found 1", false},
+			},
+		},
+		{
+			name:       "Values out of range",
+			expression: "60 0  * 13 *",
+			want: []cronIssue{
+				{0, 2, "Invalid minute 60, must be between 0 and 59", false},
+				{8, 2, "Invalid month 13, must be between 1 and 12", false},
+			},
+		},
+		{
+			name:       "Unsupported characters",
+			expression: "0 0 L * MON",
+			want: []cronIssue{
+				{4, 1, "Invalid day of month L, must be between 1 and 31", false},
+				{8, 3, "Invalid day of week MON, must be between 0 and 6", false},
+			},
+		},
+		{
+			name:       "Invalid lists, ranges and steps",
+			expression: "0,,5 17-9 */0 5/2 *",
+			want: []cronIssue{
+				{2, 1, "Empty value in the minute list", false},
+				{5, 4, "Invalid hour range 17-9, its start is greater than its end", false},
+				{12, 1, "Invalid day of month step 0, must be between 1 and 31", false},
+				{14, 3, "Steps can only be applied to * or to a range, not to 5", false},
+			},
+		},
+		{
+			name:       "Both day of month and day of week",
+			expression: "0 0 1 * 1",
+			want: []cronIssue{
+				{0, 9, "Both day of month and day of week are set, the workflow will run when either of them matches", true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, checkCron(tt.expression))
+		})
+	}
+}
@@ -1,47 +1,119 @@
 package validate
 
-func isValidDAG(dag map[string][]string) []string {
-	in_degree := map[string]int{}
-	for node := range dag {
-		in_degree[node] = 0
-	}
-	for _, children := range dag {
-		for _, child := range children {
-			in_degree[child]++
+import "sort"
+
+// Returns one cycle per strongly connected component of the graph that
+// contains one, self-references included. Each cycle is the list of its nodes
+// in the order of the edges, starting with the smallest node name
+func findCycles(graph map[string][]string) [][]string {
+	cycles := [][]string{}
+
+	for _, component := range stronglyConnectedComponents(graph) {
+		inComponent := map[string]bool{}
+		for _, node := range component {
+			inComponent[node] = true
 		}
-	}
-	queue := []string{}
-	for node, degree := range in_degree {
-		if degree == 0 {
-			queue = append(queue, node)
+
+		sort.Strings(component)
+		if cycle := findCycleFrom(graph, component[0], inComponent); len(cycle) > 0 {
+			cycles = append(cycles, cycle)
 		}
 	}
 
-	visited := map[string]bool{}
-	for node := range in_degree {
-		visited[node] = false
-	}
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+	return cycles
+}
+
+// Shortest path going from start back to start, only through the given nodes
+func findCycleFrom(graph map[string][]string, start string, allowed map[string]bool) []string {
+	parents := map[string]string{}
+	queue := []string{start}
 
 	for len(queue) > 0 {
 		node := queue[0]
 		queue = queue[1:]
-		if visited[node] {
-			continue
+
+		for _, child := range sortedChildren(graph, node) {
+			if child == start {
+				cycle := []string{node}
+				for node != start {
+					node = parents[node]
+					cycle = append([]string{node}, cycle...)
+				}
+				return cycle
+			}
+
+			if _, seen := parents[child]; seen || !allowed[child] {
+				continue
+			}
+			parents[child] = node
+			queue = append(queue, child)
+		}
+	}
+
+	return []string{}
+}
+
+// Tarjan's algorithm
+func stronglyConnectedComponents(graph map[string][]string) [][]string {
+	index := 0
+	indexes := map[string]int{}
+	lowLinks := map[string]int{}
+	onStack := map[string]bool{}
+	stack := []string{}
+	components := [][]string{}
+
+	var visit func(node string)
+	visit = func(node string) {
+		indexes[node] = index
+		lowLinks[node] = index
+		index++
+		stack = append(stack, node)
+		onStack[node] = true
+
+		for _, child := range sortedChildren(graph, node) {
+			if _, visited := indexes[child]; !visited {
+				visit(child)
+				lowLinks[node] = min(lowLinks[node], lowLinks[child])
+			} else if onStack[child] {
+				lowLinks[node] = min(lowLinks[node], indexes[child])
+			}
+		}
+
+		if lowLinks[node] != indexes[node] {
+			return
 		}
-		visited[node] = true
-		for _, child := range dag[node] {
-			in_degree[child]--
-			if in_degree[child] == 0 {
-				queue = append(queue, child)
+
+		component := []string{}
+		for {
+			last := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[last] = false
+			component = append(component, last)
+			if last == node {
+				break
 			}
 		}
+		components = append(components, component)
+	}
+
+	nodes := make([]string, 0, len(graph))
+	for node := range graph {
+		nodes = append(nodes, node)
 	}
+	sort.Strings(nodes)
 
-	not_visited := []string{}
-	for node, v := range visited {
-		if !v {
-			not_visited = append(not_visited, node)
+	for _, node := range nodes {
+		if _, visited := indexes[node]; !visited {
+			visit(node)
 		}
 	}
-	return not_visited
+
+	return components
+}
+
+func sortedChildren(graph map[string][]string, node string) []string {
+	children := append([]string{}, graph[node]...)
+	sort.Strings(children)
+	return children
 }
@@ -1,15 +1,21 @@
 package validate
 
-import "testing"
+import (
+	"testing"
 
-func TestIsValidDag(t *testing.T) {
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+)
+
+func TestFindCycles(t *testing.T) {
 	type args struct {
 		dag map[string][]string
 	}
 	tests := []struct {
 		name string
 		args args
-		want int
+		want [][]string
 	}{
 		{
 			name: "Valid DAG",
@@ -20,7 +26,7 @@ func TestIsValidDag(t *testing.T) {
 					"c": {"f", "g"},
 				},
 			},
-			want: 0,
+			want: [][]string{},
 		},
 		{
 			name: "2 way cycle",
@@ -31,7 +37,7 @@ func TestIsValidDag(t *testing.T) {
 					"c": {"d"},
 				},
 			},
-			want: 2,
+			want: [][]string{{"a", "b"}},
 		},
 		{
 			name: "3 way cycle",
@@ -42,7 +48,17 @@ func TestIsValidDag(t *testing.T) {
 					"c": {"a"},
 				},
 			},
-			want: 3,
+			want: [][]string{{"a", "b", "c"}},
+		},
+		{
+			name: "Self reference",
+			args: args{
+				map[string][]string{
+					"a": {"a"},
+					"b": {"a"},
+				},
+			},
+			want: [][]string{{"a"}},
 		},
 		{
 			name: "Complex example with cycle",
@@ -55,7 +71,20 @@ func TestIsValidDag(t *testing.T) {
 					"e": {"a"},
 				},
 			},
-			want: 7,
+			want: [][]string{{"a", "b", "e"}},
+		},
+		{
+			name: "Several cycles",
+			args: args{
+				map[string][]string{
+					"a": {"b"},
+					"b": {"a"},
+					"x": {"y"},
+					"y": {"z"},
+					"z": {"x"},
+				},
+			},
+			want: [][]string{{"a", "b"}, {"x", "y", "z"}},
 		},
 		{
 			name: "Complex example without cycle",
@@ -69,14 +98,80 @@ func TestIsValidDag(t *testing.T) {
 					"z": {"x"},
 				},
 			},
-			want: 0,
+			want: [][]string{},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := isValidDAG(tt.args.dag); len(got) != tt.want {
-				t.Errorf("IsValidDag() = %v, want %v", got, tt.want)
-			}
+			assert.Equal(t, tt.want, findCycles(tt.args.dag))
 		})
 	}
 }
+
+func TestWorkflowCycles(t *testing.T) {
+	jobs := `version: 2.1
+
+jobs:
+  a:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - checkout
+  b:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - checkout
+  c:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - checkout
+`
+
+	testCases := []ValidateTestCase{
+		{
+			Name:       "3 jobs cycle",
+			OnlyErrors: true,
+			YamlContent: jobs + `
+workflows:
+  someworkflow:
+    jobs:
+      - a:
+          requires: [c]
+      - b:
+          requires: [a]
+      - c:
+          requires: [b]
+`,
+			Diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleCircularRequires, utils.CreateErrorDiagnosticFromRange(protocol.Range{
+					Start: protocol.Position{Line: 22, Character: 8},
+					End:   protocol.Position{Line: 22, Character: 9},
+				}, "Circular requires between jobs: `a` -> `c` -> `b` -> `a`")),
+			},
+		},
+		{
+			Name:       "Self requiring job",
+			OnlyErrors: true,
+			YamlContent: jobs + `
+workflows:
+  someworkflow:
+    jobs:
+      - a
+      - b:
+          requires: [b]
+      - c:
+          requires: [a]
+`,
+			Diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleCircularRequires, utils.CreateErrorDiagnosticFromRange(protocol.Range{
+					Start: protocol.Position{Line: 23, Character: 8},
+					End:   protocol.Position{Line: 23, Character: 9},
+				}, "Circular requires between jobs: `b` -> `b`")),
+			},
+		},
+	}
+
+	CheckYamlErrors(t, testCases)
+}
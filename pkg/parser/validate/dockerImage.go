@@ -2,6 +2,7 @@ package validate
 
 import (
 	"strings"
+	"sync"
 
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/dockerhub"
@@ -11,7 +12,7 @@ import (
 	"go.lsp.dev/protocol"
 )
 
-func DoesDockerImageExists(img *ast.DockerImage, cache *utils.DockerCache, api dockerhub.DockerHubAPI) bool {
+func DoesDockerImageExists(img *ast.DockerImage, credentials map[string]utils.DockerRegistryCredentials, cache *utils.DockerCache, api dockerhub.DockerHubAPI) bool {
 	cachedDockerImage := cache.Get(img.Image.FullPath)
 
 	if !isDockerImageCheckable(img) {
@@ -20,29 +21,174 @@ func DoesDockerImageExists(img *ast.DockerImage, cache *utils.DockerCache, api d
 	}
 
 	if cachedDockerImage == nil {
-		cache.Add(
-			img.Image.FullPath,
-			api.DoesImageExist(img.Image.Namespace, img.Image.Name),
-		)
+		exists, err := checkDockerImage(img, credentials, api)
+		cachedDockerImage = cache.AddWithError(img.Image.FullPath, exists, err)
+	}
+
+	// When the existence is unknown, consider the image valid rather than
+	// reporting a possibly wrong diagnostic
+	return cachedDockerImage.Exists || cachedDockerImage.Err != nil
+}
+
+// Number of images checked at the same time by CheckDockerImagesExist when no
+// limit is given
+const DefaultDockerImagesCheckConcurrency = 8
+
+// Checks the images not cached yet concurrently, with at most `concurrency`
+// requests at a time, and caches the results. An image whose existence could
+// not be determined is cached with its error without affecting the others
+func CheckDockerImagesExist(images []ast.DockerImage, credentials map[string]utils.DockerRegistryCredentials, cache *utils.DockerCache, api dockerhub.DockerHubAPI, concurrency int) {
+	if concurrency <= 0 {
+		concurrency = DefaultDockerImagesCheckConcurrency
+	}
+
+	toCheck := []ast.DockerImage{}
+	seen := map[string]bool{}
+	for _, img := range images {
+		if seen[img.Image.FullPath] || cache.Get(img.Image.FullPath) != nil {
+			continue
+		}
+		seen[img.Image.FullPath] = true
+		toCheck = append(toCheck, img)
+	}
+
+	queue := make(chan ast.DockerImage)
+	wg := sync.WaitGroup{}
+	for i := 0; i < min(concurrency, len(toCheck)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for img := range queue {
+				// Other documents may be checking the same image
+				cache.GetOrSet(img.Image.FullPath, func() (bool, error) {
+					return checkDockerImage(&img, credentials, api)
+				})
+			}
+		}()
+	}
+
+	for _, img := range toCheck {
+		queue <- img
+	}
+	close(queue)
+	wg.Wait()
+}
+
+// Checks all the images of the document at once, ahead of the validation of
+// the executors which then only reads the cache
+func (val Validate) checkDockerImages() {
+	if val.Context != nil && val.Context.DisableDockerImageChecks {
+		return
+	}
 
-		cachedDockerImage = cache.Get(img.Image.FullPath)
+	executors := []ast.DockerExecutor{}
+	for _, executor := range val.Doc.Executors {
+		if docker, ok := executor.(ast.DockerExecutor); ok {
+			executors = append(executors, docker)
+		}
 	}
+	for _, job := range val.Doc.Jobs {
+		executors = append(executors, job.Docker)
+	}
+
+	images := []ast.DockerImage{}
+	for _, executor := range executors {
+		for _, img := range executor.Image {
+			if isDockerImageCheckable(&img) {
+				images = append(images, img)
+			}
+		}
+	}
+
+	// Offline, the images not cached yet are recorded as unknown
+	if val.Context.IsOffline() {
+		for _, img := range images {
+			if val.Cache.DockerCache.Get(img.Image.FullPath) == nil {
+				val.Cache.DockerCache.AddWithError(img.Image.FullPath, false, utils.ErrOffline)
+			}
+		}
+		return
+	}
+
+	CheckDockerImagesExist(images, val.getDockerRegistryCredentials(), &val.Cache.DockerCache, val.APIs.DockerHub, DefaultDockerImagesCheckConcurrency)
+}
 
-	return cachedDockerImage.Exists
+// The checks reach the registries, they can be disabled by the settings and
+// are not done offline
+func (val Validate) areDockerImagesCheckable() bool {
+	return val.Context == nil || (!val.Context.DisableDockerImageChecks && !val.Context.IsOffline())
+}
+
+func (val Validate) getDockerRegistryCredentials() map[string]utils.DockerRegistryCredentials {
+	if val.Context == nil {
+		return nil
+	}
+	return val.Context.DockerRegistryCredentials
+}
+
+// Public Docker Hub images are checked with the Docker Hub API, the others
+// with the registry API and the credentials of the registry
+func checkDockerImage(img *ast.DockerImage, credentials map[string]utils.DockerRegistryCredentials, api dockerhub.DockerHubAPI) (bool, error) {
+	imageCredentials, requiresCredentials := getDockerImageCredentials(img, credentials)
+
+	// Without the credentials it asks for, a private image can not be told
+	// apart from a missing one: its existence is unknown
+	if imageCredentials == nil && requiresCredentials {
+		return false, dockerhub.ErrMissingCredentials
+	}
+
+	if img.Image.Registry == "" && imageCredentials == nil {
+		return api.DoesImageExist(img.Image.Namespace, img.Image.Name)
+	}
+
+	repository := img.Image.Name
+	if img.Image.Namespace != "" {
+		repository = img.Image.Namespace + "/" + repository
+	}
+
+	return api.DoesRegistryImageExist(img.Image.Registry, repository, imageCredentials)
+}
+
+// Returns the credentials of the `auth` block of the image, as long as they
+// are written in the configuration rather than coming from environment
+// variables, or else the ones of its registry from the settings. The second
+// value tells whether the image has an `auth` or `aws_auth` block
+func getDockerImageCredentials(img *ast.DockerImage, credentials map[string]utils.DockerRegistryCredentials) (*utils.DockerRegistryCredentials, bool) {
+	requiresCredentials := img.Auth != ast.DockerImageAuth{} || img.AwsAuth != ast.DockerImageAWSAuth{}
+
+	if img.Auth.Username != "" && isLiteralCredential(img.Auth.Username) && isLiteralCredential(img.Auth.Password) {
+		return &utils.DockerRegistryCredentials{Username: img.Auth.Username, Password: img.Auth.Password}, true
+	}
+
+	registry := img.Image.Registry
+	if registry == "" {
+		registry = "docker.io"
+	}
+
+	if registryCredentials, ok := credentials[registry]; ok {
+		return &registryCredentials, requiresCredentials
+	}
+
+	return nil, requiresCredentials
+}
+
+func isLiteralCredential(value string) bool {
+	return !strings.HasPrefix(value, "$") && !strings.Contains(value, "<<")
 }
 
 /*
 Not all Docker image syntaxes are supported
 Unsupported syntaxes:
-  - Direct URL, not on Docker HUB (Example: 183081753049.dkr.ecr.us-east-1.amazonaws.com/circleci/ecs-test-kms:0.1)
   - Using aliases (Example: image: *my_alias)
-  - When authentication is required
   - When tag uses CircleCI parameter syntax
+
+Images of private registries are checked with the credentials of the `auth`
+block or of the settings, their existence is unknown without them
 */
 func isDockerImageCheckable(img *ast.DockerImage) bool {
 	// For now, just make the name & version mandatory
 	hasParamInTag, _ := utils.CheckIfParamIsPartiallyReferenced(img.Image.Tag)
-	return img.Image.Name != "" && img.Auth == ast.DockerImageAuth{} && img.AwsAuth == ast.DockerImageAWSAuth{} && !hasParamInTag
+	return img.Image.Name != "" && !hasParamInTag
 }
 
 func DoesTagExist(img *ast.DockerImage, searchedTag string, cache *utils.DockerTagsCache, api dockerhub.DockerHubAPI) bool {
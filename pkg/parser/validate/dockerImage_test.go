@@ -1,10 +1,16 @@
 package validate
 
 import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/dockerhub"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
 	"github.com/stretchr/testify/assert"
 	"go.lsp.dev/protocol"
 )
@@ -25,10 +31,19 @@ type DockerHubMock struct {
 	NoLatest bool
 	NoTag    bool
 	Tags     []string
+
+	RequiresCredentials bool
+}
+
+func (me DockerHubMock) DoesImageExist(namespace, image string) (bool, error) {
+	return !me.NoExist, nil
 }
 
-func (me DockerHubMock) DoesImageExist(namespace, image string) bool {
-	return !me.NoExist
+func (me DockerHubMock) DoesRegistryImageExist(registry, repository string, credentials *utils.DockerRegistryCredentials) (bool, error) {
+	if me.RequiresCredentials && credentials == nil {
+		return false, dockerhub.ErrMissingCredentials
+	}
+	return !me.NoExist, nil
 }
 
 func (me DockerHubMock) GetImageTags(namespace, image string) ([]string, error) {
@@ -50,6 +65,7 @@ func TestValidateDockerImage(t *testing.T) {
 		Name        string
 		YamlContent string
 		MockAPI     dockerhub.DockerHubAPI
+		Credentials map[string]utils.DockerRegistryCredentials
 		Diagnostics []ComparableDiagnostic
 	}{
 		{
@@ -67,11 +83,11 @@ executors:
       - image: namespace/image:tag`,
 		},
 		{
-			Name: "Should give error on non-existing image",
+			Name: "Should give a warning on non-existing image",
 
 			Diagnostics: []ComparableDiagnostic{
 				{
-					Severity: protocol.DiagnosticSeverityError,
+					Severity: protocol.DiagnosticSeverityWarning,
 					Message:  "Docker image not found namespace/image:tag",
 				},
 			},
@@ -200,6 +216,71 @@ workflows:
           image-tag: tag
 `,
 		},
+		{
+			Name: "Should not report private images when their credentials come from environment variables",
+
+			Diagnostics: []ComparableDiagnostic{},
+
+			MockAPI: DockerHubMock{
+				NoExist: true,
+			},
+
+			YamlContent: `version: 2.1
+
+executors:
+  some-executor:
+    docker:
+      - image: namespace/private:tag
+        auth:
+          username: $DOCKERHUB_USERNAME
+          password: $DOCKERHUB_PASSWORD`,
+		},
+		{
+			Name: "Should not report images of registries requiring missing credentials",
+
+			Diagnostics: []ComparableDiagnostic{},
+
+			MockAPI: DockerHubMock{
+				NoExist:             true,
+				RequiresCredentials: true,
+			},
+
+			YamlContent: `version: 2.1
+
+executors:
+  some-executor:
+    docker:
+      - image: gcr.io/project/image:tag`,
+		},
+		{
+			Name: "Should check private images with the credentials of their registry",
+
+			Diagnostics: []ComparableDiagnostic{
+				{
+					Severity: protocol.DiagnosticSeverityWarning,
+					Message:  "Docker image not found 123456789012.dkr.ecr.us-east-1.amazonaws.com/image:tag",
+				},
+			},
+
+			MockAPI: DockerHubMock{
+				NoExist:             true,
+				RequiresCredentials: true,
+			},
+
+			Credentials: map[string]utils.DockerRegistryCredentials{
+				"123456789012.dkr.ecr.us-east-1.amazonaws.com": {Username: "AWS", Password: "token"},
+			},
+
+			YamlContent: `version: 2.1
+
+executors:
+  some-executor:
+    docker:
+      - image: 123456789012.dkr.ecr.us-east-1.amazonaws.com/image:tag
+        aws_auth:
+          aws_access_key_id: $AWS_ACCESS_KEY_ID
+          aws_secret_access_key: $AWS_SECRET_ACCESS_KEY`,
+		},
 	}
 
 	for _, tt := range testCases {
@@ -208,6 +289,9 @@ workflows:
 			val.APIs = ValidateAPIs{
 				DockerHub: tt.MockAPI,
 			}
+			// Most cases only define an executor
+			val.Context.IgnoreUnusedDefinitions = true
+			val.Context.DockerRegistryCredentials = tt.Credentials
 
 			val.Validate(false)
 
@@ -217,6 +301,147 @@ workflows:
 	}
 }
 
+func TestDockerImageChecksSettings(t *testing.T) {
+	yamlContent := `version: 2.1
+
+executors:
+  some-executor:
+    docker:
+      - image: namespace/image:tag`
+
+	t.Run("Should check each image once across validations", func(t *testing.T) {
+		api := &SlowDockerHubMock{}
+		cache := utils.CreateCache()
+
+		for i := 0; i < 3; i++ {
+			val := CreateValidateFromYAML(yamlContent)
+			val.APIs.DockerHub = api
+			val.Cache = cache
+			val.Validate(false)
+		}
+
+		assert.Equal(t, int32(1), api.checked.Load())
+	})
+
+	t.Run("Should not reach the registry when the checks are disabled", func(t *testing.T) {
+		api := &SlowDockerHubMock{Unreachable: "image"}
+
+		val := CreateValidateFromYAML(yamlContent)
+		val.APIs.DockerHub = api
+		val.Context.IgnoreUnusedDefinitions = true
+		val.Context.DisableDockerImageChecks = true
+		val.Validate(false)
+
+		assert.Equal(t, int32(0), api.checked.Load())
+		assert.Empty(t, *val.Diagnostics)
+	})
+
+	t.Run("Should record the images as unknown when offline", func(t *testing.T) {
+		api := &SlowDockerHubMock{Unreachable: "image"}
+
+		val := CreateValidateFromYAML(yamlContent)
+		val.APIs.DockerHub = api
+		val.Context.IgnoreUnusedDefinitions = true
+		val.Context.SetOffline(true)
+		val.Validate(false)
+
+		assert.Equal(t, int32(0), api.checked.Load())
+		assert.Empty(t, *val.Diagnostics)
+
+		cached := val.Cache.DockerCache.Get("namespace/image:tag")
+		assert.NotNil(t, cached)
+		assert.False(t, cached.Exists)
+		assert.ErrorIs(t, cached.Err, utils.ErrOffline)
+
+		val.Cache.DockerCache.RemoveWithError(utils.ErrOffline)
+		assert.Nil(t, val.Cache.DockerCache.Get("namespace/image:tag"))
+	})
+}
+
+// Answers after a delay, as the registry would, and keeps track of the
+// number of requests running at the same time
+type SlowDockerHubMock struct {
+	DockerHubMock
+	Delay       time.Duration
+	Unreachable string
+
+	mutex      sync.Mutex
+	running    int
+	maxRunning int
+	checked    atomic.Int32
+}
+
+func (me *SlowDockerHubMock) DoesImageExist(namespace, image string) (bool, error) {
+	me.checked.Add(1)
+	me.mutex.Lock()
+	me.running++
+	me.maxRunning = max(me.maxRunning, me.running)
+	me.mutex.Unlock()
+
+	time.Sleep(me.Delay)
+
+	me.mutex.Lock()
+	me.running--
+	me.mutex.Unlock()
+
+	if image == me.Unreachable {
+		return false, errors.New("registry unreachable")
+	}
+	return true, nil
+}
+
+func makeDockerImages(count int) []ast.DockerImage {
+	images := make([]ast.DockerImage, count)
+	for i := range images {
+		name := fmt.Sprintf("image-%d", i)
+		images[i] = ast.DockerImage{
+			Image: ast.DockerImageInfo{Namespace: "namespace", Name: name, FullPath: "namespace/" + name},
+		}
+	}
+	return images
+}
+
+func TestCheckDockerImagesExist(t *testing.T) {
+	t.Run("Should check the images not cached with a bounded concurrency", func(t *testing.T) {
+		cache := utils.CreateCache()
+		api := &SlowDockerHubMock{Delay: 5 * time.Millisecond, Unreachable: "image-3"}
+		images := makeDockerImages(20)
+		cache.DockerCache.Add("namespace/image-0", true)
+
+		CheckDockerImagesExist(append(images, images[1]), nil, &cache.DockerCache, api, 4)
+
+		assert.Equal(t, int32(19), api.checked.Load())
+		assert.LessOrEqual(t, api.maxRunning, 4)
+		for _, img := range images {
+			cached := cache.DockerCache.Get(img.Image.FullPath)
+			assert.NotNil(t, cached)
+			if img.Image.Name == "image-3" {
+				assert.Error(t, cached.Err)
+			} else {
+				assert.True(t, cached.Exists)
+			}
+		}
+	})
+}
+
+func benchmarkCheckDockerImagesExist(b *testing.B, concurrency int) {
+	images := makeDockerImages(50)
+	api := &SlowDockerHubMock{Delay: time.Millisecond}
+
+	for i := 0; i < b.N; i++ {
+		cache := utils.CreateCache()
+		CheckDockerImagesExist(images, nil, &cache.DockerCache, api, concurrency)
+	}
+}
+
+func BenchmarkCheckDockerImagesSerially(b *testing.B) {
+	benchmarkCheckDockerImagesExist(b, 1)
+}
+
+func BenchmarkCheckDockerImagesConcurrently(b *testing.B) {
+	benchmarkCheckDockerImagesExist(b, DefaultDockerImagesCheckConcurrency)
+}
+
 func TestChooseTagToRecommend(t *testing.T) {
 	testCases := []struct {
 		Name   string
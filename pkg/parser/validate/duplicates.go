@@ -0,0 +1,58 @@
+package validate
+
+import (
+	"fmt"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	sitter "github.com/smacker/go-tree-sitter"
+	"go.lsp.dev/protocol"
+)
+
+// YAML keeps the last value of a duplicated key, silently dropping a job or
+// a step defined twice. Every mapping of the document is checked, before the
+// parser keeps a single definition of each key
+func (val Validate) ValidateDuplicateKeys() {
+	val.findDuplicateKeys(val.Doc.RootNode)
+}
+
+func (val Validate) findDuplicateKeys(node *sitter.Node) {
+	if node.Type() == "block_mapping" || node.Type() == "flow_mapping" {
+		firstKeys := map[string]*sitter.Node{}
+
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			child := node.NamedChild(i)
+			if child.Type() != "block_mapping_pair" && child.Type() != "flow_pair" {
+				continue
+			}
+
+			keyNode := child.ChildByFieldName("key")
+			key := val.Doc.GetNodeText(keyNode)
+			// Merge keys can be repeated, the parser reports them
+			if keyNode == nil || key == "" || key == "<<" {
+				continue
+			}
+
+			first, ok := firstKeys[key]
+			if !ok {
+				firstKeys[key] = keyNode
+				continue
+			}
+
+			firstRange := val.Doc.NodeToRange(first)
+			diagnostic := utils.CreateErrorDiagnosticFromRange(
+				val.Doc.NodeToRange(keyNode),
+				fmt.Sprintf("Duplicate key %s, first defined at line %d", key, firstRange.Start.Line+1))
+			diagnostic.RelatedInformation = []protocol.DiagnosticRelatedInformation{
+				{
+					Location: protocol.Location{URI: val.Doc.URI, Range: firstRange},
+					Message:  fmt.Sprintf("First definition of %s", key),
+				},
+			}
+			val.addDiagnostic(utils.RuleDuplicateKey, diagnostic)
+		}
+	}
+
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		val.findDuplicateKeys(node.NamedChild(i))
+	}
+}
@@ -0,0 +1,98 @@
+package validate
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+func TestValidateDuplicateKeys(t *testing.T) {
+	content := `version: 2.1
+
+jobs:
+  build:
+    machine: true
+    steps:
+      - run:
+          name: Test
+          command: make test
+          name: Lint
+  build:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - checkout
+  deploy:
+    machine: true
+    steps:
+      - save_cache: { key: v1, paths: [node_modules], key: v2 }
+
+workflows:
+  main:
+    jobs:
+      - build
+      - deploy
+
+workflows:
+  nightly:
+    jobs:
+      - deploy
+`
+
+	val := CreateValidateFromYAML(content)
+	val.Doc.URI = uri.File("/tmp/duplicates.yml")
+	val.ValidateDuplicateKeys()
+
+	expected := []struct {
+		key        string
+		rng        protocol.Range
+		firstRange protocol.Range
+	}{
+		{
+			key:        "build",
+			rng:        createRange(10, 2, 7),
+			firstRange: createRange(3, 2, 7),
+		},
+		{
+			key:        "workflows",
+			rng:        createRange(26, 0, 9),
+			firstRange: createRange(20, 0, 9),
+		},
+		{
+			key:        "name",
+			rng:        createRange(9, 10, 14),
+			firstRange: createRange(7, 10, 14),
+		},
+		{
+			key:        "key",
+			rng:        createRange(18, 54, 57),
+			firstRange: createRange(18, 22, 25),
+		},
+	}
+
+	diagnostics := []protocol.Diagnostic{}
+	for _, duplicate := range expected {
+		diagnostic := utils.WithDiagnosticRule(utils.RuleDuplicateKey, utils.CreateErrorDiagnosticFromRange(
+			duplicate.rng,
+			fmt.Sprintf("Duplicate key %s, first defined at line %d", duplicate.key, duplicate.firstRange.Start.Line+1)))
+		diagnostic.RelatedInformation = []protocol.DiagnosticRelatedInformation{
+			{
+				Location: protocol.Location{URI: val.Doc.URI, Range: duplicate.firstRange},
+				Message:  "First definition of " + duplicate.key,
+			},
+		}
+		diagnostics = append(diagnostics, diagnostic)
+	}
+
+	CompareDiagnostics(t, &diagnostics, val.Diagnostics)
+}
+
+func createRange(line uint32, start uint32, end uint32) protocol.Range {
+	return protocol.Range{
+		Start: protocol.Position{Line: line, Character: start},
+		End:   protocol.Position{Line: line, Character: end},
+	}
+}
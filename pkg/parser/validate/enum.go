@@ -11,7 +11,7 @@ import (
 func (val Validate) checkEnumTypeDefinition(definedParam ast.EnumParameter) {
 	if definedParam.HasDefault {
 		if utils.FindInArray(definedParam.Enum, definedParam.Default) == -1 {
-			val.addDiagnostic(utils.CreateErrorDiagnosticFromRange(
+			val.addDiagnostic(utils.RuleParameterDefault, utils.CreateErrorDiagnosticFromRange(
 				definedParam.Range,
 				fmt.Sprintf("Default value %s is not in enum '%s'", definedParam.Default, strings.Join(definedParam.Enum, ", "))))
 		}
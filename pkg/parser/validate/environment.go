@@ -0,0 +1,147 @@
+package validate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	sitter "github.com/smacker/go-tree-sitter"
+	"go.lsp.dev/protocol"
+)
+
+// Checks the `environment` maps of the jobs, the executors, their Docker
+// images and the run steps, local orbs included. Values must be strings: the
+// numbers and booleans are converted in ways that are easy to miss, 1.10
+// becoming 1.1 for instance, and the names must be valid variable names
+func (val Validate) ValidateEnvironments() {
+	val.iterateOnMapping(parser.GetBlockMappingNode(val.Doc.RootNode), func(key string, _ *sitter.Node, value *sitter.Node) {
+		if key != "orbs" {
+			val.validateSectionEnvironments(key, value)
+			return
+		}
+
+		val.iterateOnMapping(parser.GetChildMapping(value), func(_ string, _ *sitter.Node, orb *sitter.Node) {
+			val.iterateOnMapping(parser.GetChildMapping(orb), func(key string, _ *sitter.Node, value *sitter.Node) {
+				val.validateSectionEnvironments(key, value)
+			})
+		})
+	})
+}
+
+func (val Validate) validateSectionEnvironments(section string, sectionNode *sitter.Node) {
+	if section != "jobs" && section != "executors" && section != "commands" {
+		return
+	}
+
+	val.iterateOnMapping(parser.GetChildMapping(sectionNode), func(_ string, _ *sitter.Node, definition *sitter.Node) {
+		val.iterateOnMapping(parser.GetChildMapping(definition), func(key string, _ *sitter.Node, value *sitter.Node) {
+			switch key {
+			case "environment":
+				val.validateEnvironment(value)
+
+			case "docker":
+				for _, image := range val.getSequenceItems(value) {
+					val.iterateOnMapping(parser.GetChildMapping(image), func(key string, _ *sitter.Node, value *sitter.Node) {
+						if key == "environment" {
+							val.validateEnvironment(value)
+						}
+					})
+				}
+
+			case "steps":
+				val.validateStepsEnvironments(value)
+			}
+		})
+	})
+}
+
+// The steps of `when` and `unless` are checked as well
+func (val Validate) validateStepsEnvironments(steps *sitter.Node) {
+	for _, step := range val.getSequenceItems(steps) {
+		val.iterateOnMapping(parser.GetChildMapping(step), func(key string, _ *sitter.Node, value *sitter.Node) {
+			switch key {
+			case "run":
+				val.iterateOnMapping(parser.GetChildMapping(value), func(key string, _ *sitter.Node, value *sitter.Node) {
+					if key == "environment" {
+						val.validateEnvironment(value)
+					}
+				})
+
+			case "when", "unless":
+				val.iterateOnMapping(parser.GetChildMapping(value), func(key string, _ *sitter.Node, value *sitter.Node) {
+					if key == "steps" {
+						val.validateStepsEnvironments(value)
+					}
+				})
+			}
+		})
+	}
+}
+
+// Environments are either maps or lists of KEY=VALUE strings. Empty values
+// are allowed, and aliases and tagged values are only known once resolved
+func (val Validate) validateEnvironment(environment *sitter.Node) {
+	val.iterateOnMapping(parser.GetChildMapping(environment), func(name string, keyNode *sitter.Node, value *sitter.Node) {
+		val.validateEnvironmentName(name, val.Doc.NodeToRange(keyNode))
+
+		if value == nil {
+			return
+		}
+
+		kind := val.getValueKind(value)
+		if kind == "" || kind == "string" || kind == "null" {
+			return
+		}
+
+		rng := val.Doc.NodeToRange(value)
+		if kind == "map" || kind == "list" {
+			val.addDiagnostic(utils.RuleEnvironment, utils.CreateErrorDiagnosticFromRange(
+				rng,
+				fmt.Sprintf("The value of %s is %s, environment values must be strings", name, valueKindNames[kind])))
+			return
+		}
+
+		diagnostic := utils.CreateErrorDiagnosticFromRange(
+			rng,
+			fmt.Sprintf("The value of %s is %s, environment values must be strings: quote it to keep it as written", name, valueKindNames[kind]))
+		diagnostic.Data = []protocol.CodeAction{
+			utils.CreateCodeActionTextEdit("Quote the value", val.Doc.URI, []protocol.TextEdit{{
+				Range:   rng,
+				NewText: fmt.Sprintf("%q", val.Doc.GetRawNodeText(value)),
+			}}, true),
+		}
+		val.addDiagnostic(utils.RuleEnvironment, diagnostic)
+	})
+
+	for _, item := range val.getSequenceItems(environment) {
+		if item == nil {
+			continue
+		}
+
+		rng := val.Doc.NodeToRange(item)
+		if kind := val.getValueKind(item); kind != "string" {
+			if kind != "" {
+				val.addDiagnostic(utils.RuleEnvironment, utils.CreateErrorDiagnosticFromRange(
+					rng,
+					fmt.Sprintf("Environment variables must be given as KEY=VALUE strings, found %s", valueKindNames[kind])))
+			}
+			continue
+		}
+
+		if name, _, found := strings.Cut(val.Doc.GetNodeText(item), "="); found {
+			val.validateEnvironmentName(name, rng)
+		}
+	}
+}
+
+// Names given by parameters are only known once the parameters are resolved
+func (val Validate) validateEnvironmentName(name string, rng protocol.Range) {
+	if envVarNameRegex.MatchString(name) || strings.Contains(name, "<<") {
+		return
+	}
+
+	val.addDiagnostic(utils.RuleEnvironment, utils.CreateWarningDiagnosticFromRange(
+		rng,
+		fmt.Sprintf("%s is not a valid environment variable name, names are made of letters, digits and underscores and do not start with a digit", name)))
+}
@@ -0,0 +1,76 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"go.lsp.dev/protocol"
+)
+
+func TestValidateEnvironments(t *testing.T) {
+	val := CreateValidateFromYAML(`version: 2.1
+
+executors:
+  node:
+    docker:
+      - image: cimg/node:20.5
+        environment:
+          PGPORT: 5432
+    environment:
+      NODE_ENV: production
+
+jobs:
+  build:
+    executor: node
+    environment:
+      GO_VERSION: 1.10
+      EMPTY:
+      NAME: << parameters.name >>
+      << parameters.variable >>: value
+      MY-VAR: value
+      NESTED:
+        KEY: value
+    steps:
+      - run:
+          command: make
+          environment:
+            DEBUG: true
+      - when:
+          condition: true
+          steps:
+            - run:
+                command: make
+                environment:
+                  - 1VAR=value
+                  - TOKEN=<< parameters.token >>
+
+workflows:
+  main:
+    jobs:
+      - build
+`)
+	val.ValidateEnvironments()
+
+	quoted := func(rng protocol.Range, name string, kind string, text string) protocol.Diagnostic {
+		diagnostic := utils.CreateErrorDiagnosticFromRange(rng, "The value of "+name+" is "+kind+", environment values must be strings: quote it to keep it as written")
+		diagnostic.Data = []protocol.CodeAction{
+			utils.CreateCodeActionTextEdit("Quote the value", val.Doc.URI, []protocol.TextEdit{{Range: rng, NewText: text}}, true),
+		}
+		return utils.WithDiagnosticRule(utils.RuleEnvironment, diagnostic)
+	}
+
+	CompareDiagnostics(t, &[]protocol.Diagnostic{
+		quoted(createRange(7, 18, 22), "PGPORT", "an integer", `"5432"`),
+		quoted(createRange(15, 18, 22), "GO_VERSION", "a float", `"1.10"`),
+		utils.WithDiagnosticRule(utils.RuleEnvironment, utils.CreateWarningDiagnosticFromRange(
+			createRange(19, 6, 12),
+			"MY-VAR is not a valid environment variable name, names are made of letters, digits and underscores and do not start with a digit")),
+		utils.WithDiagnosticRule(utils.RuleEnvironment, utils.CreateErrorDiagnosticFromRange(
+			createRange(21, 8, 18),
+			"The value of NESTED is a map, environment values must be strings")),
+		quoted(createRange(26, 19, 23), "DEBUG", "a boolean", `"true"`),
+		utils.WithDiagnosticRule(utils.RuleEnvironment, utils.CreateWarningDiagnosticFromRange(
+			createRange(33, 20, 30),
+			"1VAR is not a valid environment variable name, names are made of letters, digits and underscores and do not start with a digit")),
+	}, val.Diagnostics)
+}
@@ -2,6 +2,7 @@ package validate
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
@@ -12,6 +13,7 @@ import (
 func (val Validate) ValidateExecutors() {
 	if len(val.Doc.Executors) == 0 && !utils.IsDefaultRange(val.Doc.ExecutorsRange) {
 		val.addDiagnostic(
+			utils.RuleEmptySection,
 			utils.CreateEmptyAssignationWarning(val.Doc.ExecutorsRange),
 		)
 
@@ -24,6 +26,10 @@ func (val Validate) ValidateExecutors() {
 }
 
 func (val Validate) validateSingleExecutor(executor ast.Executor) {
+	if !val.checkIfExecutorIsUsed(executor) {
+		val.addUnusedDiagnostic(utils.RuleUnusedExecutor, executor.GetNameRange(), "Executor is unused")
+	}
+
 	switch executor := executor.(type) {
 	case ast.MacOSExecutor:
 		val.validateMacOSExecutor(executor)
@@ -49,16 +55,9 @@ var ValidXCodeVersions = []string{
 	"12.5.1",
 }
 
-var ValidMacOSResourceClasses = []string{
-	"macos.x86.medium.gen2",
-	"macos.m1.medium.gen1",
-	"macos.m1.large.gen1",
-	"macos.x86.metal.gen1",
-}
-
 func (val Validate) validateMacOSExecutor(executor ast.MacOSExecutor) {
 	if utils.FindInArray(ValidXCodeVersions, executor.Xcode) == -1 {
-		val.addDiagnostic(utils.CreateErrorDiagnosticFromRange(
+		val.addDiagnostic(utils.RuleXcodeVersion, utils.CreateErrorDiagnosticFromRange(
 			executor.XcodeRange,
 			fmt.Sprintf("Invalid Xcode version %s", executor.Xcode),
 		))
@@ -79,44 +78,22 @@ func (val Validate) validateMachineExecutor(executor ast.MachineExecutor) {
 	}
 }
 
-var ValidARMResourceClasses = []string{
-	"arm.medium",
-	"arm.large",
-	"arm.xlarge",
-	"arm.2xlarge",
-}
-
 func (val Validate) validateARMMachineExecutor(executor ast.MachineExecutor) {
 	val.validateImage(executor.Image, executor.ImageRange)
 	val.checkIfValidResourceClass(executor.ResourceClass, ValidARMResourceClasses, executor.ResourceClassRange)
 }
 
-var ValidNvidiaGPUResourceClasses = []string{
-	"gpu.nvidia.small",
-	"gpu.nvidia.medium",
-	"gpu.nvidia.large",
-	"windows.gpu.nvidia.medium",
-}
-
 func (val Validate) validateNvidiaGPUMachineExecutor(executor ast.MachineExecutor) {
 	val.checkIfValidResourceClass(executor.ResourceClass, ValidNvidiaGPUResourceClasses, executor.ResourceClassRange)
 }
 
-var ValidLinuxResourceClasses = []string{
-	"medium",
-	"large",
-	"xlarge",
-	"2xlarge",
-	"2xlarge+",
-}
-
 func (val Validate) validateLinuxMachineExecutor(executor ast.MachineExecutor) {
 	val.checkIfValidResourceClass(executor.ResourceClass, ValidLinuxResourceClasses, executor.ResourceClassRange)
 
 	if executor.Image != "" {
 		val.validateImage(executor.Image, executor.ImageRange)
 	} else if !executor.IsDeprecated && !val.Doc.IsSelfHostedRunner(executor.ResourceClass) {
-		val.addDiagnostic(utils.CreateErrorDiagnosticFromRange(
+		val.addDiagnostic(utils.RuleMachineImage, utils.CreateErrorDiagnosticFromRange(
 			executor.Range,
 			"Missing image",
 		))
@@ -124,26 +101,43 @@ func (val Validate) validateLinuxMachineExecutor(executor ast.MachineExecutor) {
 }
 
 func (val Validate) validateImage(img string, imgRange protocol.Range) {
+	if deprecated, ok := utils.FindDeprecatedImage(img); ok {
+		val.addDeprecatedImageDiagnostic(img, deprecated, imgRange)
+		return
+	}
+
 	if utils.FindInArray(utils.ValidARMOrMachineImages, img) == -1 {
-		val.addDiagnostic(utils.CreateErrorDiagnosticFromRange(
+		val.addDiagnostic(utils.RuleMachineImage, utils.CreateErrorDiagnosticFromRange(
 			imgRange,
 			"Invalid or deprecated image",
 		))
 	}
 }
 
-// DockerExecutor
+func (val Validate) addDeprecatedImageDiagnostic(img string, deprecated utils.DeprecatedImage, imgRange protocol.Range) {
+	diagnostic := utils.CreateDiagnosticFromRange(
+		imgRange,
+		protocol.DiagnosticSeverityWarning,
+		fmt.Sprintf("Image %s is deprecated: %s. Use %s instead", img, deprecated.Reason, deprecated.Replacement),
+		[]protocol.CodeAction{
+			utils.CreateCodeActionTextEdit(
+				fmt.Sprintf("Use %s", deprecated.Replacement),
+				val.Doc.URI, []protocol.TextEdit{
+					{
+						Range:   imgRange,
+						NewText: fmt.Sprintf("image: %s", deprecated.Replacement),
+					},
+				}, true,
+			),
+		},
+	)
+	diagnostic.Tags = []protocol.DiagnosticTag{protocol.DiagnosticTagDeprecated}
 
-var ValidDockerResourceClasses = []string{
-	"small",
-	"medium",
-	"medium+",
-	"large",
-	"xlarge",
-	"2xlarge",
-	"2xlarge+",
+	val.addDiagnostic(utils.RuleDeprecatedImage, diagnostic)
 }
 
+// DockerExecutor
+
 func (val Validate) validateDockerExecutor(executor ast.DockerExecutor) {
 	val.checkIfValidResourceClass(executor.ResourceClass, ValidDockerResourceClasses, executor.ResourceClassRange)
 
@@ -154,51 +148,17 @@ func (val Validate) validateDockerExecutor(executor ast.DockerExecutor) {
 			continue
 		}
 
-		imageExists := DoesDockerImageExists(&img, &val.Cache.DockerCache, val.APIs.DockerHub)
-		if !imageExists {
-			val.addDiagnostic(
-				utils.CreateErrorDiagnosticFromRange(
-					img.ImageRange,
-					fmt.Sprintf("Docker image not found %s", img.Image.FullPath),
-				),
-			)
-		} else {
-			// Validate image tag
-			imgTag := img.Image.Tag
-
-			if imgTag == "" {
-				imgTag = "latest"
-			}
-
-			tagExists := DoesTagExist(&img, imgTag, &val.Cache.DockerTagsCache, val.APIs.DockerHub)
-
-			if !tagExists {
-				actions := GetImageTagActions(&val.Doc, &img, &val.Cache.DockerTagsCache, val.APIs.DockerHub)
-				val.addDiagnostic(
-					utils.CreateDiagnosticFromRange(
-						img.ImageRange,
-						protocol.DiagnosticSeverityError,
-						fmt.Sprintf("Docker image %s has no tag %s", img.Image.FullPath, imgTag),
-						actions,
-					),
-				)
-			}
+		if val.areDockerImagesCheckable() {
+			val.validateDockerImageOnRegistry(img)
+		}
 
-			if tagExists && img.Image.Tag == "" {
-				actions := GetImageTagActions(&val.Doc, &img, &val.Cache.DockerTagsCache, val.APIs.DockerHub)
-				val.addDiagnostic(
-					utils.CreateDiagnosticFromRange(
-						img.ImageRange,
-						protocol.DiagnosticSeverityHint,
-						"It is recommended to set explicit tags",
-						actions,
-					),
-				)
-			}
+		if deprecated, ok := utils.FindDeprecatedImage(img.Image.FullPath); ok {
+			val.addDeprecatedImageDiagnostic(img.Image.FullPath, deprecated, img.ImageRange)
 		}
 
 		if img.Image.Namespace == "circleci" {
 			val.addDiagnostic(
+				utils.RuleDeprecatedImage,
 				utils.CreateDiagnosticFromRange(
 					img.ImageRange,
 					protocol.DiagnosticSeverityWarning,
@@ -220,15 +180,63 @@ func (val Validate) validateDockerExecutor(executor ast.DockerExecutor) {
 	}
 }
 
-// WindowsExecutor
+func (val Validate) validateDockerImageOnRegistry(img ast.DockerImage) {
+	// A warning rather than an error, as the registry may not be the
+	// source of truth, e.g. when images are mirrored
+	imageExists := DoesDockerImageExists(&img, val.getDockerRegistryCredentials(), &val.Cache.DockerCache, val.APIs.DockerHub)
+	if !imageExists {
+		val.addDiagnostic(
+			utils.RuleDockerImage,
+			utils.CreateWarningDiagnosticFromRange(
+				img.ImageRange,
+				fmt.Sprintf("Docker image not found %s", img.Image.FullPath),
+			),
+		)
+		return
+	}
 
-var ValidWindowsResourceClasses = []string{
-	"medium",
-	"large",
-	"xlarge",
-	"2xlarge",
+	// The tags are only known for Docker Hub images
+	if img.Image.Registry != "" {
+		return
+	}
+
+	imgTag := img.Image.Tag
+
+	if imgTag == "" {
+		imgTag = "latest"
+	}
+
+	tagExists := DoesTagExist(&img, imgTag, &val.Cache.DockerTagsCache, val.APIs.DockerHub)
+
+	if !tagExists {
+		actions := GetImageTagActions(&val.Doc, &img, &val.Cache.DockerTagsCache, val.APIs.DockerHub)
+		val.addDiagnostic(
+			utils.RuleDockerImage,
+			utils.CreateDiagnosticFromRange(
+				img.ImageRange,
+				protocol.DiagnosticSeverityError,
+				fmt.Sprintf("Docker image %s has no tag %s", img.Image.FullPath, imgTag),
+				actions,
+			),
+		)
+	}
+
+	if tagExists && img.Image.Tag == "" {
+		actions := GetImageTagActions(&val.Doc, &img, &val.Cache.DockerTagsCache, val.APIs.DockerHub)
+		val.addDiagnostic(
+			utils.RuleDockerImageTag,
+			utils.CreateDiagnosticFromRange(
+				img.ImageRange,
+				protocol.DiagnosticSeverityHint,
+				"It is recommended to set explicit tags",
+				actions,
+			),
+		)
+	}
 }
 
+// WindowsExecutor
+
 func (val Validate) validateWindowsExecutor(executor ast.WindowsExecutor) {
 	// Same resource class as Linux
 	val.checkIfValidResourceClass(executor.ResourceClass, ValidWindowsResourceClasses, executor.ResourceClassRange)
@@ -239,9 +247,13 @@ func (val Validate) checkIfValidResourceClass(resourceClass string, validResourc
 		utils.FindInArray(validResourceClasses, resourceClass) == -1 &&
 		!val.Doc.IsSelfHostedRunner(resourceClass) {
 
-		val.addDiagnostic(utils.CreateErrorDiagnosticFromRange(
+		val.addDiagnostic(utils.RuleResourceClass, utils.CreateErrorDiagnosticFromRange(
 			resourceClassRange,
-			fmt.Sprintf("Invalid resource class: \"%s\"", resourceClass),
+			fmt.Sprintf(
+				"Invalid resource class: \"%s\", valid classes are: %s",
+				resourceClass,
+				strings.Join(validResourceClasses, ", "),
+			),
 		))
 	}
 
@@ -258,6 +270,10 @@ type RegistryNamespace struct {
 }
 
 func (val Validate) validateExecutorNamespace(resourceClass string, resourceClassRange protocol.Range) {
+	if val.Context.IsOffline() {
+		return
+	}
+
 	client := utils.NewClient(val.Context.Api.HostUrl, "graphql-unstable", val.Context.Api.Token, false)
 
 	query := `query($name: String!) {
@@ -279,7 +295,7 @@ func (val Validate) validateExecutorNamespace(resourceClass string, resourceClas
 	}
 
 	if response.RegistryNameSpace == nil {
-		val.addDiagnostic(utils.CreateErrorDiagnosticFromRange(
+		val.addDiagnostic(utils.RuleResourceClass, utils.CreateErrorDiagnosticFromRange(
 			resourceClassRange,
 			fmt.Sprintf("Namespace \"%s\" does not exist", resourceClass),
 		))
@@ -294,6 +310,7 @@ func (val Validate) validateExecutorReference(executor string, rng protocol.Rang
 			if possibleOrbName, couldBeOrbReference := val.Doc.CouldBeOrbReference(executor); couldBeOrbReference &&
 				!val.Doc.IsOrbReference(executor) {
 				val.addDiagnostic(
+					utils.RuleUnknownExecutor,
 					protocol.Diagnostic{
 						Range:    rng,
 						Message:  fmt.Sprintf("Cannot find orb %s. Looking for executor named %s.", possibleOrbName, executor),
@@ -301,10 +318,16 @@ func (val Validate) validateExecutorReference(executor string, rng protocol.Rang
 					},
 				)
 			} else {
+				message := fmt.Sprintf("Executor `%s` does not exist", executor)
+				if closest, found := utils.FindClosestMatch(executor, val.getExecutorNames()); found {
+					message += fmt.Sprintf(", did you mean %s?", closest)
+				}
+
 				val.addDiagnostic(
+					utils.RuleUnknownExecutor,
 					protocol.Diagnostic{
 						Range:    rng,
-						Message:  fmt.Sprintf("Executor `%s` does not exist", executor),
+						Message:  message,
 						Severity: protocol.DiagnosticSeverityError,
 					},
 				)
@@ -312,3 +335,26 @@ func (val Validate) validateExecutorReference(executor string, rng protocol.Rang
 		}
 	}
 }
+
+// The local executors and the ones of the orbs that have been fetched, named
+// as they are referenced
+func (val Validate) getExecutorNames() []string {
+	names := []string{}
+	for name := range val.Doc.Executors {
+		names = append(names, name)
+	}
+
+	for alias, orb := range val.Doc.Orbs {
+		orbInfo := val.Cache.OrbCache.GetOrb(orb.Url.GetOrbID())
+		if orbInfo == nil {
+			continue
+		}
+
+		for name := range orbInfo.Executors {
+			names = append(names, alias+"/"+name)
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,70 @@
+package validate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// Checks that the jobs and the executors, local orbs included, set a single
+// executor: the keys conflicting in the configuration schema, such as docker
+// and machine or a named executor and docker, are reported
+func (val Validate) ValidateExecutorKeys() {
+	val.iterateOnMapping(parser.GetBlockMappingNode(val.Doc.RootNode), func(key string, _ *sitter.Node, value *sitter.Node) {
+		if key != "orbs" {
+			val.validateSectionExecutorKeys(key, value)
+			return
+		}
+
+		val.iterateOnMapping(parser.GetChildMapping(value), func(_ string, _ *sitter.Node, orb *sitter.Node) {
+			val.iterateOnMapping(parser.GetChildMapping(orb), func(key string, _ *sitter.Node, value *sitter.Node) {
+				val.validateSectionExecutorKeys(key, value)
+			})
+		})
+	})
+}
+
+func (val Validate) validateSectionExecutorKeys(section string, sectionNode *sitter.Node) {
+	entity := map[string]string{"jobs": "a job", "executors": "an executor"}[section]
+	if entity == "" {
+		return
+	}
+
+	val.iterateOnMapping(parser.GetChildMapping(sectionNode), func(name string, _ *sitter.Node, value *sitter.Node) {
+		schema := utils.GetConfigKeyAtPath([]string{section, name})
+
+		keys := []string{}
+		keyNodes := map[string]*sitter.Node{}
+		val.iterateOnMapping(parser.GetChildMapping(value), func(key string, keyNode *sitter.Node, _ *sitter.Node) {
+			if _, ok := keyNodes[key]; !ok {
+				keys = append(keys, key)
+				keyNodes[key] = keyNode
+			}
+		})
+
+		for _, key := range keys {
+			child := schema.GetKey(key)
+			if child == nil {
+				continue
+			}
+
+			conflicting := []string{}
+			for _, other := range keys {
+				if utils.FindInArray(child.Conflicts, other) != -1 {
+					conflicting = append(conflicting, other)
+				}
+			}
+			if len(conflicting) == 0 {
+				continue
+			}
+
+			val.addDiagnostic(utils.RuleExecutorKeys, utils.CreateErrorDiagnosticFromRange(
+				val.Doc.NodeToRange(keyNodes[key]),
+				fmt.Sprintf("%s can not be used along %s, only one executor can be set for %s", key, strings.Join(conflicting, " and "), entity),
+			))
+		}
+	})
+}
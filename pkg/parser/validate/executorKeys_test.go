@@ -0,0 +1,165 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"go.lsp.dev/protocol"
+)
+
+func TestValidateExecutorKeys(t *testing.T) {
+	testCases := []struct {
+		name        string
+		yamlContent string
+		diagnostics []protocol.Diagnostic
+	}{
+		{
+			name: "Single executor",
+			yamlContent: `version: 2.1
+
+executors:
+  node:
+    docker:
+      - image: cimg/node:lts
+
+jobs:
+  build:
+    executor: node
+    steps:
+      - checkout
+  test:
+    machine:
+      image: ubuntu-2204:current
+    steps:
+      - checkout
+`,
+			diagnostics: []protocol.Diagnostic{},
+		},
+		{
+			name: "Docker and machine on a job",
+			yamlContent: `version: 2.1
+
+jobs:
+  build:
+    docker:
+      - image: cimg/node:lts
+    machine:
+      image: ubuntu-2204:current
+    steps:
+      - checkout
+`,
+			diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleExecutorKeys, utils.CreateErrorDiagnosticFromRange(createRange(4, 4, 10), "docker can not be used along machine, only one executor can be set for a job")),
+				utils.WithDiagnosticRule(utils.RuleExecutorKeys, utils.CreateErrorDiagnosticFromRange(createRange(6, 4, 11), "machine can not be used along docker, only one executor can be set for a job")),
+			},
+		},
+		{
+			name: "Docker and macos on a job",
+			yamlContent: `version: 2.1
+
+jobs:
+  build:
+    macos:
+      xcode: 15.0.0
+    docker:
+      - image: cimg/node:lts
+    steps:
+      - checkout
+`,
+			diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleExecutorKeys, utils.CreateErrorDiagnosticFromRange(createRange(4, 4, 9), "macos can not be used along docker, only one executor can be set for a job")),
+				utils.WithDiagnosticRule(utils.RuleExecutorKeys, utils.CreateErrorDiagnosticFromRange(createRange(6, 4, 10), "docker can not be used along macos, only one executor can be set for a job")),
+			},
+		},
+		{
+			name: "Machine and macos on a job",
+			yamlContent: `version: 2.1
+
+jobs:
+  build:
+    machine: true
+    macos:
+      xcode: 15.0.0
+    steps:
+      - checkout
+`,
+			diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleExecutorKeys, utils.CreateErrorDiagnosticFromRange(createRange(4, 4, 11), "machine can not be used along macos, only one executor can be set for a job")),
+				utils.WithDiagnosticRule(utils.RuleExecutorKeys, utils.CreateErrorDiagnosticFromRange(createRange(5, 4, 9), "macos can not be used along machine, only one executor can be set for a job")),
+			},
+		},
+		{
+			name: "Named executor along inline executors",
+			yamlContent: `version: 2.1
+
+executors:
+  node:
+    docker:
+      - image: cimg/node:lts
+
+jobs:
+  build:
+    executor: node
+    docker:
+      - image: cimg/node:lts
+    machine: true
+    steps:
+      - checkout
+`,
+			diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleExecutorKeys, utils.CreateErrorDiagnosticFromRange(createRange(9, 4, 12), "executor can not be used along docker and machine, only one executor can be set for a job")),
+				utils.WithDiagnosticRule(utils.RuleExecutorKeys, utils.CreateErrorDiagnosticFromRange(createRange(10, 4, 10), "docker can not be used along executor and machine, only one executor can be set for a job")),
+				utils.WithDiagnosticRule(utils.RuleExecutorKeys, utils.CreateErrorDiagnosticFromRange(createRange(12, 4, 11), "machine can not be used along executor and docker, only one executor can be set for a job")),
+			},
+		},
+		{
+			name: "Executor definitions",
+			yamlContent: `version: 2.1
+
+executors:
+  linux:
+    docker:
+      - image: cimg/base:stable
+    machine: true
+  windows:
+    windows:
+      name: win/default
+    macos:
+      xcode: 15.0.0
+`,
+			diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleExecutorKeys, utils.CreateErrorDiagnosticFromRange(createRange(4, 4, 10), "docker can not be used along machine, only one executor can be set for an executor")),
+				utils.WithDiagnosticRule(utils.RuleExecutorKeys, utils.CreateErrorDiagnosticFromRange(createRange(6, 4, 11), "machine can not be used along docker, only one executor can be set for an executor")),
+				utils.WithDiagnosticRule(utils.RuleExecutorKeys, utils.CreateErrorDiagnosticFromRange(createRange(8, 4, 11), "windows can not be used along macos, only one executor can be set for an executor")),
+				utils.WithDiagnosticRule(utils.RuleExecutorKeys, utils.CreateErrorDiagnosticFromRange(createRange(10, 4, 9), "macos can not be used along windows, only one executor can be set for an executor")),
+			},
+		},
+		{
+			name: "Local orb jobs",
+			yamlContent: `version: 2.1
+
+orbs:
+  local:
+    jobs:
+      build:
+        executor: default
+        machine: true
+        steps:
+          - checkout
+`,
+			diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleExecutorKeys, utils.CreateErrorDiagnosticFromRange(createRange(6, 8, 16), "executor can not be used along machine, only one executor can be set for a job")),
+				utils.WithDiagnosticRule(utils.RuleExecutorKeys, utils.CreateErrorDiagnosticFromRange(createRange(7, 8, 15), "machine can not be used along executor, only one executor can be set for a job")),
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			val := CreateValidateFromYAML(tt.yamlContent)
+			val.ValidateExecutorKeys()
+
+			CompareDiagnostics(t, &tt.diagnostics, val.Diagnostics)
+		})
+	}
+}
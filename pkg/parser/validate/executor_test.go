@@ -1,9 +1,12 @@
 package validate
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"github.com/stretchr/testify/assert"
 	"go.lsp.dev/protocol"
 )
 
@@ -35,7 +38,8 @@ workflows:
 			Diagnostics: []protocol.Diagnostic{},
 		},
 		{
-			Name: "flag resource class error",
+			Name:       "flag resource class error",
+			OnlyErrors: true,
 			YamlContent: `version: 2.1
 
 executors:
@@ -44,13 +48,202 @@ executors:
       xcode: "15.1.0"
     resource_class: large`,
 			Diagnostics: []protocol.Diagnostic{
-				utils.CreateErrorDiagnosticFromRange(protocol.Range{
+				utils.WithDiagnosticRule(utils.RuleResourceClass, utils.CreateErrorDiagnosticFromRange(protocol.Range{
 					Start: protocol.Position{Line: 6, Character: 4},
 					End:   protocol.Position{Line: 6, Character: 0x19},
-				}, "Invalid resource class: \"large\""),
+				}, "Invalid resource class: \"large\", valid classes are: "+strings.Join(ValidMacOSResourceClasses, ", "))),
 			},
 		},
 	}
 
 	CheckYamlErrors(t, testCases)
 }
+
+func TestJobResourceClassValidation(t *testing.T) {
+	testCases := []ValidateTestCase{
+		{
+			Name: "Job resource class must match its named executor",
+			YamlContent: `version: 2.1
+
+executors:
+  mac:
+    macos:
+      xcode: "15.1.0"
+
+jobs:
+  build:
+    executor: mac
+    resource_class: 2xlarge
+    steps:
+      - checkout
+
+workflows:
+  main:
+    jobs:
+      - build`,
+			OnlyErrors: true,
+			Diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleResourceClass, utils.CreateErrorDiagnosticFromRange(protocol.Range{
+					Start: protocol.Position{Line: 10, Character: 4},
+					End:   protocol.Position{Line: 10, Character: 27},
+				}, "Invalid resource class: \"2xlarge\", valid classes are: "+strings.Join(ValidMacOSResourceClasses, ", "))),
+			},
+		},
+		{
+			Name: "Job resource class valid for its named executor",
+			YamlContent: `version: 2.1
+
+executors:
+  machine:
+    machine:
+      image: ubuntu-2204:2023.07.2
+
+jobs:
+  build:
+    executor: machine
+    resource_class: arm.large
+    steps:
+      - checkout
+
+workflows:
+  main:
+    jobs:
+      - build`,
+			OnlyErrors: true,
+		},
+	}
+
+	CheckYamlErrors(t, testCases)
+}
+
+func TestDeprecatedImages(t *testing.T) {
+	testCases := []struct {
+		Name        string
+		YamlContent string
+		Diagnostics []ComparableDiagnostic
+	}{
+		{
+			Name: "Should warn about end-of-life convenience images",
+			YamlContent: `version: 2.1
+
+executors:
+  node:
+    docker:
+      - image: cimg/node:14.17`,
+			Diagnostics: []ComparableDiagnostic{
+				{
+					Severity: protocol.DiagnosticSeverityWarning,
+					Message:  "Image cimg/node:14.17 is deprecated: This Node.js version reached its end of life. Use cimg/node:lts instead",
+					Actions: []ComparableAction{
+						{Title: "Use cimg/node:lts", Writes: []string{"image: cimg/node:lts"}},
+					},
+				},
+			},
+		},
+		{
+			Name: "Should not warn about maintained versions of deprecated images",
+			YamlContent: `version: 2.1
+
+executors:
+  node:
+    docker:
+      - image: cimg/node:140.1`,
+			Diagnostics: []ComparableDiagnostic{},
+		},
+		{
+			Name: "Should warn about removed machine images rather than reporting them as invalid",
+			YamlContent: `version: 2.1
+
+executors:
+  machine:
+    machine:
+      image: ubuntu-1604:202007-01`,
+			Diagnostics: []ComparableDiagnostic{
+				{
+					Severity: protocol.DiagnosticSeverityWarning,
+					Message:  "Image ubuntu-1604:202007-01 is deprecated: Ubuntu 16.04 machine images have been removed. Use ubuntu-2204:current instead",
+					Actions: []ComparableAction{
+						{Title: "Use ubuntu-2204:current", Writes: []string{"image: ubuntu-2204:current"}},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			val := CreateValidateFromYAML(tt.YamlContent)
+			val.APIs.DockerHub = DockerHubMock{}
+			val.Context.IgnoreUnusedDefinitions = true
+			val.Validate(false)
+
+			compareDiagnostics(t, tt.Diagnostics, *val.Diagnostics)
+		})
+	}
+}
+
+func TestExecutorReferences(t *testing.T) {
+	content := `version: 2.1
+
+orbs:
+  node: circleci/node@5.1.0
+
+executors:
+  linux-executor:
+    docker:
+      - image: cimg/base:2023.01
+
+jobs:
+  build:
+    executor: linux-executr
+    steps:
+      - checkout
+  test:
+    executor:
+      name: linux-executr
+    steps:
+      - checkout
+  deploy:
+    executor: node/defaut
+    steps:
+      - checkout
+  lint:
+    executor: linux-executor
+    steps:
+      - checkout
+
+workflows:
+  main:
+    jobs:
+      - build
+      - test
+      - deploy
+      - lint
+`
+
+	val := CreateValidateFromYAML(content)
+	val.Cache.OrbCache.SetOrb(&ast.OrbInfo{
+		OrbParsedAttributes: ast.OrbParsedAttributes{
+			Executors: map[string]ast.Executor{
+				"default": ast.DockerExecutor{},
+			},
+		},
+	}, "circleci/node@5.1.0")
+	val.APIs.DockerHub = DockerHubMock{}
+	val.Validate(false)
+
+	expected := map[uint32]string{
+		12: "Executor `linux-executr` does not exist, did you mean linux-executor?",
+		16: "Executor `linux-executr` does not exist, did you mean linux-executor?",
+		21: "Cannot find executor defaut in orb node, did you mean node/default?",
+	}
+
+	found := map[uint32]string{}
+	for _, diagnostic := range *val.Diagnostics {
+		if strings.Contains(diagnostic.Message, "xecutor") && diagnostic.Severity == protocol.DiagnosticSeverityError {
+			found[diagnostic.Range.Start.Line] = diagnostic.Message
+		}
+	}
+
+	assert.Equal(t, expected, found)
+}
@@ -0,0 +1,47 @@
+package validate
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+)
+
+func (val Validate) validateFilters(filters ast.WorkflowFilters) {
+	for _, filter := range []ast.BranchesFilter{filters.Branches, filters.Tags} {
+		for _, pattern := range append(append([]ast.TextAndRange{}, filter.Only...), filter.Ignore...) {
+			val.validateFilterPattern(pattern)
+		}
+	}
+}
+
+// Only the patterns between slashes are regular expressions, the other ones
+// are exact names. CircleCI matches them against the whole name, as if they
+// were anchored, which has no incidence on whether they compile
+func (val Validate) validateFilterPattern(pattern ast.TextAndRange) {
+	if len(pattern.Text) < 2 || !strings.HasPrefix(pattern.Text, "/") || !strings.HasSuffix(pattern.Text, "/") {
+		return
+	}
+
+	if _, err := regexp.Compile(pattern.Text[1 : len(pattern.Text)-1]); err != nil {
+		val.addDiagnostic(utils.RuleFilterRegex, utils.CreateErrorDiagnosticFromRange(
+			pattern.Range,
+			fmt.Sprintf("Invalid regular expression: %s", strings.TrimPrefix(err.Error(), "error parsing regexp: "))))
+	}
+}
+
+// Jobs with a tags filter also run for every branch, unless they ignore them
+func (val Validate) validateJobRefFilters(jobRef ast.JobRef) {
+	val.validateFilters(jobRef.Filters)
+
+	tags := jobRef.Filters.Tags
+	if utils.IsDefaultRange(tags.Range) || !utils.IsDefaultRange(jobRef.Filters.Branches.Range) {
+		return
+	}
+
+	val.addDiagnostic(utils.RuleTagsFilter, utils.CreateWarningDiagnosticFromRange(
+		tags.Range,
+		"Tags filter without branches filter, the job also runs for all branches. Add `branches: { ignore: /.*/ }` to only run it for tags"))
+}
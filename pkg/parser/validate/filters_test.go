@@ -0,0 +1,93 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"go.lsp.dev/protocol"
+)
+
+func TestFilters(t *testing.T) {
+	testCases := []ValidateTestCase{
+		{
+			Name: "Broken regular expressions in scalar and list forms",
+			YamlContent: `version: 2.1
+
+jobs:
+  build:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - checkout
+
+workflows:
+  main:
+    jobs:
+      - build:
+          filters:
+            branches:
+              only: /release-[0-9+/
+              ignore:
+                - main
+                - /feature-.*/
+                - "/(wip/"
+  nightly:
+    triggers:
+      - schedule:
+          cron: "0 0 * * *"
+          filters:
+            branches:
+              only: [main, /hotfix-(.*/]
+    jobs:
+      - build`,
+			Diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleFilterRegex, utils.CreateErrorDiagnosticFromRange(protocol.Range{
+					Start: protocol.Position{Line: 15, Character: 20},
+					End:   protocol.Position{Line: 15, Character: 35},
+				}, "Invalid regular expression: missing closing ]: `[0-9+`")),
+				utils.WithDiagnosticRule(utils.RuleFilterRegex, utils.CreateErrorDiagnosticFromRange(protocol.Range{
+					Start: protocol.Position{Line: 19, Character: 18},
+					End:   protocol.Position{Line: 19, Character: 26},
+				}, "Invalid regular expression: missing closing ): `(wip`")),
+				utils.WithDiagnosticRule(utils.RuleFilterRegex, utils.CreateErrorDiagnosticFromRange(protocol.Range{
+					Start: protocol.Position{Line: 26, Character: 27},
+					End:   protocol.Position{Line: 26, Character: 39},
+				}, "Invalid regular expression: missing closing ): `hotfix-(.*`")),
+			},
+		},
+		{
+			Name: "Tags filter without branches filter",
+			YamlContent: `version: 2.1
+
+jobs:
+  build:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - checkout
+
+workflows:
+  main:
+    jobs:
+      - build:
+          filters:
+            tags:
+              only: /^v.*/
+      - build:
+          name: release
+          filters:
+            tags:
+              only: /^v.*/
+            branches:
+              ignore: /.*/`,
+			Diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleTagsFilter, utils.CreateWarningDiagnosticFromRange(protocol.Range{
+					Start: protocol.Position{Line: 15, Character: 14},
+					End:   protocol.Position{Line: 15, Character: 26},
+				}, "Tags filter without branches filter, the job also runs for all branches. Add `branches: { ignore: /.*/ }` to only run it for tags")),
+			},
+		},
+	}
+
+	CheckYamlErrors(t, testCases)
+}
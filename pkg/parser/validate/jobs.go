@@ -24,6 +24,7 @@ func (val Validate) validateSingleJob(job ast.Job) {
 
 	if !utils.HasStoreTestResultStep(job.Steps) && strings.Contains(job.Name, "test") {
 		val.addDiagnostic(
+			utils.RuleMissingTestResults,
 			protocol.Diagnostic{
 				Range:    job.NameRange,
 				Message:  "You may want to add the `store_test_results` step to visualize the test results in CircleCI",
@@ -32,6 +33,8 @@ func (val Validate) validateSingleJob(job ast.Job) {
 		)
 	}
 
+	val.validateJobCheckout(job)
+
 	if job.Executor != "" {
 		if utils.CheckIfOnlyParamUsed(job.Executor) {
 			_, paramName := utils.ExtractParameterName(job.Executor)
@@ -41,6 +44,7 @@ func (val Validate) validateSingleJob(job ast.Job) {
 				isOrbExecutor, err := val.doesOrbExecutorExist(executorDefault, rng)
 				if !param.IsOptional() {
 					val.addDiagnostic(
+						utils.RuleParameter,
 						protocol.Diagnostic{
 							Range: rng,
 							Message: fmt.Sprintf(
@@ -53,13 +57,16 @@ func (val Validate) validateSingleJob(job ast.Job) {
 				} else if val.Context.Api.UseDefaultInstance() && !val.Doc.DoesExecutorExist(executorDefault) &&
 					(!isOrbExecutor && err == nil) {
 					// Error on the default value
+					message := fmt.Sprintf("Parameter is used as executor but executor `%s` does not exist.", executorDefault)
+					if closest, found := utils.FindClosestMatch(executorDefault, val.getExecutorNames()); found {
+						message += fmt.Sprintf(" Did you mean %s?", closest)
+					}
+
 					val.addDiagnostic(
+						utils.RuleUnknownExecutor,
 						protocol.Diagnostic{
-							Range: rng,
-							Message: fmt.Sprintf(
-								"Parameter is used as executor but executor `%s` does not exist.",
-								executorDefault,
-							),
+							Range:    rng,
+							Message:  message,
 							Severity: protocol.DiagnosticSeverityError,
 						},
 					)
@@ -88,22 +95,12 @@ func (val Validate) validateSingleJob(job ast.Job) {
 		}
 	}
 
-	// By default Parallelism is set to -1; see parser.parseSingleJob
-	if job.Parallelism == 0 || job.Parallelism == 1 {
-		val.addDiagnostic(
-			protocol.Diagnostic{
-				Range:    job.ParallelismRange,
-				Message:  "To benefit from parallelism, you should select a value greater than 1. You can read more about how to leverage parallelism to speed up pipelines in the CircleCI docs.",
-				Severity: protocol.DiagnosticSeverityWarning,
-				CodeDescription: &protocol.CodeDescription{
-					Href: "https://circleci.com/docs/parallelism-faster-jobs/",
-				},
-				Source: "More info",
-				Code:   "Docs",
-			},
-		)
+	if job.Executor != "" && job.ResourceClass != "" && !utils.CheckIfOnlyParamUsed(job.Executor) {
+		val.validateJobResourceClass(job)
 	}
 
+	val.validateJobParallelism(job)
+
 	if len(job.Docker.Image) > 0 {
 		val.validateDockerExecutor(job.Docker)
 	} else if job.MacOS.Xcode != "" {
@@ -113,6 +110,19 @@ func (val Validate) validateSingleJob(job ast.Job) {
 	}
 }
 
+// The resource class of a job overrides the one of its executor, it must
+// match the type of the executor it references
+func (val Validate) validateJobResourceClass(job ast.Job) {
+	executor := val.Doc.GetJobExecutor(job, val.Cache)
+
+	validResourceClasses := getValidResourceClasses(executor, job.ResourceClass)
+	if validResourceClasses == nil {
+		return
+	}
+
+	val.checkIfValidResourceClass(job.ResourceClass, validResourceClasses, job.ResourceClassRange)
+}
+
 func (val Validate) checkIfJobIsUsed(job ast.Job) bool {
 	for _, definedJob := range val.Doc.Jobs {
 		if val.checkIfStepsContainStep(definedJob.Steps, job.Name) {
@@ -132,5 +142,5 @@ func (val Validate) checkIfJobIsUsed(job ast.Job) bool {
 }
 
 func (val Validate) jobIsUnused(job ast.Job) {
-	val.addDiagnostic(utils.CreateWarningDiagnosticFromRange(job.NameRange, "Job is unused"))
+	val.addUnusedDiagnostic(utils.RuleUnusedJob, job.NameRange, "Job is unused")
 }
@@ -10,10 +10,10 @@ func (val Validate) CheckNames() {
 	for _, workflow := range val.Doc.Workflows {
 		for _, job := range val.Doc.Jobs {
 			if workflow.Name == job.Name {
-				val.addDiagnostic(utils.CreateWarningDiagnosticFromRange(
+				val.addDiagnostic(utils.RuleAmbiguousName, utils.CreateWarningDiagnosticFromRange(
 					workflow.NameRange,
 					fmt.Sprintf("The name \"%s\" is already used to define a job. You might want to use a different name them to avoid confusion.", workflow.Name)))
-				val.addDiagnostic(utils.CreateWarningDiagnosticFromRange(
+				val.addDiagnostic(utils.RuleAmbiguousName, utils.CreateWarningDiagnosticFromRange(
 					job.NameRange,
 					fmt.Sprintf("The name \"%s\" is already used to define a workflow. You might want to use a different name them to avoid confusion.", job.Name)))
 			}
@@ -21,10 +21,10 @@ func (val Validate) CheckNames() {
 
 		for _, command := range val.Doc.Commands {
 			if workflow.Name == command.Name {
-				val.addDiagnostic(utils.CreateWarningDiagnosticFromRange(
+				val.addDiagnostic(utils.RuleAmbiguousName, utils.CreateWarningDiagnosticFromRange(
 					workflow.NameRange,
 					fmt.Sprintf("The name \"%s\" is already used to define a command. You might want to use a different name them to avoid confusion.", workflow.Name)))
-				val.addDiagnostic(utils.CreateWarningDiagnosticFromRange(
+				val.addDiagnostic(utils.RuleAmbiguousName, utils.CreateWarningDiagnosticFromRange(
 					command.NameRange,
 					fmt.Sprintf("The name \"%s\" is already used to define a workflow. You might want to use a different name them to avoid confusion.", command.Name)))
 			}
@@ -34,10 +34,10 @@ func (val Validate) CheckNames() {
 	for _, job := range val.Doc.Jobs {
 		for _, command := range val.Doc.Commands {
 			if job.Name == command.Name {
-				val.addDiagnostic(utils.CreateWarningDiagnosticFromRange(
+				val.addDiagnostic(utils.RuleAmbiguousName, utils.CreateWarningDiagnosticFromRange(
 					job.NameRange,
 					fmt.Sprintf("The name \"%s\" is already used to define a command. You might want to use a different name them to avoid confusion.", job.Name)))
-				val.addDiagnostic(utils.CreateWarningDiagnosticFromRange(
+				val.addDiagnostic(utils.RuleAmbiguousName, utils.CreateWarningDiagnosticFromRange(
 					command.NameRange,
 					fmt.Sprintf("The name \"%s\" is already used to define a job. You might want to use a different name them to avoid confusion.", command.Name)))
 			}
@@ -1,6 +1,7 @@
 package validate
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
@@ -15,6 +16,7 @@ import (
 func (val Validate) ValidateOrbs() {
 	if len(val.Doc.Orbs) == 0 && len(val.Doc.LocalOrbs) == 0 && !utils.IsDefaultRange(val.Doc.OrbsRange) {
 		val.addDiagnostic(
+			utils.RuleEmptySection,
 			utils.CreateEmptyAssignationWarning(val.Doc.OrbsRange),
 		)
 
@@ -43,6 +45,7 @@ func (val Validate) validateSingleOrb(orb ast.Orb) {
 		}
 
 		val.addDiagnostic(
+			utils.RuleOrb,
 			utils.CreateErrorDiagnosticFromRange(
 				orb.Range,
 				message,
@@ -54,14 +57,19 @@ func (val Validate) validateSingleOrb(orb ast.Orb) {
 
 	orbVersion, err := val.Doc.GetOrFetchOrbInfo(orb, val.Cache)
 
+	// The orb is neither cached nor persisted, nothing is known about it
+	if errors.Is(err, utils.ErrOffline) {
+		return
+	}
+
 	if err != nil {
 		if strings.HasPrefix(err.Error(), "could not find orb") {
-			val.addDiagnostic(utils.CreateErrorDiagnosticFromRange(
+			val.addDiagnostic(utils.RuleOrb, utils.CreateErrorDiagnosticFromRange(
 				orb.Range,
 				fmt.Sprintf("Unknown version %s for orb %s", orb.Url.Version, orb.Url.Name),
 			))
 		} else {
-			val.addDiagnostic(utils.CreateErrorDiagnosticFromRange(
+			val.addDiagnostic(utils.RuleOrb, utils.CreateErrorDiagnosticFromRange(
 				orb.Range,
 				fmt.Sprintf("error while retrieving orb %s", orb.Url.GetOrbID()),
 			))
@@ -70,7 +78,7 @@ func (val Validate) validateSingleOrb(orb ast.Orb) {
 
 	// Adding diagnostics based on versions
 	if orbVersion == nil {
-		val.addDiagnostic(utils.CreateErrorDiagnosticFromRange(
+		val.addDiagnostic(utils.RuleOrb, utils.CreateErrorDiagnosticFromRange(
 			orb.Range,
 			"Orb or version not found",
 		))
@@ -95,6 +103,7 @@ func (val Validate) validateSingleOrb(orb ast.Orb) {
 		}
 
 		val.addDiagnostic(
+			utils.RuleOrbVersion,
 			utils.CreateDiagnosticFromRange(
 				orb.Range,
 				severity,
@@ -180,24 +189,26 @@ func (val Validate) checkIfOrbIsUsed(orb ast.Orb) bool {
 }
 
 func (val Validate) orbIsUnused(orb ast.Orb) {
-	val.addDiagnostic(utils.CreateWarningDiagnosticFromRange(
+	val.addDiagnostic(utils.RuleUnusedOrb, utils.CreateWarningDiagnosticFromRange(
 		orb.Range,
 		"Orb is unused",
 	))
 }
 
 func (val Validate) validateOrbExecutor(executorName string, executorRange protocol.Range) {
-	if val.Doc.IsFromUnfetchableOrb(executorName) {
+	if val.Doc.IsFromUnfetchableOrb(executorName, val.Cache) {
 		return
 	}
 
 	orbExecutorExist, err := val.doesOrbExecutorExist(executorName, executorRange)
 	if !orbExecutorExist && err == nil {
 		splittedName := strings.Split(executorName, "/")
-		val.addDiagnostic(utils.CreateErrorDiagnosticFromRange(
-			executorRange,
-			fmt.Sprintf("Cannot find executor %s in orb %s", splittedName[1], splittedName[0]),
-		))
+		message := fmt.Sprintf("Cannot find executor %s in orb %s", splittedName[1], splittedName[0])
+		if closest, found := utils.FindClosestMatch(executorName, val.getExecutorNames()); found {
+			message += fmt.Sprintf(", did you mean %s?", closest)
+		}
+
+		val.addDiagnostic(utils.RuleUnknownExecutor, utils.CreateErrorDiagnosticFromRange(executorRange, message))
 	}
 }
 
@@ -212,7 +223,7 @@ func (val Validate) doesOrbExecutorExist(executorName string, executorRange prot
 	orb, ok := val.Doc.Orbs[splittedName[0]]
 	if !ok {
 		err := fmt.Errorf("unknown orb referenced: %s", splittedName[0])
-		val.addDiagnostic(utils.CreateWarningDiagnosticFromRange(
+		val.addDiagnostic(utils.RuleUnknownExecutor, utils.CreateWarningDiagnosticFromRange(
 			executorRange,
 			err.Error(),
 		))
@@ -220,8 +231,11 @@ func (val Validate) doesOrbExecutorExist(executorName string, executorRange prot
 	}
 
 	remoteOrb, err := parser.GetOrbInfo(orb.Url.GetOrbID(), val.Cache, val.Context)
+	if errors.Is(err, utils.ErrOffline) {
+		return false, err
+	}
 	if err != nil {
-		val.addDiagnostic(utils.CreateWarningDiagnosticFromRange(
+		val.addDiagnostic(utils.RuleUnknownExecutor, utils.CreateWarningDiagnosticFromRange(
 			executorRange,
 			fmt.Sprintf("Invalid orb or error trying to fetch it: %+v", err),
 		))
@@ -42,11 +42,11 @@ orbs:
         macos:
           xcode: 12.5`,
 			Diagnostics: []protocol.Diagnostic{
-				utils.CreateErrorDiagnosticFromRange(protocol.Range{
+				utils.WithDiagnosticRule(utils.RuleXcodeVersion, utils.CreateErrorDiagnosticFromRange(protocol.Range{
 					Start: protocol.Position{Line: 7, Character: 10},
 					End:   protocol.Position{Line: 7, Character: 21},
 				},
-					"Invalid Xcode version 12.5"),
+					"Invalid Xcode version 12.5")),
 			},
 		},
 		{
@@ -63,11 +63,11 @@ orbs:
           - run: echo "Hello world"
           - localorb/echo`,
 			Diagnostics: []protocol.Diagnostic{
-				utils.CreateErrorDiagnosticFromRange(protocol.Range{
+				utils.WithDiagnosticRule(utils.RuleUnknownStep, utils.CreateErrorDiagnosticFromRange(protocol.Range{
 					Start: protocol.Position{Line: 9, Character: 12},
 					End:   protocol.Position{Line: 9, Character: 25},
 				},
-					"Cannot find declaration for step localorb/echo"),
+					"Cannot find declaration for step localorb/echo")),
 			},
 		},
 		{
@@ -100,26 +100,26 @@ jobs:
       - run: echo "Hello world"`,
 			// We want an error on the orb and a warning on the executor
 			Diagnostics: []protocol.Diagnostic{
-				utils.CreateErrorDiagnosticFromRange(protocol.Range{
+				utils.WithDiagnosticRule(utils.RuleOrb, utils.CreateErrorDiagnosticFromRange(protocol.Range{
 					Start: protocol.Position{Line: 3, Character: 2},
 					End:   protocol.Position{Line: 3, Character: 28},
 				},
-					"Orb circleci/toto does not exist or is private."),
-				utils.CreateWarningDiagnosticFromRange(protocol.Range{
+					"Orb circleci/toto does not exist or is private.")),
+				utils.WithDiagnosticRule(utils.RuleUnknownExecutor, utils.CreateWarningDiagnosticFromRange(protocol.Range{
 					Start: protocol.Position{Line: 7, Character: 4},
 					End:   protocol.Position{Line: 7, Character: 24},
 				},
-					"Invalid orb or error trying to fetch it: could not find orb circleci/toto@1.0.0"),
-				utils.CreateWarningDiagnosticFromRange(protocol.Range{
+					"Invalid orb or error trying to fetch it: could not find orb circleci/toto@1.0.0")),
+				utils.WithDiagnosticRule(utils.RuleUnusedJob, utils.CreateUnusedDiagnosticFromRange(protocol.Range{
 					Start: protocol.Position{Line: 6, Character: 2},
 					End:   protocol.Position{Line: 6, Character: 10},
 				},
-					"Job is unused"),
+					"Job is unused")),
 			},
 		},
 		{
 			Name: "Local orb with job",
-			YamlContent: `version: 2.1,
+			YamlContent: `version: 2.1
 
 orbs:
   localorb:
@@ -271,3 +271,34 @@ func TestOrbStepsUsedInParameters(t *testing.T) {
 		}
 	}
 }
+
+func TestOrbValidationOffline(t *testing.T) {
+	val := CreateValidateFromYAML(`version: 2.1
+
+orbs:
+  tools: some-namespace/offline-tools@1.0.0
+
+executors:
+  default:
+    docker:
+      - image: cimg/base:2023.01
+
+jobs:
+  build:
+    executor: tools/default
+    steps:
+      - tools/install-packages
+
+workflows:
+  test:
+    jobs:
+      - build
+      - tools/test
+`)
+	val.Context.SetOffline(true)
+	val.Context.IgnoreUnusedDefinitions = true
+	val.Validate(false)
+
+	// Nothing is known about the orb, which is not cached
+	assert.Empty(t, *val.Diagnostics)
+}
@@ -0,0 +1,110 @@
+package validate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"go.lsp.dev/protocol"
+)
+
+// Tests are split across the executors of a job by this command
+const testSplittingCommand = "circleci tests split"
+
+// Parallelism must be a positive integer, and is only useful when the job
+// splits its tests across the executors, which is detected by looking for
+// the split command in the run steps, commands included
+func (val Validate) validateJobParallelism(job ast.Job) {
+	if strings.Contains(job.RawParallelism, "<<") {
+		return
+	}
+
+	if job.RawParallelism != "" && job.Parallelism < 1 {
+		val.addDiagnostic(utils.RuleInvalidParallelism, utils.CreateErrorDiagnosticFromRange(
+			job.ParallelismRange,
+			fmt.Sprintf("Parallelism must be a positive integer, got %s", job.RawParallelism),
+		))
+		return
+	}
+
+	splittingStep, opaque := val.findTestSplittingStep(job.Steps, map[string]bool{})
+
+	switch {
+	case job.RawParallelism == "":
+		if splittingStep != nil {
+			val.addDiagnostic(utils.RuleParallelism, utils.CreateWarningDiagnosticFromRange(
+				splittingStep.GetRange(),
+				"Tests are split but the job runs on a single executor, set parallelism to a value greater than 1 to run them in parallel",
+			))
+		}
+
+	case job.Parallelism == 1:
+		if splittingStep != nil {
+			val.addDiagnostic(utils.RuleParallelism, utils.CreateWarningDiagnosticFromRange(
+				job.ParallelismRange,
+				"Tests are split but parallelism is 1, set a value greater than 1 to run them in parallel",
+			))
+			return
+		}
+
+		val.addDiagnostic(
+			utils.RuleParallelism,
+			protocol.Diagnostic{
+				Range:    job.ParallelismRange,
+				Message:  "To benefit from parallelism, you should select a value greater than 1. You can read more about how to leverage parallelism to speed up pipelines in the CircleCI docs.",
+				Severity: protocol.DiagnosticSeverityWarning,
+				CodeDescription: &protocol.CodeDescription{
+					Href: "https://circleci.com/docs/parallelism-faster-jobs/",
+				},
+				Source: "More info",
+			},
+		)
+
+	case splittingStep == nil && !opaque:
+		val.addDiagnostic(utils.RuleParallelism, utils.CreateWarningDiagnosticFromRange(
+			job.ParallelismRange,
+			fmt.Sprintf("Parallelism is %d but the tests are never split with `%s`, every executor runs the same steps", job.Parallelism, testSplittingCommand),
+		))
+	}
+}
+
+// Step of the given ones that splits the tests, directly or through the
+// commands it runs. Also returns whether some steps could not be inspected,
+// such as orb commands or parameters
+func (val Validate) findTestSplittingStep(steps []ast.Step, visitedCommands map[string]bool) (ast.Step, bool) {
+	opaque := false
+
+	for _, step := range steps {
+		switch step := step.(type) {
+		case ast.Run:
+			if strings.Contains(step.Command, testSplittingCommand) {
+				return step, opaque
+			}
+			opaque = opaque || strings.Contains(step.Command, "<<")
+
+		case ast.Steps:
+			opaque = true
+
+		case ast.NamedStep:
+			command, ok := val.Doc.Commands[step.Name]
+			if !ok {
+				opaque = opaque || !val.Doc.IsBuiltIn(step.Name)
+				continue
+			}
+
+			if visitedCommands[step.Name] {
+				continue
+			}
+			visitedCommands[step.Name] = true
+
+			splittingStep, commandOpaque := val.findTestSplittingStep(command.Steps, visitedCommands)
+			if splittingStep != nil {
+				return step, opaque
+			}
+			opaque = opaque || commandOpaque
+		}
+	}
+
+	return nil, opaque
+}
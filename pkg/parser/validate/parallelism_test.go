@@ -0,0 +1,195 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"go.lsp.dev/protocol"
+)
+
+func TestValidateJobParallelism(t *testing.T) {
+	testCases := []struct {
+		name        string
+		yamlContent string
+		diagnostics []protocol.Diagnostic
+	}{
+		{
+			name: "Split tests",
+			yamlContent: `version: 2.1
+
+jobs:
+  test:
+    docker:
+      - image: cimg/go:1.21
+    parallelism: 4
+    steps:
+      - checkout
+      - run: go test $(go list ./... | circleci tests split --split-by=timings)
+`,
+			diagnostics: []protocol.Diagnostic{},
+		},
+		{
+			name: "Split tests in a command",
+			yamlContent: `version: 2.1
+
+commands:
+  split-tests:
+    steps:
+      - run:
+          command: circleci tests glob "**/*_test.go" | circleci tests split
+
+jobs:
+  test:
+    docker:
+      - image: cimg/go:1.21
+    parallelism: 4
+    steps:
+      - split-tests
+`,
+			diagnostics: []protocol.Diagnostic{},
+		},
+		{
+			name: "Parameterized parallelism",
+			yamlContent: `version: 2.1
+
+jobs:
+  test:
+    parameters:
+      executors:
+        type: integer
+        default: 1
+    docker:
+      - image: cimg/go:1.21
+    parallelism: << parameters.executors >>
+    steps:
+      - checkout
+`,
+			diagnostics: []protocol.Diagnostic{},
+		},
+		{
+			name: "Non positive parallelism",
+			yamlContent: `version: 2.1
+
+jobs:
+  test:
+    docker:
+      - image: cimg/go:1.21
+    parallelism: 0
+    steps:
+      - checkout
+  lint:
+    docker:
+      - image: cimg/go:1.21
+    parallelism: -2
+    steps:
+      - checkout
+`,
+			diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleInvalidParallelism, utils.CreateErrorDiagnosticFromRange(createRange(6, 4, 18), "Parallelism must be a positive integer, got 0")),
+				utils.WithDiagnosticRule(utils.RuleInvalidParallelism, utils.CreateErrorDiagnosticFromRange(createRange(12, 4, 19), "Parallelism must be a positive integer, got -2")),
+			},
+		},
+		{
+			name: "Non integer parallelism",
+			yamlContent: `version: 2.1
+
+jobs:
+  test:
+    docker:
+      - image: cimg/go:1.21
+    parallelism: 2.5
+    steps:
+      - checkout
+  lint:
+    docker:
+      - image: cimg/go:1.21
+    parallelism: many
+    steps:
+      - checkout
+`,
+			diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleInvalidParallelism, utils.CreateErrorDiagnosticFromRange(createRange(6, 4, 20), "Parallelism must be a positive integer, got 2.5")),
+				utils.WithDiagnosticRule(utils.RuleInvalidParallelism, utils.CreateErrorDiagnosticFromRange(createRange(12, 4, 21), "Parallelism must be a positive integer, got many")),
+			},
+		},
+		{
+			name: "Split tests without parallelism",
+			yamlContent: `version: 2.1
+
+jobs:
+  test:
+    docker:
+      - image: cimg/go:1.21
+    steps:
+      - checkout
+      - run: go test $(go list ./... | circleci tests split)
+`,
+			diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleParallelism, utils.CreateWarningDiagnosticFromRange(createRange(8, 8, 11), "Tests are split but the job runs on a single executor, set parallelism to a value greater than 1 to run them in parallel")),
+			},
+		},
+		{
+			name: "Split tests with a parallelism of 1",
+			yamlContent: `version: 2.1
+
+jobs:
+  test:
+    docker:
+      - image: cimg/go:1.21
+    parallelism: 1
+    steps:
+      - checkout
+      - run: go test $(go list ./... | circleci tests split)
+`,
+			diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleParallelism, utils.CreateWarningDiagnosticFromRange(createRange(6, 4, 18), "Tests are split but parallelism is 1, set a value greater than 1 to run them in parallel")),
+			},
+		},
+		{
+			name: "Parallelism without split tests",
+			yamlContent: `version: 2.1
+
+jobs:
+  test:
+    docker:
+      - image: cimg/go:1.21
+    parallelism: 3
+    steps:
+      - checkout
+      - run: go test ./...
+`,
+			diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleParallelism, utils.CreateWarningDiagnosticFromRange(createRange(6, 4, 18), "Parallelism is 3 but the tests are never split with `circleci tests split`, every executor runs the same steps")),
+			},
+		},
+		{
+			name: "Parallelism with orb commands",
+			yamlContent: `version: 2.1
+
+orbs:
+  go: circleci/go@1.9.0
+
+jobs:
+  test:
+    docker:
+      - image: cimg/go:1.21
+    parallelism: 3
+    steps:
+      - checkout
+      - go/test
+`,
+			diagnostics: []protocol.Diagnostic{},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			val := CreateValidateFromYAML(tt.yamlContent)
+			for _, job := range val.Doc.Jobs {
+				val.validateJobParallelism(job)
+			}
+
+			CompareDiagnostics(t, &tt.diagnostics, val.Diagnostics)
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package validate
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// Environment variable names are POSIX names
+var envVarNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+var valueKindNames = map[string]string{
+	"string":  "a string",
+	"integer": "an integer",
+	"float":   "a float",
+	"boolean": "a boolean",
+	"null":    "null",
+	"list":    "a list",
+	"map":     "a map",
+}
+
+// Checks the default value of the parameters declared by the jobs, the
+// commands, the executors and the pipeline against their type
+func (val Validate) ValidateParameterDefaults() {
+	for _, job := range val.Doc.Jobs {
+		val.validateParameterDefaults(job.Parameters)
+	}
+
+	for _, command := range val.Doc.Commands {
+		val.validateParameterDefaults(command.Parameters)
+	}
+
+	for _, executor := range val.Doc.Executors {
+		val.validateParameterDefaults(executor.GetParameters())
+	}
+
+	val.validateParameterDefaults(val.Doc.PipelineParameters)
+}
+
+func (val Validate) validateParameterDefaults(parameters map[string]ast.Parameter) {
+	for _, param := range parameters {
+		defaultNode := val.getParameterDefaultNode(param)
+		if defaultNode == nil {
+			continue
+		}
+
+		text := val.Doc.GetNodeText(defaultNode)
+		kind := val.getValueKind(defaultNode)
+
+		// Interpolated values and aliases are only known once resolved
+		if kind == "" || strings.Contains(text, "<<") {
+			continue
+		}
+
+		switch param.GetType() {
+		case "string":
+			val.checkDefaultKind(param, defaultNode, kind, "string")
+
+		case "integer":
+			val.checkDefaultKind(param, defaultNode, kind, "integer")
+
+		case "boolean":
+			isPlain := parser.GetFirstChild(defaultNode).Type() == "plain_scalar"
+			if kind == "string" && isPlain && utils.IsValidYAMLBooleanValue(text) {
+				val.addDiagnostic(utils.RuleLegacyBoolean, utils.CreateWarningDiagnosticFromRange(
+					val.Doc.NodeToRange(defaultNode),
+					fmt.Sprintf("%s is only a boolean in YAML 1.1, use %t instead", text, utils.GetYAMLBooleanValue(text))))
+				continue
+			}
+			val.checkDefaultKind(param, defaultNode, kind, "boolean")
+
+		case "enum":
+			// Whether the value is one of the enum is checked by the parser
+			val.checkDefaultKind(param, defaultNode, kind, "string")
+
+		case "env_var_name":
+			if !val.checkDefaultKind(param, defaultNode, kind, "string") {
+				continue
+			}
+
+			if !envVarNameRegex.MatchString(text) {
+				val.addDiagnostic(utils.RuleParameterDefault, utils.CreateErrorDiagnosticFromRange(
+					val.Doc.NodeToRange(defaultNode),
+					fmt.Sprintf("Invalid default value for parameter %s: %s is not a valid environment variable name", param.GetName(), text)))
+			}
+
+		case "steps":
+			val.checkDefaultKind(param, defaultNode, kind, "list")
+		}
+	}
+}
+
+func (val Validate) checkDefaultKind(param ast.Parameter, defaultNode *sitter.Node, kind string, expected string) bool {
+	if kind == expected {
+		return true
+	}
+
+	val.addDiagnostic(utils.RuleParameterDefault, utils.CreateErrorDiagnosticFromRange(
+		val.Doc.NodeToRange(defaultNode),
+		fmt.Sprintf("Invalid default value for parameter %s: expected %s, found %s", param.GetName(), valueKindNames[expected], valueKindNames[kind])))
+	return false
+}
+
+// The value of the `default` key of the parameter definition
+func (val Validate) getParameterDefaultNode(param ast.Parameter) *sitter.Node {
+	rng := param.GetRange()
+	paramNode := val.Doc.RootNode.NamedDescendantForPointRange(
+		sitter.Point{Row: rng.Start.Line, Column: rng.Start.Character},
+		sitter.Point{Row: rng.End.Line, Column: rng.End.Character},
+	)
+	if paramNode == nil || paramNode.Type() != "block_mapping_pair" {
+		return nil
+	}
+
+	_, definition := val.Doc.GetKeyValueNodes(paramNode)
+
+	var defaultNode *sitter.Node
+	val.iterateOnMapping(parser.GetChildMapping(definition), func(key string, _ *sitter.Node, value *sitter.Node) {
+		if key == "default" {
+			defaultNode = value
+		}
+	})
+
+	return defaultNode
+}
+
+// Kind of the YAML value, empty for aliases and tagged values
+func (val Validate) getValueKind(node *sitter.Node) string {
+	if parser.GetChildSequence(node) != nil {
+		return "list"
+	}
+	if parser.GetChildMapping(node) != nil {
+		return "map"
+	}
+
+	child := parser.GetFirstChild(node)
+	if child == nil {
+		return ""
+	}
+
+	switch child.Type() {
+	case "double_quote_scalar", "single_quote_scalar", "block_scalar":
+		return "string"
+
+	case "plain_scalar":
+		scalar := parser.GetFirstChild(child)
+		if scalar == nil {
+			return ""
+		}
+
+		switch scalar.Type() {
+		case "string_scalar":
+			return "string"
+		case "integer_scalar":
+			return "integer"
+		case "float_scalar":
+			return "float"
+		case "boolean_scalar":
+			return "boolean"
+		case "null_scalar":
+			return "null"
+		}
+	}
+
+	return ""
+}
@@ -2,6 +2,7 @@ package validate
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
@@ -14,6 +15,7 @@ import (
 func (val Validate) ValidatePipelineParameters() {
 	if len(val.Doc.PipelineParameters) == 0 && !utils.IsDefaultRange(val.Doc.PipelineParametersRange) {
 		val.addDiagnostic(
+			utils.RuleEmptySection,
 			utils.CreateEmptyAssignationWarning(val.Doc.PipelineParametersRange),
 		)
 	}
@@ -25,7 +27,7 @@ func (val Validate) checkIfParamAssigned(params map[string]ast.ParameterValue, d
 	_, assigned := params[definedParam.GetName()]
 
 	if !assigned && !definedParam.IsOptional() {
-		val.addDiagnostic(utils.CreateErrorDiagnosticFromRange(
+		val.addDiagnostic(utils.RuleParameter, utils.CreateErrorDiagnosticFromRange(
 			stepRange,
 			fmt.Sprintf("Parameter %s is required for %s", definedParam.GetName(), stepName)))
 		return false
@@ -46,7 +48,7 @@ func (val Validate) checkParamSimpleType(param ast.ParameterValue, stepName stri
 
 		value := param.Value.(string)
 		if utils.FindInArray(definedParam.(ast.EnumParameter).Enum, value) == -1 {
-			val.addDiagnostic(utils.CreateErrorDiagnosticFromRange(
+			val.addDiagnostic(utils.RuleParameter, utils.CreateErrorDiagnosticFromRange(
 				param.Range,
 				fmt.Sprintf("Parameter %s is not a valid value for %s", value, definedParam.GetName()),
 			))
@@ -68,6 +70,7 @@ func (val Validate) checkParamSimpleType(param ast.ParameterValue, stepName stri
 
 				if !commandExists {
 					val.addDiagnostic(
+						utils.RuleParameter,
 						utils.CreateErrorDiagnosticFromRange(
 							value.Range,
 							fmt.Sprintf("Cannot find a definition for command named %s", commandName),
@@ -123,9 +126,15 @@ func (val Validate) checkParamUsedWithParam(param ast.ParameterValue, stepName s
 }
 
 func (val Validate) CheckIfParamsExist() {
+	workflowConditionRanges := val.getWorkflowConditionRanges()
+
 	checkOnNode := func(match *sitter.QueryMatch) {
 		for _, capture := range match.Captures {
 			node := capture.Node
+			if isInRanges(workflowConditionRanges, val.Doc.NodeToRange(node).Start) {
+				continue
+			}
+
 			content := val.Doc.GetRawNodeText(node)
 			params, err := utils.GetParamsInString(content)
 
@@ -173,11 +182,14 @@ func (val Validate) CheckIfParamsExist() {
 
 				if isPipeline {
 					errorMessage = fmt.Sprintf("Pipeline parameter %s is not defined", param.Name)
+					if closest, found := utils.FindClosestMatch(param.Name, getParameterNames(parameters)); found {
+						errorMessage += fmt.Sprintf(", did you mean %s?", closest)
+					}
 				} else {
 					errorMessage = fmt.Sprintf("Parameter %s is not defined", param.Name)
 				}
 
-				val.addDiagnostic(utils.CreateErrorDiagnosticFromRange(
+				val.addDiagnostic(utils.RuleUndefinedParameter, utils.CreateErrorDiagnosticFromRange(
 					diagnosticRange,
 					errorMessage,
 				))
@@ -187,6 +199,26 @@ func (val Validate) CheckIfParamsExist() {
 
 	parser.ExecQuery(val.Doc.RootNode, "(string_scalar) @string", checkOnNode)
 	parser.ExecQuery(val.Doc.RootNode, "(block_scalar) @string", checkOnNode)
+	parser.ExecQuery(val.Doc.RootNode, "(double_quote_scalar) @string", checkOnNode)
+	parser.ExecQuery(val.Doc.RootNode, "(single_quote_scalar) @string", checkOnNode)
+}
+
+func isInRanges(ranges []protocol.Range, pos protocol.Position) bool {
+	for _, rng := range ranges {
+		if utils.PosInRange(rng, pos) {
+			return true
+		}
+	}
+	return false
+}
+
+func getParameterNames(parameters map[string]ast.Parameter) []string {
+	names := make([]string, 0, len(parameters))
+	for name := range parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 func (val Validate) validateParametersValue(paramsValue map[string]ast.ParameterValue, calledEntity string, entityRange protocol.Range, calledEntityDefinedParams map[string]ast.Parameter, usableParams map[string]ast.Parameter) {
@@ -213,17 +245,30 @@ func (val Validate) validateParametersValue(paramsValue map[string]ast.Parameter
 	}
 
 	for _, param := range paramsValue {
-		if _, ok := calledEntityDefinedParams[param.Name]; !ok {
-			val.addDiagnostic(
-				utils.CreateErrorDiagnosticFromRange(
-					param.Range,
-					fmt.Sprintf("Parameter %s is not defined for %s", param.Name, calledEntity),
-				),
+		if _, ok := calledEntityDefinedParams[param.Name]; !ok && utils.FindInArray(STEP_INVOCATION_KEYS, param.Name) == -1 {
+			val.addUndefinedParameterWarning(
+				fmt.Sprintf("Parameter %s is not defined for %s", param.Name, calledEntity),
+				param.Name,
+				param.Range,
+				calledEntityDefinedParams,
 			)
 		}
 	}
 }
 
+// Keys accepted by every step, whatever the command it invokes
+var STEP_INVOCATION_KEYS = []string{"name"}
+
+// Undefined parameters are ignored when running the pipeline, they are only
+// reported as warnings along with the closest defined parameter
+func (val Validate) addUndefinedParameterWarning(message string, name string, rng protocol.Range, definedParams map[string]ast.Parameter) {
+	if closest, found := utils.FindClosestMatch(name, getParameterNames(definedParams)); found {
+		message += fmt.Sprintf(", did you mean %s?", closest)
+	}
+
+	val.addDiagnostic(utils.RuleUndefinedParameter, utils.CreateWarningDiagnosticFromRange(rng, message))
+}
+
 func (val Validate) checkExecutorParamValue(param ast.ParameterValue) {
 	executorName := ""
 	executorNameRange := param.Range
@@ -233,6 +278,7 @@ func (val Validate) checkExecutorParamValue(param ast.ParameterValue) {
 
 		if !ok || nameParam.Type != "string" {
 			val.addDiagnostic(
+				utils.RuleParameter,
 				utils.CreateErrorDiagnosticFromRange(
 					param.Range,
 					"Missing executor name",
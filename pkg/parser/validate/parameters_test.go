@@ -39,33 +39,174 @@ func TestJobParameterType(t *testing.T) {
 			Name:        "Parameter usage should error when param usage is different from param definition",
 			YamlContent: string(wrongParamFileContent),
 			Diagnostics: []protocol.Diagnostic{
-				utils.CreateErrorDiagnosticFromRange(protocol.Range{
+				utils.WithDiagnosticRule(utils.RuleParameter, utils.CreateErrorDiagnosticFromRange(protocol.Range{
 					Start: protocol.Position{Line: 24, Character: 9},
 					End:   protocol.Position{Line: 24, Character: 54},
-				}, "Parameter skip for build must be a string"),
+				}, "Parameter skip for build must be a string")),
 			},
 		},
 		{
 			Name:        "Parameter usage should error when param usage is different from param definition",
 			YamlContent: string(wrongParamIntegerFileContent),
 			Diagnostics: []protocol.Diagnostic{
-				utils.CreateErrorDiagnosticFromRange(protocol.Range{
+				utils.WithDiagnosticRule(utils.RuleParameter, utils.CreateErrorDiagnosticFromRange(protocol.Range{
 					Start: protocol.Position{Line: 24, Character: 9},
 					End:   protocol.Position{Line: 24, Character: 54},
-				}, "Parameter skip for build must be a boolean"),
+				}, "Parameter skip for build must be a boolean")),
 			},
 		},
 		{
 			Name:        "Parameter usage should error when param usage is different from param definition",
 			YamlContent: string(wrongParamBooleanFileContent),
 			Diagnostics: []protocol.Diagnostic{
-				utils.CreateErrorDiagnosticFromRange(protocol.Range{
+				utils.WithDiagnosticRule(utils.RuleParameter, utils.CreateErrorDiagnosticFromRange(protocol.Range{
 					Start: protocol.Position{Line: 24, Character: 9},
 					End:   protocol.Position{Line: 24, Character: 54},
-				}, "Parameter skip for build must be a boolean"),
+				}, "Parameter skip for build must be a boolean")),
 			},
 		},
 	}
 
 	CheckYamlErrors(t, testCases)
 }
+
+func TestPipelineParameterReferences(t *testing.T) {
+	config := `version: 2.1
+
+parameters:
+  deploy-env:
+    type: string
+    default: staging
+
+jobs:
+  build:
+    parameters:
+      deploy-env-job:
+        type: string
+        default: staging
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - run: echo << pipeline.parameters.deploy-env >> << parameters.deploy-env-job >>
+      - run: echo << pipeline.parameters.deploy-evn >>
+      - run: "echo << pipeline.parameters.unknown >>"
+
+workflows:
+  main:
+    jobs:
+      - build
+`
+
+	testCases := []ValidateTestCase{
+		{
+			Name:        "Undefined pipeline parameters should be reported, with the closest defined one",
+			YamlContent: config,
+			Diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleUndefinedParameter, utils.CreateErrorDiagnosticFromRange(protocol.Range{
+					Start: protocol.Position{Line: 17, Character: 18},
+					End:   protocol.Position{Line: 17, Character: 54},
+				}, "Pipeline parameter deploy-evn is not defined, did you mean deploy-env?")),
+				utils.WithDiagnosticRule(utils.RuleUndefinedParameter, utils.CreateErrorDiagnosticFromRange(protocol.Range{
+					Start: protocol.Position{Line: 18, Character: 19},
+					End:   protocol.Position{Line: 18, Character: 52},
+				}, "Pipeline parameter unknown is not defined")),
+			},
+		},
+		{
+			Name: "References of the workflow conditions should only be reported by the conditions",
+			YamlContent: `version: 2.1
+
+jobs:
+  build:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - checkout
+
+workflows:
+  main:
+    when: << pipeline.parameters.deploy >>
+    jobs:
+      - build
+`,
+			Diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleCondition, utils.CreateErrorDiagnosticFromRange(protocol.Range{
+					Start: protocol.Position{Line: 11, Character: 13},
+					End:   protocol.Position{Line: 11, Character: 39},
+				}, "Pipeline parameter deploy is not defined")),
+			},
+		},
+	}
+
+	CheckYamlErrors(t, testCases)
+}
+
+func TestParameterDefaults(t *testing.T) {
+	config := `version: 2.1
+
+parameters:
+  retries:
+    type: integer
+    default: "3"
+  nightly:
+    type: boolean
+    default: yes
+
+commands:
+  greet:
+    parameters:
+      to:
+        type: string
+        default: [world]
+      loud:
+        type: boolean
+        default: "true"
+      quiet:
+        type: boolean
+        default: false
+    steps:
+      - run: echo << parameters.to >>
+
+jobs:
+  build:
+    parameters:
+      size:
+        type: enum
+        enum: [small, large]
+        default: 1
+      token:
+        type: env_var_name
+        default: GITHUB TOKEN
+      valid-token:
+        type: env_var_name
+        default: GITHUB_TOKEN
+      before:
+        type: steps
+        default: checkout
+      after:
+        type: steps
+        default: []
+      workers:
+        type: integer
+        default: << pipeline.parameters.retries >>
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - checkout
+`
+
+	val := CreateValidateFromYAML(config)
+	val.ValidateParameterDefaults()
+
+	expected := []protocol.Diagnostic{
+		utils.WithDiagnosticRule(utils.RuleParameterDefault, utils.CreateErrorDiagnosticFromRange(createRange(5, 13, 16), "Invalid default value for parameter retries: expected an integer, found a string")),
+		utils.WithDiagnosticRule(utils.RuleLegacyBoolean, utils.CreateWarningDiagnosticFromRange(createRange(8, 13, 16), "yes is only a boolean in YAML 1.1, use true instead")),
+		utils.WithDiagnosticRule(utils.RuleParameterDefault, utils.CreateErrorDiagnosticFromRange(createRange(15, 17, 24), "Invalid default value for parameter to: expected a string, found a list")),
+		utils.WithDiagnosticRule(utils.RuleParameterDefault, utils.CreateErrorDiagnosticFromRange(createRange(18, 17, 23), "Invalid default value for parameter loud: expected a boolean, found a string")),
+		utils.WithDiagnosticRule(utils.RuleParameterDefault, utils.CreateErrorDiagnosticFromRange(createRange(31, 17, 18), "Invalid default value for parameter size: expected a string, found an integer")),
+		utils.WithDiagnosticRule(utils.RuleParameterDefault, utils.CreateErrorDiagnosticFromRange(createRange(34, 17, 29), "Invalid default value for parameter token: GITHUB TOKEN is not a valid environment variable name")),
+		utils.WithDiagnosticRule(utils.RuleParameterDefault, utils.CreateErrorDiagnosticFromRange(createRange(40, 17, 25), "Invalid default value for parameter before: expected a list, found a string")),
+	}
+
+	CompareDiagnostics(t, &expected, val.Diagnostics)
+}
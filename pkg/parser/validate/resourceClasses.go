@@ -0,0 +1,121 @@
+package validate
+
+import (
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
+)
+
+// Resource classes available on CircleCI cloud for each type of executor,
+// sorted by size
+
+type ResourceClass struct {
+	Name string
+
+	// Resources of the class, such as "2 vCPU, 4 GB RAM"
+	Description string
+}
+
+var DockerResourceClasses = []ResourceClass{
+	{"small", "1 vCPU, 2 GB RAM"},
+	{"medium", "2 vCPU, 4 GB RAM"},
+	{"medium+", "3 vCPU, 6 GB RAM"},
+	{"large", "4 vCPU, 8 GB RAM"},
+	{"xlarge", "8 vCPU, 16 GB RAM"},
+	{"2xlarge", "16 vCPU, 32 GB RAM"},
+	{"2xlarge+", "20 vCPU, 40 GB RAM"},
+}
+
+var LinuxResourceClasses = []ResourceClass{
+	{"medium", "2 vCPU, 7.5 GB RAM"},
+	{"large", "4 vCPU, 15 GB RAM"},
+	{"xlarge", "8 vCPU, 32 GB RAM"},
+	{"2xlarge", "16 vCPU, 64 GB RAM"},
+	{"2xlarge+", "32 vCPU, 64 GB RAM"},
+}
+
+var ARMResourceClasses = []ResourceClass{
+	{"arm.medium", "2 vCPU, 8 GB RAM"},
+	{"arm.large", "4 vCPU, 16 GB RAM"},
+	{"arm.xlarge", "8 vCPU, 32 GB RAM"},
+	{"arm.2xlarge", "16 vCPU, 64 GB RAM"},
+}
+
+var NvidiaGPUResourceClasses = []ResourceClass{
+	{"gpu.nvidia.small", "4 vCPU, 15 GB RAM, 1 Nvidia Tesla P4"},
+	{"gpu.nvidia.medium", "8 vCPU, 30 GB RAM, 1 Nvidia Tesla T4"},
+	{"gpu.nvidia.large", "8 vCPU, 30 GB RAM, 1 Nvidia Tesla V100"},
+	{"windows.gpu.nvidia.medium", "16 vCPU, 60 GB RAM, 1 Nvidia Tesla T4"},
+}
+
+var MacOSResourceClasses = []ResourceClass{
+	{"macos.m1.medium.gen1", "4 vCPU, 6 GB RAM"},
+	{"macos.x86.medium.gen2", "4 vCPU, 8 GB RAM"},
+	{"macos.m1.large.gen1", "8 vCPU, 12 GB RAM"},
+	{"macos.x86.metal.gen1", "12 vCPU, 32 GB RAM"},
+}
+
+var WindowsResourceClasses = []ResourceClass{
+	{"medium", "4 vCPU, 15 GB RAM"},
+	{"large", "8 vCPU, 30 GB RAM"},
+	{"xlarge", "16 vCPU, 60 GB RAM"},
+	{"2xlarge", "32 vCPU, 128 GB RAM"},
+}
+
+var ValidDockerResourceClasses = getResourceClassNames(DockerResourceClasses)
+var ValidLinuxResourceClasses = getResourceClassNames(LinuxResourceClasses)
+var ValidARMResourceClasses = getResourceClassNames(ARMResourceClasses)
+var ValidNvidiaGPUResourceClasses = getResourceClassNames(NvidiaGPUResourceClasses)
+var ValidMacOSResourceClasses = getResourceClassNames(MacOSResourceClasses)
+var ValidWindowsResourceClasses = getResourceClassNames(WindowsResourceClasses)
+
+func getResourceClassNames(resourceClasses []ResourceClass) []string {
+	names := make([]string, len(resourceClasses))
+	for i, resourceClass := range resourceClasses {
+		names[i] = resourceClass.Name
+	}
+	return names
+}
+
+// Returns the resource classes allowed for the type of the executor, nil when
+// the type is unknown. Machine executors allow the classes of every kind of
+// machine
+func GetResourceClasses(executor ast.Executor) []ResourceClass {
+	switch executor.(type) {
+	case ast.DockerExecutor:
+		return DockerResourceClasses
+	case ast.MacOSExecutor:
+		return MacOSResourceClasses
+	case ast.WindowsExecutor:
+		return WindowsResourceClasses
+	case ast.MachineExecutor:
+		machineResourceClasses := append([]ResourceClass{}, LinuxResourceClasses...)
+		machineResourceClasses = append(machineResourceClasses, ARMResourceClasses...)
+		return append(machineResourceClasses, NvidiaGPUResourceClasses...)
+	}
+
+	return nil
+}
+
+// Returns the resource classes allowed for the type of the executor. Machine
+// executors select their kind of machine with the resource class itself
+func getValidResourceClasses(executor ast.Executor, resourceClass string) []string {
+	switch executor.(type) {
+	case ast.DockerExecutor:
+		return ValidDockerResourceClasses
+	case ast.MacOSExecutor:
+		return ValidMacOSResourceClasses
+	case ast.WindowsExecutor:
+		return ValidWindowsResourceClasses
+	case ast.MachineExecutor:
+		if strings.HasPrefix(resourceClass, "arm.") {
+			return ValidARMResourceClasses
+		}
+		if strings.HasPrefix(resourceClass, "gpu.nvidia") || strings.HasPrefix(resourceClass, "windows.gpu.nvidia") {
+			return ValidNvidiaGPUResourceClasses
+		}
+		return ValidLinuxResourceClasses
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package validate
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	sitter "github.com/smacker/go-tree-sitter"
+	"go.lsp.dev/protocol"
+)
+
+type secretPattern struct {
+	// What the value looks like, used in the message: "looks like <name>"
+	name    string
+	pattern *regexp.Regexp
+}
+
+// Heuristics telling that a value looks like a secret, the first matching
+// pattern is reported. Generic patterns come last so that the message names
+// the kind of secret whenever it is known
+var secretPatterns = []secretPattern{
+	{"an AWS access key ID", regexp.MustCompile(`^(AKIA|ASIA|AGPA|AIDA|AROA)[0-9A-Z]{16}$`)},
+	{"a GitHub token", regexp.MustCompile(`^(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})$`)},
+	{"a GitLab token", regexp.MustCompile(`^glpat-[A-Za-z0-9_-]{20,}$`)},
+	{"a Slack token", regexp.MustCompile(`^xox[abposr]-[A-Za-z0-9-]{10,}$`)},
+	{"a Stripe key", regexp.MustCompile(`^(sk|rk)_live_[A-Za-z0-9]{16,}$`)},
+	{"a Google API key", regexp.MustCompile(`^AIza[0-9A-Za-z_-]{35}$`)},
+	{"an npm token", regexp.MustCompile(`^npm_[A-Za-z0-9]{36}$`)},
+	{"a CircleCI token", regexp.MustCompile(`^CCIPAT_[A-Za-z0-9]{22,}_[0-9a-f]{40}$`)},
+	{"a private key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)},
+	{"a long hexadecimal secret", regexp.MustCompile(`^[0-9a-fA-F]{32,}$`)},
+	{"a long base64 secret", regexp.MustCompile(`^[A-Za-z0-9+/_-]{40,}={0,2}$`)},
+}
+
+var (
+	// Values that can not be secrets: URLs, paths, and references to
+	// parameters or to other environment variables
+	nonSecretValue = regexp.MustCompile(`://|^[./~]|<<|\$`)
+
+	// Variables that hold hashes rather than secrets, such as the SHA of a
+	// commit
+	nonSecretName = regexp.MustCompile(`(?i)(sha\d*|commit|revision|hash|checksum|digest|version)$`)
+
+	// Assignments of the commands, such as `export TOKEN=value`
+	envAssignment = regexp.MustCompile(`(?:^|[\s;&|(])([A-Za-z_][A-Za-z0-9_]*)=("[^"\n]*"|'[^'\n]*'|[^\s;&|)]+)`)
+)
+
+// Returns what the value looks like when it looks like a secret
+func looksLikeSecret(name string, value string) (string, bool) {
+	value = strings.Trim(value, `"'`)
+	if value == "" || nonSecretValue.MatchString(value) || nonSecretName.MatchString(name) {
+		return "", false
+	}
+
+	for _, secret := range secretPatterns {
+		if !secret.pattern.MatchString(value) {
+			continue
+		}
+
+		// Long identifiers such as "this_is_a_very_long_plain_variable_name" are
+		// not random enough to be base64 secrets
+		if strings.HasSuffix(secret.name, "base64 secret") && !hasMixedCharacters(value) {
+			continue
+		}
+
+		return secret.name, true
+	}
+
+	return "", false
+}
+
+func hasMixedCharacters(value string) bool {
+	return strings.ContainsAny(value, "0123456789") &&
+		strings.ContainsAny(value, "abcdefghijklmnopqrstuvwxyz") &&
+		strings.ContainsAny(value, "ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+}
+
+// Warns about the values of the `environment` keys, and the variables
+// assigned by the commands of the `run` steps, that look like secrets. Such
+// values are visible to anyone reading the configuration, they belong in a
+// context or in the project settings. The rule can be turned off with the
+// diagnostic severities setting
+func (val Validate) ValidateSecrets() {
+	val.findSecrets(val.Doc.RootNode)
+}
+
+func (val Validate) findSecrets(node *sitter.Node) {
+	if node.Type() == "block_mapping_pair" || node.Type() == "flow_pair" {
+		keyNode, valueNode := val.Doc.GetKeyValueNodes(node)
+
+		switch val.Doc.GetNodeText(keyNode) {
+		case "environment":
+			val.iterateOnMapping(parser.GetChildMapping(valueNode), func(name string, _ *sitter.Node, value *sitter.Node) {
+				if value == nil {
+					return
+				}
+
+				if kind, ok := looksLikeSecret(name, val.Doc.GetNodeText(value)); ok {
+					val.addSecretDiagnostic(name, kind, val.Doc.NodeToRange(value))
+				}
+			})
+			return
+
+		case "run":
+			if mapping := parser.GetChildMapping(valueNode); mapping != nil {
+				val.iterateOnMapping(mapping, func(key string, _ *sitter.Node, command *sitter.Node) {
+					if key == "command" {
+						val.findSecretsInCommand(command)
+					}
+				})
+			} else {
+				val.findSecretsInCommand(valueNode)
+			}
+		}
+	}
+
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		val.findSecrets(node.NamedChild(i))
+	}
+}
+
+func (val Validate) findSecretsInCommand(command *sitter.Node) {
+	if command == nil {
+		return
+	}
+
+	text := val.Doc.GetRawNodeText(command)
+	for _, match := range envAssignment.FindAllStringSubmatchIndex(text, -1) {
+		name, value := text[match[2]:match[3]], text[match[4]:match[5]]
+
+		if kind, ok := looksLikeSecret(name, value); ok {
+			val.addSecretDiagnostic(name, kind, getRangeInNode(command, text, match[4], match[5]))
+		}
+	}
+}
+
+func (val Validate) addSecretDiagnostic(name string, kind string, rng protocol.Range) {
+	val.addDiagnostic(utils.RuleHardcodedSecret, utils.CreateWarningDiagnosticFromRange(
+		rng,
+		fmt.Sprintf("The value of %s looks like %s, store it in a context or in the project settings instead", name, kind)))
+}
@@ -0,0 +1,85 @@
+package validate
+
+import (
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"go.lsp.dev/protocol"
+)
+
+// Orbs whose commands and jobs continue the pipeline with another
+// configuration
+var continuationOrbs = []string{
+	"circleci/continuation",
+	"circleci/path-filtering",
+}
+
+// A setup configuration only runs once, to generate and continue with the
+// configuration of the pipeline: it must continue it, through an orb or by
+// calling the API with its continuation key. The other configurations can not
+// continue the pipeline
+func (val Validate) ValidateSetup() {
+	continuations := val.getContinuationRanges()
+
+	if val.Doc.Setup {
+		if len(continuations) == 0 {
+			val.addDiagnostic(utils.RuleSetup, utils.CreateWarningDiagnosticFromRange(
+				val.Doc.SetupRange,
+				"Setup configuration never continues the pipeline, use the circleci/continuation orb or call the API with $CIRCLE_CONTINUATION_KEY"))
+		}
+		return
+	}
+
+	for _, rng := range continuations {
+		val.addDiagnostic(utils.RuleSetup, utils.CreateErrorDiagnosticFromRange(
+			rng,
+			"The pipeline can only be continued from a setup configuration, add `setup: true` to this file"))
+	}
+}
+
+func (val Validate) getContinuationRanges() []protocol.Range {
+	ranges := []protocol.Range{}
+	steps := []ast.Step{}
+
+	for _, job := range val.Doc.Jobs {
+		steps = append(steps, job.Steps...)
+	}
+	for _, command := range val.Doc.Commands {
+		steps = append(steps, command.Steps...)
+	}
+	for _, workflow := range val.Doc.Workflows {
+		for _, jobRef := range workflow.JobRefs {
+			if val.isContinuationOrbEntity(jobRef.JobName) {
+				ranges = append(ranges, jobRef.JobNameRange)
+			}
+			steps = append(steps, jobRef.PreSteps...)
+			steps = append(steps, jobRef.PostSteps...)
+		}
+	}
+
+	for _, step := range steps {
+		switch step := step.(type) {
+		case ast.NamedStep:
+			if val.isContinuationOrbEntity(step.Name) {
+				ranges = append(ranges, step.Range)
+			}
+		case ast.Run:
+			if strings.Contains(step.Command, "CIRCLE_CONTINUATION_KEY") {
+				ranges = append(ranges, step.CommandRange)
+			}
+		}
+	}
+
+	return ranges
+}
+
+func (val Validate) isContinuationOrbEntity(name string) bool {
+	alias, _, found := strings.Cut(name, "/")
+	if !found {
+		return false
+	}
+
+	orb, ok := val.Doc.Orbs[alias]
+	return ok && utils.FindInArray(continuationOrbs, orb.Url.Name) != -1
+}
@@ -0,0 +1,103 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"go.lsp.dev/protocol"
+)
+
+func TestValidateSetup(t *testing.T) {
+	continuation := `
+orbs:
+  continuation: circleci/continuation@1.0.0
+
+jobs:
+  generate:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - checkout
+      - continuation/continue:
+          configuration_path: generated.yml
+
+workflows:
+  setup:
+    jobs:
+      - generate
+`
+
+	testCases := []struct {
+		name        string
+		yamlContent string
+		diagnostics []protocol.Diagnostic
+	}{
+		{
+			name:        "Setup configuration continuing the pipeline with the continuation orb",
+			yamlContent: "version: 2.1\nsetup: true\n" + continuation,
+			diagnostics: []protocol.Diagnostic{},
+		},
+		{
+			name: "Setup configuration continuing the pipeline through the API",
+			yamlContent: `version: 2.1
+setup: true
+
+jobs:
+  generate:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - run: curl -X POST https://circleci.com/api/v2/pipeline/continue -d "{\"continuation-key\": \"$CIRCLE_CONTINUATION_KEY\"}"
+
+workflows:
+  setup:
+    jobs:
+      - generate
+`,
+			diagnostics: []protocol.Diagnostic{},
+		},
+		{
+			name: "Setup configuration never continuing the pipeline",
+			yamlContent: `version: 2.1
+setup: true
+
+jobs:
+  generate:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - checkout
+
+workflows:
+  setup:
+    jobs:
+      - generate
+`,
+			diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleSetup, utils.CreateWarningDiagnosticFromRange(protocol.Range{
+					Start: protocol.Position{Line: 1, Character: 0},
+					End:   protocol.Position{Line: 1, Character: 11},
+				}, "Setup configuration never continues the pipeline, use the circleci/continuation orb or call the API with $CIRCLE_CONTINUATION_KEY")),
+			},
+		},
+		{
+			name:        "Continuing the pipeline outside of a setup configuration",
+			yamlContent: "version: 2.1\n" + continuation,
+			diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleSetup, utils.CreateErrorDiagnosticFromRange(protocol.Range{
+					Start: protocol.Position{Line: 11, Character: 8},
+					End:   protocol.Position{Line: 11, Character: 29},
+				}, "The pipeline can only be continued from a setup configuration, add `setup: true` to this file")),
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			val := CreateValidateFromYAML(tt.yamlContent)
+			val.ValidateSetup()
+
+			CompareDiagnostics(t, &tt.diagnostics, val.Diagnostics)
+		})
+	}
+}
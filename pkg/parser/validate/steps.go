@@ -2,6 +2,8 @@ package validate
 
 import (
 	"fmt"
+	"path"
+	"regexp"
 	"strings"
 
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
@@ -15,11 +17,39 @@ var WHEN_KEYWORDS = []string{
 	"on_fail",
 }
 
+// Options of the `run` steps
+var RUN_KEYS = []string{
+	"command",
+	"name",
+	"shell",
+	"environment",
+	"background",
+	"working_directory",
+	"no_output_timeout",
+	"when",
+	"max_auto_reruns",
+	"auto_rerun_delay",
+}
+
+var (
+	// Interpreters available on the images and machines of CircleCI, with the
+	// versioned names such as python3.11
+	knownShellInterpreter = regexp.MustCompile(`^(sh|bash|zsh|dash|ash|ksh|mksh|fish|csh|tcsh|busybox|python[0-9.]*|node|ruby|perl|pwsh|powershell|cmd)$`)
+
+	windowsAbsolutePath = regexp.MustCompile(`^[a-zA-Z]:[\\/]`)
+)
+
 func (val Validate) validateSteps(steps []ast.Step, name string, jobOrCommandParameters map[string]ast.Parameter) error {
 	for _, step := range steps {
 		switch step := step.(type) {
 		case ast.Run:
+			val.validateRunKeys(step)
+			val.validateRunShell(step)
 			val.validateRunCommand(step, jobOrCommandParameters)
+		case ast.StoreArtifacts:
+			val.validateStoreArtifacts(step)
+		case ast.StoreTestResults:
+			val.validateStoreTestResults(step)
 		case ast.NamedStep:
 			val.validateNamedStep(step, jobOrCommandParameters)
 		case ast.Steps:
@@ -31,7 +61,7 @@ func (val Validate) validateSteps(steps []ast.Step, name string, jobOrCommandPar
 
 func (val Validate) validateRunCommand(step ast.Run, jobOrCommandParameters map[string]ast.Parameter) {
 	if step.IsDeployStep {
-		val.addDiagnostic(protocol.Diagnostic{
+		val.addDiagnostic(utils.RuleDeprecatedStep, protocol.Diagnostic{
 			Range:    step.Range,
 			Message:  "The `deploy` step is deprecated. Please use the `run` job instead.",
 			Severity: protocol.DiagnosticSeverityWarning,
@@ -68,7 +98,7 @@ func (val Validate) validateRunCommand(step ast.Run, jobOrCommandParameters map[
 			case ast.StringParameter:
 				value = param.Default
 			default:
-				val.addDiagnostic(utils.CreateErrorDiagnosticFromRange(
+				val.addDiagnostic(utils.RuleStepWhen, utils.CreateErrorDiagnosticFromRange(
 					step.WhenRange,
 					fmt.Sprintf("Parameter %s is not a string type parameter, and therefore cannot be used inside the `when` field", paramName),
 				))
@@ -80,12 +110,70 @@ func (val Validate) validateRunCommand(step ast.Run, jobOrCommandParameters map[
 	}
 
 	if utils.FindInArray(WHEN_KEYWORDS, value) < 0 {
-		val.addDiagnostic(utils.CreateErrorDiagnosticFromRange(
+		val.addDiagnostic(utils.RuleStepWhen, utils.CreateErrorDiagnosticFromRange(
 			step.WhenRange,
 			fmt.Sprintf("Invalid when condition: expected `%s`; got `%s`", strings.Join(WHEN_KEYWORDS, "`, `"), value)))
 	}
 }
 
+// A step has a single key, the options of a `run` step written next to it
+// instead of under it are not applied
+func (val Validate) validateRunKeys(step ast.Run) {
+	stepType := "run"
+	if step.IsDeployStep {
+		stepType = "deploy"
+	}
+
+	for _, key := range step.MisplacedKeys {
+		message := fmt.Sprintf("A step can only have one key, `%s` is not part of the `%s` step", key.Text, stepType)
+		if utils.FindInArray(RUN_KEYS, key.Text) >= 0 {
+			message = fmt.Sprintf("`%s` must be nested under `%s`, a command given directly to `%s` can not have options", key.Text, stepType, stepType)
+		}
+
+		val.addDiagnostic(utils.RuleInvalidStep, utils.CreateErrorDiagnosticFromRange(key.Range, message))
+	}
+}
+
+// The interpreter of the shell is the first word of the shell, or the one run
+// by `env`. Shells that are parameters are not known until the configuration
+// is processed
+func (val Validate) validateRunShell(step ast.Run) {
+	if step.Shell == "" || strings.Contains(step.Shell, "<<") {
+		return
+	}
+
+	fields := strings.Fields(step.Shell)
+	interpreter := fields[0]
+	if end := strings.Index(strings.ToLower(step.Shell), ".exe"); end >= 0 && windowsAbsolutePath.MatchString(step.Shell) {
+		// Paths of Windows such as C:\Program Files\Git\bin\bash.exe have spaces
+		interpreter = step.Shell[:end+len(".exe")]
+	} else if getExecutableName(interpreter) == "env" {
+		if len(fields) < 2 {
+			return
+		}
+		interpreter = fields[1]
+	}
+
+	if strings.ContainsAny(interpreter, "/\\") && !strings.HasPrefix(interpreter, "/") && !windowsAbsolutePath.MatchString(interpreter) {
+		val.addDiagnostic(utils.RuleStepShell, utils.CreateWarningDiagnosticFromRange(
+			step.ShellRange,
+			fmt.Sprintf("The shell interpreter %s is a relative path, it is resolved from the working directory of the step", interpreter)))
+		return
+	}
+
+	if !knownShellInterpreter.MatchString(getExecutableName(interpreter)) {
+		val.addDiagnostic(utils.RuleStepShell, utils.CreateWarningDiagnosticFromRange(
+			step.ShellRange,
+			fmt.Sprintf("Unknown shell interpreter %s, expected a shell such as /bin/bash, /bin/sh or powershell.exe", interpreter)))
+	}
+}
+
+// Name of the executable of a path, such as `bash` for `C:\Program Files\Git\bin\bash.exe`
+func getExecutableName(path string) string {
+	name := path[strings.LastIndexAny(path, "/\\")+1:]
+	return strings.TrimSuffix(strings.ToLower(name), ".exe")
+}
+
 func (val Validate) validateNamedStep(step ast.NamedStep, usableParams map[string]ast.Parameter) {
 	commandExists := val.Doc.DoesJobExist(step.Name) ||
 		val.Doc.DoesCommandExist(step.Name) ||
@@ -93,14 +181,14 @@ func (val Validate) validateNamedStep(step ast.NamedStep, usableParams map[strin
 		val.Doc.IsOrbCommand(step.Name, val.Cache) ||
 		val.Doc.IsAlias(step.Name)
 
-	if val.Doc.IsFromUnfetchableOrb(step.Name) {
+	if val.Doc.IsFromUnfetchableOrb(step.Name, val.Cache) {
 		return
 	}
 
 	if !commandExists {
-		val.addDiagnostic(utils.CreateErrorDiagnosticFromRange(
+		val.addDiagnostic(val.getUnknownReferenceRule(step.Name, utils.RuleUnknownStep), utils.CreateErrorDiagnosticFromRange(
 			step.Range,
-			fmt.Sprintf("Cannot find declaration for step %s", step.Name)))
+			fmt.Sprintf("Cannot find declaration for step %s%s", step.Name, val.getUnknownOrbMemberDetail(step.Name, "command"))))
 	}
 
 	if !val.Doc.IsBuiltIn(step.Name) {
@@ -114,16 +202,71 @@ func (val Validate) validateNamedStep(step ast.NamedStep, usableParams map[strin
 		)
 	}
 
-	if step.Name == "store_test_results" {
-		val.addDiagnostic(
-			protocol.Diagnostic{
-				Message:  "Path must be specified for `store_test_results` step",
-				Range:    step.Range,
-				Severity: protocol.DiagnosticSeverityError,
-			})
+	if step.Name == "store_test_results" || step.Name == "store_artifacts" {
+		val.addMissingPathDiagnostic(step.Name, step.Range)
 	}
 }
 
+func (val Validate) validateStoreArtifacts(step ast.StoreArtifacts) {
+	if step.Path == "" {
+		val.addMissingPathDiagnostic("store_artifacts", step.Range)
+		return
+	}
+	val.validateStoredPath(step.Path, step.PathRange)
+
+	if step.Destination == "" || utils.CheckIfOnlyParamUsed(step.Destination) {
+		return
+	}
+	if path.IsAbs(step.Destination) || strings.HasPrefix(step.Destination, "~") || hasParentSegment(step.Destination) {
+		val.addDiagnostic(utils.RuleStepPath, utils.CreateWarningDiagnosticFromRange(
+			step.DestinationRange,
+			fmt.Sprintf("The destination %s is a prefix of the artifacts paths, it must be a relative path such as test-reports", step.Destination)))
+	}
+}
+
+func (val Validate) validateStoreTestResults(step ast.StoreTestResults) {
+	if step.Path == "" {
+		val.addMissingPathDiagnostic("store_test_results", step.Range)
+		return
+	}
+	val.validateStoredPath(step.Path, step.PathRange)
+}
+
+func (val Validate) addMissingPathDiagnostic(stepName string, rng protocol.Range) {
+	val.addDiagnostic(utils.RuleInvalidStep, utils.CreateErrorDiagnosticFromRange(
+		rng,
+		fmt.Sprintf("Path must be specified for `%s` step", stepName)))
+}
+
+// Directories of the operating system, storing them uploads the files of the
+// image rather than the ones produced by the job
+var systemDirectories = []string{"/", "/bin", "/boot", "/dev", "/etc", "/lib", "/lib64", "/proc", "/sbin", "/sys", "/usr"}
+
+func (val Validate) validateStoredPath(storedPath string, rng protocol.Range) {
+	if !path.IsAbs(storedPath) {
+		return
+	}
+
+	cleaned := path.Clean(storedPath)
+	for _, directory := range systemDirectories {
+		if cleaned == directory || (directory != "/" && strings.HasPrefix(cleaned, directory+"/")) {
+			val.addDiagnostic(utils.RuleStepPath, utils.CreateWarningDiagnosticFromRange(
+				rng,
+				fmt.Sprintf("%s is a system directory, the stored path is usually relative to the working directory", storedPath)))
+			return
+		}
+	}
+}
+
+func hasParentSegment(relativePath string) bool {
+	for _, segment := range strings.Split(relativePath, "/") {
+		if segment == ".." {
+			return true
+		}
+	}
+	return false
+}
+
 func (val Validate) validateStepSteps(step ast.Steps, name string) {
 	if !val.Doc.DoesCommandExist(name) {
 		return
@@ -135,7 +278,7 @@ func (val Validate) validateStepSteps(step ast.Steps, name string) {
 	}
 	parameterType := parameter.GetType()
 	if parameterType != "steps" {
-		val.addDiagnostic(protocol.Diagnostic{
+		val.addDiagnostic(utils.RuleParameter, protocol.Diagnostic{
 			Severity: protocol.DiagnosticSeverityError,
 			Range:    step.Range,
 			Message:  "Parameter type is not steps",
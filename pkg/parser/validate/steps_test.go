@@ -3,6 +3,8 @@ package validate
 import (
 	"testing"
 
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
 	"go.lsp.dev/protocol"
 )
 
@@ -38,3 +40,310 @@ workflows:
 
 	CheckYamlErrors(t, testCases)
 }
+
+func TestStepParameters(t *testing.T) {
+	val := CreateValidateFromYAML(`version: 2.1
+
+orbs:
+  node: circleci/node@5.1.0
+
+commands:
+  greet:
+    parameters:
+      to:
+        type: string
+      loud:
+        type: boolean
+        default: false
+    steps:
+      - run: echo << parameters.to >>
+
+jobs:
+  build:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - greet:
+          name: Greet the world
+          to: world
+          lodu: true
+      - greet:
+          loud: true
+      - node/install:
+          node-versoin: "20"
+      - node/install:
+          node-version: "20"
+          cache: true
+
+workflows:
+  main:
+    jobs:
+      - build
+`)
+	val.Cache.OrbCache.SetOrb(&ast.OrbInfo{
+		OrbParsedAttributes: ast.OrbParsedAttributes{
+			Commands: map[string]ast.Command{
+				"install": {
+					Name: "install",
+					Parameters: map[string]ast.Parameter{
+						"node-version": ast.StringParameter{BaseParameter: ast.BaseParameter{Name: "node-version"}},
+					},
+				},
+			},
+		},
+	}, "circleci/node@5.1.0")
+	val.ValidateJobs()
+
+	CompareDiagnostics(t, &[]protocol.Diagnostic{
+		utils.WithDiagnosticRule(utils.RuleUndefinedParameter, utils.CreateWarningDiagnosticFromRange(createRange(24, 10, 20), "Parameter lodu is not defined for greet, did you mean loud?")),
+		utils.WithDiagnosticRule(utils.RuleParameter, utils.CreateErrorDiagnosticFromRange(createRange(25, 8, 13), "Parameter to is required for greet")),
+		utils.WithDiagnosticRule(utils.RuleUndefinedParameter, utils.CreateWarningDiagnosticFromRange(createRange(28, 10, 28), "Parameter node-versoin is not defined for node/install, did you mean node-version?")),
+		utils.WithDiagnosticRule(utils.RuleParameter, utils.CreateErrorDiagnosticFromRange(createRange(27, 8, 20), "Parameter node-version is required for node/install")),
+		utils.WithDiagnosticRule(utils.RuleUndefinedParameter, utils.CreateWarningDiagnosticFromRange(createRange(31, 10, 21), "Parameter cache is not defined for node/install")),
+	}, val.Diagnostics)
+}
+
+func TestAliasedOrbReferences(t *testing.T) {
+	val := CreateValidateFromYAML(`version: 2.1
+
+orbs:
+  js: circleci/node@5.1.0
+
+jobs:
+  build:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - js/install:
+          node-version: "20"
+      - js/setup
+
+workflows:
+  main:
+    jobs:
+      - build
+      - js/test
+      - js/lint
+`)
+	val.Cache.OrbCache.SetOrb(&ast.OrbInfo{
+		OrbParsedAttributes: ast.OrbParsedAttributes{
+			Commands: map[string]ast.Command{
+				"install": {
+					Name: "install",
+					Parameters: map[string]ast.Parameter{
+						"node-version": ast.StringParameter{BaseParameter: ast.BaseParameter{Name: "node-version"}},
+					},
+				},
+			},
+			Jobs: map[string]ast.Job{
+				"test": {Name: "test"},
+			},
+		},
+	}, "circleci/node@5.1.0")
+	val.ValidateJobs()
+	val.ValidateWorkflows()
+
+	CompareDiagnostics(t, &[]protocol.Diagnostic{
+		utils.WithDiagnosticRule(utils.RuleUnknownStep, utils.CreateErrorDiagnosticFromRange(
+			createRange(12, 8, 16),
+			"Cannot find declaration for step js/setup, orb circleci/node@5.1.0 has no command setup")),
+		utils.WithDiagnosticRule(utils.RuleUnknownJob, utils.CreateErrorDiagnosticFromRange(
+			createRange(19, 6, 15),
+			"Cannot find declaration for job js/lint, orb circleci/node@5.1.0 has no job lint")),
+	}, val.Diagnostics)
+}
+
+func TestRunStepWhen(t *testing.T) {
+	testCases := []struct {
+		when    string
+		allowed bool
+	}{
+		{when: "always", allowed: true},
+		{when: "on_success", allowed: true},
+		{when: "on_fail", allowed: true},
+		{when: "on_failure", allowed: false},
+		{when: "never", allowed: false},
+		{when: "Always", allowed: false},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.when, func(t *testing.T) {
+			val := CreateValidateFromYAML(`version: 2.1
+
+jobs:
+  build:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - run:
+          command: echo hello
+          when: ` + tt.when + `
+
+workflows:
+  main:
+    jobs:
+      - build
+`)
+			val.ValidateJobs()
+
+			expected := []protocol.Diagnostic{}
+			if !tt.allowed {
+				expected = append(expected, utils.WithDiagnosticRule(utils.RuleStepWhen, utils.CreateErrorDiagnosticFromRange(
+					createRange(9, 16, 16+uint32(len(tt.when))),
+					"Invalid when condition: expected `on_success`, `always`, `on_fail`; got `"+tt.when+"`")))
+			}
+			CompareDiagnostics(t, &expected, val.Diagnostics)
+		})
+	}
+}
+
+func TestRunStepKeys(t *testing.T) {
+	val := CreateValidateFromYAML(`version: 2.1
+
+jobs:
+  build:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - checkout
+      - run: make test
+        name: Test
+      - run:
+          name: Lint
+          command: make lint
+        when: always
+      - run:
+          command: make build
+        cache: true
+      - deploy: make deploy
+        command: make release
+
+workflows:
+  main:
+    jobs:
+      - build
+`)
+	val.ValidateJobs()
+
+	CompareDiagnostics(t, &[]protocol.Diagnostic{
+		utils.WithDiagnosticRule(utils.RuleInvalidStep, utils.CreateErrorDiagnosticFromRange(
+			createRange(9, 8, 12),
+			"`name` must be nested under `run`, a command given directly to `run` can not have options")),
+		utils.WithDiagnosticRule(utils.RuleInvalidStep, utils.CreateErrorDiagnosticFromRange(
+			createRange(13, 8, 12),
+			"`when` must be nested under `run`, a command given directly to `run` can not have options")),
+		utils.WithDiagnosticRule(utils.RuleInvalidStep, utils.CreateErrorDiagnosticFromRange(
+			createRange(16, 8, 13),
+			"A step can only have one key, `cache` is not part of the `run` step")),
+		{
+			Range:    createRange(17, 8, 14),
+			Message:  "The `deploy` step is deprecated. Please use the `run` job instead.",
+			Severity: protocol.DiagnosticSeverityWarning,
+			Code:     utils.RuleDeprecatedStep,
+			Tags:     []protocol.DiagnosticTag{protocol.DiagnosticTagDeprecated},
+		},
+		utils.WithDiagnosticRule(utils.RuleInvalidStep, utils.CreateErrorDiagnosticFromRange(
+			createRange(18, 8, 15),
+			"`command` must be nested under `deploy`, a command given directly to `deploy` can not have options")),
+	}, val.Diagnostics)
+}
+
+func TestRunStepShell(t *testing.T) {
+	testCases := []struct {
+		shell   string
+		message string
+	}{
+		{shell: "/bin/bash -eo pipefail"},
+		{shell: "/usr/bin/env python3"},
+		{shell: "python3.11"},
+		{shell: "powershell.exe -ExecutionPolicy Bypass"},
+		{shell: `C:\Program Files\Git\bin\bash.exe -eo pipefail`},
+		{shell: "<< parameters.shell >>"},
+		{shell: "/bin/bsh", message: "Unknown shell interpreter /bin/bsh, expected a shell such as /bin/bash, /bin/sh or powershell.exe"},
+		{shell: "/usr/bin/env bassh", message: "Unknown shell interpreter bassh, expected a shell such as /bin/bash, /bin/sh or powershell.exe"},
+		{shell: "bin/bash", message: "The shell interpreter bin/bash is a relative path, it is resolved from the working directory of the step"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.shell, func(t *testing.T) {
+			val := CreateValidateFromYAML(`version: 2.1
+
+jobs:
+  build:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - run:
+          command: echo hello
+          shell: ` + tt.shell + `
+
+workflows:
+  main:
+    jobs:
+      - build
+`)
+			val.ValidateJobs()
+
+			expected := []protocol.Diagnostic{}
+			if tt.message != "" {
+				expected = append(expected, utils.WithDiagnosticRule(utils.RuleStepShell, utils.CreateWarningDiagnosticFromRange(
+					createRange(9, 17, 17+uint32(len(tt.shell))),
+					tt.message)))
+			}
+			CompareDiagnostics(t, &expected, val.Diagnostics)
+		})
+	}
+}
+
+func TestStoreStepsPaths(t *testing.T) {
+	val := CreateValidateFromYAML(`version: 2.1
+
+jobs:
+  build:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - store_test_results:
+          path: test-results
+      - store_artifacts:
+          path: ./dist
+          destination: build
+      - store_test_results
+      - store_artifacts:
+          destination: build
+      - store_artifacts:
+          path: /etc
+      - store_artifacts:
+          path: /tmp/artifacts
+          destination: /artifacts
+      - store_test_results:
+          path: ~/project/results
+      - store_artifacts:
+          path: logs
+          destination: ../outside
+
+workflows:
+  main:
+    jobs:
+      - build
+`)
+	val.ValidateJobs()
+
+	CompareDiagnostics(t, &[]protocol.Diagnostic{
+		utils.WithDiagnosticRule(utils.RuleInvalidStep, utils.CreateErrorDiagnosticFromRange(
+			createRange(12, 8, 26),
+			"Path must be specified for `store_test_results` step")),
+		utils.WithDiagnosticRule(utils.RuleInvalidStep, utils.CreateErrorDiagnosticFromRange(
+			createRange(13, 8, 23),
+			"Path must be specified for `store_artifacts` step")),
+		utils.WithDiagnosticRule(utils.RuleStepPath, utils.CreateWarningDiagnosticFromRange(
+			createRange(16, 16, 20),
+			"/etc is a system directory, the stored path is usually relative to the working directory")),
+		utils.WithDiagnosticRule(utils.RuleStepPath, utils.CreateWarningDiagnosticFromRange(
+			createRange(19, 23, 33),
+			"The destination /artifacts is a prefix of the artifacts paths, it must be a relative path such as test-reports")),
+		utils.WithDiagnosticRule(utils.RuleStepPath, utils.CreateWarningDiagnosticFromRange(
+			createRange(24, 23, 33),
+			"The destination ../outside is a prefix of the artifacts paths, it must be a relative path such as test-reports")),
+	}, val.Diagnostics)
+}
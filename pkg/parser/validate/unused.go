@@ -0,0 +1,123 @@
+package validate
+
+import (
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"go.lsp.dev/protocol"
+)
+
+func (val Validate) addUnusedDiagnostic(rule string, rng protocol.Range, msg string) {
+	// The definitions of an orb are meant to be used by the configurations
+	// importing it
+	if val.Doc.LocalOrbName != "" {
+		return
+	}
+
+	if val.Context != nil && val.Context.IgnoreUnusedDefinitions {
+		return
+	}
+
+	val.addDiagnostic(rule, utils.CreateUnusedDiagnosticFromRange(rng, msg))
+}
+
+func (val Validate) checkIfExecutorIsUsed(executor ast.Executor) bool {
+	for _, job := range val.Doc.Jobs {
+		if job.Executor == executor.GetName() {
+			return true
+		}
+	}
+
+	return val.checkIfUsedThroughParameters(executor.GetName())
+}
+
+// An entity can be used dynamically, for example with
+// `executor: << parameters.executor >>`: look for its name in the parameter
+// defaults and in the values given to parameters
+func (val Validate) checkIfUsedThroughParameters(name string) bool {
+	definitions := []map[string]ast.Parameter{val.Doc.PipelineParameters}
+	values := []map[string]ast.ParameterValue{}
+	steps := []ast.Step{}
+
+	for _, job := range val.Doc.Jobs {
+		definitions = append(definitions, job.Parameters)
+		values = append(values, job.ExecutorParameters)
+		steps = append(steps, job.Steps...)
+	}
+
+	for _, command := range val.Doc.Commands {
+		definitions = append(definitions, command.Parameters)
+		steps = append(steps, command.Steps...)
+	}
+
+	for _, executor := range val.Doc.Executors {
+		definitions = append(definitions, executor.GetParameters())
+	}
+
+	for _, workflow := range val.Doc.Workflows {
+		for _, jobRef := range workflow.JobRefs {
+			values = append(values, jobRef.Parameters)
+			steps = append(steps, jobRef.PreSteps...)
+			steps = append(steps, jobRef.PostSteps...)
+		}
+	}
+
+	for _, step := range steps {
+		if namedStep, ok := step.(ast.NamedStep); ok {
+			values = append(values, namedStep.Parameters)
+		}
+	}
+
+	for _, params := range definitions {
+		for _, param := range params {
+			if parameterDefaultUses(param, name) {
+				return true
+			}
+		}
+	}
+
+	for _, params := range values {
+		for _, value := range params {
+			if parameterValueUses(value, name) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func parameterDefaultUses(param ast.Parameter, name string) bool {
+	switch param := param.(type) {
+	case ast.ExecutorParameter:
+		return param.Default == name
+	case ast.StringParameter:
+		return param.Default == name
+	case ast.EnumParameter:
+		return param.Default == name
+	case ast.StepsParameter:
+		return parameterValueUses(param.Default, name)
+	}
+
+	return false
+}
+
+func parameterValueUses(value ast.ParameterValue, name string) bool {
+	switch content := value.Value.(type) {
+	case string:
+		return content == name
+	case []ast.Step:
+		for _, step := range content {
+			if step.GetName() == name {
+				return true
+			}
+		}
+	case []ast.ParameterValue:
+		for _, item := range content {
+			if parameterValueUses(item, name) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,107 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"go.lsp.dev/protocol"
+)
+
+func TestUnusedDefinitions(t *testing.T) {
+	testCases := []ValidateTestCase{
+		{
+			Name: "Unused executor and command",
+			YamlContent: `version: 2.1
+
+executors:
+  unused:
+    docker:
+      - image: cimg/base:2023.01
+
+commands:
+  greet:
+    steps:
+      - run: echo hello
+
+jobs:
+  build:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - checkout
+
+workflows:
+  someworkflow:
+    jobs:
+      - build
+`,
+			Diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleUnusedExecutor, utils.CreateUnusedDiagnosticFromRange(protocol.Range{
+					Start: protocol.Position{Line: 3, Character: 2},
+					End:   protocol.Position{Line: 3, Character: 8},
+				}, "Executor is unused")),
+				utils.WithDiagnosticRule(utils.RuleUnusedCommand, utils.CreateUnusedDiagnosticFromRange(protocol.Range{
+					Start: protocol.Position{Line: 8, Character: 2},
+					End:   protocol.Position{Line: 8, Character: 7},
+				}, "Command is unused")),
+			},
+		},
+		{
+			Name: "Executor and command used through parameters",
+			YamlContent: `version: 2.1
+
+executors:
+  default:
+    docker:
+      - image: cimg/base:2023.01
+
+commands:
+  greet:
+    steps:
+      - run: echo hello
+
+jobs:
+  build:
+    parameters:
+      executor:
+        type: executor
+        default: default
+      extra-steps:
+        type: steps
+        default: []
+    executor: << parameters.executor >>
+    steps:
+      - steps: << parameters.extra-steps >>
+
+workflows:
+  someworkflow:
+    jobs:
+      - build:
+          extra-steps:
+            - greet
+`,
+			Diagnostics: []protocol.Diagnostic{},
+		},
+	}
+
+	CheckYamlErrors(t, testCases)
+}
+
+func TestIgnoreUnusedDefinitions(t *testing.T) {
+	val := CreateValidateFromYAML(`version: 2.1
+
+executors:
+  unused:
+    docker:
+      - image: cimg/base:2023.01
+
+commands:
+  greet:
+    steps:
+      - run: echo hello
+`)
+	val.Context.IgnoreUnusedDefinitions = true
+	val.Validate(false)
+
+	CompareDiagnostics(t, &[]protocol.Diagnostic{}, val.Diagnostics)
+}
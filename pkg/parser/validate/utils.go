@@ -2,6 +2,7 @@ package validate
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
@@ -9,12 +10,34 @@ import (
 )
 
 func (val Validate) createParameterError(param ast.ParameterValue, stepName string, shouldBeType string) {
-	*val.Diagnostics = append(*val.Diagnostics, utils.CreateErrorDiagnosticFromRange(
+	val.addDiagnostic(utils.RuleParameter, utils.CreateErrorDiagnosticFromRange(
 		param.Range,
 		fmt.Sprintf("Parameter %s for %s must be a %s", param.Name, stepName, shouldBeType)),
 	)
 }
 
-func (val Validate) addDiagnostic(diagnostic protocol.Diagnostic) {
-	*val.Diagnostics = append(*val.Diagnostics, diagnostic)
+// The rule identifies the kind of issue reported, see the utils.Rule constants
+func (val Validate) addDiagnostic(rule string, diagnostic protocol.Diagnostic) {
+	*val.Diagnostics = append(*val.Diagnostics, utils.WithDiagnosticRule(rule, diagnostic))
+}
+
+// References such as `node/install` to an orb that is not imported are
+// reported apart so that the orb can be imported by a quick fix
+func (val Validate) getUnknownReferenceRule(name string, rule string) string {
+	if strings.Contains(name, "/") && !val.Doc.IsOrbReference(name) && !val.Doc.IsLocalOrbReference(name) {
+		return utils.RuleOrbNotDeclared
+	}
+	return rule
+}
+
+// Tells which orb an unknown member of a remote orb was looked up in, as the
+// alias of the reference may not be the name of the orb. Orbs that could not
+// be fetched say nothing about their members
+func (val Validate) getUnknownOrbMemberDetail(name string, kind string) string {
+	orbID, ok := val.Doc.GetOrbIDFromReference(name)
+	if !ok || !strings.Contains(name, "/") || !val.Cache.OrbCache.HasOrb(orbID) {
+		return ""
+	}
+
+	return fmt.Sprintf(", orb %s has no %s %s", orbID, kind, strings.SplitN(name, "/", 2)[1])
 }
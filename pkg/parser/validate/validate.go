@@ -23,14 +23,27 @@ type Validate struct {
 func (val *Validate) Validate(inLocalOrb bool) {
 	val.ValidateAnchors()
 	if !inLocalOrb {
+		val.ValidateVersion()
+		val.ValidateDuplicateKeys()
 		val.CheckIfParamsExist()
+		val.ValidateConditions()
+		val.ValidateSecrets()
+		val.ValidateEnvironments()
+		val.ValidateCacheKeys()
+		val.ValidateExecutorKeys()
 	}
 	val.ValidateWorkflows()
+	val.ValidateWorkspaces()
+	val.checkDockerImages()
 	val.ValidateJobs()
 	val.ValidateCommands()
 	val.ValidateOrbs()
 	val.ValidateExecutors()
 	val.CheckNames()
 	val.ValidatePipelineParameters()
+	val.ValidateParameterDefaults()
+	if !inLocalOrb {
+		val.ValidateSetup()
+	}
 	val.ValidateLocalOrbs()
 }
@@ -52,6 +52,10 @@ func CheckYamlErrors(t *testing.T, testCases []ValidateTestCase) {
 			val.Validate(false)
 
 			diags := *val.Diagnostics
+			for _, diag := range diags {
+				assert.NotEmpty(t, utils.GetDiagnosticRule(diag), "diagnostic without rule: %s", diag.Message)
+			}
+
 			if tt.OnlyErrors == true {
 				diags = getErrorDiagnostic(&diags)
 			}
@@ -77,6 +81,9 @@ func getErrorDiagnostic(diags *[]protocol.Diagnostic) []protocol.Diagnostic {
 
 func sortDiagnostic(diags *[]protocol.Diagnostic) {
 	sort.Slice(*diags, func(i, j int) bool {
+		if (*diags)[i].Range.Start == (*diags)[j].Range.Start {
+			return (*diags)[i].Message < (*diags)[j].Message
+		}
 		if (*diags)[i].Range.Start.Line == (*diags)[j].Range.Start.Line {
 			return (*diags)[i].Range.Start.Character < (*diags)[j].Range.Start.Character
 		}
@@ -2,6 +2,8 @@ package validate
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
@@ -16,10 +18,14 @@ func (val Validate) ValidateWorkflows() {
 
 func (val Validate) validateSingleWorkflow(workflow ast.Workflow) error {
 	for _, jobRef := range workflow.JobRefs {
-		if val.Doc.IsFromUnfetchableOrb(jobRef.JobName) {
+		if val.Doc.IsFromUnfetchableOrb(jobRef.JobName, val.Cache) {
 			continue
 		}
 
+		val.validateJobRefRequires(workflow, jobRef)
+		val.validateJobRefFilters(jobRef)
+		val.validateMatrixReferences(jobRef)
+
 		isApprovalJob := jobRef.Type == "approval"
 		if isApprovalJob {
 			continue
@@ -27,54 +33,218 @@ func (val Validate) validateSingleWorkflow(workflow ast.Workflow) error {
 
 		jobTypeIsDefined := jobRef.Type != ""
 		if jobTypeIsDefined {
-			val.addDiagnostic(utils.CreateErrorDiagnosticFromRange(jobRef.TypeRange, "Type can only be \"approval\""))
+			val.addDiagnostic(utils.RuleJobType, utils.CreateErrorDiagnosticFromRange(jobRef.TypeRange, "Type can only be \"approval\""))
 			continue
 		}
 
 		if !val.Doc.DoesJobExist(jobRef.JobName) &&
 			!(val.Doc.IsOrbReference(jobRef.JobName) && (val.Doc.IsOrbCommand(jobRef.JobName, val.Cache) || val.Doc.IsOrbJob(jobRef.JobName, val.Cache))) {
-			val.addDiagnostic(utils.CreateErrorDiagnosticFromRange(
+			val.addDiagnostic(val.getUnknownReferenceRule(jobRef.JobName, utils.RuleUnknownJob), utils.CreateErrorDiagnosticFromRange(
 				jobRef.JobRefRange,
-				fmt.Sprintf("Cannot find declaration for job %s", jobRef.JobName)))
+				fmt.Sprintf("Cannot find declaration for job %s%s", jobRef.JobName, val.getUnknownOrbMemberDetail(jobRef.JobName, "job"))))
 		}
 
 		if !val.Doc.IsOrbReference(jobRef.JobName) && !val.Doc.IsBuiltIn(jobRef.JobName) {
 			val.validateWorkflowParameters(jobRef, jobRef.JobName, jobRef.JobRefRange)
+		} else if jobRef.HasMatrix && val.Doc.IsOrbJob(jobRef.JobName, val.Cache) {
+			val.validateMatrixParameters(jobRef, jobRef.JobName, val.Doc.GetOrbDefinedParams(jobRef.JobName, val.Cache))
 		}
-		for _, require := range jobRef.Requires {
-			if !val.doesJobRefExist(workflow, require.Text) && !utils.CheckIfMatrixParamIsPartiallyReferenced(require.Text) {
-				val.addDiagnostic(utils.CreateErrorDiagnosticFromRange(
-					require.Range,
-					fmt.Sprintf("Cannot find declaration for job reference %s", require.Text)))
-			}
+		val.validateJobRefContexts(jobRef)
+	}
+
+	val.validateDAG(workflow)
+
+	for _, trigger := range workflow.Triggers {
+		val.validateScheduleCron(trigger.Schedule)
+		val.validateFilters(trigger.Schedule.Filters)
+	}
+
+	return nil
+}
+
+func (val Validate) validateScheduleCron(schedule ast.ScheduleTrigger) {
+	if utils.IsDefaultRange(schedule.CronRange) {
+		return
+	}
+
+	// Issues are located within the value, after its quote if any
+	start := schedule.CronRange.Start
+	if index := utils.PosToIndex(start, val.Doc.Content); index < len(val.Doc.Content) && strings.ContainsRune(`"'`, rune(val.Doc.Content[index])) {
+		start.Character++
+	}
+
+	for _, issue := range checkCron(schedule.Cron) {
+		rng := protocol.Range{
+			Start: protocol.Position{Line: start.Line, Character: start.Character + uint32(issue.offset)},
+			End:   protocol.Position{Line: start.Line, Character: start.Character + uint32(issue.offset+issue.length)},
 		}
 
-		if cachedFile := val.Cache.FileCache.GetFile(val.Doc.URI); val.Context.Api.Token != "" &&
-			cachedFile != nil && cachedFile.Project.OrganizationName != "" {
-			for _, context := range jobRef.Context {
-				if context.Text != "org-global" && val.Cache.ContextCache.GetOrganizationContext(cachedFile.Project.OrganizationName, context.Text) == nil {
-					val.addDiagnostic(utils.CreateErrorDiagnosticFromRange(
-						context.Range,
-						fmt.Sprintf("Context %s does not exist", context.Text)))
-				}
-			}
+		if issue.isWarning {
+			val.addDiagnostic(utils.RuleCron, utils.CreateWarningDiagnosticFromRange(rng, issue.message))
+		} else {
+			val.addDiagnostic(utils.RuleCron, utils.CreateErrorDiagnosticFromRange(rng, issue.message))
 		}
 	}
+}
 
-	val.validateDAG(workflow)
+// Contexts are only checked once all the contexts of the organization have
+// been fetched, otherwise any context not fetched yet would be reported
+func (val Validate) validateJobRefContexts(jobRef ast.JobRef) {
+	cachedFile := val.Cache.FileCache.GetFile(val.Doc.URI)
+	if cachedFile == nil || cachedFile.Project.OrganizationName == "" {
+		return
+	}
 
-	return nil
+	organization := cachedFile.Project.OrganizationName
+	if !val.Cache.ContextCache.IsOrganizationResolved(organization) {
+		return
+	}
+
+	for _, context := range jobRef.Context {
+		if context.Text == "org-global" {
+			continue
+		}
+		if _, ok := val.Cache.ContextCache.TryGetContext(organization, context.Text); ok {
+			continue
+		}
+
+		message := fmt.Sprintf("Context %s does not exist", context.Text)
+		if closest, found := utils.FindClosestMatch(context.Text, val.Cache.ContextCache.ContextNames(organization)); found {
+			message += fmt.Sprintf(", did you mean %s?", closest)
+		}
+
+		val.addDiagnostic(utils.RuleUnknownContext, utils.CreateWarningDiagnosticFromRange(context.Range, message))
+	}
+}
+
+func (val Validate) validateJobRefRequires(workflow ast.Workflow, jobRef ast.JobRef) {
+	for _, require := range jobRef.Requires {
+		if val.doesJobRefExist(workflow, require.Text) || utils.CheckIfMatrixParamIsPartiallyReferenced(require.Text) {
+			continue
+		}
+
+		if aliased, found := getMatrixAliasedJobRef(workflow, require.Text); found {
+			val.addDiagnostic(utils.RuleUnknownRequires, utils.CreateErrorDiagnosticFromRange(
+				require.Range,
+				fmt.Sprintf("The matrix of job %s is aliased, it must be required as %s", require.Text, aliased.MatrixAlias)))
+			continue
+		}
+
+		message := fmt.Sprintf("Cannot find declaration for job reference %s", require.Text)
+		if closest, found := utils.FindClosestMatch(require.Text, getJobRefNames(workflow)); found {
+			message += fmt.Sprintf(", did you mean %s?", closest)
+		}
+
+		val.addDiagnostic(utils.RuleUnknownRequires, utils.CreateErrorDiagnosticFromRange(require.Range, message))
+	}
+}
+
+var matrixInterpolation = regexp.MustCompile(`<<\s*matrix\.([A-Za-z0-9_-]+)\s*>>`)
+
+// The `<< matrix.* >>` interpolations of a job of a workflow, in its name or
+// its requirements for instance, reference the parameters of its own matrix
+func (val Validate) validateMatrixReferences(jobRef ast.JobRef) {
+	start := utils.PosToIndex(jobRef.JobRefRange.Start, val.Doc.Content)
+	end := utils.PosToIndex(jobRef.JobRefRange.End, val.Doc.Content)
+	if start < 0 || end > len(val.Doc.Content) || start >= end {
+		return
+	}
+
+	names := make([]string, 0, len(jobRef.MatrixParams))
+	for name := range jobRef.MatrixParams {
+		names = append(names, name)
+	}
+
+	text := string(val.Doc.Content[start:end])
+	for _, match := range matrixInterpolation.FindAllStringSubmatchIndex(text, -1) {
+		name := text[match[2]:match[3]]
+		if _, ok := jobRef.MatrixParams[name]; ok {
+			continue
+		}
+
+		message := fmt.Sprintf("Job %s has no matrix, matrix.%s can not be resolved", jobRef.StepName, name)
+		if jobRef.HasMatrix {
+			message = fmt.Sprintf("Matrix parameter %s is not defined by the matrix of job %s", name, jobRef.StepName)
+			if closest, found := utils.FindClosestMatch(name, names); found {
+				message += fmt.Sprintf(", did you mean %s?", closest)
+			}
+		}
+
+		val.addDiagnostic(utils.RuleUndefinedParameter, utils.CreateErrorDiagnosticFromRange(
+			protocol.Range{
+				Start: utils.IndexToPos(start+match[0], val.Doc.Content),
+				End:   utils.IndexToPos(start+match[1], val.Doc.Content),
+			},
+			message))
+	}
 }
 
 func (val Validate) doesJobRefExist(workflow ast.Workflow, requireName string) bool {
 	for _, jobRef := range workflow.JobRefs {
-		if jobRef.JobName == requireName || jobRef.StepName == requireName {
+		if isJobRefRequiredAs(jobRef, requireName) {
 			return true
 		}
 	}
 	return false
 }
 
+// A matrix produces one job per combination of its parameters, named after
+// `name` with the matrix values interpolated when given, otherwise after the
+// job name followed by the values: <name>-<value>-...
+func isJobRefRequiredAs(jobRef ast.JobRef, requireName string) bool {
+	if requireName == jobRef.GetRequireName() {
+		return true
+	}
+
+	if !jobRef.HasMatrix {
+		return jobRef.JobName == requireName || jobRef.StepName == requireName
+	}
+
+	if jobRef.StepName == jobRef.JobName {
+		return strings.HasPrefix(requireName, jobRef.JobName+"-")
+	}
+
+	return getMatrixNameRegex(jobRef).MatchString(requireName)
+}
+
+// Each interpolation of the name matches one of the values of its parameter
+func getMatrixNameRegex(jobRef ast.JobRef) *regexp.Regexp {
+	pattern := ""
+	last := 0
+
+	for _, match := range matrixInterpolation.FindAllStringSubmatchIndex(jobRef.StepName, -1) {
+		pattern += regexp.QuoteMeta(jobRef.StepName[last:match[0]])
+		last = match[1]
+
+		values := []string{}
+		if params, ok := jobRef.MatrixParams[jobRef.StepName[match[2]:match[3]]]; ok && len(params) > 0 {
+			if items, ok := params[0].Value.([]ast.ParameterValue); ok {
+				for _, item := range items {
+					values = append(values, regexp.QuoteMeta(fmt.Sprint(item.Value)))
+				}
+			}
+		}
+
+		if len(values) == 0 {
+			pattern += ".+"
+		} else {
+			pattern += "(" + strings.Join(values, "|") + ")"
+		}
+	}
+	pattern += regexp.QuoteMeta(jobRef.StepName[last:])
+
+	return regexp.MustCompile("^" + pattern + "$")
+}
+
+func getMatrixAliasedJobRef(workflow ast.Workflow, requireName string) (ast.JobRef, bool) {
+	for _, jobRef := range workflow.JobRefs {
+		if jobRef.HasMatrix && jobRef.MatrixAlias != "" && jobRef.JobName == requireName {
+			return jobRef, true
+		}
+	}
+	return ast.JobRef{}, false
+}
+
 func (val Validate) validateWorkflowParameters(jobRef ast.JobRef, stepName string, stepRange protocol.Range) {
 	definedParams := val.Doc.GetDefinedParams(stepName, val.Cache)
 
@@ -84,6 +254,7 @@ func (val Validate) validateWorkflowParameters(jobRef ast.JobRef, stepName strin
 
 		if !okMatrix && !okParams && !definedParam.IsOptional() {
 			val.addDiagnostic(
+				utils.RuleParameter,
 				utils.CreateErrorDiagnosticFromRange(
 					stepRange,
 					fmt.Sprintf("Parameter %s is required for %s", definedParam.GetName(), stepName),
@@ -92,44 +263,87 @@ func (val Validate) validateWorkflowParameters(jobRef ast.JobRef, stepName strin
 			continue
 		}
 
-		if okMatrix {
-			for _, param := range jobRef.MatrixParams[definedParam.GetName()] {
-				if param.Type == "enum" {
-					for _, value := range param.Value.([]ast.ParameterValue) {
-						val.checkParamSimpleType(value, stepName, definedParam)
-					}
-				} else if param.Type != "alias" {
-					val.addDiagnostic(utils.CreateErrorDiagnosticFromRange(
-						param.Range,
-						fmt.Sprintf("Parameter %s is not an enum of values", param.Name)),
-					)
-				}
-			}
-		} else if okParams {
+		if !okMatrix && okParams {
 			val.checkParamSimpleType(jobRef.Parameters[definedParam.GetName()], stepName, definedParam)
 		}
 	}
 
+	val.validateMatrixParameters(jobRef, stepName, definedParams)
+
 	for _, param := range jobRef.Parameters {
 		if definedParams[param.Name] == nil {
-			val.addDiagnostic(utils.CreateErrorDiagnosticFromRange(
+			val.addUndefinedParameterWarning(
+				fmt.Sprintf("Parameter %s is not defined in %s", param.Name, stepName),
+				param.Name,
 				param.Range,
-				fmt.Sprintf("Parameter %s is not defined in %s", param.Name, stepName)),
+				definedParams,
 			)
 		}
 	}
 }
 
+// Each value of a matrix parameter must be a valid value for the parameter of
+// the job
+func (val Validate) validateMatrixParameters(jobRef ast.JobRef, stepName string, definedParams map[string]ast.Parameter) {
+	for name, params := range jobRef.MatrixParams {
+		definedParam, ok := definedParams[name]
+
+		for _, param := range params {
+			if !ok {
+				val.addDiagnostic(utils.RuleUndefinedParameter, utils.CreateErrorDiagnosticFromRange(
+					param.Range,
+					fmt.Sprintf("Parameter %s is not defined in %s", name, stepName)),
+				)
+			} else if param.Type == "enum" {
+				for _, value := range param.Value.([]ast.ParameterValue) {
+					val.checkParamSimpleType(value, stepName, definedParam)
+				}
+			} else if param.Type != "alias" {
+				val.addDiagnostic(utils.RuleParameter, utils.CreateErrorDiagnosticFromRange(
+					param.Range,
+					fmt.Sprintf("Parameter %s is not an enum of values", param.Name)),
+				)
+			}
+		}
+	}
+}
+
 func (val Validate) validateDAG(workflow ast.Workflow) {
-	nodes_in_cycle := isValidDAG(workflow.JobsDAG)
+	// The DAG maps each job to the jobs requiring it, reverse it so that the
+	// cycles read in the order of the requires
+	requires := map[string][]string{}
+	for requirement, jobs := range workflow.JobsDAG {
+		for _, job := range jobs {
+			requires[job] = append(requires[job], requirement)
+		}
+	}
+
+	for _, cycle := range findCycles(requires) {
+		jobs := make([]string, 0, len(cycle)+1)
+		for _, job := range cycle {
+			jobs = append(jobs, fmt.Sprintf("`%s`", job))
+		}
+		jobs = append(jobs, jobs[0])
 
-	for _, node := range nodes_in_cycle {
 		for _, jobRef := range workflow.JobRefs {
-			if jobRef.JobName == node {
-				val.addDiagnostic(utils.CreateErrorDiagnosticFromRange(
+			if jobRef.GetRequireName() == cycle[0] {
+				val.addDiagnostic(utils.RuleCircularRequires, utils.CreateErrorDiagnosticFromRange(
 					jobRef.JobNameRange,
-					fmt.Sprintf("The job `%s` is part of a cycle", node)))
+					fmt.Sprintf("Circular requires between jobs: %s", strings.Join(jobs, " -> "))))
+				break
 			}
 		}
 	}
 }
+
+// Names other jobs of the workflow can use to require the given one
+func getJobRefNames(workflow ast.Workflow) []string {
+	names := []string{}
+	for _, jobRef := range workflow.JobRefs {
+		names = append(names, jobRef.GetRequireName())
+		if !jobRef.HasMatrix && jobRef.JobName != jobRef.StepName {
+			names = append(names, jobRef.JobName)
+		}
+	}
+	return names
+}
@@ -3,6 +3,7 @@ package validate
 import (
 	"testing"
 
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
 	"go.lsp.dev/protocol"
 )
@@ -39,10 +40,10 @@ workflows:
       - hold:
           type: invalid`,
 			Diagnostics: []protocol.Diagnostic{
-				utils.CreateErrorDiagnosticFromRange(protocol.Range{
+				utils.WithDiagnosticRule(utils.RuleJobType, utils.CreateErrorDiagnosticFromRange(protocol.Range{
 					Start: protocol.Position{Line: 0x6, Character: 0x10},
 					End:   protocol.Position{Line: 0x6, Character: 0x17},
-				}, "Type can only be \"approval\""),
+				}, "Type can only be \"approval\"")),
 			},
 		},
 		{
@@ -68,3 +69,408 @@ workflows:
 
 	CheckYamlErrors(t, testCases)
 }
+
+func TestWorkflowJobRefRequires(t *testing.T) {
+	testCases := []ValidateTestCase{
+		{
+			Name:       "Requires of an undefined job with a close match",
+			OnlyErrors: true,
+			YamlContent: `version: 2.1
+
+jobs:
+  build:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - checkout
+
+workflows:
+  someworkflow:
+    jobs:
+      - build
+      - hold:
+          type: approval
+          requires:
+            - biuld`,
+			Diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleUnknownRequires, utils.CreateErrorDiagnosticFromRange(protocol.Range{
+					Start: protocol.Position{Line: 16, Character: 14},
+					End:   protocol.Position{Line: 16, Character: 19},
+				}, "Cannot find declaration for job reference biuld, did you mean build?")),
+			},
+		},
+		{
+			Name:       "Requires of approval jobs and matrix jobs",
+			OnlyErrors: true,
+			YamlContent: `version: 2.1
+
+jobs:
+  build:
+    docker:
+      - image: cimg/base:2023.01
+    parameters:
+      version:
+        type: string
+    steps:
+      - checkout
+
+workflows:
+  someworkflow:
+    jobs:
+      - hold:
+          type: approval
+      - build:
+          requires:
+            - hold
+          matrix:
+            alias: build-all
+            parameters:
+              version: ["1", "2"]
+      - build:
+          name: deploy
+          version: "1"
+          requires:
+            - build-all
+            - build-1`,
+			Diagnostics: []protocol.Diagnostic{},
+		},
+		{
+			Name:       "Requires of a matrix with the implicit alias",
+			OnlyErrors: true,
+			YamlContent: `version: 2.1
+
+jobs:
+  build:
+    docker:
+      - image: cimg/base:2023.01
+    parameters:
+      os:
+        type: string
+    steps:
+      - checkout
+
+workflows:
+  someworkflow:
+    jobs:
+      - build:
+          name: build-<< matrix.os >>
+          matrix:
+            parameters:
+              os: [linux, macos]
+      - hold:
+          type: approval
+          requires:
+            - build
+            - build-linux
+            - build-macos-1`,
+			Diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleUnknownRequires, utils.CreateErrorDiagnosticFromRange(protocol.Range{
+					Start: protocol.Position{Line: 25, Character: 14},
+					End:   protocol.Position{Line: 25, Character: 27},
+				}, "Cannot find declaration for job reference build-macos-1")),
+			},
+		},
+		{
+			Name:       "Requires of a matrix with an explicit alias",
+			OnlyErrors: true,
+			YamlContent: `version: 2.1
+
+jobs:
+  build:
+    docker:
+      - image: cimg/base:2023.01
+    parameters:
+      os:
+        type: string
+    steps:
+      - checkout
+
+workflows:
+  someworkflow:
+    jobs:
+      - build:
+          matrix:
+            alias: build-all
+            parameters:
+              os: [linux, macos]
+      - hold:
+          type: approval
+          requires:
+            - build
+            - build-all
+            - build-linux`,
+			Diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleUnknownRequires, utils.CreateErrorDiagnosticFromRange(protocol.Range{
+					Start: protocol.Position{Line: 23, Character: 14},
+					End:   protocol.Position{Line: 23, Character: 19},
+				}, "The matrix of job build is aliased, it must be required as build-all")),
+			},
+		},
+	}
+
+	CheckYamlErrors(t, testCases)
+}
+
+func TestWorkflowJobRefContexts(t *testing.T) {
+	yaml := `version: 2.1
+
+jobs:
+  build:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - checkout
+
+workflows:
+  main:
+    jobs:
+      - build:
+          context: deplyo
+      - build:
+          name: other
+          context: [org-global, deploy, unknown]
+`
+
+	validate := func(resolved bool) []protocol.Diagnostic {
+		val := CreateValidateFromYAML(yaml)
+		val.Cache.FileCache.SetFile(utils.CachedFile{
+			TextDocument: protocol.TextDocumentItem{URI: val.Doc.URI, Text: yaml},
+			Project:      utils.Project{OrganizationName: "org"},
+		})
+		val.Cache.ContextCache.SetOrganizationContext("org", &utils.Context{Name: "deploy"})
+		if resolved {
+			val.Cache.ContextCache.SetOrganizationResolved("org", "https://circleci.com")
+		}
+
+		val.Validate(false)
+		return *val.Diagnostics
+	}
+
+	t.Run("Should warn about the contexts not in the organization", func(t *testing.T) {
+		diags := validate(true)
+		CompareDiagnostics(t, &[]protocol.Diagnostic{
+			utils.WithDiagnosticRule(utils.RuleUnknownContext, utils.CreateWarningDiagnosticFromRange(protocol.Range{
+				Start: protocol.Position{Line: 13, Character: 19},
+				End:   protocol.Position{Line: 13, Character: 25},
+			}, "Context deplyo does not exist, did you mean deploy?")),
+			utils.WithDiagnosticRule(utils.RuleUnknownContext, utils.CreateWarningDiagnosticFromRange(protocol.Range{
+				Start: protocol.Position{Line: 16, Character: 40},
+				End:   protocol.Position{Line: 16, Character: 47},
+			}, "Context unknown does not exist")),
+		}, &diags)
+	})
+
+	t.Run("Should not check the contexts before they are fetched", func(t *testing.T) {
+		diags := validate(false)
+		CompareDiagnostics(t, &[]protocol.Diagnostic{}, &diags)
+	})
+}
+
+func TestWorkflowMatrixParameters(t *testing.T) {
+	testCases := []ValidateTestCase{
+		{
+			Name: "Matrix values must match the parameters of the job",
+			YamlContent: `version: 2.1
+
+jobs:
+  build:
+    parameters:
+      os:
+        type: enum
+        enum: [linux, macos]
+      retries:
+        type: integer
+        default: 1
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - run: echo << parameters.os >> << parameters.retries >>
+
+workflows:
+  main:
+    jobs:
+      - build:
+          matrix:
+            parameters:
+              os: [linux, windows]
+              retries: [1, "two"]
+              unknown: [a, b]
+`,
+			Diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleParameter, utils.CreateErrorDiagnosticFromRange(protocol.Range{
+					Start: protocol.Position{Line: 22, Character: 26},
+					End:   protocol.Position{Line: 22, Character: 33},
+				}, "Parameter windows is not a valid value for os")),
+				utils.WithDiagnosticRule(utils.RuleParameter, utils.CreateErrorDiagnosticFromRange(protocol.Range{
+					Start: protocol.Position{Line: 23, Character: 27},
+					End:   protocol.Position{Line: 23, Character: 32},
+				}, "Parameter retries for build must be a integer")),
+				utils.WithDiagnosticRule(utils.RuleUndefinedParameter, utils.CreateErrorDiagnosticFromRange(protocol.Range{
+					Start: protocol.Position{Line: 24, Character: 14},
+					End:   protocol.Position{Line: 24, Character: 29},
+				}, "Parameter unknown is not defined in build")),
+			},
+		},
+	}
+
+	CheckYamlErrors(t, testCases)
+}
+
+func TestWorkflowOrbJobMatrixParameters(t *testing.T) {
+	val := CreateValidateFromYAML(`version: 2.1
+
+orbs:
+  tools: company/tools@1.0.0
+
+workflows:
+  main:
+    jobs:
+      - tools/test:
+          matrix:
+            parameters:
+              os: [linux, windows]
+              version: ["1", "2"]
+`)
+	val.Cache.OrbCache.SetOrb(&ast.OrbInfo{
+		OrbParsedAttributes: ast.OrbParsedAttributes{
+			Jobs: map[string]ast.Job{
+				"test": {
+					Name: "test",
+					Parameters: map[string]ast.Parameter{
+						"os": ast.EnumParameter{
+							BaseParameter: ast.BaseParameter{Name: "os"},
+							Enum:          []string{"linux", "macos"},
+						},
+					},
+				},
+			},
+		},
+	}, "company/tools@1.0.0")
+
+	jobRef := val.Doc.Workflows["main"].JobRefs[0]
+	val.validateMatrixParameters(jobRef, jobRef.JobName, val.Doc.GetOrbDefinedParams(jobRef.JobName, val.Cache))
+
+	CompareDiagnostics(t, &[]protocol.Diagnostic{
+		utils.WithDiagnosticRule(utils.RuleParameter, utils.CreateErrorDiagnosticFromRange(protocol.Range{
+			Start: protocol.Position{Line: 11, Character: 26},
+			End:   protocol.Position{Line: 11, Character: 33},
+		}, "Parameter windows is not a valid value for os")),
+		utils.WithDiagnosticRule(utils.RuleUndefinedParameter, utils.CreateErrorDiagnosticFromRange(protocol.Range{
+			Start: protocol.Position{Line: 12, Character: 14},
+			End:   protocol.Position{Line: 12, Character: 33},
+		}, "Parameter version is not defined in tools/test")),
+	}, val.Diagnostics)
+}
+
+func TestWorkflowScheduleCron(t *testing.T) {
+	testCases := []ValidateTestCase{
+		{
+			Name: "Cron issues are located within the value",
+			YamlContent: `version: 2.1
+
+jobs:
+  build:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - checkout
+
+workflows:
+  nightly:
+    triggers:
+      - schedule:
+          cron: "0 24 * * *"
+          filters:
+            branches:
+              only: main
+    jobs:
+      - build
+  weekly:
+    triggers:
+      - schedule:
+          cron: 0 0 1 * 1
+          filters:
+            branches:
+              only: main
+    jobs:
+      - build`,
+			Diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleCron, utils.CreateErrorDiagnosticFromRange(protocol.Range{
+					Start: protocol.Position{Line: 13, Character: 19},
+					End:   protocol.Position{Line: 13, Character: 21},
+				}, "Invalid hour 24, must be between 0 and 23")),
+				utils.WithDiagnosticRule(utils.RuleCron, utils.CreateWarningDiagnosticFromRange(protocol.Range{
+					Start: protocol.Position{Line: 22, Character: 16},
+					End:   protocol.Position{Line: 22, Character: 25},
+				}, "Both day of month and day of week are set, the workflow will run when either of them matches")),
+			},
+		},
+	}
+
+	CheckYamlErrors(t, testCases)
+}
+
+func TestWorkflowJobParameters(t *testing.T) {
+	val := CreateValidateFromYAML(`version: 2.1
+
+jobs:
+  build:
+    parameters:
+      retries:
+        type: integer
+        default: 1
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - checkout
+
+workflows:
+  main:
+    jobs:
+      - build:
+          name: build-twice
+          retires: 2
+`)
+	val.ValidateWorkflows()
+
+	CompareDiagnostics(t, &[]protocol.Diagnostic{
+		utils.WithDiagnosticRule(utils.RuleUndefinedParameter, utils.CreateWarningDiagnosticFromRange(createRange(18, 10, 20), "Parameter retires is not defined in build, did you mean retries?")),
+	}, val.Diagnostics)
+}
+
+func TestWorkflowMatrixReferences(t *testing.T) {
+	val := CreateValidateFromYAML(`version: 2.1
+
+jobs:
+  build:
+    parameters:
+      version:
+        type: string
+      os:
+        type: string
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - checkout
+
+workflows:
+  main:
+    jobs:
+      - build:
+          name: build-<< matrix.os >>-<< matrix.verison >>
+          matrix:
+            parameters:
+              version: ["1.0", "2.0"]
+              os: [linux]
+      - build:
+          name: build-<< matrix.os >>
+          version: "1.0"
+          os: linux
+`)
+	val.ValidateWorkflows()
+
+	CompareDiagnostics(t, &[]protocol.Diagnostic{
+		utils.WithDiagnosticRule(utils.RuleUndefinedParameter, utils.CreateErrorDiagnosticFromRange(createRange(18, 38, 58), "Matrix parameter verison is not defined by the matrix of job build-<< matrix.os >>-<< matrix.verison >>, did you mean version?")),
+		utils.WithDiagnosticRule(utils.RuleUndefinedParameter, utils.CreateErrorDiagnosticFromRange(createRange(24, 22, 37), "Job build-<< matrix.os >> has no matrix, matrix.os can not be resolved")),
+	}, val.Diagnostics)
+}
@@ -0,0 +1,248 @@
+package validate
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"go.lsp.dev/protocol"
+)
+
+// Workspace steps of a job, commands included
+type workspaceSteps struct {
+	persists int
+	attaches []workspaceAttach
+
+	// Top-level entries of the workspace persisted by the job, such as dist
+	// for dist/app.js. Unknown when a path is dynamic or a glob
+	persistedEntries map[string]bool
+	persistsUnknown  bool
+
+	// Set when some steps of the job are not known, orb commands or steps
+	// parameters for instance, which could persist to the workspace
+	opaque bool
+}
+
+type workspaceAttach struct {
+	step ast.AttachWorkspace
+
+	// Paths used by the steps following the attach
+	usages []workspaceUsage
+}
+
+type workspaceUsage struct {
+	path string
+	rng  protocol.Range
+}
+
+// Checks that the jobs of the workflows attaching a workspace require jobs
+// persisting to it, and that the paths of the workspace used after attaching
+// it are persisted by them. Where the workspace is mounted does not have to
+// match the root it was persisted from. Steps and paths can be dynamic, the
+// diagnostics are hints
+func (val Validate) ValidateWorkspaces() {
+	for _, workflow := range val.Doc.Workflows {
+		val.validateWorkflowWorkspaces(workflow)
+	}
+}
+
+func (val Validate) validateWorkflowWorkspaces(workflow ast.Workflow) {
+	reported := map[string]bool{}
+	addHint := func(rng protocol.Range, message string) {
+		key := fmt.Sprintf("%v %s", rng, message)
+		if !reported[key] {
+			reported[key] = true
+			val.addDiagnostic(utils.RuleWorkspace, utils.CreateHintDiagnosticFromRange(rng, message))
+		}
+	}
+
+	for _, jobRef := range workflow.JobRefs {
+		attaches := val.getJobRefWorkspaceSteps(jobRef).attaches
+		if len(attaches) == 0 {
+			continue
+		}
+
+		persists, opaque, unknown := 0, false, false
+		entries := map[string]bool{}
+		for _, upstream := range getUpstreamJobRefs(workflow, jobRef) {
+			steps := val.getJobRefWorkspaceSteps(upstream)
+			opaque = opaque || steps.opaque
+			unknown = unknown || steps.persistsUnknown
+			persists += steps.persists
+			for entry := range steps.persistedEntries {
+				entries[entry] = true
+			}
+		}
+
+		if opaque {
+			continue
+		}
+
+		if persists == 0 {
+			for _, attach := range attaches {
+				addHint(attach.step.Range, fmt.Sprintf(
+					"None of the jobs required by %s in workflow %s persists to the workspace, there is nothing to attach",
+					jobRef.StepName, workflow.Name))
+			}
+			continue
+		}
+
+		if unknown {
+			continue
+		}
+
+		for _, attach := range attaches {
+			for _, usage := range attach.usages {
+				entry, ok := getWorkspaceEntry(attach.step.At, usage.path)
+				if !ok || entries[entry] {
+					continue
+				}
+
+				addHint(usage.rng, fmt.Sprintf(
+					"%s is not persisted to the workspace by the jobs required by %s in workflow %s, they persist %s",
+					path.Join(path.Clean(attach.step.At), entry), jobRef.StepName, workflow.Name, strings.Join(sortedEntries(entries), ", ")))
+			}
+		}
+	}
+}
+
+// Top-level entry of the workspace attached at the given path that the used
+// path is in, false when the path is outside of the workspace or either path
+// is dynamic. Relative and absolute paths are not compared, resolving them
+// needs the working directory
+func getWorkspaceEntry(at string, usedPath string) (string, bool) {
+	if isDynamicWorkspacePath(at) || isDynamicWorkspacePath(usedPath) {
+		return "", false
+	}
+
+	at, usedPath = path.Clean(at), path.Clean(usedPath)
+	if at == "." || !strings.HasPrefix(usedPath, at+"/") {
+		return "", false
+	}
+
+	entry := strings.Split(strings.TrimPrefix(usedPath, at+"/"), "/")[0]
+	if strings.ContainsAny(entry, "*?[") {
+		return "", false
+	}
+
+	return entry, true
+}
+
+func isDynamicWorkspacePath(workspacePath string) bool {
+	return workspacePath == "" || strings.ContainsAny(workspacePath, "<$")
+}
+
+func sortedEntries(entries map[string]bool) []string {
+	res := make([]string, 0, len(entries))
+	for entry := range entries {
+		res = append(res, entry)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Job references required by the given one, directly or not
+func getUpstreamJobRefs(workflow ast.Workflow, jobRef ast.JobRef) []ast.JobRef {
+	upstream := []ast.JobRef{}
+	visited := map[int]bool{}
+	queue := []ast.JobRef{jobRef}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, require := range current.Requires {
+			for i, candidate := range workflow.JobRefs {
+				if visited[i] || !isJobRefRequiredAs(candidate, require.Text) {
+					continue
+				}
+				visited[i] = true
+				upstream = append(upstream, candidate)
+				queue = append(queue, candidate)
+			}
+		}
+	}
+
+	return upstream
+}
+
+func (val Validate) getJobRefWorkspaceSteps(jobRef ast.JobRef) workspaceSteps {
+	res := workspaceSteps{}
+
+	job, ok := val.Doc.Jobs[jobRef.JobName]
+	if !ok {
+		// Orb jobs and approval jobs
+		res.opaque = jobRef.Type != "approval"
+		return res
+	}
+
+	visited := map[string]bool{}
+	val.collectWorkspaceSteps(jobRef.PreSteps, visited, &res)
+	val.collectWorkspaceSteps(job.Steps, visited, &res)
+	val.collectWorkspaceSteps(jobRef.PostSteps, visited, &res)
+
+	return res
+}
+
+func (val Validate) collectWorkspaceSteps(steps []ast.Step, visitedCommands map[string]bool, res *workspaceSteps) {
+	for _, step := range steps {
+		switch step := step.(type) {
+		case ast.PersistToWorkspace:
+			res.persists++
+			for _, persistedPath := range step.Paths {
+				entry := strings.Split(path.Clean(persistedPath), "/")[0]
+				if isDynamicWorkspacePath(persistedPath) || entry == "." || entry == ".." || strings.ContainsAny(entry, "*?[") {
+					res.persistsUnknown = true
+					continue
+				}
+				if res.persistedEntries == nil {
+					res.persistedEntries = map[string]bool{}
+				}
+				res.persistedEntries[entry] = true
+			}
+
+		case ast.AttachWorkspace:
+			res.attaches = append(res.attaches, workspaceAttach{step: step})
+
+		case ast.StoreArtifacts:
+			res.addUsage(step.Path, step.PathRange)
+
+		case ast.StoreTestResults:
+			res.addUsage(step.Path, step.PathRange)
+
+		case ast.Run:
+			for _, word := range strings.FieldsFunc(step.Command, isShellWordSeparator) {
+				res.addUsage(strings.TrimPrefix(word, "./"), step.CommandRange)
+			}
+
+		case ast.Steps:
+			res.opaque = true
+
+		case ast.NamedStep:
+			command, ok := val.Doc.Commands[step.Name]
+			if !ok {
+				res.opaque = res.opaque || !val.Doc.IsBuiltIn(step.Name)
+				continue
+			}
+
+			if !visitedCommands[step.Name] {
+				visitedCommands[step.Name] = true
+				val.collectWorkspaceSteps(command.Steps, visitedCommands, res)
+			}
+		}
+	}
+}
+
+// Paths are used by the steps following the attaches
+func (res *workspaceSteps) addUsage(usedPath string, rng protocol.Range) {
+	for i := range res.attaches {
+		res.attaches[i].usages = append(res.attaches[i].usages, workspaceUsage{path: usedPath, rng: rng})
+	}
+}
+
+func isShellWordSeparator(r rune) bool {
+	return strings.ContainsRune(" \t\n\"'=;|&()", r)
+}
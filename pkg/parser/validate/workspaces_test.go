@@ -0,0 +1,245 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"go.lsp.dev/protocol"
+)
+
+func TestValidateWorkspaces(t *testing.T) {
+	testCases := []struct {
+		name        string
+		yamlContent string
+		diagnostics []protocol.Diagnostic
+	}{
+		{
+			name: "Workspace persisted by an indirectly required job",
+			yamlContent: `version: 2.1
+
+commands:
+  persist-dist:
+    steps:
+      - persist_to_workspace:
+          root: .
+          paths:
+            - dist
+
+jobs:
+  build:
+    docker:
+      - image: cimg/node:lts
+    steps:
+      - checkout
+      - persist-dist
+  test:
+    docker:
+      - image: cimg/node:lts
+    steps:
+      - run: make test
+  deploy:
+    docker:
+      - image: cimg/node:lts
+    working_directory: /home/circleci/app
+    steps:
+      - attach_workspace:
+          at: ~/project
+
+workflows:
+  main:
+    jobs:
+      - build
+      - test:
+          requires: [build]
+      - hold:
+          type: approval
+          requires: [test]
+      - deploy:
+          requires: [hold]
+`,
+			diagnostics: []protocol.Diagnostic{},
+		},
+		{
+			name: "Nothing persisted upstream",
+			yamlContent: `version: 2.1
+
+jobs:
+  build:
+    docker:
+      - image: cimg/node:lts
+    steps:
+      - checkout
+  deploy:
+    docker:
+      - image: cimg/node:lts
+    steps:
+      - attach_workspace:
+          at: .
+
+workflows:
+  main:
+    jobs:
+      - build
+      - deploy:
+          requires: [build]
+  standalone:
+    jobs:
+      - deploy
+`,
+			diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleWorkspace, utils.CreateHintDiagnosticFromRange(createRange(12, 8, 24), "None of the jobs required by deploy in workflow main persists to the workspace, there is nothing to attach")),
+				utils.WithDiagnosticRule(utils.RuleWorkspace, utils.CreateHintDiagnosticFromRange(createRange(12, 8, 24), "None of the jobs required by deploy in workflow standalone persists to the workspace, there is nothing to attach")),
+			},
+		},
+		{
+			name: "Workspace mounted elsewhere than its root",
+			yamlContent: `version: 2.1
+
+jobs:
+  build:
+    docker:
+      - image: cimg/node:lts
+    steps:
+      - persist_to_workspace:
+          root: workspace
+          paths:
+            - dist
+  deploy:
+    docker:
+      - image: cimg/node:lts
+    steps:
+      - attach_workspace:
+          at: /tmp/workspace
+
+workflows:
+  main:
+    jobs:
+      - build
+      - deploy:
+          requires: [build]
+`,
+			diagnostics: []protocol.Diagnostic{},
+		},
+		{
+			name: "Workspace paths not persisted upstream",
+			yamlContent: `version: 2.1
+
+jobs:
+  build:
+    docker:
+      - image: cimg/node:lts
+    steps:
+      - persist_to_workspace:
+          root: workspace
+          paths:
+            - dist/app.js
+            - ./docs
+  deploy:
+    docker:
+      - image: cimg/node:lts
+    steps:
+      - store_artifacts:
+          path: /tmp/workspace/reports
+      - attach_workspace:
+          at: /tmp/workspace
+      - run: ./deploy.sh /tmp/workspace/dist/app.js --docs=/tmp/workspace/docs
+      - run: cat /tmp/workspace/build.log
+      - store_artifacts:
+          path: /tmp/workspace/reports
+      - store_test_results:
+          path: /tmp/workspace/<< pipeline.id >>
+
+workflows:
+  main:
+    jobs:
+      - build
+      - deploy:
+          requires: [build]
+`,
+			diagnostics: []protocol.Diagnostic{
+				utils.WithDiagnosticRule(utils.RuleWorkspace, utils.CreateHintDiagnosticFromRange(createRange(21, 13, 41), "/tmp/workspace/build.log is not persisted to the workspace by the jobs required by deploy in workflow main, they persist dist, docs")),
+				utils.WithDiagnosticRule(utils.RuleWorkspace, utils.CreateHintDiagnosticFromRange(createRange(23, 16, 38), "/tmp/workspace/reports is not persisted to the workspace by the jobs required by deploy in workflow main, they persist dist, docs")),
+			},
+		},
+		{
+			name: "Workspace paths persisted with a glob",
+			yamlContent: `version: 2.1
+
+jobs:
+  build:
+    docker:
+      - image: cimg/node:lts
+    steps:
+      - persist_to_workspace:
+          root: .
+          paths:
+            - "*"
+  deploy:
+    docker:
+      - image: cimg/node:lts
+    steps:
+      - attach_workspace:
+          at: workspace
+      - run: ./workspace/deploy.sh
+
+workflows:
+  main:
+    jobs:
+      - build
+      - deploy:
+          requires: [build]
+`,
+			diagnostics: []protocol.Diagnostic{},
+		},
+		{
+			name: "Unknown or dynamic steps upstream",
+			yamlContent: `version: 2.1
+
+orbs:
+  node: circleci/node@5.1.0
+
+jobs:
+  build:
+    docker:
+      - image: cimg/node:lts
+    parameters:
+      root:
+        type: string
+        default: /tmp
+    steps:
+      - persist_to_workspace:
+          root: << parameters.root >>
+          paths:
+            - dist
+  deploy:
+    docker:
+      - image: cimg/node:lts
+    steps:
+      - attach_workspace:
+          at: /tmp/workspace
+
+workflows:
+  main:
+    jobs:
+      - build
+      - deploy:
+          requires: [build]
+  orb:
+    jobs:
+      - node/test
+      - deploy:
+          requires: [node/test]
+`,
+			diagnostics: []protocol.Diagnostic{},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			val := CreateValidateFromYAML(tt.yamlContent)
+			val.ValidateWorkspaces()
+
+			CompareDiagnostics(t, &tt.diagnostics, val.Diagnostics)
+		})
+	}
+}
@@ -21,6 +21,7 @@ func (doc *YamlDocument) parseWorkflows(workflowsNode *sitter.Node) {
 			if doc.Version >= 2.1 {
 				rng := doc.NodeToRange(child)
 				doc.addDiagnostic(
+					utils.RuleDeprecatedVersionKey,
 					utils.CreateDiagnosticFromRange(
 						rng,
 						protocol.DiagnosticSeverityWarning,
@@ -39,19 +40,8 @@ func (doc *YamlDocument) parseWorkflows(workflowsNode *sitter.Node) {
 			}
 		default:
 			workflow := doc.parseSingleWorkflow(child)
-			if definedWorkflow, ok := doc.Workflows[workflow.Name]; ok {
-				doc.addDiagnostic(protocol.Diagnostic{
-					Severity: protocol.DiagnosticSeverityWarning,
-					Range:    workflow.NameRange,
-					Message:  "Workflow already defined",
-					Source:   "cci-language-server",
-				})
-				doc.addDiagnostic(protocol.Diagnostic{
-					Severity: protocol.DiagnosticSeverityWarning,
-					Range:    definedWorkflow.NameRange,
-					Message:  "Workflow already defined",
-					Source:   "cci-language-server",
-				})
+			// Duplicate keys are reported by the validation
+			if _, ok := doc.Workflows[workflow.Name]; ok {
 				return
 			}
 
@@ -102,7 +92,7 @@ func (doc *YamlDocument) buildJobsDAG(jobRefs []ast.JobRef) map[string][]string
 	res := make(map[string][]string)
 	for _, jobRef := range jobRefs {
 		for _, requirement := range jobRef.Requires {
-			res[requirement.Text] = append(res[requirement.Text], jobRef.StepName)
+			res[requirement.Text] = append(res[requirement.Text], jobRef.GetRequireName())
 		}
 	}
 	return res
@@ -203,15 +193,17 @@ func (doc *YamlDocument) parseSingleJobReference(jobRefNode *sitter.Node) ast.Jo
 				case "context":
 					res.Context = doc.parseContext(valueNode)
 				case "filters":
+					if filters := doc.parseFilters(valueNode); filters != nil {
+						res.Filters = *filters
+					}
 				case "branches":
 				case "tags":
 				case "matrix":
 					res.HasMatrix = true
 					matrixParams, alias := doc.parseMatrixAttributes(valueNode)
 					res.MatrixParams = matrixParams
-					if alias != "" {
-						res.StepName = alias
-					}
+					res.MatrixAlias = alias.Text
+					res.MatrixAliasRange = alias.Range
 
 				case "pre-steps":
 					res.PreStepsRange = doc.NodeToRange(child)
@@ -255,11 +247,11 @@ func (doc *YamlDocument) parseSingleJobRequires(node *sitter.Node) []ast.TextAnd
 	return res
 }
 
-func (doc *YamlDocument) parseMatrixAttributes(node *sitter.Node) (map[string][]ast.ParameterValue, string) {
+func (doc *YamlDocument) parseMatrixAttributes(node *sitter.Node) (map[string][]ast.ParameterValue, ast.TextAndRange) {
 	// node is a block_node
 	blockMapping := GetChildOfType(node, "block_mapping")
 	res := make(map[string][]ast.ParameterValue)
-	alias := ""
+	alias := ast.TextAndRange{}
 
 	doc.iterateOnBlockMapping(blockMapping, func(child *sitter.Node) {
 		keyNode, valueNode := doc.GetKeyValueNodes(child)
@@ -271,7 +263,7 @@ func (doc *YamlDocument) parseMatrixAttributes(node *sitter.Node) (map[string][]
 		case "parameters":
 			res = doc.parseMatrixParam(valueNode)
 		case "alias":
-			alias = doc.GetNodeText(valueNode)
+			alias = doc.GetNodeTextWithRange(valueNode)
 		case "exclude":
 		}
 	})
@@ -392,6 +384,7 @@ func (doc *YamlDocument) parseSingleScheduleTrigger(node *sitter.Node) *ast.Sche
 	}
 
 	crontab := ""
+	cronRange := protocol.Range{}
 	filters := ast.WorkflowFilters{}
 
 	// Iterate on the blockmapping keys & fill stuff in, bruh.
@@ -402,6 +395,7 @@ func (doc *YamlDocument) parseSingleScheduleTrigger(node *sitter.Node) *ast.Sche
 
 		if key == "cron" {
 			crontab = doc.GetNodeText(valueNode)
+			cronRange = doc.NodeToRange(valueNode)
 		} else if key == "filters" {
 			f := doc.parseFilters(valueNode)
 
@@ -413,9 +407,10 @@ func (doc *YamlDocument) parseSingleScheduleTrigger(node *sitter.Node) *ast.Sche
 
 	// Cool, now construct the thingy thing
 	scheduleTrigger := ast.ScheduleTrigger{
-		Cron:    crontab,
-		Filters: filters,
-		Range:   doc.NodeToRange(blockMapping),
+		Cron:      crontab,
+		CronRange: cronRange,
+		Filters:   filters,
+		Range:     doc.NodeToRange(blockMapping),
 	}
 
 	return &scheduleTrigger
@@ -446,6 +441,12 @@ func (doc *YamlDocument) parseFilters(node *sitter.Node) *ast.WorkflowFilters {
 			if b != nil {
 				filters.Branches = *b
 			}
+		} else if key == "tags" {
+			t := doc.parseBranchFilter(valueNode)
+
+			if t != nil {
+				filters.Tags = *t
+			}
 		}
 	})
 
@@ -476,16 +477,10 @@ func (doc *YamlDocument) parseBranchFilter(node *sitter.Node) *ast.BranchesFilte
 		}
 
 		if key == "only" {
-			if valueNode.Child(0) != nil {
-				branchesFilter.Only = doc.sequenceToStrings(valueNode.Child(0))
-			}
-
+			branchesFilter.Only = doc.parseFilterPatterns(valueNode)
 			branchesFilter.OnlyRange = doc.NodeToRange(valueNode)
 		} else if key == "ignore" {
-			if valueNode.Child(0) != nil {
-				branchesFilter.Ignore = doc.sequenceToStrings(valueNode.Child(0))
-			}
-
+			branchesFilter.Ignore = doc.parseFilterPatterns(valueNode)
 			branchesFilter.IgnoreRange = doc.NodeToRange(valueNode)
 		}
 	})
@@ -493,33 +488,15 @@ func (doc *YamlDocument) parseBranchFilter(node *sitter.Node) *ast.BranchesFilte
 	return &branchesFilter
 }
 
-func (doc *YamlDocument) sequenceToStrings(node *sitter.Node) []string {
-	strs := []string{}
-
-	if node == nil || (node.Type() != "block_sequence" && node.Type() != "flow_sequence") {
-		return strs
+// Filters accept either a single pattern or a list of patterns
+func (doc *YamlDocument) parseFilterPatterns(node *sitter.Node) []ast.TextAndRange {
+	if GetChildSequence(node) != nil {
+		return doc.getNodeTextArrayWithRange(node)
 	}
 
-	for i := 0; i < int(node.ChildCount()); i++ {
-		child := node.Child(i)
-
-		if child.Type() == "block_sequence_item" {
-			child = child.Child(0)
-
-			if child.Type() == "-" {
-				child = child.NextSibling()
-			}
-		} else if child.Type() != "flow_node" {
-			continue
-		}
-
-		txt := doc.GetNodeText(child)
-
-		strs = append(
-			strs,
-			txt,
-		)
+	if node.Type() != "flow_node" {
+		return []ast.TextAndRange{}
 	}
 
-	return strs
+	return []ast.TextAndRange{doc.GetNodeTextWithRange(node)}
 }
@@ -128,14 +128,14 @@ func (doc *YamlDocument) ValidateYAML() {
 		for _, capture := range match.Captures {
 			node := capture.Node
 			diagnostic := utils.CreateErrorDiagnosticFromNode(node, "Error! Please fix your yaml file")
-			doc.addDiagnostic(diagnostic)
+			doc.addDiagnostic(utils.RuleYAMLSyntax, diagnostic)
 		}
 	})
 
 	// rootNode should be of type "stream"
 	if document := GetChildOfType(rootNode, "document"); document == nil {
 		diagnostic := utils.CreateErrorDiagnosticFromNode(rootNode, "Invalid yaml file")
-		doc.addDiagnostic(diagnostic)
+		doc.addDiagnostic(utils.RuleYAMLSyntax, diagnostic)
 	}
 }
 
@@ -191,6 +191,8 @@ type YamlDocument struct {
 	Context        *utils.LsContext
 	SchemaLocation string
 
+	// Whether the file is the setup configuration of a dynamic config,
+	// continuing the pipeline with a generated configuration
 	Setup              bool
 	Orbs               map[string]ast.Orb
 	LocalOrbs          []LocalOrb
@@ -250,6 +252,27 @@ func (doc *YamlDocument) IsOrbReference(orbReference string) bool {
 	return ok
 }
 
+// Tells if the reference is to an orb declared inline in the configuration.
+// The orb itself is included, as its members reference each other by its name
+func (doc *YamlDocument) IsLocalOrbReference(orbReference string) bool {
+	orbName, ok := doc.CouldBeOrbReference(orbReference)
+	if !ok {
+		return false
+	}
+
+	if orbName == doc.LocalOrbName {
+		return true
+	}
+
+	for _, localOrb := range doc.LocalOrbs {
+		if localOrb.Name == orbName {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (doc *YamlDocument) CouldBeOrbReference(orbReference string) (string, bool) {
 	splittedCommand := strings.Split(orbReference, "/")
 
@@ -260,8 +283,9 @@ func (doc *YamlDocument) CouldBeOrbReference(orbReference string) (string, bool)
 	return splittedCommand[0], true
 }
 
-// Takes the name of anything that may be in an orb and returns if it is inside an orb that we can not use
-func (doc *YamlDocument) IsFromUnfetchableOrb(name string) bool {
+// Takes the name of anything that may be in an orb and returns if it is inside an orb that we can not use,
+// either because its version is a parameter or because it is not cached while offline
+func (doc *YamlDocument) IsFromUnfetchableOrb(name string, cache *utils.Cache) bool {
 	components := strings.Split(name, "/")
 	if len(components) != 2 {
 		return false
@@ -273,20 +297,23 @@ func (doc *YamlDocument) IsFromUnfetchableOrb(name string) bool {
 	}
 
 	hasParamInTag, _ := utils.CheckIfParamIsPartiallyReferenced(orb.Url.Version)
-	return hasParamInTag
+	if hasParamInTag {
+		return true
+	}
+
+	if _, isLocal := doc.LocalOrbInfo[orb.Name]; isLocal || orb.Url.IsLocal || !doc.Context.IsOffline() {
+		return false
+	}
+
+	return cache == nil || !cache.OrbCache.HasOrb(orb.Url.GetOrbID())
 }
 
 func (doc *YamlDocument) IsOrbCommand(orbCommand string, cache *utils.Cache) bool {
-	splittedCommand := strings.Split(orbCommand, "/")
-
-	if len(splittedCommand) != 2 {
+	if strings.Count(orbCommand, "/") != 1 {
 		return false
 	}
 
-	orbName := splittedCommand[0]
-	commandName := splittedCommand[1]
-
-	orbInfo, err := doc.GetOrbInfoFromName(orbName, cache)
+	orbInfo, commandName, err := doc.GetOrbInfoFromReference(orbCommand, cache)
 
 	if err != nil || orbInfo == nil {
 		return false
@@ -298,16 +325,11 @@ func (doc *YamlDocument) IsOrbCommand(orbCommand string, cache *utils.Cache) boo
 }
 
 func (doc *YamlDocument) IsOrbJob(orbCommand string, cache *utils.Cache) bool {
-	splittedCommand := strings.Split(orbCommand, "/")
-
-	if len(splittedCommand) != 2 {
+	if strings.Count(orbCommand, "/") != 1 {
 		return false
 	}
 
-	orbName := splittedCommand[0]
-	commandName := splittedCommand[1]
-
-	orbInfo, err := doc.GetOrbInfoFromName(orbName, cache)
+	orbInfo, commandName, err := doc.GetOrbInfoFromReference(orbCommand, cache)
 
 	if err != nil || orbInfo == nil {
 		return false
@@ -377,8 +399,9 @@ func (doc *YamlDocument) parseVersion(versionNode *sitter.Node) {
 	doc.Version = float32(parsedVersion)
 }
 
-func (doc *YamlDocument) addDiagnostic(diagnostic protocol.Diagnostic) {
-	*doc.Diagnostics = append(*doc.Diagnostics, diagnostic)
+// The rule identifies the kind of issue reported, see the utils.Rule constants
+func (doc *YamlDocument) addDiagnostic(rule string, diagnostic protocol.Diagnostic) {
+	*doc.Diagnostics = append(*doc.Diagnostics, utils.WithDiagnosticRule(rule, diagnostic))
 }
 
 func (doc *YamlDocument) InsertText(pos protocol.Position, text string) (YamlDocument, error) {
@@ -591,12 +614,8 @@ func (doc *YamlDocument) GetDefinedParams(entityName string, cache *utils.Cache)
 func (doc *YamlDocument) GetOrbDefinedParams(entityName string, cache *utils.Cache) map[string]ast.Parameter {
 	var definedParams map[string]ast.Parameter
 
-	splittedName := strings.Split(entityName, "/")
-	orbName := splittedName[0]
-	commandOrJob := splittedName[1]
-
-	orbInfo, err := doc.GetOrFetchOrbInfo(doc.Orbs[orbName], cache)
-	if err != nil {
+	orbInfo, commandOrJob, err := doc.GetOrbInfoFromReference(entityName, cache)
+	if err != nil || orbInfo == nil {
 		return definedParams
 	}
 
@@ -630,6 +649,7 @@ func (doc *YamlDocument) ToOrbParsedAttributes() ast.OrbParsedAttributes {
 func (doc *YamlDocument) FromOrbParsedAttributesToYamlDocument(orb ast.OrbParsedAttributes) YamlDocument {
 	return YamlDocument{
 		LocalOrbName: orb.Name,
+		LocalOrbs:    doc.LocalOrbs,
 
 		RootNode: doc.RootNode,
 
@@ -48,6 +48,7 @@ jobs:
 	expect.DiagnosticList(t, *yamlDocument.Diagnostics).To.Include(protocol.Diagnostic{
 		Range:    machineRange,
 		Severity: protocol.DiagnosticSeverityWarning,
+		Code:     utils.RuleDeprecatedImage,
 		Message:  utils.GetMachineTrueMessage(img),
 		Data: []protocol.CodeAction{
 			utils.CreateCodeActionTextEdit("Replace with most updated ubuntu image", yamlDocument.URI,
@@ -136,6 +137,7 @@ jobs:
 		protocol.Diagnostic{
 			Range:    machineRange,
 			Severity: protocol.DiagnosticSeverityWarning,
+			Code:     utils.RuleDeprecatedImage,
 			Message:  utils.GetMachineTrueMessage(img),
 			Data: []protocol.CodeAction{
 				utils.CreateCodeActionTextEdit("Replace with most updated ubuntu image", yamlDocument.URI,
@@ -234,6 +236,7 @@ jobs:
 		protocol.Diagnostic{
 			Range:    machineRange,
 			Severity: protocol.DiagnosticSeverityWarning,
+			Code:     utils.RuleDeprecatedImage,
 			Message:  utils.GetMachineTrueMessage(img),
 			Data: []protocol.CodeAction{
 				utils.CreateCodeActionTextEdit("Replace with most updated ubuntu image", yamlDocument.URI,
@@ -258,8 +261,8 @@ orbs:
 `), testHelpers.GetDefaultLsContext(), uri.File(""), protocol.Position{})
 
 	assert.Nil(t, err)
-	assert.True(t, yamlDocument.IsFromUnfetchableOrb("ccc/entity"))
-	assert.False(t, yamlDocument.IsFromUnfetchableOrb("slack/entity"))
+	assert.True(t, yamlDocument.IsFromUnfetchableOrb("ccc/entity", nil))
+	assert.False(t, yamlDocument.IsFromUnfetchableOrb("slack/entity", nil))
 }
 
 func TestSetupKey(t *testing.T) {
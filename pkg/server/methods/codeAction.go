@@ -3,6 +3,7 @@ package methods
 import (
 	"fmt"
 
+	languageservice "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/services"
 	"github.com/segmentio/encoding/json"
 	"go.lsp.dev/jsonrpc2"
 	"go.lsp.dev/protocol"
@@ -31,5 +32,10 @@ func (methods *Methods) CodeAction(reply jsonrpc2.Replier, req jsonrpc2.Request)
 		res = append(res, codeActions...)
 	}
 
+	refactors, err := languageservice.CodeActions(params, methods.Cache, methods.LsContext)
+	if err == nil {
+		res = append(res, refactors...)
+	}
+
 	return reply(methods.Ctx, res, nil)
 }
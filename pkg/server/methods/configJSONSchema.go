@@ -0,0 +1,14 @@
+package methods
+
+import (
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"go.lsp.dev/jsonrpc2"
+)
+
+// Custom request returning the JSON Schema of the configuration the server
+// completes and validates, for the other tools to reuse
+const MethodConfigJSONSchema = "circleci/configJSONSchema"
+
+func (methods *Methods) ConfigJSONSchema(reply jsonrpc2.Replier, req jsonrpc2.Request) error {
+	return reply(methods.Ctx, utils.GetConfigJSONSchema(), nil)
+}
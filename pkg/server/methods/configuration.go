@@ -0,0 +1,86 @@
+package methods
+
+import (
+	"fmt"
+	"maps"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"github.com/segmentio/encoding/json"
+	"go.lsp.dev/jsonrpc2"
+)
+
+// The settings are either sent as is or under the section of the extension
+type ConfigurationSettings struct {
+	Offline              *bool                  `json:"offline"`
+	DiagnosticSeverities map[string]interface{} `json:"diagnosticSeverities"`
+	LogLevel             *string                `json:"logLevel"`
+	CircleCI             *struct {
+		Offline              *bool                  `json:"offline"`
+		DiagnosticSeverities map[string]interface{} `json:"diagnosticSeverities"`
+		LogLevel             *string                `json:"logLevel"`
+	} `json:"circleci"`
+}
+
+func (methods *Methods) DidChangeConfiguration(reply jsonrpc2.Replier, req jsonrpc2.Request) error {
+	params := struct {
+		Settings ConfigurationSettings `json:"settings"`
+	}{}
+	if err := json.Unmarshal(req.Params(), &params); err != nil {
+		return reply(methods.Ctx, nil, fmt.Errorf("%s: %w", jsonrpc2.ErrParse, err))
+	}
+
+	offline := params.Settings.Offline
+	if params.Settings.CircleCI != nil && params.Settings.CircleCI.Offline != nil {
+		offline = params.Settings.CircleCI.Offline
+	}
+
+	severitiesSetting := params.Settings.DiagnosticSeverities
+	if params.Settings.CircleCI != nil && params.Settings.CircleCI.DiagnosticSeverities != nil {
+		severitiesSetting = params.Settings.CircleCI.DiagnosticSeverities
+	}
+
+	logLevel := params.Settings.LogLevel
+	if params.Settings.CircleCI != nil && params.Settings.CircleCI.LogLevel != nil {
+		logLevel = params.Settings.CircleCI.LogLevel
+	}
+
+	// The level only changes the logs, the files are not validated again
+	if logLevel != nil {
+		if level, ok := utils.ParseLogLevel(*logLevel); ok {
+			methods.LsContext.Logger.SetLevel(level)
+		}
+	}
+
+	changed := false
+
+	if offline != nil && *offline != methods.LsContext.IsOffline() {
+		methods.LsContext.SetOffline(*offline)
+		changed = true
+
+		if !*offline {
+			// The images recorded as unknown are checked again
+			methods.Cache.DockerCache.RemoveWithError(utils.ErrOffline)
+		}
+	}
+
+	if severitiesSetting != nil {
+		severities := utils.ParseDiagnosticSeverities(severitiesSetting)
+		if !maps.Equal(severities, methods.LsContext.GetDiagnosticSeverities()) {
+			methods.LsContext.SetDiagnosticSeverities(severities)
+			changed = true
+		}
+	}
+
+	if changed {
+		go methods.revalidateAllFiles()
+	}
+
+	return reply(methods.Ctx, nil, nil)
+}
+
+func (methods *Methods) revalidateAllFiles() {
+	for _, file := range methods.Cache.FileCache.GetFiles() {
+		methods.parsingMethods(file.TextDocument)
+		methods.notificationMethods(file.TextDocument)
+	}
+}
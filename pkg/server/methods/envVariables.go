@@ -27,9 +27,8 @@ func (methods *Methods) updateProjectsEnvVariables() {
 }
 
 func (methods *Methods) updateProjectEnvVariables(file *utils.CachedFile) {
+	methods.Cache.FileCache.ResetProjectEnvVariables(file.TextDocument.URI)
 	cachedFile := methods.Cache.FileCache.GetFile(file.TextDocument.URI)
-	cachedFile.EnvVariables = []string{}
-	methods.Cache.FileCache.SetFile(*cachedFile)
 	if methods.LsContext.Api.Token != "" {
 		utils.GetAllProjectEnvVariables(methods.LsContext, methods.Cache, cachedFile)
 	}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
+	languageservice "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/services"
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
 	"github.com/rollbar/rollbar-go"
 	"github.com/segmentio/encoding/json"
@@ -60,6 +61,14 @@ func (methods *Methods) ExecuteCommand(reply jsonrpc2.Replier, req jsonrpc2.Requ
 
 		return reply(methods.Ctx, workflows, nil)
 
+	case languageservice.UpgradeAllOrbsCommand:
+		edit := languageservice.UpgradeAllOrbs(methods.Cache, methods.LsContext)
+		return reply(methods.Ctx, edit, nil)
+
+	case languageservice.PinAllOrbsCommand:
+		edit := languageservice.PinAllOrbs(methods.Cache, methods.LsContext)
+		return reply(methods.Ctx, edit, nil)
+
 	case "setRollbarInformation":
 		parameters, ok := arguments[0].(map[string]interface{})
 		if !ok {
@@ -93,10 +102,11 @@ func (methods *Methods) ExecuteCommand(reply jsonrpc2.Replier, req jsonrpc2.Requ
 
 func (methods *Methods) setToken(token string) {
 	if methods.LsContext.Api.Token != token {
-		methods.Cache.ClearHostData()
+		methods.Cache.ClearAllHostData()
 	}
 
 	methods.LsContext.Api.Token = token
+	methods.LsContext.Logger.Redact(token)
 	filesCache := methods.Cache.FileCache.GetFiles()
 	for _, file := range filesCache {
 		go methods.notificationMethods(file.TextDocument)
@@ -107,7 +117,8 @@ func (methods *Methods) setToken(token string) {
 
 func (methods *Methods) setHostUrl(hostUrl string) {
 	if methods.LsContext.Api.HostUrl != hostUrl {
-		methods.Cache.ClearHostData()
+		methods.Cache.ClearAllHostData()
+		methods.Cache.ClearHostDataFor(methods.LsContext.Api.HostUrl)
 	}
 
 	if hostUrl != "" {
@@ -0,0 +1,36 @@
+package methods
+
+import (
+	"fmt"
+
+	languageservice "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/services"
+	"github.com/segmentio/encoding/json"
+	"go.lsp.dev/jsonrpc2"
+	"go.lsp.dev/protocol"
+)
+
+func (methods *Methods) Formatting(reply jsonrpc2.Replier, req jsonrpc2.Request) error {
+	params := protocol.DocumentFormattingParams{}
+	if err := json.Unmarshal(req.Params(), &params); err != nil {
+		return reply(methods.Ctx, nil, fmt.Errorf("%s: %w", jsonrpc2.ErrParse, err))
+	}
+
+	res, err := languageservice.Formatting(params, methods.Cache, methods.LsContext)
+	if err != nil {
+		return reply(methods.Ctx, nil, err)
+	}
+	return reply(methods.Ctx, res, nil)
+}
+
+func (methods *Methods) RangeFormatting(reply jsonrpc2.Replier, req jsonrpc2.Request) error {
+	params := protocol.DocumentRangeFormattingParams{}
+	if err := json.Unmarshal(req.Params(), &params); err != nil {
+		return reply(methods.Ctx, nil, fmt.Errorf("%s: %w", jsonrpc2.ErrParse, err))
+	}
+
+	res, err := languageservice.RangeFormatting(params, methods.Cache, methods.LsContext)
+	if err != nil {
+		return reply(methods.Ctx, nil, err)
+	}
+	return reply(methods.Ctx, res, nil)
+}
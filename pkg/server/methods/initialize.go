@@ -3,6 +3,7 @@ package methods
 import (
 	"fmt"
 
+	languageservice "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/services"
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
 	"github.com/segmentio/encoding/json"
 	"go.lsp.dev/jsonrpc2"
@@ -16,12 +17,25 @@ type SemanticTokensOptions struct {
 	Full             bool                          `json:"full,omitempty"`
 }
 
+// Inlay hints are not part of the capabilities of the protocol package
+type ServerCapabilities struct {
+	protocol.ServerCapabilities
+	InlayHintProvider bool `json:"inlayHintProvider,omitempty"`
+}
+
+type InitializeResult struct {
+	Capabilities ServerCapabilities   `json:"capabilities"`
+	ServerInfo   *protocol.ServerInfo `json:"serverInfo,omitempty"`
+}
+
 var TokenTypes = []protocol.SemanticTokenTypes{
 	protocol.SemanticTokenKeyword,
 	protocol.SemanticTokenNamespace,
 	protocol.SemanticTokenClass,
 	protocol.SemanticTokenComment,
 	protocol.SemanticTokenFunction,
+	protocol.SemanticTokenOperator,
+	protocol.SemanticTokenVariable,
 }
 
 var TokenModifiers = []protocol.SemanticTokenModifiers{
@@ -40,6 +54,51 @@ func (methods *Methods) Initialize(reply jsonrpc2.Replier, req jsonrpc2.Request)
 		if ok && isCciExtension == true {
 			methods.LsContext.IsCciExtension = true
 		}
+		ignoreUnusedDefinitions, ok := params.InitializationOptions.(map[string]interface{})["ignoreUnusedDefinitions"]
+		if ok && ignoreUnusedDefinitions == true {
+			methods.LsContext.IgnoreUnusedDefinitions = true
+		}
+		disableInlayHints, ok := params.InitializationOptions.(map[string]interface{})["disableInlayHints"]
+		if ok && disableInlayHints == true {
+			methods.LsContext.DisableInlayHints = true
+		}
+		disableDockerImageChecks, ok := params.InitializationOptions.(map[string]interface{})["disableDockerImageChecks"]
+		if ok && disableDockerImageChecks == true {
+			methods.LsContext.DisableDockerImageChecks = true
+		}
+		disableFormatterKeyOrdering, ok := params.InitializationOptions.(map[string]interface{})["disableFormatterKeyOrdering"]
+		if ok && disableFormatterKeyOrdering == true {
+			methods.LsContext.DisableFormatterKeyOrdering = true
+		}
+		checkoutCommands, ok := params.InitializationOptions.(map[string]interface{})["checkoutCommands"]
+		if ok {
+			methods.LsContext.CheckoutCommands = parseStringList(checkoutCommands)
+		}
+		offline, ok := params.InitializationOptions.(map[string]interface{})["offline"]
+		if ok && offline == true {
+			methods.LsContext.SetOffline(true)
+		}
+		diagnosticSeverities, ok := params.InitializationOptions.(map[string]interface{})["diagnosticSeverities"]
+		if ok {
+			methods.LsContext.SetDiagnosticSeverities(utils.ParseDiagnosticSeverities(diagnosticSeverities))
+		}
+		dockerRegistryCredentials, ok := params.InitializationOptions.(map[string]interface{})["dockerRegistryCredentials"]
+		if ok {
+			methods.LsContext.DockerRegistryCredentials = parseDockerRegistryCredentials(dockerRegistryCredentials)
+			for _, credentials := range methods.LsContext.DockerRegistryCredentials {
+				methods.LsContext.Logger.Redact(credentials.Password)
+			}
+		}
+		logLevel, ok := params.InitializationOptions.(map[string]interface{})["logLevel"]
+		if ok {
+			if level, ok := utils.ParseLogLevel(logLevel); ok {
+				methods.LsContext.Logger.SetLevel(level)
+			}
+		}
+		logFile, ok := params.InitializationOptions.(map[string]interface{})["logFile"]
+		if ok {
+			methods.setLogFile(logFile)
+		}
 		userAgent, ok := params.InitializationOptions.(map[string]interface{})["userAgent"]
 		if ok {
 			userAgentString, ok := userAgent.(string)
@@ -49,57 +108,149 @@ func (methods *Methods) Initialize(reply jsonrpc2.Replier, req jsonrpc2.Request)
 		}
 	}
 
-	v := protocol.InitializeResult{
-		Capabilities: protocol.ServerCapabilities{
-			RenameProvider: false,
-			TextDocumentSync: protocol.TextDocumentSyncOptions{
-				OpenClose: true,
-				Change:    protocol.TextDocumentSyncKindIncremental,
-			},
-			SemanticTokensProvider: SemanticTokensOptions{
-				Legend: protocol.SemanticTokensLegend{
-					TokenTypes:     TokenTypes,
-					TokenModifiers: TokenModifiers,
+	methods.LsContext.Logger.SetSink("client", methods.newClientLogSink())
+
+	// Without the capability, the progress of the work is simply not reported
+	if params.Capabilities.Window != nil && params.Capabilities.Window.WorkDoneProgress {
+		methods.LsContext.Progress = methods.newProgressReporter()
+	}
+
+	v := InitializeResult{
+		Capabilities: ServerCapabilities{
+			ServerCapabilities: protocol.ServerCapabilities{
+				RenameProvider: protocol.RenameOptions{
+					PrepareProvider: true,
 				},
-				Full:  true,
-				Range: false,
-			},
-			DefinitionProvider: protocol.DefinitionOptions{
-				WorkDoneProgressOptions: protocol.WorkDoneProgressOptions{
-					WorkDoneProgress: true,
+				TextDocumentSync: protocol.TextDocumentSyncOptions{
+					OpenClose: true,
+					Change:    protocol.TextDocumentSyncKindIncremental,
 				},
-			},
-			ReferencesProvider: protocol.ReferenceOptions{
-				WorkDoneProgressOptions: protocol.WorkDoneProgressOptions{
-					WorkDoneProgress: true,
+				SemanticTokensProvider: SemanticTokensOptions{
+					Legend: protocol.SemanticTokensLegend{
+						TokenTypes:     TokenTypes,
+						TokenModifiers: TokenModifiers,
+					},
+					Full:  true,
+					Range: false,
 				},
-			},
-			CompletionProvider: &protocol.CompletionOptions{
-				ResolveProvider: false,
-				// TriggerCharacters: []string{":"},
-			},
-			HoverProvider: &protocol.HoverOptions{
-				WorkDoneProgressOptions: protocol.WorkDoneProgressOptions{
-					WorkDoneProgress: true,
+				DefinitionProvider: protocol.DefinitionOptions{
+					WorkDoneProgressOptions: protocol.WorkDoneProgressOptions{
+						WorkDoneProgress: true,
+					},
 				},
-			},
-			ExecuteCommandProvider: &protocol.ExecuteCommandOptions{
-				Commands: []string{"setToken"},
-			},
-			CodeActionProvider: &protocol.CodeActionRegistrationOptions{
-				CodeActionOptions: protocol.CodeActionOptions{
-					CodeActionKinds: []protocol.CodeActionKind{
-						"quickfix",
+				ReferencesProvider: protocol.ReferenceOptions{
+					WorkDoneProgressOptions: protocol.WorkDoneProgressOptions{
+						WorkDoneProgress: true,
 					},
+				},
+				CompletionProvider: &protocol.CompletionOptions{
+					ResolveProvider: false,
+					// TriggerCharacters: []string{":"},
+				},
+				HoverProvider: &protocol.HoverOptions{
+					WorkDoneProgressOptions: protocol.WorkDoneProgressOptions{
+						WorkDoneProgress: true,
+					},
+				},
+				ExecuteCommandProvider: &protocol.ExecuteCommandOptions{
+					Commands: []string{"setToken", languageservice.UpgradeAllOrbsCommand, languageservice.PinAllOrbsCommand},
+				},
+				CodeActionProvider: &protocol.CodeActionRegistrationOptions{
+					CodeActionOptions: protocol.CodeActionOptions{
+						CodeActionKinds: []protocol.CodeActionKind{
+							"quickfix",
+							protocol.RefactorExtract,
+							protocol.RefactorRewrite,
+						},
+						ResolveProvider: true,
+					},
+				},
+				DocumentSymbolProvider: true,
+				FoldingRangeProvider:   true,
+				SignatureHelpProvider: &protocol.SignatureHelpOptions{
+					TriggerCharacters:   []string{":"},
+					RetriggerCharacters: []string{"\n"},
+				},
+				CodeLensProvider: &protocol.CodeLensOptions{
 					ResolveProvider: true,
 				},
+				DocumentLinkProvider:            &protocol.DocumentLinkOptions{},
+				DocumentFormattingProvider:      true,
+				DocumentRangeFormattingProvider: true,
 			},
-			DocumentSymbolProvider: true,
+			InlayHintProvider: true,
 		},
 		ServerInfo: &protocol.ServerInfo{
 			Name:    "circleci-language-server",
 			Version: utils.ServerVersion,
 		},
 	}
+
+	for _, warning := range methods.StartupWarnings {
+		methods.Conn.Notify(methods.Ctx, protocol.MethodWindowLogMessage, protocol.LogMessageParams{
+			Type:    protocol.MessageTypeWarning,
+			Message: warning,
+		})
+	}
+
 	return reply(methods.Ctx, v, nil)
 }
+
+// The logs are also appended to the file of the `logFile` option
+func (methods *Methods) setLogFile(option interface{}) {
+	path, ok := option.(string)
+	if !ok || path == "" {
+		return
+	}
+
+	sink, err := utils.OpenLogFile(path)
+	if err != nil {
+		methods.StartupWarnings = append(methods.StartupWarnings, fmt.Sprintf("The log file %s can not be opened: %s", path, err))
+		return
+	}
+
+	methods.LsContext.Logger.SetSink("file", sink)
+}
+
+// The credentials are given by registry host:
+// { "gcr.io": { "username": "_json_key", "password": "..." } }
+func parseDockerRegistryCredentials(option interface{}) map[string]utils.DockerRegistryCredentials {
+	credentials := map[string]utils.DockerRegistryCredentials{}
+
+	registries, ok := option.(map[string]interface{})
+	if !ok {
+		return credentials
+	}
+
+	for registry, value := range registries {
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		username, _ := fields["username"].(string)
+		password, _ := fields["password"].(string)
+		credentials[registry] = utils.DockerRegistryCredentials{Username: username, Password: password}
+	}
+
+	return credentials
+}
+
+// Reads an option listing strings, the values that are not strings are
+// ignored. Nil when the option is not a list, an empty list being kept empty
+func parseStringList(option interface{}) []string {
+	list, ok := option.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	values := []string{}
+
+	for _, value := range list {
+		if text, ok := value.(string); ok && text != "" {
+			values = append(values, text)
+		}
+	}
+
+	return values
+}
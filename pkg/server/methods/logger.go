@@ -0,0 +1,34 @@
+package methods
+
+import (
+	"log/slog"
+
+	"go.lsp.dev/protocol"
+)
+
+// Sends the logs to the client with `window/logMessage`, the debug logs with
+// the log type the clients usually show in their output channel only
+type clientLogSink struct {
+	methods *Methods
+}
+
+func (methods *Methods) newClientLogSink() clientLogSink {
+	return clientLogSink{methods: methods}
+}
+
+func (sink clientLogSink) WriteLog(level slog.Level, message string) {
+	messageType := protocol.MessageTypeLog
+	switch {
+	case level >= slog.LevelError:
+		messageType = protocol.MessageTypeError
+	case level >= slog.LevelWarn:
+		messageType = protocol.MessageTypeWarning
+	case level >= slog.LevelInfo:
+		messageType = protocol.MessageTypeInfo
+	}
+
+	sink.methods.Conn.Notify(sink.methods.Ctx, protocol.MethodWindowLogMessage, protocol.LogMessageParams{
+		Type:    messageType,
+		Message: message,
+	})
+}
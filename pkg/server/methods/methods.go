@@ -13,4 +13,8 @@ type Methods struct {
 	Cache          *utils.Cache
 	LsContext      *utils.LsContext
 	SchemaLocation string
+
+	// Warnings of the start of the server, logged to the client during the
+	// initialization, the earliest it can receive them
+	StartupWarnings []string
 }
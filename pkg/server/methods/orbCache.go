@@ -0,0 +1,66 @@
+package methods
+
+import (
+	"fmt"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
+	"github.com/segmentio/encoding/json"
+	"go.lsp.dev/jsonrpc2"
+)
+
+// Custom request describing the orbs currently cached, used to diagnose orb
+// resolution issues
+const MethodDumpOrbCache = "circleci/dumpOrbCache"
+
+// Custom request fetching an orb again, for orbs republished under the same
+// version while being developed
+const MethodRefreshOrb = "circleci/refreshOrb"
+
+type RefreshOrbParams struct {
+	OrbID string `json:"orbId"`
+}
+
+type RefreshOrbResult struct {
+	Version string `json:"version"`
+}
+
+func (methods *Methods) DumpOrbCache(reply jsonrpc2.Replier, req jsonrpc2.Request) error {
+	return reply(methods.Ctx, methods.Cache.OrbCache.Snapshot(), nil)
+}
+
+func (methods *Methods) RefreshOrb(reply jsonrpc2.Replier, req jsonrpc2.Request) error {
+	params := RefreshOrbParams{}
+	if err := json.Unmarshal(req.Params(), &params); err != nil {
+		return reply(methods.Ctx, nil, fmt.Errorf("%s: %w", jsonrpc2.ErrParse, err))
+	}
+	if params.OrbID == "" {
+		return reply(methods.Ctx, nil, fmt.Errorf("%s: missing orb ID", jsonrpc2.ErrInvalidParams))
+	}
+
+	orb, err := parser.RefreshOrbInfo(params.OrbID, methods.Cache, methods.LsContext)
+
+	// The files are diagnosed again even when the orb could not be fetched,
+	// for them to report it
+	methods.diagnoseFilesReferencingOrb(params.OrbID)
+
+	if err != nil {
+		return reply(methods.Ctx, nil, err)
+	}
+	return reply(methods.Ctx, RefreshOrbResult{Version: orb.RemoteInfo.Version}, nil)
+}
+
+func (methods *Methods) diagnoseFilesReferencingOrb(orbID string) {
+	for fileURI, file := range methods.Cache.FileCache.GetFiles() {
+		doc, err := parser.ParseFromUriWithCache(fileURI, methods.Cache, methods.LsContext)
+		if err != nil {
+			continue
+		}
+
+		for _, orb := range doc.Orbs {
+			if orb.Url.GetOrbID() == orbID {
+				go methods.notificationMethods(file.TextDocument)
+				break
+			}
+		}
+	}
+}
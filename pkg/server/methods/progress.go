@@ -0,0 +1,113 @@
+package methods
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"go.lsp.dev/protocol"
+)
+
+// Reports progress with the work done progress of the protocol, for the
+// clients advertising the `window.workDoneProgress` capability
+type workDoneProgressReporter struct {
+	methods *Methods
+	tokens  atomic.Int64
+}
+
+func (methods *Methods) newProgressReporter() *workDoneProgressReporter {
+	return &workDoneProgressReporter{methods: methods}
+}
+
+// The token is created by a request to the client. The handlers run on the
+// goroutine reading the responses, waiting for the client would block them,
+// so the notifications are queued until the token is created
+type workDoneProgress struct {
+	methods *Methods
+	token   protocol.ProgressToken
+
+	mutex   sync.Mutex
+	created bool
+	failed  bool
+	queued  []interface{}
+}
+
+func (reporter *workDoneProgressReporter) Begin(title string) utils.Progress {
+	progress := &workDoneProgress{
+		methods: reporter.methods,
+		token:   *protocol.NewProgressToken(fmt.Sprintf("circleci-yaml-language-server/%d", reporter.tokens.Add(1))),
+	}
+
+	progress.send(protocol.WorkDoneProgressBegin{
+		Kind:  protocol.WorkDoneProgressKindBegin,
+		Title: title,
+	})
+
+	go progress.create()
+
+	return progress
+}
+
+func (progress *workDoneProgress) create() {
+	err := protocol.Call(
+		progress.methods.Ctx,
+		progress.methods.Conn,
+		protocol.MethodWorkDoneProgressCreate,
+		protocol.WorkDoneProgressCreateParams{Token: progress.token},
+		nil,
+	)
+
+	progress.mutex.Lock()
+	defer progress.mutex.Unlock()
+
+	// The client refused the token, progress is not reported
+	if err != nil {
+		progress.failed = true
+		progress.queued = nil
+		return
+	}
+
+	progress.created = true
+	for _, value := range progress.queued {
+		progress.notify(value)
+	}
+	progress.queued = nil
+}
+
+func (progress *workDoneProgress) Report(message string, percentage uint32) {
+	progress.send(protocol.WorkDoneProgressReport{
+		Kind:       protocol.WorkDoneProgressKindReport,
+		Message:    message,
+		Percentage: percentage,
+	})
+}
+
+func (progress *workDoneProgress) End(message string) {
+	progress.send(protocol.WorkDoneProgressEnd{
+		Kind:    protocol.WorkDoneProgressKindEnd,
+		Message: message,
+	})
+}
+
+func (progress *workDoneProgress) send(value interface{}) {
+	progress.mutex.Lock()
+	defer progress.mutex.Unlock()
+
+	switch {
+	case progress.failed:
+	case progress.created:
+		progress.notify(value)
+	default:
+		progress.queued = append(progress.queued, value)
+	}
+}
+
+// The lock must be held, to send the notifications in order
+func (progress *workDoneProgress) notify(value interface{}) {
+	progress.methods.Conn.Notify(
+		progress.methods.Ctx,
+		protocol.MethodProgress,
+		protocol.ProgressParams{Token: progress.token, Value: value},
+	)
+}
@@ -31,7 +31,7 @@ func getResourceClassOfOrg(textDocumentUri protocol.URI, context *utils.LsContex
 	projectSlug := utils.GetProjectSlug(textDocumentUri.Filename())
 	org := utils.GetProjectOrg(projectSlug)
 
-	if org == "" {
+	if org == "" || context.IsOffline() {
 		return []string{}
 	}
 
@@ -1,10 +1,7 @@
 package methods
 
 import (
-	"bytes"
 	"fmt"
-	"path"
-	"strings"
 	"time"
 
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
@@ -16,7 +13,7 @@ import (
 )
 
 func (methods *Methods) setChangeInFileCache(textDocument protocol.TextDocumentItem) {
-	if cachedFile := methods.Cache.FileCache.GetFile(textDocument.URI); cachedFile != nil {
+	if _, ok := methods.Cache.FileCache.TryGetFile(textDocument.URI); ok {
 		methods.Cache.FileCache.UpdateTextDocument(textDocument.URI, textDocument)
 	} else {
 		methods.Cache.FileCache.SetFile(utils.CachedFile{
@@ -69,14 +66,31 @@ func (methods *Methods) DidChange(reply jsonrpc2.Replier, req jsonrpc2.Request)
 	if err := json.Unmarshal(req.Params(), &params); err != nil {
 		return reply(methods.Ctx, nil, fmt.Errorf("%s: %w", jsonrpc2.ErrParse, err))
 	}
-	newText := methods.applyIncrementalChanges(params.TextDocument.URI, params.ContentChanges)
-	textDocument := protocol.TextDocumentItem{
-		URI:     params.TextDocument.URI,
-		Text:    newText,
-		Version: params.TextDocument.Version,
+
+	// Change events without range replace the whole document
+	ranges := struct {
+		ContentChanges []struct {
+			Range *protocol.Range `json:"range"`
+		} `json:"contentChanges"`
+	}{}
+	if err := json.Unmarshal(req.Params(), &ranges); err != nil {
+		return reply(methods.Ctx, nil, fmt.Errorf("%s: %w", jsonrpc2.ErrParse, err))
+	}
+	for i, change := range ranges.ContentChanges {
+		if change.Range == nil && i < len(params.ContentChanges) {
+			params.ContentChanges[i].Range = utils.FullDocumentRange
+		}
 	}
-	methods.setChangeInFileCache(textDocument)
-	methods.updateOrbFile([]byte(newText), params.TextDocument.URI)
+
+	textDocument, ok := methods.Cache.FileCache.ApplyChanges(
+		params.TextDocument.URI,
+		params.ContentChanges,
+		params.TextDocument.Version,
+	)
+	if !ok {
+		return reply(methods.Ctx, nil, nil)
+	}
+	methods.updateOrbFile([]byte(textDocument.Text), params.TextDocument.URI)
 
 	debounceInnerChange(func() {
 		methods.parsingMethods(textDocument)
@@ -103,6 +117,9 @@ func (methods *Methods) DidClose(reply jsonrpc2.Replier, req jsonrpc2.Request) e
 				Diagnostics: []protocol.Diagnostic{},
 			},
 		)
+	} else {
+		// The file is kept in the cache but its orbs are no longer in use
+		methods.Cache.FileCache.SetFileOrbs(params.TextDocument.URI, nil)
 	}
 
 	return reply(methods.Ctx, nil, nil)
@@ -114,7 +131,15 @@ func (methods *Methods) notificationMethods(textDocument protocol.TextDocumentIt
 		methods.getAllEnvVariables(textDocument)
 	}
 
-	diagnostics := methods.Diagnostics(textDocument)
+	// The client can open any YAML file, the ones that are not
+	// configurations are not diagnosed
+	diagnostics := protocol.PublishDiagnosticsParams{
+		URI:         textDocument.URI,
+		Diagnostics: []protocol.Diagnostic{},
+	}
+	if isOrb || utils.IsCircleCIConfig(textDocument.URI, textDocument.Text) {
+		diagnostics = methods.Diagnostics(textDocument)
+	}
 
 	original := methods.Cache.FileCache.GetFile(textDocument.URI)
 
@@ -146,24 +171,15 @@ func (methods *Methods) parsingMethods(textDocument protocol.TextDocumentItem) {
 		return
 	}
 
-	parser.ParseRemoteOrbs(parsedFile.Orbs, methods.Cache, methods.LsContext)
-}
-
-func (methods *Methods) applyIncrementalChanges(uri protocol.URI, changes []protocol.TextDocumentContentChangeEvent) string {
-	file := methods.Cache.FileCache.GetFile(uri)
-	content := []byte(file.TextDocument.Text)
-
-	for _, change := range changes {
-		start, end := utils.PosToIndex(change.Range.Start, content), utils.PosToIndex(change.Range.End, content)
-
-		var buf bytes.Buffer
-		buf.Write(content[:start])
-		buf.Write([]byte(change.Text))
-		buf.Write(content[end:])
-		content = buf.Bytes()
+	orbIDs := []string{}
+	for _, orb := range parsedFile.Orbs {
+		if !orb.Url.IsLocal {
+			orbIDs = append(orbIDs, orb.Url.GetOrbID())
+		}
 	}
+	methods.Cache.FileCache.SetFileOrbs(textDocument.URI, orbIDs)
 
-	return string(content)
+	parser.ParseRemoteOrbs(parsedFile.Orbs, methods.Cache, methods.LsContext)
 }
 
 func (methods *Methods) updateOrbFile(content []byte, uri protocol.URI) {
@@ -177,11 +193,10 @@ func (methods *Methods) updateOrbFile(content []byte, uri protocol.URI) {
 }
 
 func (methods *Methods) isOrb(uri protocol.URI) (bool, string) {
-	namespace := path.Base((path.Dir(uri.Filename())))
-	orb := path.Base(uri.Filename())
-	orbId := strings.TrimRight(path.Join(namespace, orb), ".yml")
-
-	isOrb := methods.Cache.OrbCache.HasOrb(orbId)
+	orbId, ok := utils.GetOrbIDFromCacheFSPath(uri.Filename())
+	if !ok {
+		return false, ""
+	}
 
-	return isOrb, orbId
+	return methods.Cache.OrbCache.HasOrb(orbId), orbId
 }
@@ -0,0 +1,44 @@
+package methods
+
+import (
+	"context"
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/testHelpers"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+func TestOpenCachedOrbFile(t *testing.T) {
+	methods := Methods{
+		Ctx:       context.Background(),
+		Cache:     utils.CreateCache(),
+		LsContext: testHelpers.GetDefaultLsContext(),
+	}
+	methods.Cache.OrbCache.SetOrb(&ast.OrbInfo{}, "circleci/node@5.1.0")
+
+	orbURI := uri.File(utils.GetOrbCacheFSPath("circleci/node@5.1.0"))
+	content := `version: 2.1
+
+commands:
+  install:
+    steps:
+      - run: npm install
+`
+	methods.setChangeInFileCache(protocol.TextDocumentItem{URI: orbURI, Text: content})
+	methods.updateOrbFile([]byte(content), orbURI)
+
+	isOrb, orbID := methods.isOrb(orbURI)
+	assert.True(t, isOrb)
+	assert.Equal(t, "circleci/node@5.1.0", orbID)
+	assert.Contains(t, methods.Cache.OrbCache.GetOrb("circleci/node@5.1.0").Commands, "install")
+	assert.False(t, methods.isConfigFile(orbURI))
+
+	// A config named like the orb is not its source
+	configURI := uri.File("/home/user/project/.circleci/circleci%2Fnode%405.1.0.yml")
+	isOrb, _ = methods.isOrb(configURI)
+	assert.False(t, isOrb)
+}
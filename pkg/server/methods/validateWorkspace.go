@@ -0,0 +1,77 @@
+package methods
+
+import (
+	"go.lsp.dev/jsonrpc2"
+	"go.lsp.dev/protocol"
+)
+
+// Custom request diagnosing every configuration file of the workspace at
+// once, for editor tasks and scripts that need a single answer
+const MethodValidateWorkspace = "circleci/validateWorkspace"
+
+type ValidateWorkspaceResult struct {
+	// Whether none of the files has an error, warnings do not fail the
+	// validation
+	Passed bool                                   `json:"passed"`
+	Files  map[protocol.URI][]protocol.Diagnostic `json:"files"`
+}
+
+func (methods *Methods) ValidateWorkspace(reply jsonrpc2.Replier, req jsonrpc2.Request) error {
+	result := ValidateWorkspaceResult{
+		Passed: true,
+		Files:  map[protocol.URI][]protocol.Diagnostic{},
+	}
+
+	for fileURI, file := range methods.Cache.FileCache.GetFiles() {
+		if !methods.isConfigFile(fileURI) {
+			continue
+		}
+
+		result.addFile(fileURI, methods.Diagnostics(file.TextDocument).Diagnostics)
+	}
+
+	return reply(methods.Ctx, result, nil)
+}
+
+// Diagnoses the given files the way the files opened by the client are, their
+// orbs being resolved first. Used by the command line to validate files
+// without an editor
+func (methods *Methods) ValidateFiles(textDocuments []protocol.TextDocumentItem) ValidateWorkspaceResult {
+	result := ValidateWorkspaceResult{
+		Passed: true,
+		Files:  map[protocol.URI][]protocol.Diagnostic{},
+	}
+
+	for _, textDocument := range textDocuments {
+		methods.setChangeInFileCache(textDocument)
+		methods.parsingMethods(textDocument)
+	}
+
+	for _, textDocument := range textDocuments {
+		result.addFile(textDocument.URI, methods.Diagnostics(textDocument).Diagnostics)
+	}
+
+	return result
+}
+
+func (result *ValidateWorkspaceResult) addFile(fileURI protocol.URI, diagnostics []protocol.Diagnostic) {
+	result.Files[fileURI] = diagnostics
+
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == protocol.DiagnosticSeverityError {
+			result.Passed = false
+			break
+		}
+	}
+}
+
+// The source of the remote orbs are cached as well, they are not part of the
+// workspace
+func (methods *Methods) isConfigFile(fileURI protocol.URI) bool {
+	if isOrb, _ := methods.isOrb(fileURI); isOrb {
+		return false
+	}
+
+	file := methods.Cache.FileCache.GetFile(fileURI)
+	return file != nil && file.IsCircleCIConfig()
+}
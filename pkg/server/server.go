@@ -11,7 +11,9 @@ import (
 	"go.lsp.dev/jsonrpc2"
 	"go.lsp.dev/protocol"
 
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
 	methods "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/server/methods"
+	languageservice "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/services"
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
 	"github.com/rollbar/rollbar-go"
 )
@@ -26,12 +28,13 @@ type JSONRPCServer struct {
 }
 
 func (server JSONRPCServer) commandHandler(_ context.Context, reply jsonrpc2.Replier, req jsonrpc2.Request) error {
-	fmt.Println("Called method: " + req.Method())
+	server.lsContext.GetLogger().Debug("Request received", "method", req.Method())
 
 	defer func() {
 		err := recover()
 
 		if err != nil {
+			server.lsContext.GetLogger().Error("Request failed", "method", req.Method(), "error", fmt.Sprint(err))
 			rollbar.LogPanic(err, true)
 			panic(err)
 		}
@@ -54,6 +57,9 @@ func (server JSONRPCServer) commandHandler(_ context.Context, reply jsonrpc2.Rep
 	case protocol.MethodTextDocumentDidChange:
 		return server.methods.DidChange(reply, req)
 
+	case protocol.MethodWorkspaceDidChangeConfiguration:
+		return server.methods.DidChangeConfiguration(reply, req)
+
 	case protocol.MethodTextDocumentHover:
 		return server.methods.Hover(reply, req)
 
@@ -66,6 +72,12 @@ func (server JSONRPCServer) commandHandler(_ context.Context, reply jsonrpc2.Rep
 	case protocol.MethodTextDocumentReferences:
 		return server.methods.References(reply, req)
 
+	case protocol.MethodTextDocumentPrepareRename:
+		return server.methods.PrepareRename(reply, req)
+
+	case protocol.MethodTextDocumentRename:
+		return server.methods.Rename(reply, req)
+
 	case protocol.MethodTextDocumentCompletion:
 		return server.methods.Complete(reply, req)
 
@@ -78,6 +90,42 @@ func (server JSONRPCServer) commandHandler(_ context.Context, reply jsonrpc2.Rep
 	case protocol.MethodTextDocumentDocumentSymbol:
 		return server.methods.DocumentSymbols(reply, req)
 
+	case protocol.MethodTextDocumentFoldingRange:
+		return server.methods.FoldingRange(reply, req)
+
+	case protocol.MethodTextDocumentSignatureHelp:
+		return server.methods.SignatureHelp(reply, req)
+
+	case protocol.MethodTextDocumentCodeLens:
+		return server.methods.CodeLens(reply, req)
+
+	case protocol.MethodCodeLensResolve:
+		return server.methods.ResolveCodeLens(reply, req)
+
+	case protocol.MethodTextDocumentDocumentLink:
+		return server.methods.DocumentLink(reply, req)
+
+	case protocol.MethodTextDocumentFormatting:
+		return server.methods.Formatting(reply, req)
+
+	case protocol.MethodTextDocumentRangeFormatting:
+		return server.methods.RangeFormatting(reply, req)
+
+	case languageservice.MethodTextDocumentInlayHint:
+		return server.methods.InlayHint(reply, req)
+
+	case methods.MethodDumpOrbCache:
+		return server.methods.DumpOrbCache(reply, req)
+
+	case methods.MethodRefreshOrb:
+		return server.methods.RefreshOrb(reply, req)
+
+	case methods.MethodValidateWorkspace:
+		return server.methods.ValidateWorkspace(reply, req)
+
+	case methods.MethodConfigJSONSchema:
+		return server.methods.ConfigJSONSchema(reply, req)
+
 	case protocol.MethodExit:
 		os.Exit(0)
 		return nil
@@ -89,16 +137,24 @@ func (server JSONRPCServer) commandHandler(_ context.Context, reply jsonrpc2.Rep
 
 func (server JSONRPCServer) ServeStream(_ context.Context, conn jsonrpc2.Conn) error {
 	defer rollbar.Close()
-	fmt.Println("New client connection")
+	logger := server.lsContext.GetLogger()
+	logger.Info("New client connection")
 
 	server.conn = conn
-	server.cache = utils.CreateCache()
+	orbCacheOption, warnings := getOrbCacheOption(utils.GetOrbCacheFSDir())
+	for _, warning := range warnings {
+		logger.Warn(warning)
+	}
+	server.cache = utils.CreateCache(orbCacheOption, utils.WithLogger(logger), utils.WithOrbSweeper())
+	defer server.cache.OrbCache.StopSweeper()
+	parser.LoadPersistedOrbs(server.cache, server.lsContext)
 	server.methods = methods.Methods{
-		Ctx:            server.ctx,
-		Conn:           server.conn,
-		Cache:          server.cache,
-		LsContext:      server.lsContext,
-		SchemaLocation: server.SchemaLocation,
+		Ctx:             server.ctx,
+		Conn:            server.conn,
+		Cache:           server.cache,
+		LsContext:       server.lsContext,
+		SchemaLocation:  server.SchemaLocation,
+		StartupWarnings: warnings,
 	}
 	conn.Go(server.ctx, server.commandHandler)
 	<-conn.Done()
@@ -106,10 +162,24 @@ func (server JSONRPCServer) ServeStream(_ context.Context, conn jsonrpc2.Conn) e
 	return conn.Err()
 }
 
+// Orbs are persisted in the orb cache directory, or only kept in memory when
+// the directory is not writable, such as in read-only containers
+func getOrbCacheOption(dir string) (utils.CacheOption, []string) {
+	if err := utils.CheckDirWritable(dir); err != nil {
+		warning := fmt.Sprintf("The orb cache directory %s is not writable, orbs are only cached in memory: %s", dir, err)
+		return utils.WithInMemoryOrbs(), []string{warning}
+	}
+
+	return utils.WithOrbPersistenceDir(dir), nil
+}
+
 func StartServer(port int, host string, schemaLocation string) {
 	ctx := context.Background()
 	server := getJsonRpcServer(ctx, schemaLocation)
 
+	// Over TCP, the standard output is free for the logs
+	server.lsContext.Logger.SetSink("stdout", utils.NewWriterLogSink(os.Stdout))
+
 	if port == -1 {
 		port = 0
 	}
@@ -172,6 +242,7 @@ func getJsonRpcServer(ctx context.Context, schemaLocation string) JSONRPCServer
 				Token:   "",
 			},
 			IsCciExtension: false,
+			Logger:         utils.NewLogger(utils.DefaultLogLevel),
 		},
 		SchemaLocation: schemaLocation,
 	}
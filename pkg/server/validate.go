@@ -0,0 +1,62 @@
+package languageserver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
+	methods "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/server/methods"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+)
+
+// Validates configuration files outside of an editor, with the validation of
+// the files opened by the clients so that both report the same diagnostics.
+// Offline, the network is never reached and only the orbs persisted by the
+// previous sessions are resolved. The warnings are written to the given
+// writer, keeping the output of the validation apart
+func ValidateFiles(paths []string, schemaLocation string, offline bool, warnings io.Writer) (methods.ValidateWorkspaceResult, error) {
+	textDocuments := []protocol.TextDocumentItem{}
+	for _, path := range paths {
+		absolutePath, err := filepath.Abs(path)
+		if err != nil {
+			return methods.ValidateWorkspaceResult{}, err
+		}
+
+		content, err := os.ReadFile(absolutePath)
+		if err != nil {
+			return methods.ValidateWorkspaceResult{}, err
+		}
+
+		textDocuments = append(textDocuments, protocol.TextDocumentItem{
+			URI:        uri.File(absolutePath),
+			LanguageID: "yaml",
+			Text:       string(content),
+		})
+	}
+
+	lsContext := getJsonRpcServer(context.Background(), schemaLocation).lsContext
+	lsContext.SetOffline(offline)
+
+	orbCacheOption, cacheWarnings := getOrbCacheOption(utils.GetOrbCacheFSDir())
+	for _, warning := range cacheWarnings {
+		fmt.Fprintln(warnings, warning)
+	}
+
+	cache := utils.CreateCache(orbCacheOption)
+	parser.LoadPersistedOrbs(cache, lsContext)
+
+	validator := methods.Methods{
+		Ctx:            context.Background(),
+		Cache:          cache,
+		LsContext:      lsContext,
+		SchemaLocation: schemaLocation,
+	}
+
+	return validator.ValidateFiles(textDocuments), nil
+}
@@ -0,0 +1,253 @@
+package languageservice
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
+	yamlparser "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
+	utils "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"go.lsp.dev/protocol"
+)
+
+const extractedCommandName = "new-command"
+
+// Client command moving the cursor to the given document and position, then
+// starting a rename there
+const RenameAtPositionCommand = "circleci-language-server.renameAtPosition"
+
+var parameterInterpolation = regexp.MustCompile(`<<\s*parameters\.([A-Za-z0-9_-]+)\s*>>`)
+
+// Code actions that are not attached to diagnostics at validation time,
+// depending on the selection, or needing to reach the API
+func CodeActions(params protocol.CodeActionParams, cache *utils.Cache, context *utils.LsContext) ([]protocol.CodeAction, error) {
+	doc, err := yamlparser.ParseFromUriWithCache(params.TextDocument.URI, cache, context)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []protocol.CodeAction{}
+	for _, diagnostic := range params.Context.Diagnostics {
+		res = append(res, addMissingOrbActions(doc, diagnostic, cache, context)...)
+	}
+
+	res = append(res, upgradeOrbActions(doc, params.Range, cache, context)...)
+	res = append(res, pinOrbActions(doc, params.Range, cache)...)
+
+	if action, ok := extractStepsToCommand(doc, params.Range); ok {
+		res = append(res, action)
+	}
+
+	return res, nil
+}
+
+// Move the steps of a job covered by a multi-line selection to a new command
+// and call it instead. The command gets a placeholder name, which the client
+// starts renaming once the edits are applied
+func extractStepsToCommand(doc yamlparser.YamlDocument, selection protocol.Range) (protocol.CodeAction, bool) {
+	startLine, endLine := selection.Start.Line, selection.End.Line
+	if selection.End.Character == 0 && endLine > startLine {
+		endLine--
+	}
+	if startLine == endLine {
+		return protocol.CodeAction{}, false
+	}
+
+	job, steps := getSelectedJobSteps(doc, startLine, endLine)
+	if len(steps) == 0 {
+		return protocol.CodeAction{}, false
+	}
+
+	lines := strings.Split(string(doc.Content), "\n")
+	firstLine := steps[0].GetRange().Start.Line
+	lastLine := getStepLastLine(steps[len(steps)-1])
+	if int(lastLine) >= len(lines) {
+		return protocol.CodeAction{}, false
+	}
+
+	indent := getIndentationUnit(job)
+	stepsIndent := strings.Repeat(" ", getIndentation(lines[firstLine]))
+	name := getAvailableCommandName(doc)
+	paramNames := getInterpolatedParameters(lines[firstLine : lastLine+1])
+
+	// Command definition
+	command := []string{
+		strings.Repeat(indent, 1) + name + ":",
+	}
+	if len(paramNames) > 0 {
+		command = append(command, strings.Repeat(indent, 2)+"parameters:")
+		for _, paramName := range paramNames {
+			command = append(command, strings.Repeat(indent, 3)+paramName+":")
+			command = append(command, getParameterDefinition(job.Parameters[paramName], strings.Repeat(indent, 4))...)
+		}
+	}
+	command = append(command, strings.Repeat(indent, 2)+"steps:")
+	for _, line := range lines[firstLine : lastLine+1] {
+		command = append(command, reindentLine(line, stepsIndent, strings.Repeat(indent, 3)))
+	}
+
+	// Command call
+	call := []string{stepsIndent + "- " + name}
+	if len(paramNames) > 0 {
+		call[0] += ":"
+		for _, paramName := range paramNames {
+			call = append(call, fmt.Sprintf("%s  %s%s: << parameters.%s >>", stepsIndent, indent, paramName, paramName))
+		}
+	}
+
+	insertion := getCommandInsertionEdit(doc, lines, command)
+	edits := []protocol.TextEdit{
+		{
+			Range: protocol.Range{
+				Start: protocol.Position{Line: firstLine, Character: 0},
+				End:   protocol.Position{Line: lastLine, Character: uint32(len(lines[lastLine]))},
+			},
+			NewText: strings.Join(call, "\n"),
+		},
+		insertion,
+	}
+
+	// The name is on the line following the insertion point, which moves when
+	// the call replacing the steps comes first
+	nameLine := int(insertion.Range.Start.Line) + 1
+	if lastLine < insertion.Range.Start.Line {
+		nameLine += len(call) - int(lastLine-firstLine+1)
+	}
+	namePosition := protocol.Position{Line: uint32(nameLine), Character: uint32(len(indent))}
+
+	return protocol.CodeAction{
+		Title: "Extract steps to a new command",
+		Kind:  protocol.RefactorExtract,
+		Edit: &protocol.WorkspaceEdit{
+			Changes: map[protocol.DocumentURI][]protocol.TextEdit{
+				doc.URI: edits,
+			},
+		},
+		Command: &protocol.Command{
+			Title:     "Rename the extracted command",
+			Command:   RenameAtPositionCommand,
+			Arguments: []interface{}{doc.URI, namePosition},
+		},
+	}, true
+}
+
+// Returns the job whose steps are in the selection, along with the steps
+// starting within the selection
+func getSelectedJobSteps(doc yamlparser.YamlDocument, startLine uint32, endLine uint32) (ast.Job, []ast.Step) {
+	for _, job := range doc.Jobs {
+		if !utils.PosInRange(job.StepsRange, protocol.Position{Line: startLine}) &&
+			!utils.PosInRange(job.StepsRange, protocol.Position{Line: endLine}) {
+			continue
+		}
+
+		steps := []ast.Step{}
+		for _, step := range job.Steps {
+			rng := step.GetRange()
+			// Steps coming from an alias are defined elsewhere
+			if !utils.PosInRange(job.StepsRange, rng.Start) {
+				continue
+			}
+			if rng.Start.Line >= startLine && rng.Start.Line <= endLine {
+				steps = append(steps, step)
+			}
+		}
+
+		return job, steps
+	}
+
+	return ast.Job{}, []ast.Step{}
+}
+
+func getStepLastLine(step ast.Step) uint32 {
+	rng := step.GetRange()
+	if rng.End.Character == 0 && rng.End.Line > rng.Start.Line {
+		return rng.End.Line - 1
+	}
+	return rng.End.Line
+}
+
+// Jobs are one level below the `jobs` key, their indentation is the one of
+// the document
+func getIndentationUnit(job ast.Job) string {
+	if job.NameRange.Start.Character == 0 {
+		return "  "
+	}
+	return strings.Repeat(" ", int(job.NameRange.Start.Character))
+}
+
+func reindentLine(line string, from string, to string) string {
+	if strings.TrimSpace(line) == "" {
+		return ""
+	}
+	return to + strings.TrimPrefix(line, from)
+}
+
+func getAvailableCommandName(doc yamlparser.YamlDocument) string {
+	name := extractedCommandName
+	for i := 1; ; i++ {
+		_, isCommand := doc.Commands[name]
+		_, isJob := doc.Jobs[name]
+		if !isCommand && !isJob {
+			return name
+		}
+		name = fmt.Sprintf("%s-%d", extractedCommandName, i)
+	}
+}
+
+func getInterpolatedParameters(lines []string) []string {
+	names := []string{}
+	for _, match := range parameterInterpolation.FindAllStringSubmatch(strings.Join(lines, "\n"), -1) {
+		if utils.FindInArray(names, match[1]) == -1 {
+			names = append(names, match[1])
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// The parameters of the command have the same type as the job's ones they are
+// taking the value of
+func getParameterDefinition(param ast.Parameter, indent string) []string {
+	if param == nil {
+		return []string{indent + "type: string"}
+	}
+
+	definition := []string{indent + "type: " + param.GetType()}
+	if enum, ok := param.(ast.EnumParameter); ok {
+		definition = append(definition, indent+"enum: ["+strings.Join(enum.Enum, ", ")+"]")
+	}
+	return definition
+}
+
+func getCommandInsertionEdit(doc yamlparser.YamlDocument, lines []string, command []string) protocol.TextEdit {
+	if !utils.IsDefaultRange(doc.CommandsRange) {
+		// The range of a section ending the document spans its trailing
+		// blank lines
+		end := doc.CommandsRange.End
+		for end.Line > 0 && int(end.Line) < len(lines) && int(end.Character) <= len(lines[end.Line]) && strings.TrimSpace(lines[end.Line][:end.Character]) == "" {
+			end.Line--
+			end.Character = uint32(len(lines[end.Line]))
+		}
+
+		return protocol.TextEdit{
+			Range:   protocol.Range{Start: end, End: end},
+			NewText: "\n" + strings.Join(command, "\n"),
+		}
+	}
+
+	// No `commands` section yet: add one right before the jobs
+	line := doc.JobsRange.Start.Line
+	for line > 0 && !strings.HasPrefix(lines[line], "jobs:") {
+		line--
+	}
+
+	return protocol.TextEdit{
+		Range: protocol.Range{
+			Start: protocol.Position{Line: line, Character: 0},
+			End:   protocol.Position{Line: line, Character: 0},
+		},
+		NewText: "commands:\n" + strings.Join(command, "\n") + "\n\n",
+	}
+}
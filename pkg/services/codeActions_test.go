@@ -0,0 +1,296 @@
+package languageservice
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/testHelpers"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+func TestExtractStepsToCommand(t *testing.T) {
+	fileURI := uri.File("/tmp/extract.yml")
+
+	// Returns the edited content along with the name the rename starts at
+	applyExtraction := func(t *testing.T, content string, selection protocol.Range) (string, string, bool) {
+		cache := utils.CreateCache()
+		cache.FileCache.SetFile(utils.CachedFile{
+			TextDocument: protocol.TextDocumentItem{URI: fileURI, Text: content},
+		})
+
+		actions, err := CodeActions(protocol.CodeActionParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: fileURI},
+			Range:        selection,
+		}, cache, testHelpers.GetDefaultLsContext())
+		assert.Nil(t, err)
+
+		if len(actions) == 0 {
+			return "", "", false
+		}
+		assert.Equal(t, protocol.RefactorExtract, actions[0].Kind)
+		res := applyTextEdits(content, actions[0].Edit.Changes[fileURI])
+
+		command := actions[0].Command
+		assert.Equal(t, RenameAtPositionCommand, command.Command)
+		assert.Equal(t, fileURI, command.Arguments[0])
+		position := command.Arguments[1].(protocol.Position)
+		line := strings.Split(res, "\n")[position.Line]
+		renamed := strings.TrimSuffix(line[position.Character:], ":")
+
+		return res, renamed, true
+	}
+
+	selection := func(startLine, endLine uint32) protocol.Range {
+		return protocol.Range{
+			Start: protocol.Position{Line: startLine, Character: 0},
+			End:   protocol.Position{Line: endLine, Character: 0},
+		}
+	}
+
+	t.Run("Should create the commands section and promote parameters", func(t *testing.T) {
+		content := `version: 2.1
+
+jobs:
+  build:
+    parameters:
+      version:
+        type: string
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - checkout
+      - run:
+          name: Install
+          command: make install VERSION=<< parameters.version >>
+      - run: make test
+`
+
+		res, renamed, ok := applyExtraction(t, content, selection(11, 15))
+		assert.True(t, ok)
+		assert.Equal(t, "new-command", renamed)
+		assert.Equal(t, `version: 2.1
+
+commands:
+  new-command:
+    parameters:
+      version:
+        type: string
+    steps:
+      - run:
+          name: Install
+          command: make install VERSION=<< parameters.version >>
+      - run: make test
+
+jobs:
+  build:
+    parameters:
+      version:
+        type: string
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - checkout
+      - new-command:
+          version: << parameters.version >>
+`, res)
+	})
+
+	t.Run("Should append to the existing commands", func(t *testing.T) {
+		content := `version: 2.1
+
+commands:
+  new-command:
+    steps:
+      - checkout
+
+jobs:
+  build:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - new-command
+      - run: make
+      - run: make test
+`
+
+		res, renamed, ok := applyExtraction(t, content, selection(13, 15))
+		assert.True(t, ok)
+		assert.Equal(t, "new-command-1", renamed)
+		assert.Equal(t, `version: 2.1
+
+commands:
+  new-command:
+    steps:
+      - checkout
+  new-command-1:
+    steps:
+      - run: make
+      - run: make test
+
+jobs:
+  build:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - new-command
+      - new-command-1
+`, res)
+	})
+
+	t.Run("Should start the rename after the steps when the commands come last", func(t *testing.T) {
+		content := `version: 2.1
+
+jobs:
+  build:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - checkout
+      - run: make
+      - run: make test
+
+commands:
+  setup:
+    steps:
+      - checkout
+`
+
+		res, renamed, ok := applyExtraction(t, content, selection(8, 10))
+		assert.True(t, ok)
+		assert.Equal(t, "new-command", renamed)
+		assert.Equal(t, `version: 2.1
+
+jobs:
+  build:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - checkout
+      - new-command
+
+commands:
+  setup:
+    steps:
+      - checkout
+  new-command:
+    steps:
+      - run: make
+      - run: make test
+`, res)
+	})
+
+	t.Run("Should not be available outside of steps or on a single line", func(t *testing.T) {
+		content := `version: 2.1
+
+jobs:
+  build:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - run: make
+      - run: make test
+`
+
+		_, _, ok := applyExtraction(t, content, selection(3, 5))
+		assert.False(t, ok)
+
+		_, _, ok = applyExtraction(t, content, protocol.Range{
+			Start: protocol.Position{Line: 7, Character: 0},
+			End:   protocol.Position{Line: 7, Character: 10},
+		})
+		assert.False(t, ok)
+	})
+}
+
+// Edits are applied from the end of the document so that their ranges stay
+// valid
+func TestAddMissingOrb(t *testing.T) {
+	fileURI := uri.File("/tmp/missingOrb.yml")
+
+	// The diagnostic is on the first reference to an orb
+	getActions := func(t *testing.T, content string, rule string) []protocol.CodeAction {
+		cache := utils.CreateCache()
+		cache.FileCache.SetFile(utils.CachedFile{
+			TextDocument: protocol.TextDocumentItem{URI: fileURI, Text: content},
+		})
+		cache.OrbCache.SetOrb(&ast.OrbInfo{
+			OrbParsedAttributes: ast.OrbParsedAttributes{
+				Commands: map[string]ast.Command{"install": {Name: "install"}},
+			},
+			RemoteInfo: ast.RemoteOrbInfo{Version: "5.1.0"},
+		}, "circleci/node@volatile")
+
+		pos := utils.IndexToPos(strings.Index(content, "node/"), []byte(content))
+		actions, err := CodeActions(protocol.CodeActionParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: fileURI},
+			Context: protocol.CodeActionContext{
+				Diagnostics: []protocol.Diagnostic{{Range: protocol.Range{Start: pos, End: pos}, Code: rule}},
+			},
+		}, cache, testHelpers.GetDefaultLsContext())
+		assert.Nil(t, err)
+		return actions
+	}
+
+	jobs := `jobs:
+  build:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - node/install
+`
+
+	t.Run("Should create the orbs section with the latest and the volatile versions", func(t *testing.T) {
+		content := "version: 2.1\n\n" + jobs
+		actions := getActions(t, content, utils.RuleOrbNotDeclared)
+
+		assert.Len(t, actions, 2)
+		assert.Equal(t, "Import orb circleci/node@5.1.0", actions[0].Title)
+		assert.Equal(t, protocol.QuickFix, actions[0].Kind)
+		assert.True(t, actions[0].IsPreferred)
+		assert.Equal(t, "version: 2.1\n\norbs:\n  node: circleci/node@5.1.0\n\n"+jobs, applyTextEdits(content, actions[0].Edit.Changes[fileURI]))
+
+		assert.Equal(t, "Import orb circleci/node@volatile", actions[1].Title)
+		assert.False(t, actions[1].IsPreferred)
+	})
+
+	t.Run("Should append to the existing orbs", func(t *testing.T) {
+		content := "version: 2.1\n\norbs:\n    slack: circleci/slack@4.12.5\n\n" + jobs
+		actions := getActions(t, content, utils.RuleOrbNotDeclared)
+
+		assert.Len(t, actions, 2)
+		assert.Equal(t,
+			"version: 2.1\n\norbs:\n    slack: circleci/slack@4.12.5\n    node: circleci/node@5.1.0\n\n"+jobs,
+			applyTextEdits(content, actions[0].Edit.Changes[fileURI]))
+	})
+
+	t.Run("Should not import orbs without the referenced command", func(t *testing.T) {
+		content := "version: 2.1\n\n" + strings.Replace(jobs, "node/install", "node/unknown", 1)
+		assert.Empty(t, getActions(t, content, utils.RuleOrbNotDeclared))
+	})
+
+	t.Run("Should only fix undeclared orbs", func(t *testing.T) {
+		content := "version: 2.1\n\n" + jobs
+		assert.Empty(t, getActions(t, content, utils.RuleUnknownStep))
+	})
+}
+
+func applyTextEdits(content string, edits []protocol.TextEdit) string {
+	sorted := append([]protocol.TextEdit{}, edits...)
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i].Range.Start, sorted[j].Range.Start
+		return a.Line > b.Line || (a.Line == b.Line && a.Character > b.Character)
+	})
+
+	res := []byte(content)
+	for _, edit := range sorted {
+		start := utils.PosToIndex(edit.Range.Start, res)
+		end := utils.PosToIndex(edit.Range.End, res)
+		res = append(res[:start], append([]byte(edit.NewText), res[end:]...)...)
+	}
+	return string(res)
+}
@@ -0,0 +1,101 @@
+package languageservice
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
+	yamlparser "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
+	utils "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"go.lsp.dev/protocol"
+)
+
+// Command run by the editor, as the server can not open pages by itself. Its
+// arguments are the URL of the orb on the registry, empty for self-hosted
+// instances, and the path of its cached source
+const OpenOrbSourceCommand = "openOrbSource"
+
+// Adds a lens above each orb imported from the registry. The ones of orbs not
+// cached yet are left unresolved, their orb being fetched on resolve
+func CodeLenses(params protocol.CodeLensParams, cache *utils.Cache, context *utils.LsContext) ([]protocol.CodeLens, error) {
+	doc, err := yamlparser.ParseFromUriWithCache(params.TextDocument.URI, cache, context)
+	if err != nil {
+		return nil, err
+	}
+
+	orbNames := []string{}
+	for name, orb := range doc.Orbs {
+		if !orb.Url.IsLocal {
+			orbNames = append(orbNames, name)
+		}
+	}
+	sort.Slice(orbNames, func(i, j int) bool {
+		return doc.Orbs[orbNames[i]].NameRange.Start.Line < doc.Orbs[orbNames[j]].NameRange.Start.Line
+	})
+
+	lenses := []protocol.CodeLens{}
+	for _, name := range orbNames {
+		orb := doc.Orbs[name]
+		lens := protocol.CodeLens{
+			Range: orb.NameRange,
+			Data:  orb.Url.GetOrbID(),
+		}
+
+		if orbInfo := cache.OrbCache.GetOrb(orb.Url.GetOrbID()); orbInfo != nil {
+			lens.Command = openOrbSourceCommand(orb.Url, orbInfo, context)
+		}
+
+		lenses = append(lenses, lens)
+	}
+
+	return lenses, nil
+}
+
+func ResolveCodeLens(lens protocol.CodeLens, cache *utils.Cache, context *utils.LsContext) (protocol.CodeLens, error) {
+	orbID, ok := lens.Data.(string)
+	if !ok || lens.Command != nil {
+		return lens, nil
+	}
+
+	orbInfo, err := yamlparser.GetOrbInfo(orbID, cache, context)
+	if err != nil {
+		return lens, err
+	}
+
+	orbName, version, _ := strings.Cut(orbID, "@")
+	lens.Command = openOrbSourceCommand(ast.OrbURL{Name: orbName, Version: version}, orbInfo, context)
+
+	return lens, nil
+}
+
+// The title shows the version the reference resolved to, such as 5.1.0 for
+// circleci/node@5
+func openOrbSourceCommand(orbURL ast.OrbURL, orbInfo *ast.OrbInfo, context *utils.LsContext) *protocol.Command {
+	version := orbInfo.RemoteInfo.Version
+	if version == "" {
+		version = orbURL.Version
+	}
+
+	return &protocol.Command{
+		Title:     fmt.Sprintf("Open %s source", ast.FormatOrbID(orbURL.Name, version)),
+		Command:   OpenOrbSourceCommand,
+		Arguments: []interface{}{getOrbRegistryURL(orbURL.Name, version, context), orbInfo.RemoteInfo.FilePath},
+	}
+}
+
+// The registry is only available on circleci.com, the URL is empty for
+// self-hosted instances
+func getOrbRegistryURL(orbName string, version string, context *utils.LsContext) string {
+	if !context.Api.UseDefaultInstance() {
+		return ""
+	}
+
+	return fmt.Sprintf(
+		"%s/developer/orbs/orb/%s?version=%s",
+		utils.CIRCLE_CI_APP_HOST_URL,
+		orbName,
+		url.QueryEscape(version),
+	)
+}
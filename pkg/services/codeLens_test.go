@@ -0,0 +1,99 @@
+package languageservice
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/testHelpers"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+const codeLensYaml = `version: 2.1
+
+orbs:
+  node: circleci/node@5
+  slack: circleci/slack@4.12.0
+  local:
+    commands:
+      hello:
+        steps:
+          - run: echo hello
+`
+
+func TestCodeLenses(t *testing.T) {
+	cache := utils.CreateCache()
+	fileURI := uri.File("/tmp/codeLens.yml")
+	cache.FileCache.SetFile(utils.CachedFile{
+		TextDocument: protocol.TextDocumentItem{URI: fileURI, Text: codeLensYaml},
+	})
+	cache.OrbCache.SetOrb(&ast.OrbInfo{
+		RemoteInfo: ast.RemoteOrbInfo{
+			ID:       "node-id",
+			FilePath: "/tmp/orbs/circleci/node@5.1.0.yml",
+			Version:  "5.1.0",
+		},
+	}, "circleci/node@5")
+
+	context := testHelpers.GetDefaultLsContext()
+	lenses, err := CodeLenses(protocol.CodeLensParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: fileURI},
+	}, cache, context)
+	assert.Nil(t, err)
+
+	assert.Equal(t, []protocol.CodeLens{
+		{
+			Range: protocol.Range{
+				Start: protocol.Position{Line: 3, Character: 2},
+				End:   protocol.Position{Line: 3, Character: 6},
+			},
+			Command: &protocol.Command{
+				Title:   "Open circleci/node@5.1.0 source",
+				Command: OpenOrbSourceCommand,
+				Arguments: []interface{}{
+					"https://circleci.com/developer/orbs/orb/circleci/node?version=5.1.0",
+					"/tmp/orbs/circleci/node@5.1.0.yml",
+				},
+			},
+			Data: "circleci/node@5",
+		},
+		{
+			Range: protocol.Range{
+				Start: protocol.Position{Line: 4, Character: 2},
+				End:   protocol.Position{Line: 4, Character: 7},
+			},
+			Data: "circleci/slack@4.12.0",
+		},
+	}, lenses)
+
+	t.Run("Should resolve the lens of an orb once it is cached", func(t *testing.T) {
+		cache.OrbCache.SetOrb(&ast.OrbInfo{
+			RemoteInfo: ast.RemoteOrbInfo{
+				FilePath: "/tmp/orbs/circleci/slack@4.12.0.yml",
+				Version:  "4.12.0",
+			},
+		}, "circleci/slack@4.12.0")
+
+		lens, err := ResolveCodeLens(lenses[1], cache, context)
+		assert.Nil(t, err)
+		assert.Equal(t, &protocol.Command{
+			Title:   "Open circleci/slack@4.12.0 source",
+			Command: OpenOrbSourceCommand,
+			Arguments: []interface{}{
+				"https://circleci.com/developer/orbs/orb/circleci/slack?version=4.12.0",
+				"/tmp/orbs/circleci/slack@4.12.0.yml",
+			},
+		}, lens.Command)
+	})
+
+	t.Run("Should not link to the registry for self-hosted instances", func(t *testing.T) {
+		selfHosted := testHelpers.GetDefaultLsContext()
+		selfHosted.Api.HostUrl = "https://circleci.example.com"
+
+		lens, err := ResolveCodeLens(protocol.CodeLens{Data: "circleci/node@5"}, cache, selfHosted)
+		assert.Nil(t, err)
+		assert.Equal(t, []interface{}{"", "/tmp/orbs/circleci/node@5.1.0.yml"}, lens.Command.Arguments)
+	})
+}
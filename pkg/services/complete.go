@@ -15,7 +15,10 @@ func Complete(params protocol.CompletionParams, cache *utils.Cache, context *uti
 		return protocol.CompletionList{}, err
 	}
 
-	if yamlDocument.Version < 2.1 {
+	// The version of a document being edited may not be parsed, such as
+	// while a key is typed, the completion then relies on the documents
+	// modified to be valid
+	if yamlDocument.Version < 2.1 && !yamlDocument.RootNode.HasError() {
 		return protocol.CompletionList{
 			IsIncomplete: true,
 			Items:        []protocol.CompletionItem{},
@@ -6,7 +6,6 @@ import (
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
 	yamlparser "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
-	sitter "github.com/smacker/go-tree-sitter"
 	"go.lsp.dev/protocol"
 )
 
@@ -21,11 +20,7 @@ func (ch *CompletionHandler) completeCommands() {
 		ch.addParametersDefinitionCompletion(command.Parameters)
 		return
 	case utils.PosInRange(command.StepsRange, ch.Params.Position):
-		nodeToComplete, _, _ := utils.NodeAtPos(ch.Doc.RootNode, ch.Params.Position)
-		if nodeToComplete.Type() == ":" {
-			nodeToComplete = nodeToComplete.PrevSibling()
-		}
-		ch.completeSteps(command.Name, false, true, nodeToComplete)
+		ch.completeSteps(command.Name, false, true)
 		return
 	}
 
@@ -46,26 +41,20 @@ func findCommand(pos protocol.Position, doc yamlparser.YamlDocument) (ast.Comman
 	return ast.Command{}, fmt.Errorf("no command found")
 }
 
-func (ch *CompletionHandler) userDefinedCommands() {
+func (ch *CompletionHandler) userDefinedCommands(prefix string, atListItem bool) {
 	for _, cmd := range ch.Doc.Commands {
-		ch.addCompletionItem(cmd.Name)
+		ch.addStepCompletionItem(cmd.Name, cmd.Description, getCommandSnippet(cmd.Name, cmd.Parameters), protocol.CompletionItemKindFunction, prefix, atListItem)
 	}
 }
 
-func (ch *CompletionHandler) orbCommands(nodeToComplete *sitter.Node) []protocol.CompletionItem {
+func (ch *CompletionHandler) orbCommands(prefix string, atListItem bool) {
 	for _, orb := range ch.Doc.Orbs {
 		orbInfo := ch.GetOrbInfo(orb)
 		if orbInfo != nil {
-			for cmdName := range orbInfo.Commands {
-				cmdName = fmt.Sprintf("%s/%s", orb.Name, cmdName)
-
-				if nodeToComplete == nil {
-					ch.addCompletionItem(cmdName)
-				} else {
-					ch.addReplaceTextCompletionItem(nodeToComplete, cmdName)
-				}
+			for name, cmd := range orbInfo.Commands {
+				cmdName := fmt.Sprintf("%s/%s", orb.Name, name)
+				ch.addStepCompletionItem(cmdName, cmd.Description, getCommandSnippet(cmdName, cmd.Parameters), protocol.CompletionItemKindFunction, prefix, atListItem)
 			}
 		}
 	}
-	return ch.Items
 }
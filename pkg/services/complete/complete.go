@@ -23,6 +23,14 @@ type CompletionHandler struct {
 }
 
 func (ch *CompletionHandler) GetCompletionItems() {
+	if ch.addPipelineValuesCompletion() {
+		return
+	}
+
+	if ch.addMatrixParametersCompletion() {
+		return
+	}
+
 	node, _, err := utils.NodeAtPos(ch.Doc.RootNode, ch.Params.Position)
 	if err == nil {
 		ch.addParameterReferenceCompletion(node)
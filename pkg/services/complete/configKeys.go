@@ -0,0 +1,143 @@
+package complete
+
+import (
+	yamlparser "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	sitter "github.com/smacker/go-tree-sitter"
+	"go.lsp.dev/protocol"
+)
+
+// Completes the keys valid in the map the cursor is in, as described by the
+// configuration schema, except the ones already there. Returns whether the
+// cursor is at the position of a key of a known map
+func (ch *CompletionHandler) addConfigKeysCompletion() bool {
+	path, present, ok := ch.getKeyPathAtPos()
+	if !ok {
+		return false
+	}
+
+	key := utils.GetConfigKeyAtPath(path)
+	if key == nil || len(key.Keys) == 0 {
+		return false
+	}
+
+	for _, child := range key.Keys {
+		if present[child.Name] || child.ConflictsWith(present) {
+			continue
+		}
+
+		ch.Items = append(ch.Items, protocol.CompletionItem{
+			Label:            child.Name,
+			Kind:             protocol.CompletionItemKindProperty,
+			Documentation:    child.Description,
+			InsertText:       child.GetSnippet(),
+			InsertTextFormat: protocol.InsertTextFormatSnippet,
+		})
+	}
+
+	return true
+}
+
+// Path from the root of the document to the map in which a key would be
+// written at the cursor, along with the keys already in that map. The items
+// of the lists are designated by "-"
+func (ch *CompletionHandler) getKeyPathAtPos() ([]string, map[string]bool, bool) {
+	mapping := yamlparser.GetBlockMappingNode(ch.Doc.RootNode)
+	if mapping == nil {
+		return nil, nil, false
+	}
+
+	return ch.getKeyPathInMapping(mapping, []string{})
+}
+
+func (ch *CompletionHandler) getKeyPathInMapping(mapping *sitter.Node, path []string) ([]string, map[string]bool, bool) {
+	pos := ch.Params.Position
+	present := map[string]bool{}
+	typing := false
+	var target, previous *sitter.Node
+
+	for i := 0; i < int(mapping.NamedChildCount()); i++ {
+		pair := mapping.NamedChild(i)
+		if pair.Type() != "block_mapping_pair" {
+			continue
+		}
+
+		keyNode, _ := ch.Doc.GetKeyValueNodes(pair)
+		if keyNode == nil {
+			continue
+		}
+
+		// The key being written
+		if utils.PosInRange(ch.Doc.NodeToRange(keyNode), pos) {
+			typing = true
+			continue
+		}
+		present[ch.Doc.GetNodeText(keyNode)] = true
+
+		switch {
+		case pair.StartPoint().Row > pos.Line:
+		case pos.Character <= keyNode.StartPoint().Column:
+			previous = nil
+		case pos.Line <= pair.EndPoint().Row:
+			target = pair
+		default:
+			previous = pair
+		}
+	}
+
+	if typing {
+		return path, present, true
+	}
+
+	// Lines following a key belong to its value when they are more indented
+	if target == nil {
+		target = previous
+	}
+	if target == nil {
+		return path, present, true
+	}
+
+	keyNode, valueNode := ch.Doc.GetKeyValueNodes(target)
+	path = append(path, ch.Doc.GetNodeText(keyNode))
+
+	if valueNode == nil {
+		if keyNode.StartPoint().Row == pos.Line {
+			// Position of the value
+			return nil, nil, false
+		}
+		return path, map[string]bool{}, true
+	}
+
+	if child := yamlparser.GetChildOfType(valueNode, "block_mapping"); child != nil {
+		return ch.getKeyPathInMapping(child, path)
+	}
+
+	if sequence := yamlparser.GetChildOfType(valueNode, "block_sequence"); sequence != nil {
+		return ch.getKeyPathInSequence(sequence, path)
+	}
+
+	return nil, nil, false
+}
+
+func (ch *CompletionHandler) getKeyPathInSequence(sequence *sitter.Node, path []string) ([]string, map[string]bool, bool) {
+	pos := ch.Params.Position
+	var item *sitter.Node
+
+	for i := 0; i < int(sequence.NamedChildCount()); i++ {
+		child := sequence.NamedChild(i)
+		if child.Type() == "block_sequence_item" && child.StartPoint().Row <= pos.Line && child.StartPoint().Column < pos.Character {
+			item = child
+		}
+	}
+
+	if item == nil {
+		return nil, nil, false
+	}
+
+	mapping := yamlparser.GetChildMapping(yamlparser.GetChildOfType(item, "block_node"))
+	if mapping == nil || mapping.Type() != "block_mapping" {
+		return nil, nil, false
+	}
+
+	return ch.getKeyPathInMapping(mapping, append(path, "-"))
+}
@@ -20,10 +20,7 @@ func (ch *CompletionHandler) completeExecutors() {
 	}
 
 	if executor.IsUncomplete() {
-		ch.addCompletionItemField("docker")
-		ch.addCompletionItemField("macos")
-		ch.addCompletionItemField("windows")
-		ch.addCompletionItemField("machine")
+		ch.addConfigKeysCompletion()
 		return
 	}
 
@@ -51,13 +48,18 @@ func findExecutor(pos protocol.Position, doc yamlparser.YamlDocument) (ast.Execu
 
 func (ch *CompletionHandler) completeDockerExecutor(executor ast.DockerExecutor) {
 	if utils.PosInRange(executor.ResourceClassRange, ch.Params.Position) {
-		ch.addResourceClassCompletion(validate.ValidDockerResourceClasses)
+		ch.addResourceClassCompletion(validate.DockerResourceClasses)
 		return
 	}
 
 	// Check if we are in an image's range
 	for _, img := range executor.Image {
 		if utils.PosInRange(img.ImageRange, ch.Params.Position) {
+			// The images and their tags are searched on Docker Hub
+			if ch.Context.IsOffline() {
+				return
+			}
+
 			// Suggest docker images w/ dockerhub package to perform search
 
 			node, _, _ := utils.NodeAtPos(ch.Doc.RootNode, ch.Params.Position)
@@ -123,26 +125,16 @@ func (ch *CompletionHandler) completeDockerExecutor(executor ast.DockerExecutor)
 				}
 			}
 
-			break
+			return
 		}
 	}
+
+	ch.addConfigKeysCompletion()
 }
 
 func (ch *CompletionHandler) completeMachineExecutor(executor ast.MachineExecutor) {
 	if utils.PosInRange(executor.ResourceClassRange, ch.Params.Position) {
-		if strings.HasPrefix(executor.ResourceClass, "arm.") {
-			ch.addResourceClassCompletion(validate.ValidARMResourceClasses)
-		} else if strings.HasPrefix(executor.ResourceClass, "gpu.nvidia") || strings.HasPrefix(executor.ResourceClass, "windows.gpu.nvidia") {
-			ch.addResourceClassCompletion(validate.ValidNvidiaGPUResourceClasses)
-		} else {
-			ch.addResourceClassCompletion(validate.ValidLinuxResourceClasses)
-			if ch.Context.Api.IsLoggedIn() {
-				customResourceClasses := ch.Cache.ResourceClassCache.GetResourceClassOfFile(ch.Doc.URI)
-				for _, resourceClass := range customResourceClasses {
-					ch.addCompletionItem(resourceClass)
-				}
-			}
-		}
+		ch.addMachineResourceClassCompletion(executor)
 		return
 	}
 
@@ -164,40 +156,69 @@ func (ch *CompletionHandler) completeMachineExecutor(executor ast.MachineExecuto
 		}
 	}
 
-	ch.checkAndAddResourceClassFieldCompletion(executor)
+	ch.addConfigKeysCompletion()
 }
 
 func (ch *CompletionHandler) completeMacOSExecutor(executor ast.MacOSExecutor) {
 	if utils.PosInRange(executor.ResourceClassRange, ch.Params.Position) {
-		ch.addResourceClassCompletion(validate.ValidMacOSResourceClasses)
+		ch.addResourceClassCompletion(validate.MacOSResourceClasses)
 		return
 	} else {
-		ch.checkAndAddResourceClassFieldCompletion(executor)
+		ch.addConfigKeysCompletion()
 	}
 }
 
 func (ch *CompletionHandler) completeWindowsExecutor(executor ast.WindowsExecutor) {
 	if utils.PosInRange(executor.ResourceClassRange, ch.Params.Position) {
-		ch.addResourceClassCompletion(validate.ValidLinuxResourceClasses)
+		ch.addResourceClassCompletion(validate.WindowsResourceClasses)
 		return
 	} else {
-		ch.checkAndAddResourceClassFieldCompletion(executor)
+		ch.addConfigKeysCompletion()
 	}
 }
 
-func (ch *CompletionHandler) addResourceClassCompletion(resourceClasses []string) {
-	for _, resourceClass := range resourceClasses {
-		ch.addCompletionItem(resourceClass)
+// Machines also run on the self-hosted runners of the organization
+func (ch *CompletionHandler) addMachineResourceClassCompletion(executor ast.Executor) {
+	ch.addResourceClassCompletion(validate.GetResourceClasses(executor))
+
+	if ch.Context.Api.IsLoggedIn() {
+		prefix := ch.getValuePrefix()
+		for _, resourceClass := range ch.Cache.ResourceClassCache.GetResourceClassOfFile(ch.Doc.URI) {
+			if strings.HasPrefix(resourceClass, prefix) {
+				ch.addCompletionItemWithDetail(resourceClass, "Self-hosted runner", "z"+resourceClass)
+			}
+		}
 	}
 }
 
-func (ch *CompletionHandler) checkAndAddResourceClassFieldCompletion(executor ast.Executor) {
-	if executor.GetResourceClass() == "" {
-		ch.addCompletionItemField("resource_class")
-		return
+// The classes are given sorted by size, the order is kept by their sort text
+func (ch *CompletionHandler) addResourceClassCompletion(resourceClasses []validate.ResourceClass) {
+	prefix := ch.getValuePrefix()
+
+	for i, resourceClass := range resourceClasses {
+		if strings.HasPrefix(resourceClass.Name, prefix) {
+			ch.addCompletionItemWithDetail(resourceClass.Name, resourceClass.Description, fmt.Sprintf("%02d", i))
+		}
 	}
 }
 
+// Text of the value being completed, up to the cursor
+func (ch *CompletionHandler) getValuePrefix() string {
+	lines := strings.Split(string(ch.Doc.Content), "\n")
+	if int(ch.Params.Position.Line) >= len(lines) {
+		return ""
+	}
+
+	line := lines[ch.Params.Position.Line]
+	line = line[:min(int(ch.Params.Position.Character), len(line))]
+	_, value, found := strings.Cut(line, ":")
+	if !found {
+		return ""
+	}
+
+	return strings.Trim(strings.TrimSpace(value), "\"'")
+}
+
 func (ch *CompletionHandler) addDockerImageCompletion(node *sitter.Node, namespace, name, tag string, retrigger bool) {
 	if node == nil {
 		return
@@ -5,6 +5,7 @@ import (
 
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
 	yamlparser "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser/validate"
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
 	"go.lsp.dev/protocol"
 )
@@ -23,19 +24,32 @@ func (ch *CompletionHandler) completeJobs() {
 		ch.addParametersDefinitionCompletion(job.Parameters)
 		return
 	case utils.PosInRange(job.StepsRange, ch.Params.Position):
-		nodeToComplete, _, _ := utils.NodeAtPos(ch.Doc.RootNode, ch.Params.Position)
-		if nodeToComplete.Type() == ":" {
-			nodeToComplete = nodeToComplete.PrevSibling()
-		}
-
-		ch.completeSteps(job.Name, true, true, nodeToComplete)
+		ch.completeSteps(job.Name, true, true)
 		return
 	case utils.PosInRange(job.DockerRange, ch.Params.Position):
 		ch.completeDockerExecutor(job.Docker)
 		return
+	case utils.PosInRange(job.ResourceClassRange, ch.Params.Position):
+		ch.completeJobResourceClass(job)
+		return
 	}
 
-	ch.Items = append(ch.Items, (*job.CompletionItem)...)
+	ch.addConfigKeysCompletion()
+}
+
+// Only the classes of the type of the executor are offered, none when the
+// executor can not be resolved rather than classes that may not apply
+func (ch *CompletionHandler) completeJobResourceClass(job ast.Job) {
+	executor := ch.Doc.GetJobExecutor(job, ch.Cache)
+
+	switch executor.(type) {
+	case ast.MachineExecutor:
+		ch.addMachineResourceClassCompletion(executor)
+	case nil:
+		return
+	default:
+		ch.addResourceClassCompletion(validate.GetResourceClasses(executor))
+	}
 }
 
 func (ch *CompletionHandler) orbsJobs() {
@@ -0,0 +1,106 @@
+package complete
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"go.lsp.dev/protocol"
+)
+
+var matrixParameterBeingWrittenRegex = regexp.MustCompile(`<<\s*matrix\.([A-Za-z0-9_-]*)$`)
+
+// Completes the parameters of the matrix of the workflow job an interpolation
+// is written in, such as `<< matrix.ver`. Returns false when the position is
+// not right after `<< matrix.`
+func (ch *CompletionHandler) addMatrixParametersCompletion() bool {
+	content := ch.Doc.Content
+	idx := utils.PosToIndex(ch.Params.Position, content)
+	if idx > len(content) {
+		return false
+	}
+
+	lineStart := strings.LastIndex(string(content[:idx]), "\n") + 1
+	match := matrixParameterBeingWrittenRegex.FindSubmatch(content[lineStart:idx])
+	if match == nil {
+		return false
+	}
+
+	jobRef, found := ch.getJobRefAtPosition()
+	if !found || !jobRef.HasMatrix {
+		// Nothing else can be completed after `<< matrix.`
+		return true
+	}
+
+	prefix := string(match[1])
+	prefixRange := protocol.Range{
+		Start: protocol.Position{
+			Line:      ch.Params.Position.Line,
+			Character: ch.Params.Position.Character - uint32(len(prefix)),
+		},
+		End: ch.Params.Position,
+	}
+
+	names := []string{}
+	for name := range jobRef.MatrixParams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	closingBrackets := ""
+	if ch.shouldAddParamsClosingBrackets() {
+		closingBrackets = " >>"
+	}
+
+	for _, name := range names {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		values := []string{}
+		for _, value := range jobRef.MatrixParams[name] {
+			values = append(values, getMatrixValues(value)...)
+		}
+
+		ch.Items = append(ch.Items, protocol.CompletionItem{
+			Label:  name,
+			Detail: "Matrix parameter: " + strings.Join(values, ", "),
+			TextEdit: &protocol.TextEdit{
+				Range:   prefixRange,
+				NewText: name + closingBrackets,
+			},
+		})
+	}
+
+	return true
+}
+
+// The values of a matrix parameter are parsed as a single parameter value
+// holding the list of values
+func getMatrixValues(value ast.ParameterValue) []string {
+	list, ok := value.Value.([]ast.ParameterValue)
+	if !ok {
+		return []string{fmt.Sprint(value.Value)}
+	}
+
+	values := []string{}
+	for _, item := range list {
+		values = append(values, getMatrixValues(item)...)
+	}
+	return values
+}
+
+func (ch *CompletionHandler) getJobRefAtPosition() (ast.JobRef, bool) {
+	for _, workflow := range ch.Doc.Workflows {
+		for _, jobRef := range workflow.JobRefs {
+			if utils.PosInRange(jobRef.JobRefRange, ch.Params.Position) {
+				return jobRef, true
+			}
+		}
+	}
+
+	return ast.JobRef{}, false
+}
@@ -5,6 +5,7 @@ import (
 	"sync"
 
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"gopkg.in/yaml.v3"
 )
 
 const versionCount = 10000
@@ -19,10 +20,20 @@ type OrbGQLData struct {
 	ID       string       `json:"id"`
 	Name     string       `json:"name"`
 	Versions []OrbVersion `json:"versions"`
+
+	// Source of the latest version, only requested along with the orbs of a
+	// namespace to read their description. It is not kept once read
+	Latest      []OrbVersionSource `json:"latest,omitempty"`
+	Description string             `json:"-"`
+}
+
+type OrbVersionSource struct {
+	Source string `json:"source"`
 }
 
 type OrbVersion struct {
-	Version string `json:"version"`
+	Version   string `json:"version"`
+	CreatedAt string `json:"createdAt"`
 }
 
 type NamespaceOrbResponse struct {
@@ -70,12 +81,13 @@ func (cache *OrbCache) request(config RequestConfig) (err error) {
 	return
 }
 
+// The lock is not held during the request, concurrent calls for a namespace
+// that is not cached yet each send their own
 func (cache *OrbCache) GetOrbsOfRegistry(registry, hostUrl, token, userId string) (*NamespaceOrbResponse, error) {
-	cache.mutex.Lock()
-	defer cache.mutex.Unlock()
-
 	// If data is cached return it
+	cache.mutex.Lock()
 	cached, cacheExists := cache.registryOrbs[registry]
+	cache.mutex.Unlock()
 	if cacheExists {
 		return cached, nil
 	}
@@ -93,6 +105,9 @@ func (cache *OrbCache) GetOrbsOfRegistry(registry, hostUrl, token, userId string
 							versions(count: $versionCount) {
 								version
 							}
+							latest: versions(count: 1) {
+								source
+							}
 						}
 					}
 				}
@@ -115,7 +130,18 @@ func (cache *OrbCache) GetOrbsOfRegistry(registry, hostUrl, token, userId string
 		return nil, err
 	}
 
+	for i := range response.RegistryNamespace.Orbs.Edges {
+		node := &response.RegistryNamespace.Orbs.Edges[i].Node
+		if len(node.Latest) > 0 {
+			node.Description = getOrbSourceDescription(node.Latest[0].Source)
+		}
+		node.Latest = nil
+	}
+
 	// Then cache it and return it
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
 	cache.registryOrbs[registry] = &response
 	for i, orb := range response.RegistryNamespace.Orbs.Edges {
 		// Here we point to response.RegistryNamespace.Orbs.Edges[i].Node and not to orb.Node.Name
@@ -127,12 +153,32 @@ func (cache *OrbCache) GetOrbsOfRegistry(registry, hostUrl, token, userId string
 	return &response, nil
 }
 
-func (cache *OrbCache) GetVersionsOfOrb(orbName, hostUrl, token, userId string) (*OrbGQLData, error) {
+// Description of an orb from the registry, empty when it is unknown
+func (cache *OrbCache) GetOrbDescription(orbName string) string {
 	cache.mutex.Lock()
 	defer cache.mutex.Unlock()
 
+	if orb, ok := cache.orbData[orbName]; ok {
+		return orb.Description
+	}
+	return ""
+}
+
+func getOrbSourceDescription(source string) string {
+	var orb struct {
+		Description string `yaml:"description"`
+	}
+	if err := yaml.Unmarshal([]byte(source), &orb); err != nil {
+		return ""
+	}
+	return orb.Description
+}
+
+func (cache *OrbCache) GetVersionsOfOrb(orbName, hostUrl, token, userId string) (*OrbGQLData, error) {
 	// If data is cached return it
+	cache.mutex.Lock()
 	cached, cacheExists := cache.orbData[orbName]
+	cache.mutex.Unlock()
 	if cacheExists {
 		return cached, nil
 	}
@@ -144,6 +190,7 @@ func (cache *OrbCache) GetVersionsOfOrb(orbName, hostUrl, token, userId string)
 				name
 				versions(count: $versionCount) {
 					version
+					createdAt
 				}
 			}
 		}
@@ -170,6 +217,9 @@ func (cache *OrbCache) GetVersionsOfOrb(orbName, hostUrl, token, userId string)
 	}
 
 	// Then store it in the cache and return it
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
 	cache.orbData[orbName] = &orb
 
 	return &orb, nil
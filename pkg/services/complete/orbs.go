@@ -2,12 +2,16 @@ package complete
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
 	sitter "github.com/smacker/go-tree-sitter"
 	"go.lsp.dev/protocol"
+	"golang.org/x/mod/semver"
 )
 
 var orbCache = OrbCache{
@@ -62,31 +66,33 @@ func (ch *CompletionHandler) wantOrbVersionCompletion(node *sitter.Node) bool {
 func (ch *CompletionHandler) completeOrbVersion(node *sitter.Node) {
 	def := ch.Doc.GetOrbURLDefinition(node)
 	orbName := fmt.Sprintf("%s/%s", def.Namespace.Text, def.Name.Text)
-	completions, err := ch.getOrbVersionCompletions(
+	versions, err := getOrbVersionCompletions(
 		orbName,
 		ch.Doc.Context.Api.HostUrl,
 		ch.Doc.Context.Api.Token,
 		ch.Doc.Context.UserIdForTelemetry,
 	)
 	if err != nil {
+		// The orb does not resolve, nothing to propose
 		return
 	}
 
-	for i, completion := range completions {
+	for i, version := range versions {
 		ch.Items = append(ch.Items, protocol.CompletionItem{
-			Label: completion,
-			// TODO: this sorting implementation may encounter problems for orbs having more than 256
-			// versions
-			SortText: fmt.Sprintf("%c", i),
+			Label:  version.Version,
+			Detail: formatOrbVersionReleaseDate(version.CreatedAt),
+			// Zero-padded so that the client keeps the newest first order
+			SortText: fmt.Sprintf("%06d", i),
 			TextEdit: &protocol.TextEdit{
 				Range:   def.Version.Range,
-				NewText: completion,
+				NewText: version.Version,
 			},
 		})
 	}
 }
 
-func (ch *CompletionHandler) getOrbVersionCompletions(name, hostUrl, token, userId string) ([]string, error) {
+// Returns the published versions of the orb, newest first
+func getOrbVersionCompletions(name, hostUrl, token, userId string) ([]OrbVersion, error) {
 	orbName := strings.TrimSuffix(name, "@")
 
 	orbData, err := orbCache.GetVersionsOfOrb(orbName, hostUrl, token, userId)
@@ -94,32 +100,80 @@ func (ch *CompletionHandler) getOrbVersionCompletions(name, hostUrl, token, user
 		return nil, err
 	}
 
-	versions := make([]string, len(orbData.Versions))
-	for i, version := range orbData.Versions {
-		versions[i] = version.Version
-	}
+	// Copy before sorting, the versions are shared with the registry cache
+	versions := make([]OrbVersion, len(orbData.Versions))
+	copy(versions, orbData.Versions)
+	sortOrbVersionsNewestFirst(versions)
+
 	return versions, nil
 }
 
-func (ch *CompletionHandler) completeOrbName(node *sitter.Node) {
-	completions, err := getOrbNameCompletions(
-		ch.Doc.GetNodeText(node),
-		ch.Doc.Context.Api.HostUrl,
-		ch.Doc.Context.Api.Token,
-		ch.Doc.Context.UserIdForTelemetry,
-	)
+func sortOrbVersionsNewestFirst(versions []OrbVersion) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		return semver.Compare("v"+versions[i].Version, "v"+versions[j].Version) > 0
+	})
+}
+
+func formatOrbVersionReleaseDate(createdAt string) string {
+	if createdAt == "" {
+		return ""
+	}
+
+	date, err := time.Parse(time.RFC3339, createdAt)
 	if err != nil {
-		return
+		return createdAt
+	}
+
+	return "Released on " + date.Format("2006-01-02")
+}
+
+func (ch *CompletionHandler) completeOrbName(node *sitter.Node) {
+	name := ch.Doc.GetNodeText(node)
+
+	completions, err := []string{}, utils.ErrOffline
+	if !ch.Doc.Context.IsOffline() {
+		completions, err = getOrbNameCompletions(
+			name,
+			ch.Doc.Context.Api.HostUrl,
+			ch.Doc.Context.Api.Token,
+			ch.Doc.Context.UserIdForTelemetry,
+		)
+	}
+	if err != nil || len(completions) == 0 {
+		// The registry may be unreachable, fallback to the orbs known locally
+		completions = getCachedOrbNameCompletions(name, ch.Cache)
 	}
 
 	for _, completion := range completions {
-		ch.addReplaceTextCompletionItem(node, completion)
+		ch.addOrbNameCompletionItem(node, completion)
+	}
+}
+
+// The description of the cached orb is preferred, the one of the registry
+// being the description of the latest version
+func (ch *CompletionHandler) addOrbNameCompletionItem(node *sitter.Node, orbID string) {
+	ch.addReplaceTextCompletionItem(node, orbID)
+
+	description := orbCache.GetOrbDescription(strings.Split(orbID, "@")[0])
+	if orbInfo := ch.Cache.OrbCache.GetOrb(orbID); orbInfo != nil && orbInfo.Description != "" {
+		description = orbInfo.Description
+	}
+
+	if description != "" {
+		ch.Items[len(ch.Items)-1].Documentation = protocol.MarkupContent{
+			Kind:  protocol.Markdown,
+			Value: description,
+		}
 	}
 }
 
+// The registry is only queried once the namespace is complete, that is
+// followed by a `/`, so that typing it does not send a request per keystroke
 func getOrbNameCompletions(name, hostUrl, token, userId string) ([]string, error) {
-	parts := strings.Split(name, "/")
-	registry := parts[0]
+	registry, _, isNamespaceComplete := strings.Cut(name, "/")
+	if !isNamespaceComplete || registry == "" {
+		return []string{}, nil
+	}
 
 	response, err := orbCache.GetOrbsOfRegistry(registry, hostUrl, token, userId)
 
@@ -127,13 +181,27 @@ func getOrbNameCompletions(name, hostUrl, token, userId string) ([]string, error
 		return nil, err
 	}
 
-	completions := make([]string, len(response.RegistryNamespace.Orbs.Edges))
+	completions := make([]string, 0, len(response.RegistryNamespace.Orbs.Edges))
 
-	for i, v := range response.RegistryNamespace.Orbs.Edges {
+	for _, v := range response.RegistryNamespace.Orbs.Edges {
 		if len(v.Node.Versions) > 0 {
-			completions[i] = fmt.Sprintf("%s@%s", v.Node.Name, v.Node.Versions[0].Version)
+			completions = append(completions, ast.FormatOrbID(v.Node.Name, v.Node.Versions[0].Version))
 		}
 	}
 
 	return completions, nil
 }
+
+func getCachedOrbNameCompletions(name string, cache *utils.Cache) []string {
+	completions := []string{}
+
+	for _, orbID := range cache.OrbCache.OrbIDs() {
+		if strings.HasPrefix(orbID, name) {
+			completions = append(completions, orbID)
+		}
+	}
+
+	sort.Strings(completions)
+
+	return completions
+}
@@ -0,0 +1,65 @@
+package complete
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortOrbVersionsNewestFirst(t *testing.T) {
+	versions := []OrbVersion{
+		{Version: "1.2.0"},
+		{Version: "1.10.0"},
+		{Version: "0.9.1"},
+		{Version: "2.0.0"},
+	}
+
+	sortOrbVersionsNewestFirst(versions)
+
+	assert.Equal(t, []OrbVersion{
+		{Version: "2.0.0"},
+		{Version: "1.10.0"},
+		{Version: "1.2.0"},
+		{Version: "0.9.1"},
+	}, versions)
+}
+
+func TestFormatOrbVersionReleaseDate(t *testing.T) {
+	assert.Equal(t, "Released on 2023-04-12", formatOrbVersionReleaseDate("2023-04-12T09:31:02.123Z"))
+	assert.Equal(t, "", formatOrbVersionReleaseDate(""))
+	assert.Equal(t, "yesterday", formatOrbVersionReleaseDate("yesterday"))
+}
+
+func TestGetOrbNameCompletions(t *testing.T) {
+	requests := atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(`{"data": {"registryNamespace": {"orbs": {"edges": [
+			{"node": {"name": "completion-test/node", "versions": [{"version": "5.1.0"}], "latest": [{"source": "version: 2.1\ndescription: Install Node.js\n"}]}},
+			{"node": {"name": "completion-test/empty", "versions": []}}
+		]}}}}`))
+	}))
+	defer server.Close()
+
+	// The namespace is still being typed
+	for _, name := range []string{"c", "completion-te", "/node"} {
+		completions, err := getOrbNameCompletions(name, server.URL, "", "")
+		assert.Nil(t, err)
+		assert.Empty(t, completions)
+	}
+	assert.Equal(t, int32(0), requests.Load())
+
+	for _, name := range []string{"completion-test/", "completion-test/no"} {
+		completions, err := getOrbNameCompletions(name, server.URL, "", "")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"completion-test/node@5.1.0"}, completions)
+	}
+	assert.Equal(t, int32(1), requests.Load())
+
+	assert.Equal(t, "Install Node.js", orbCache.GetOrbDescription("completion-test/node"))
+	assert.Equal(t, "", orbCache.GetOrbDescription("completion-test/empty"))
+	assert.Equal(t, "", orbCache.GetOrbDescription("completion-test/unknown"))
+}
@@ -62,23 +62,9 @@ func (ch *CompletionHandler) addParametersDefinitionCompletion(parameters map[st
 func (ch *CompletionHandler) addParameterReferenceCompletion(node *sitter.Node) {
 	if node.Type() == "string_scalar" {
 		isParamBeingWritten, isPipelineParam := utils.CheckIfParamIsPartiallyReferenced(ch.Doc.GetNodeText(node))
-		if isParamBeingWritten {
-			if isPipelineParam {
-				ch.addPipelineParametersReferenceCompletion()
-			} else {
-				ch.addParametersReferenceCompletion()
-			}
-		}
-	}
-}
-
-func (ch *CompletionHandler) addPipelineParametersReferenceCompletion() {
-	shouldAddClosingBrackets := ch.shouldAddParamsClosingBrackets()
-	for _, param := range ch.Doc.PipelineParameters {
-		if shouldAddClosingBrackets {
-			ch.addCompletionItemFieldWithCustomText(param.GetName(), "", " >>", "", "")
-		} else {
-			ch.addCompletionItem(param.GetName())
+		// Pipeline values are completed by addPipelineValuesCompletion
+		if isParamBeingWritten && !isPipelineParam {
+			ch.addParametersReferenceCompletion()
 		}
 	}
 }
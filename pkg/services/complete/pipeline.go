@@ -0,0 +1,74 @@
+package complete
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"go.lsp.dev/protocol"
+)
+
+var pipelineValueBeingWrittenRegex = regexp.MustCompile(`<<\s*pipeline\.([A-Za-z0-9_.-]*)$`)
+
+// Completes the pipeline values of an interpolation being written, such as
+// `<< pipeline.git.br`. Returns false when the position is not right after
+// `<< pipeline.`
+func (ch *CompletionHandler) addPipelineValuesCompletion() bool {
+	content := ch.Doc.Content
+	idx := utils.PosToIndex(ch.Params.Position, content)
+	if idx > len(content) {
+		return false
+	}
+
+	lineStart := strings.LastIndex(string(content[:idx]), "\n") + 1
+	match := pipelineValueBeingWrittenRegex.FindSubmatch(content[lineStart:idx])
+	if match == nil {
+		return false
+	}
+
+	prefix := string(match[1])
+	prefixRange := protocol.Range{
+		Start: protocol.Position{
+			Line:      ch.Params.Position.Line,
+			Character: ch.Params.Position.Character - uint32(len(prefix)),
+		},
+		End: ch.Params.Position,
+	}
+
+	values := append([]utils.PipelineValue{}, utils.PipelineValues...)
+	paramNames := []string{}
+	for name := range ch.Doc.PipelineParameters {
+		paramNames = append(paramNames, name)
+	}
+	sort.Strings(paramNames)
+	for _, name := range paramNames {
+		description := ch.Doc.PipelineParameters[name].GetDescription()
+		if description == "" {
+			description = "Pipeline parameter"
+		}
+		values = append(values, utils.PipelineValue{Name: "parameters." + name, Description: description})
+	}
+
+	closingBrackets := ""
+	if ch.shouldAddParamsClosingBrackets() {
+		closingBrackets = " >>"
+	}
+
+	for _, value := range values {
+		if !strings.HasPrefix(value.Name, prefix) {
+			continue
+		}
+
+		ch.Items = append(ch.Items, protocol.CompletionItem{
+			Label:  value.Name,
+			Detail: value.Description,
+			TextEdit: &protocol.TextEdit{
+				Range:   prefixRange,
+				NewText: value.Name + closingBrackets,
+			},
+		})
+	}
+
+	return true
+}
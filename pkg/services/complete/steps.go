@@ -1,43 +1,183 @@
 package complete
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
-	sitter "github.com/smacker/go-tree-sitter"
+	"go.lsp.dev/protocol"
 )
 
-func (ch *CompletionHandler) completeSteps(entityName string, inJob bool, includeJobSteps bool, completionNode *sitter.Node) {
+func (ch *CompletionHandler) completeSteps(entityName string, inJob bool, includeJobSteps bool) {
 	if ch.isWritingAnEnvVariableInRunStep(entityName, inJob) {
 		return
 	}
+
+	// Keys of a step, such as the ones of run
+	if path, _, ok := ch.getKeyPathAtPos(); ok && len(path) >= 2 && path[len(path)-2] == "-" && ch.addConfigKeysCompletion() {
+		return
+	}
+
+	prefix, atListItem := ch.getStepPrefix()
+	firstItem := len(ch.Items)
+
 	// We have two ifs to keep the order of the steps in the completion list.
-	ch.userDefinedCommands()
+	ch.userDefinedCommands(prefix, atListItem)
 	if includeJobSteps {
 		ch.userDefinedJobs()
 		ch.orbsJobs()
 	}
-	ch.builtInSteps()
-	ch.orbCommands(completionNode)
+	ch.builtInSteps(prefix, atListItem)
+	ch.stepSnippets(prefix, atListItem)
+	ch.orbCommands(prefix, atListItem)
+
+	items := ch.Items[:firstItem]
+	for _, item := range ch.Items[firstItem:] {
+		if strings.HasPrefix(item.Label, prefix) {
+			items = append(items, item)
+		}
+	}
+	ch.Items = items
+}
+
+// Snippets of the built-in steps, with their required keys
+var BUILT_IN_STEPS = []struct {
+	Name    string
+	Snippet string
+}{
+	{"run", "run:\n\t\tcommand: $0"},
+	{"checkout", "checkout"},
+	{"setup_remote_docker", "setup_remote_docker"},
+	{"save_cache", "save_cache:\n\t\tkey: $1\n\t\tpaths:\n\t\t\t- $0"},
+	{"restore_cache", "restore_cache:\n\t\tkeys:\n\t\t\t- $0"},
+	{"store_artifacts", "store_artifacts:\n\t\tpath: $0"},
+	{"store_test_results", "store_test_results:\n\t\tpath: $0"},
+	{"persist_to_workspace", "persist_to_workspace:\n\t\troot: $1\n\t\tpaths:\n\t\t\t- $0"},
+	{"attach_workspace", "attach_workspace:\n\t\tat: $0"},
+	{"add_ssh_keys", "add_ssh_keys"},
+	{"unless", "unless:\n\t\tcondition: $1\n\t\tsteps:\n\t\t\t- $0"},
+	{"when", "when:\n\t\tcondition: $1\n\t\tsteps:\n\t\t\t- $0"},
+}
+
+func (ch *CompletionHandler) builtInSteps(prefix string, atListItem bool) {
+	for _, step := range BUILT_IN_STEPS {
+		ch.addStepCompletionItem(step.Name, utils.GetBuiltInStep(step.Name).Description, step.Snippet, protocol.CompletionItemKindKeyword, prefix, atListItem)
+	}
+}
+
+// Scaffolds of the most common steps, offered along the built-in steps.
+// Tab stops sharing a number are edited together
+var STEP_SNIPPETS = []struct {
+	Label       string
+	Description string
+	Snippet     string
+}{
+	{
+		"restore_cache snippet",
+		"Restores the dependencies cache of the branch, falling back to the latest cache of the project",
+		"restore_cache:\n\t\tkeys:\n\t\t\t- ${1:v1-deps}-<< pipeline.git.branch >>-{{ checksum \"${2:package-lock.json}\" }}\n\t\t\t- ${1:v1-deps}-<< pipeline.git.branch >>-\n\t\t\t- ${1:v1-deps}-$0",
+	},
+	{
+		"save_cache snippet",
+		"Saves the dependencies cache, keyed by the branch and the checksum of the lock file",
+		"save_cache:\n\t\tkey: ${1:v1-deps}-<< pipeline.git.branch >>-{{ checksum \"${2:package-lock.json}\" }}\n\t\tpaths:\n\t\t\t- ${3:node_modules}$0",
+	},
+	{
+		"store_test_results snippet",
+		"Uploads the test results so they are shown in the Tests tab of the job",
+		"store_test_results:\n\t\tpath: ${1:test-results}$0",
+	},
+	{
+		"store_artifacts snippet",
+		"Uploads files to be available in the Artifacts tab of the job",
+		"store_artifacts:\n\t\tpath: ${1:artifacts}\n\t\tdestination: ${2:artifacts}$0",
+	},
+	{
+		"persist_to_workspace snippet",
+		"Persists files for the jobs downstream in the workflow",
+		"persist_to_workspace:\n\t\troot: ${1:.}\n\t\tpaths:\n\t\t\t- ${2:build}$0",
+	},
+	{
+		"attach_workspace snippet",
+		"Attaches the files persisted by the jobs upstream in the workflow",
+		"attach_workspace:\n\t\tat: ${1:.}$0",
+	},
+	{
+		"run snippet",
+		"Runs a named command",
+		"run:\n\t\tname: ${1:name}\n\t\tcommand: ${2:command}$0",
+	},
+}
+
+func (ch *CompletionHandler) stepSnippets(prefix string, atListItem bool) {
+	for _, snippet := range STEP_SNIPPETS {
+		ch.addStepCompletionItem(snippet.Label, snippet.Description, snippet.Snippet, protocol.CompletionItemKindSnippet, prefix, atListItem)
+	}
+}
+
+// The snippet of a command sets its required parameters, the ones without
+// default value
+func getCommandSnippet(name string, parameters map[string]ast.Parameter) string {
+	required := []string{}
+	for paramName, param := range parameters {
+		if !param.IsOptional() {
+			required = append(required, paramName)
+		}
+	}
+
+	if len(required) == 0 {
+		return name
+	}
+
+	sort.Strings(required)
+	snippet := name + ":"
+	for i, paramName := range required {
+		snippet += fmt.Sprintf("\n\t\t%s: $%d", paramName, i+1)
+	}
+
+	return snippet
+}
+
+// The step being typed replaces the text before the cursor up to the
+// previous whitespace, the orb commands containing a slash. Steps are
+// inserted as list items when the cursor is on a blank line
+func (ch *CompletionHandler) addStepCompletionItem(label string, documentation string, snippet string, kind protocol.CompletionItemKind, prefix string, atListItem bool) {
+	if !atListItem {
+		snippet = "- " + snippet
+	}
+
+	pos := ch.Params.Position
+	ch.Items = append(ch.Items, protocol.CompletionItem{
+		Label:            label,
+		Kind:             kind,
+		Documentation:    strings.TrimSpace(documentation),
+		InsertTextFormat: protocol.InsertTextFormatSnippet,
+		TextEdit: &protocol.TextEdit{
+			Range: protocol.Range{
+				Start: protocol.Position{Line: pos.Line, Character: pos.Character - uint32(len(prefix))},
+				End:   pos,
+			},
+			NewText: snippet,
+		},
+	})
 }
 
-func (ch *CompletionHandler) builtInSteps() {
-	BUILT_IN_STEPS := []string{
-		"run",
-		"checkout",
-		"setup_remote_docker",
-		"save_cache",
-		"restore_cache",
-		"store_artifacts",
-		"store_test_results",
-		"persist_to_workspace",
-		"attach_workspace",
-		"add_ssh_keys",
-		"unless",
-		"when",
-	}
-	for _, stepName := range BUILT_IN_STEPS {
-		ch.addCompletionItem(stepName)
+// The text typed before the cursor and whether it is typed after a list
+// item dash
+func (ch *CompletionHandler) getStepPrefix() (string, bool) {
+	index := utils.PosToIndex(ch.Params.Position, ch.Doc.Content)
+	lineStart := index
+	for lineStart > 0 && ch.Doc.Content[lineStart-1] != '\n' {
+		lineStart--
 	}
+
+	before := string(ch.Doc.Content[lineStart:index])
+	prefixStart := strings.LastIndexAny(before, " \t") + 1
+	prefix := before[prefixStart:]
+
+	return prefix, strings.TrimSpace(before[:prefixStart]) != ""
 }
 
 func (ch *CompletionHandler) isWritingAnEnvVariableInRunStep(entityName string, inJob bool) bool {
@@ -65,7 +205,7 @@ func (ch *CompletionHandler) isWritingAnEnvVariableInRunStep(entityName string,
 		case ast.Run:
 			if utils.PosInRange(step.CommandRange, ch.Params.Position) {
 				idx := utils.PosToIndex(ch.Params.Position, ch.Doc.Content)
-				if idx > 0 && string(ch.Doc.Content[idx-1]) == "$" {
+				if isAfterEnvVariableSign(ch.Doc.Content, idx) {
 					ch.addCompleteEnvVariables(contexts, parameters, environmentField)
 					return true
 				}
@@ -89,21 +229,69 @@ func (ch *CompletionHandler) addCompleteEnvVariables(contexts []string, paramete
 	}
 
 	if cachedFile := ch.Cache.FileCache.GetFile(ch.Doc.URI); cachedFile != nil {
-		for _, env := range cachedFile.EnvVariables {
-			ch.addCompletionItemWithDetail(env, "From project "+cachedFile.Project.Name, "B")
+		ch.addProjectAndContextEnvVariables(cachedFile, contexts)
+	}
+
+	for _, env := range BUILT_IN_ENV {
+		ch.addCompletionItemWithDetail(env, "Built-in environment variable", "C")
+	}
+}
+
+// Variables defined both on the project and in contexts are only proposed
+// once, the detail telling where they come from
+func (ch *CompletionHandler) addProjectAndContextEnvVariables(cachedFile *utils.CachedFile, contexts []string) {
+	names := []string{}
+	fromProject := map[string]bool{}
+	fromContexts := map[string][]string{}
+
+	// The variables of the file are the ones of the project it is linked to
+	if cachedFile.Project.Slug != "" {
+		if !ch.Cache.FileCache.IsProjectEnvVariablesResolved(cachedFile.TextDocument.URI) && ch.Context.Api.Token != "" {
+			file := *cachedFile
+			go utils.GetAllProjectEnvVariables(ch.Context, ch.Cache, &file)
 		}
 
-		contextEnvVariables := utils.GetAllContextEnvVariables(ch.Context, ch.Cache, cachedFile.Project.OrganizationName, contexts)
-		for _, env := range contextEnvVariables {
-			ch.addCompletionItemWithDetail(env.Name, "From context "+env.AssociatedContext, "B")
+		for _, env := range ch.Cache.FileCache.GetProjectEnvVariables(cachedFile.TextDocument.URI) {
+			if !fromProject[env] {
+				names = append(names, env)
+			}
+			fromProject[env] = true
 		}
 	}
 
-	for _, env := range BUILT_IN_ENV {
-		ch.addCompletionItemWithDetail(env, "Built-in environment variable", "C")
+	for _, env := range utils.GetAllContextEnvVariables(ch.Context, ch.Cache, cachedFile.Project.OrganizationName, contexts) {
+		if _, ok := fromContexts[env.Name]; !ok && !fromProject[env.Name] {
+			names = append(names, env.Name)
+		}
+		if utils.FindInArray(fromContexts[env.Name], env.AssociatedContext) == -1 {
+			fromContexts[env.Name] = append(fromContexts[env.Name], env.AssociatedContext)
+		}
+	}
+
+	for _, name := range names {
+		sources := []string{}
+		if fromProject[name] {
+			sources = append(sources, "project "+cachedFile.Project.Name)
+		}
+		if contextNames := fromContexts[name]; len(contextNames) == 1 {
+			sources = append(sources, "context "+contextNames[0])
+		} else if len(contextNames) > 1 {
+			sources = append(sources, "contexts "+strings.Join(contextNames, ", "))
+		}
+
+		ch.addCompletionItemWithDetail(name, "From "+strings.Join(sources, " and "), "B")
 	}
 }
 
+// Whether the text before the index is `$` or `${`
+func isAfterEnvVariableSign(content []byte, index int) bool {
+	if index > 0 && content[index-1] == '$' {
+		return true
+	}
+
+	return index > 1 && content[index-2] == '$' && content[index-1] == '{'
+}
+
 var BUILT_IN_ENV = []string{
 	"CI",
 	"CIRCLECI",
@@ -2,6 +2,7 @@ package complete
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
 	yamlparser "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
@@ -25,12 +26,12 @@ func (ch *CompletionHandler) completeWorkflows() {
 		return
 	}
 
-	if wf.JobRefs == nil {
-		ch.addCompletionItemFieldWithNewLine("jobs")
-	}
-	if !wf.HasTrigger {
-		ch.addCompletionItemFieldWithNewLine("trigger")
+	if jobRef, context, ok := findContextAtPos(ch.Params.Position, wf); ok {
+		ch.addContextsCompletion(jobRef, context)
+		return
 	}
+
+	ch.addConfigKeysCompletion()
 }
 
 func (ch *CompletionHandler) addJobsAndOrbsCompletion() {
@@ -50,6 +51,69 @@ func (ch *CompletionHandler) addExistingJobReferences(wf ast.Workflow) {
 	}
 }
 
+// Complete the context names of the organization of the project, fetching
+// them in the background when they are not cached yet
+func (ch *CompletionHandler) addContextsCompletion(jobRef ast.JobRef, context ast.TextAndRange) {
+	cachedFile := ch.Cache.FileCache.GetFile(ch.Doc.URI)
+	if cachedFile == nil || cachedFile.Project.OrganizationName == "" {
+		return
+	}
+	organization := cachedFile.Project.OrganizationName
+
+	// Only requested once, the organizations with several pages of contexts
+	// are never resolved
+	names := ch.Cache.ContextCache.ContextNames(organization)
+	if !ch.Cache.ContextCache.IsOrganizationRequested(organization) && ch.Context.Api.Token != "" {
+		go utils.GetAllContext(ch.Context, organization, cachedFile.Project.VcsInfo.Provider, ch.Cache)
+	}
+
+	start := utils.PosToIndex(context.Range.Start, ch.Doc.Content)
+	end := utils.PosToIndex(ch.Params.Position, ch.Doc.Content)
+	prefix := ""
+	if start >= 0 && start <= end && end <= len(ch.Doc.Content) {
+		prefix = string(ch.Doc.Content[start:end])
+	}
+
+	for _, name := range names {
+		if !strings.HasPrefix(name, prefix) || isContextAlreadyUsed(jobRef, context, name) {
+			continue
+		}
+
+		detail := "Context"
+		envVariables := []string{}
+		if cachedContext := ch.Cache.ContextCache.GetOrganizationContext(organization, name); cachedContext != nil {
+			envVariables = cachedContext.EnvVariables()
+		}
+		if len(envVariables) > 0 {
+			detail = "Environment variables: " + strings.Join(envVariables, ", ")
+		}
+
+		ch.addCompletionItemWithDetail(name, detail, "A")
+	}
+}
+
+func isContextAlreadyUsed(jobRef ast.JobRef, current ast.TextAndRange, name string) bool {
+	for _, context := range jobRef.Context {
+		if context.Text == name && context.Range != current.Range {
+			return true
+		}
+	}
+
+	return false
+}
+
+func findContextAtPos(pos protocol.Position, wf ast.Workflow) (ast.JobRef, ast.TextAndRange, bool) {
+	for _, jobRef := range wf.JobRefs {
+		for _, context := range jobRef.Context {
+			if utils.PosInRange(context.Range, pos) {
+				return jobRef, context, true
+			}
+		}
+	}
+
+	return ast.JobRef{}, ast.TextAndRange{}, false
+}
+
 func findWorkflow(pos protocol.Position, doc yamlparser.YamlDocument) (ast.Workflow, error) {
 	for _, wf := range doc.Workflows {
 		if utils.PosInRange(wf.Range, pos) {
@@ -1,18 +1,22 @@
 package languageservice
 
 import (
+	"fmt"
 	"os"
 	"path"
 	"path/filepath"
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
 
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser/validate"
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/services/complete"
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/testHelpers"
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"github.com/stretchr/testify/assert"
 	"go.lsp.dev/protocol"
 	"go.lsp.dev/uri"
 )
@@ -43,6 +47,27 @@ func TestComplete(t *testing.T) {
 		})
 	}
 
+	builtInStepsComplete := []protocol.CompletionItem{}
+	for _, step := range complete.BUILT_IN_STEPS {
+		builtInStepsComplete = append(builtInStepsComplete, createStepCompletionItem(
+			step.Name,
+			strings.TrimSpace(utils.BuiltInStepsDescription[step.Name]),
+			step.Snippet,
+			protocol.CompletionItemKindKeyword,
+			protocol.Position{Line: 19, Character: 14},
+		))
+	}
+
+	for _, snippet := range complete.STEP_SNIPPETS {
+		builtInStepsComplete = append(builtInStepsComplete, createStepCompletionItem(
+			snippet.Label,
+			snippet.Description,
+			snippet.Snippet,
+			protocol.CompletionItemKindSnippet,
+			protocol.Position{Line: 19, Character: 14},
+		))
+	}
+
 	cache.OrbCache.SetOrb(&ast.OrbInfo{
 		OrbParsedAttributes: parsedOrb.ToOrbParsedAttributes(),
 		RemoteInfo: ast.RemoteOrbInfo{
@@ -102,28 +127,12 @@ func TestComplete(t *testing.T) {
 					Character: 8,
 				},
 			},
-			want: []protocol.CompletionItem{
-				{
-					Label:      "description",
-					InsertText: "description: ",
-					Kind:       protocol.CompletionItemKindProperty,
-				},
-				{
-					Label:      "executor",
-					InsertText: "executor: ",
-					Kind:       protocol.CompletionItemKindProperty,
-				},
-				{
-					Label:      "resource_class",
-					InsertText: "resource_class: ",
-					Kind:       protocol.CompletionItemKindProperty,
-				},
-				{
-					Label:      "shell",
-					InsertText: "shell: ",
-					Kind:       protocol.CompletionItemKindProperty,
-				},
-			},
+			// The keys already there and the executor types, conflicting with
+			// the executor key, are not offered
+			want: createConfigKeysCompletionItems(
+				[]string{"jobs", "terraform-init-plan"},
+				"description", "resource_class", "shell", "environment", "parallelism", "circleci_ip_ranges",
+			),
 		},
 		{
 			name: "Completion for job steps",
@@ -134,11 +143,9 @@ func TestComplete(t *testing.T) {
 					Character: 14,
 				},
 			},
-			want: []protocol.CompletionItem{
+			want: append([]protocol.CompletionItem{
 				// User defined commands
-				{
-					Label: "dummyCommand",
-				},
+				createStepCompletionItem("dummyCommand", "", "dummyCommand", protocol.CompletionItemKindFunction, protocol.Position{Line: 19, Character: 14}),
 				// Itself (it can be called from itself)
 				{
 					Label: "terraform-init-plan",
@@ -147,47 +154,10 @@ func TestComplete(t *testing.T) {
 				{
 					Label: "dummyJob",
 				},
-				// Built-in steps
-				{
-					Label: "run",
-				},
-				{
-					Label: "checkout",
-				},
-				{
-					Label: "setup_remote_docker",
-				},
-				{
-					Label: "save_cache",
-				},
-				{
-					Label: "restore_cache",
-				},
-				{
-					Label: "store_artifacts",
-				},
-				{
-					Label: "store_test_results",
-				},
-				{
-					Label: "persist_to_workspace",
-				},
-				{
-					Label: "attach_workspace",
-				},
-				{
-					Label: "add_ssh_keys",
-				},
-				{
-					Label: "unless",
-				},
-				{
-					Label: "when",
-				},
 				{
 					Label: "superOrb/supermethod",
 				},
-			},
+			}, builtInStepsComplete...),
 		},
 		{
 			name: "Completion for executors type",
@@ -198,24 +168,10 @@ func TestComplete(t *testing.T) {
 					Character: 8,
 				},
 			},
-			want: []protocol.CompletionItem{
-				{
-					Label:      "docker",
-					InsertText: "docker: ",
-				},
-				{
-					Label:      "macos",
-					InsertText: "macos: ",
-				},
-				{
-					Label:      "windows",
-					InsertText: "windows: ",
-				},
-				{
-					Label:      "machine",
-					InsertText: "machine: ",
-				},
-			},
+			want: createConfigKeysCompletionItems(
+				[]string{"executors", "autocompleteType"},
+				"description", "docker", "machine", "macos", "windows", "resource_class", "shell", "working_directory", "environment", "parameters",
+			),
 		},
 		{
 			name: "Completion for executors machine image",
@@ -238,10 +194,10 @@ func TestComplete(t *testing.T) {
 				},
 			},
 			want: []protocol.CompletionItem{
-				{Label: "macos.x86.medium.gen2"},
-				{Label: "macos.m1.medium.gen1"},
-				{Label: "macos.m1.large.gen1"},
-				{Label: "macos.x86.metal.gen1"},
+				{Label: "macos.m1.medium.gen1", Detail: "4 vCPU, 6 GB RAM", SortText: "00"},
+				{Label: "macos.x86.medium.gen2", Detail: "4 vCPU, 8 GB RAM", SortText: "01"},
+				{Label: "macos.m1.large.gen1", Detail: "8 vCPU, 12 GB RAM", SortText: "02"},
+				{Label: "macos.x86.metal.gen1", Detail: "12 vCPU, 32 GB RAM", SortText: "03"},
 			},
 		},
 		{
@@ -344,6 +300,37 @@ func sortCompleteItem(items []protocol.CompletionItem) {
 	})
 }
 
+// Step completion item replacing the text typed before the position
+func createStepCompletionItem(label string, documentation string, snippet string, kind protocol.CompletionItemKind, pos protocol.Position) protocol.CompletionItem {
+	return protocol.CompletionItem{
+		Label:            label,
+		Kind:             kind,
+		Documentation:    documentation,
+		InsertTextFormat: protocol.InsertTextFormatSnippet,
+		TextEdit: &protocol.TextEdit{
+			Range:   protocol.Range{Start: pos, End: pos},
+			NewText: snippet,
+		},
+	}
+}
+
+// Completion items of the given keys of the map at the path
+func createConfigKeysCompletionItems(path []string, names ...string) []protocol.CompletionItem {
+	items := []protocol.CompletionItem{}
+	for _, name := range names {
+		key := utils.GetConfigKeyAtPath(append(path, name))
+		items = append(items, protocol.CompletionItem{
+			Label:            key.Name,
+			Kind:             protocol.CompletionItemKindProperty,
+			Documentation:    key.Description,
+			InsertText:       key.GetSnippet(),
+			InsertTextFormat: protocol.InsertTextFormatSnippet,
+		})
+	}
+
+	return items
+}
+
 func createCompletionItemForUbuntuImages() []protocol.CompletionItem {
 	completeItems := make([]protocol.CompletionItem, 0)
 	for _, image := range utils.ValidARMOrMachineImagesUbuntu2004 {
@@ -358,3 +345,565 @@ func createCompletionItemForUbuntuImages() []protocol.CompletionItem {
 	}
 	return completeItems
 }
+
+func TestCompleteContexts(t *testing.T) {
+	cache := utils.CreateCache()
+	context := testHelpers.GetDefaultLsContext()
+	fileURI := uri.File("/tmp/contexts.yml")
+
+	cache.ContextCache.SetOrganizationContext("org", &utils.Context{Name: "deploy"})
+	cache.ContextCache.AddEnvVariableToOrganizationContext("org", "deploy", "AWS_KEY")
+	cache.ContextCache.SetOrganizationContext("org", &utils.Context{Name: "docker"})
+	cache.ContextCache.SetOrganizationContext("org", &utils.Context{Name: "slack"})
+
+	complete := func(content string, pos protocol.Position) []protocol.CompletionItem {
+		cache.FileCache.SetFile(utils.CachedFile{
+			TextDocument: protocol.TextDocumentItem{URI: fileURI, Text: content},
+			Project:      utils.Project{OrganizationName: "org"},
+		})
+
+		res, err := Complete(protocol.CompletionParams{
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: fileURI},
+				Position:     pos,
+			},
+		}, cache, context)
+		assert.Nil(t, err)
+
+		sortCompleteItem(res.Items)
+		return res.Items
+	}
+
+	jobs := `version: 2.1
+
+jobs:
+  build:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - checkout
+
+workflows:
+  main:
+    jobs:
+      - build:
+`
+
+	t.Run("Should complete a single context filtered by prefix", func(t *testing.T) {
+		items := complete(jobs+"          context: d\n", protocol.Position{Line: 13, Character: 20})
+		assert.Equal(t, []protocol.CompletionItem{
+			{Label: "deploy", Detail: "Environment variables: AWS_KEY", SortText: "A"},
+			{Label: "docker", Detail: "Context", SortText: "A"},
+		}, items)
+	})
+
+	t.Run("Should complete the contexts of a list not already used", func(t *testing.T) {
+		items := complete(jobs+"          context:\n            - docker\n            - \n", protocol.Position{Line: 15, Character: 14})
+		assert.Equal(t, []protocol.CompletionItem{
+			{Label: "deploy", Detail: "Environment variables: AWS_KEY", SortText: "A"},
+			{Label: "slack", Detail: "Context", SortText: "A"},
+		}, items)
+	})
+}
+
+func TestCompleteProjectAndContextEnvVariables(t *testing.T) {
+	cache := utils.CreateCache()
+	context := testHelpers.GetDefaultLsContext()
+	fileURI := uri.File("/tmp/envVariables.yml")
+	content := `version: 2.1
+
+jobs:
+  build:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - run: echo ${
+
+workflows:
+  main:
+    jobs:
+      - build:
+          context: [deploy, aws]
+`
+
+	cache.ContextCache.SetOrganizationContext("org", &utils.Context{Name: "deploy"})
+	cache.ContextCache.AddEnvVariableToOrganizationContext("org", "deploy", "TOKEN")
+	cache.ContextCache.AddEnvVariableToOrganizationContext("org", "deploy", "AWS_KEY")
+	cache.ContextCache.SetOrganizationContext("org", &utils.Context{Name: "aws"})
+	cache.ContextCache.AddEnvVariableToOrganizationContext("org", "aws", "AWS_KEY")
+
+	complete := func(project utils.Project) []protocol.CompletionItem {
+		cache.FileCache.SetFile(utils.CachedFile{
+			TextDocument: protocol.TextDocumentItem{URI: fileURI, Text: content},
+			Project:      project,
+			EnvVariables: []string{"TOKEN", "NPM_TOKEN"},
+		})
+
+		res, err := Complete(protocol.CompletionParams{
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: fileURI},
+				Position:     protocol.Position{Line: 7, Character: 20},
+			},
+		}, cache, context)
+		assert.Nil(t, err)
+
+		items := []protocol.CompletionItem{}
+		for _, item := range res.Items {
+			if item.SortText == "B" {
+				items = append(items, item)
+			}
+		}
+		sortCompleteItem(items)
+		return items
+	}
+
+	t.Run("Should merge the variables of the project and of the contexts", func(t *testing.T) {
+		assert.Equal(t, []protocol.CompletionItem{
+			{Label: "AWS_KEY", Detail: "From contexts deploy, aws", SortText: "B"},
+			{Label: "NPM_TOKEN", Detail: "From project app", SortText: "B"},
+			{Label: "TOKEN", Detail: "From project app and context deploy", SortText: "B"},
+		}, complete(utils.Project{Slug: "gh/org/app", Name: "app", OrganizationName: "org"}))
+	})
+
+	t.Run("Should ignore the variables of the file when it is not linked to a project", func(t *testing.T) {
+		assert.Equal(t, []protocol.CompletionItem{
+			{Label: "AWS_KEY", Detail: "From contexts deploy, aws", SortText: "B"},
+			{Label: "TOKEN", Detail: "From context deploy", SortText: "B"},
+		}, complete(utils.Project{OrganizationName: "org"}))
+	})
+}
+
+func TestCompletePipelineValues(t *testing.T) {
+	cache := utils.CreateCache()
+	context := testHelpers.GetDefaultLsContext()
+	fileURI := uri.File("/tmp/pipeline.yml")
+
+	complete := func(content string, pos protocol.Position) []protocol.CompletionItem {
+		cache.FileCache.SetFile(utils.CachedFile{
+			TextDocument: protocol.TextDocumentItem{URI: fileURI, Text: content},
+		})
+
+		res, err := Complete(protocol.CompletionParams{
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: fileURI},
+				Position:     pos,
+			},
+		}, cache, context)
+		assert.Nil(t, err)
+
+		sortCompleteItem(res.Items)
+		return res.Items
+	}
+
+	config := `version: 2.1
+
+parameters:
+  deploy:
+    type: boolean
+    default: false
+    description: Whether to deploy
+
+jobs:
+  build:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - run: echo `
+
+	t.Run("Should complete the git values filtered by prefix", func(t *testing.T) {
+		items := complete(config+"<< pipeline.git.b\n", protocol.Position{Line: 13, Character: 35})
+		edit := func(text string) *protocol.TextEdit {
+			return &protocol.TextEdit{
+				Range: protocol.Range{
+					Start: protocol.Position{Line: 13, Character: 30},
+					End:   protocol.Position{Line: 13, Character: 35},
+				},
+				NewText: text,
+			}
+		}
+
+		assert.Equal(t, []protocol.CompletionItem{
+			{
+				Label:    "git.base_revision",
+				Detail:   "The long (40-character) git SHA of the build prior to the one being built",
+				TextEdit: edit("git.base_revision >>"),
+			},
+			{
+				Label:    "git.branch",
+				Detail:   "The name of the git branch that was pushed to trigger the pipeline",
+				TextEdit: edit("git.branch >>"),
+			},
+		}, items)
+	})
+
+	t.Run("Should complete the pipeline parameters without closing brackets", func(t *testing.T) {
+		items := complete(config+"<< pipeline.parameters. >>\n", protocol.Position{Line: 13, Character: 41})
+		assert.Equal(t, []protocol.CompletionItem{
+			{
+				Label:  "parameters.deploy",
+				Detail: "Whether to deploy",
+				TextEdit: &protocol.TextEdit{
+					Range: protocol.Range{
+						Start: protocol.Position{Line: 13, Character: 30},
+						End:   protocol.Position{Line: 13, Character: 41},
+					},
+					NewText: "parameters.deploy",
+				},
+			},
+		}, items)
+	})
+}
+
+func TestCompleteSteps(t *testing.T) {
+	cache := utils.CreateCache()
+	context := testHelpers.GetDefaultLsContext()
+	fileURI := uri.File("/tmp/steps.yml")
+
+	cache.OrbCache.SetOrb(&ast.OrbInfo{
+		OrbParsedAttributes: ast.OrbParsedAttributes{
+			Commands: map[string]ast.Command{
+				"install": {
+					Name:        "install",
+					Description: "Install Node.js",
+					Parameters: map[string]ast.Parameter{
+						"version": ast.StringParameter{BaseParameter: ast.BaseParameter{Name: "version"}},
+					},
+				},
+			},
+		},
+	}, "circleci/node@5.1.0")
+
+	complete := func(content string, pos protocol.Position) []protocol.CompletionItem {
+		cache.FileCache.SetFile(utils.CachedFile{
+			TextDocument: protocol.TextDocumentItem{URI: fileURI, Text: content},
+		})
+
+		res, err := Complete(protocol.CompletionParams{
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: fileURI},
+				Position:     pos,
+			},
+		}, cache, context)
+		assert.Nil(t, err)
+
+		sortCompleteItem(res.Items)
+		return res.Items
+	}
+
+	content := `version: 2.1
+
+orbs:
+  node: circleci/node@5.1.0
+
+commands:
+  greet:
+    description: Say hello
+    parameters:
+      to:
+        type: string
+    steps:
+      - run: echo << parameters.to >>
+
+jobs:
+  build:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - checkout
+`
+
+	t.Run("Should filter the steps by the typed prefix", func(t *testing.T) {
+		items := complete(content+"      - s\n", protocol.Position{Line: 20, Character: 9})
+
+		rng := protocol.Range{
+			Start: protocol.Position{Line: 20, Character: 8},
+			End:   protocol.Position{Line: 20, Character: 9},
+		}
+		labels := []string{}
+		for _, item := range items {
+			labels = append(labels, item.Label)
+			assert.Equal(t, rng, item.TextEdit.Range)
+		}
+		assert.Equal(t, []string{
+			"save_cache",
+			"save_cache snippet",
+			"setup_remote_docker",
+			"store_artifacts",
+			"store_artifacts snippet",
+			"store_test_results",
+			"store_test_results snippet",
+		}, labels)
+	})
+
+	t.Run("Should insert the required parameters of the commands", func(t *testing.T) {
+		items := complete(content+"      - \n", protocol.Position{Line: 20, Character: 8})
+
+		pos := protocol.Position{Line: 20, Character: 8}
+		assert.Contains(t, items, createStepCompletionItem("greet", "Say hello", "greet:\n\t\tto: $1", protocol.CompletionItemKindFunction, pos))
+		assert.Contains(t, items, createStepCompletionItem("node/install", "Install Node.js", "node/install:\n\t\tversion: $1", protocol.CompletionItemKindFunction, pos))
+		assert.Contains(t, items, createStepCompletionItem("checkout", utils.BuiltInStepsDescription["checkout"], "checkout", protocol.CompletionItemKindKeyword, pos))
+	})
+
+	t.Run("Should name the orb commands after the alias of the orb", func(t *testing.T) {
+		aliased := strings.Replace(content, "  node: circleci/node@5.1.0", "  js: circleci/node@5.1.0", 1)
+		items := complete(aliased+"      - \n", protocol.Position{Line: 20, Character: 8})
+
+		pos := protocol.Position{Line: 20, Character: 8}
+		assert.Contains(t, items, createStepCompletionItem("js/install", "Install Node.js", "js/install:\n\t\tversion: $1", protocol.CompletionItemKindFunction, pos))
+		assert.NotContains(t, items, createStepCompletionItem("node/install", "Install Node.js", "node/install:\n\t\tversion: $1", protocol.CompletionItemKindFunction, pos))
+	})
+
+	t.Run("Should offer the cache scaffolds with a templated key", func(t *testing.T) {
+		items := complete(content+"      - save_c\n", protocol.Position{Line: 20, Character: 14})
+
+		assert.Len(t, items, 2)
+		assert.Equal(t, "save_cache snippet", items[1].Label)
+		assert.Equal(t, protocol.CompletionItemKindSnippet, items[1].Kind)
+		assert.Equal(t, protocol.InsertTextFormatSnippet, items[1].InsertTextFormat)
+		assert.Contains(t, items[1].TextEdit.NewText, `key: ${1:v1-deps}-<< pipeline.git.branch >>-{{ checksum "${2:package-lock.json}" }}`)
+	})
+
+	t.Run("Should insert a list item on a blank line", func(t *testing.T) {
+		items := complete(content+"      \n", protocol.Position{Line: 20, Character: 6})
+
+		pos := protocol.Position{Line: 20, Character: 6}
+		assert.Contains(t, items, createStepCompletionItem("attach_workspace", utils.BuiltInStepsDescription["attach_workspace"], "- attach_workspace:\n\t\tat: $0", protocol.CompletionItemKindKeyword, pos))
+	})
+}
+
+func TestCompleteConfigKeys(t *testing.T) {
+	cache := utils.CreateCache()
+	context := testHelpers.GetDefaultLsContext()
+	fileURI := uri.File("/tmp/keys.yml")
+
+	complete := func(content string, pos protocol.Position) []string {
+		cache.FileCache.SetFile(utils.CachedFile{
+			TextDocument: protocol.TextDocumentItem{URI: fileURI, Text: content},
+		})
+
+		res, err := Complete(protocol.CompletionParams{
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: fileURI},
+				Position:     pos,
+			},
+		}, cache, context)
+		assert.Nil(t, err)
+
+		labels := []string{}
+		for _, item := range res.Items {
+			labels = append(labels, item.Label)
+		}
+		sort.Strings(labels)
+		return labels
+	}
+
+	jobs := `version: 2.1
+
+jobs:
+  build:
+    docker:
+      - image: cimg/base:2023.01
+        user: root
+    steps:
+      - run:
+          name: Test
+`
+
+	t.Run("Should complete the keys of the built-in steps", func(t *testing.T) {
+		labels := complete(jobs+"          \n", protocol.Position{Line: 10, Character: 10})
+		assert.Equal(t, []string{"background", "command", "environment", "no_output_timeout", "shell", "when", "working_directory"}, labels)
+	})
+
+	t.Run("Should complete the keys being typed", func(t *testing.T) {
+		labels := complete(jobs+"          co\n", protocol.Position{Line: 10, Character: 12})
+		assert.Contains(t, labels, "command")
+		assert.NotContains(t, labels, "name")
+	})
+
+	t.Run("Should complete the keys of the Docker images", func(t *testing.T) {
+		content := strings.Replace(jobs, "        user: root\n", "        user: root\n        \n", 1)
+		labels := complete(content, protocol.Position{Line: 7, Character: 8})
+		assert.Equal(t, []string{"auth", "aws_auth", "command", "entrypoint", "environment", "name"}, labels)
+	})
+
+	workflows := `version: 2.1
+
+jobs:
+  build:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - checkout
+
+workflows:
+  test:
+    jobs:
+      - build:
+          requires: [lint]
+`
+
+	t.Run("Should complete the keys of the jobs of the workflows", func(t *testing.T) {
+		labels := complete(workflows+"          \n", protocol.Position{Line: 14, Character: 10})
+		assert.Equal(t, []string{"context", "filters", "matrix", "name", "post-steps", "pre-steps", "type"}, labels)
+	})
+
+	t.Run("Should complete the keys of the workflows", func(t *testing.T) {
+		labels := complete(workflows+"    when: << pipeline.parameters.run >>\n    \n", protocol.Position{Line: 15, Character: 4})
+		assert.Equal(t, []string{"triggers"}, labels)
+	})
+}
+
+func TestCompleteResourceClasses(t *testing.T) {
+	cache := utils.CreateCache()
+	context := testHelpers.GetDefaultLsContext()
+	fileURI := uri.File("/tmp/resourceClasses.yml")
+
+	complete := func(content string, pos protocol.Position) []string {
+		cache.FileCache.SetFile(utils.CachedFile{
+			TextDocument: protocol.TextDocumentItem{URI: fileURI, Text: content},
+		})
+
+		res, err := Complete(protocol.CompletionParams{
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: fileURI},
+				Position:     pos,
+			},
+		}, cache, context)
+		assert.Nil(t, err)
+
+		sort.Slice(res.Items, func(i, j int) bool { return res.Items[i].SortText < res.Items[j].SortText })
+		labels := []string{}
+		for _, item := range res.Items {
+			labels = append(labels, item.Label)
+		}
+		return labels
+	}
+
+	config := `version: 2.1
+
+executors:
+  mac:
+    macos:
+      xcode: 15.0.0
+
+jobs:
+  build:
+    %s
+    resource_class: %s
+    steps:
+      - checkout
+`
+
+	testCases := []struct {
+		Name     string
+		Executor string
+		Value    string
+		Expected []string
+	}{
+		{
+			Name:     "Should complete the Docker classes sorted by size",
+			Executor: "docker: [{ image: cimg/base:2023.01 }]",
+			Value:    "",
+			Expected: validate.ValidDockerResourceClasses,
+		},
+		{
+			Name:     "Should filter the classes by prefix",
+			Executor: "machine: { image: ubuntu-2204:current }",
+			Value:    "arm.",
+			Expected: validate.ValidARMResourceClasses,
+		},
+		{
+			Name:     "Should resolve named executors",
+			Executor: "executor: mac",
+			Value:    "",
+			Expected: validate.ValidMacOSResourceClasses,
+		},
+		{
+			Name:     "Should not complete when the executor is unknown",
+			Executor: "executor: undeclared",
+			Value:    "",
+			Expected: []string{},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			content := fmt.Sprintf(config, tt.Executor, tt.Value)
+			labels := complete(content, protocol.Position{Line: 10, Character: uint32(20 + len(tt.Value))})
+			assert.Equal(t, tt.Expected, labels)
+		})
+	}
+}
+
+func TestCompleteMatrixParameters(t *testing.T) {
+	cache := utils.CreateCache()
+	context := testHelpers.GetDefaultLsContext()
+	fileURI := uri.File("/tmp/matrix.yml")
+
+	complete := func(content string, pos protocol.Position) []protocol.CompletionItem {
+		cache.FileCache.SetFile(utils.CachedFile{
+			TextDocument: protocol.TextDocumentItem{URI: fileURI, Text: content},
+		})
+
+		res, err := Complete(protocol.CompletionParams{
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: fileURI},
+				Position:     pos,
+			},
+		}, cache, context)
+		assert.Nil(t, err)
+
+		sortCompleteItem(res.Items)
+		return res.Items
+	}
+
+	config := `version: 2.1
+
+jobs:
+  test:
+    parameters:
+      version:
+        type: string
+      os:
+        type: string
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - checkout
+
+workflows:
+  test:
+    jobs:
+      - test:
+          matrix:
+            parameters:
+              version: ["1.0", "2.0"]
+              os: [linux]
+          name: test-<< matrix.`
+
+	t.Run("Should complete the matrix parameters filtered by prefix", func(t *testing.T) {
+		items := complete(config+"v\n", protocol.Position{Line: 22, Character: 32})
+		assert.Equal(t, []protocol.CompletionItem{
+			{
+				Label:  "version",
+				Detail: "Matrix parameter: 1.0, 2.0",
+				TextEdit: &protocol.TextEdit{
+					Range: protocol.Range{
+						Start: protocol.Position{Line: 22, Character: 31},
+						End:   protocol.Position{Line: 22, Character: 32},
+					},
+					NewText: "version >>",
+				},
+			},
+		}, items)
+	})
+
+	t.Run("Should complete every matrix parameter without closing brackets", func(t *testing.T) {
+		items := complete(config+" >>\n", protocol.Position{Line: 22, Character: 31})
+		labels := []string{}
+		for _, item := range items {
+			labels = append(labels, item.TextEdit.NewText)
+		}
+		assert.Equal(t, []string{"os", "version"}, labels)
+	})
+}
@@ -1,8 +1,6 @@
 package definition
 
 import (
-	"fmt"
-
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
 	yamlparser "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
@@ -54,7 +52,7 @@ func (def DefinitionStruct) Definition() ([]protocol.Location, error) {
 	}
 
 	if err != nil {
-		fmt.Println("error occurred during definition:", err)
+		def.Doc.Context.GetLogger().Warn("Definition could not be resolved", "error", err)
 	}
 	return res, nil
 }
@@ -23,7 +23,7 @@ func (def DefinitionStruct) getExecutorDefinition() ([]protocol.Location, error)
 func (def DefinitionStruct) getExecutorRange(name string) protocol.Range {
 	executor, ok := def.Doc.Executors[name]
 	if !ok {
-		orbLoc, _ := def.getOrbLocation(name, false)
+		orbLoc, _ := def.getOrbLocation(name, false, false)
 		if len(orbLoc) > 0 {
 			return orbLoc[0].Range
 		}
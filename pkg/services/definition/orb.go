@@ -45,19 +45,23 @@ func (def DefinitionStruct) getOrbDefinition() ([]protocol.Location, error) {
 	}, nil
 }
 
-func (def DefinitionStruct) getOrbLocation(name string, redirectToOrbFile bool) ([]protocol.Location, error) {
+func (def DefinitionStruct) getOrbLocation(name string, redirectToOrbFile bool, includeCommands bool) ([]protocol.Location, error) {
 	splittedName := strings.Split(name, "/")
 	if len(splittedName) >= 2 {
 		if orb, ok := def.Doc.Orbs[splittedName[0]]; ok {
 
 			if redirectToOrbFile {
-				orbFile, err := def.GetOrbInfo(orb.Name)
+				orbFile, _, err := def.Doc.GetOrbInfoFromReference(name, def.Cache)
 
 				if err != nil {
 					return nil, err
 				}
 
-				return def.getOrbCommandOrJobLocation(orbFile, splittedName[1])
+				if orbFile == nil {
+					return []protocol.Location{}, fmt.Errorf("orb not found")
+				}
+
+				return def.getOrbCommandOrJobLocation(orbFile, splittedName[1], includeCommands)
 			}
 
 			return []protocol.Location{
@@ -72,7 +76,7 @@ func (def DefinitionStruct) getOrbLocation(name string, redirectToOrbFile bool)
 	return []protocol.Location{}, fmt.Errorf("orb not found")
 }
 
-func (def DefinitionStruct) getOrbCommandOrJobLocation(orbInfo *ast.OrbInfo, name string) ([]protocol.Location, error) {
+func (def DefinitionStruct) getOrbCommandOrJobLocation(orbInfo *ast.OrbInfo, name string, includeCommands bool) ([]protocol.Location, error) {
 	var fileUri protocol.DocumentURI
 
 	if orbInfo.IsLocal {
@@ -82,7 +86,7 @@ func (def DefinitionStruct) getOrbCommandOrJobLocation(orbInfo *ast.OrbInfo, nam
 	}
 
 	command, ok := orbInfo.Commands[name]
-	if ok {
+	if ok && includeCommands {
 		return []protocol.Location{
 			{
 				URI:   fileUri,
@@ -105,13 +109,7 @@ func (def DefinitionStruct) getOrbCommandOrJobLocation(orbInfo *ast.OrbInfo, nam
 }
 
 func (def DefinitionStruct) getOrbParamLocation(name string, paramName string) ([]protocol.Location, error) {
-	splittedName := strings.Split(name, "/")
-	if len(splittedName) < 2 {
-		return []protocol.Location{}, fmt.Errorf("orb not found")
-	}
-
-	orbName := splittedName[0]
-	orbFile, err := def.GetOrbInfo(orbName)
+	orbFile, entityName, err := def.Doc.GetOrbInfoFromReference(name, def.Cache)
 
 	if err != nil {
 		return []protocol.Location{}, err
@@ -121,7 +119,7 @@ func (def DefinitionStruct) getOrbParamLocation(name string, paramName string) (
 		return []protocol.Location{}, fmt.Errorf("orb not found")
 	}
 
-	return def.getOrbCommandOrJobParamLocation(orbFile, splittedName[1], paramName)
+	return def.getOrbCommandOrJobParamLocation(orbFile, entityName, paramName)
 }
 
 func (def DefinitionStruct) getOrbCommandOrJobParamLocation(orbFile *ast.OrbInfo, name string, paramName string) ([]protocol.Location, error) {
@@ -27,7 +27,7 @@ func (def DefinitionStruct) getCommandOrJobLocation(name string, includeCommands
 		}, nil
 	}
 
-	if orb, err := def.getOrbLocation(name, true); err == nil {
+	if orb, err := def.getOrbLocation(name, true, includeCommands); err == nil {
 		return orb, nil
 	}
 
@@ -397,6 +397,92 @@ func TestDefinition(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Definition for workflow job ref",
+			args: args{
+				filePath: "./testdata/definitionWorkflows.yml",
+				position: protocol.Position{
+					Line:      16,
+					Character: 16,
+				},
+			},
+			want: []protocol.Location{
+				{
+					URI: uri.File("./testdata/definitionWorkflows.yml"),
+					Range: protocol.Range{
+						Start: protocol.Position{
+							Line:      7,
+							Character: 4,
+						},
+						End: protocol.Position{
+							Line:      11,
+							Character: 22,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Definition for workflow orb job ref",
+			args: args{
+				filePath: "./testdata/definitionWorkflows.yml",
+				position: protocol.Position{
+					Line:      17,
+					Character: 20,
+				},
+			},
+			want: []protocol.Location{
+				{
+					URI: uri.File("./testdata/orb.yaml"),
+					Range: protocol.Range{
+						Start: protocol.Position{
+							Line:      9,
+							Character: 4,
+						},
+						End: protocol.Position{
+							Line:      28,
+							Character: 51,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Definition for workflow job ref of aliased orb",
+			args: args{
+				filePath: "./testdata/definitionWorkflows.yml",
+				position: protocol.Position{
+					Line:      19,
+					Character: 20,
+				},
+			},
+			want: []protocol.Location{
+				{
+					URI: uri.File("./testdata/orb.yaml"),
+					Range: protocol.Range{
+						Start: protocol.Position{
+							Line:      9,
+							Character: 4,
+						},
+						End: protocol.Position{
+							Line:      28,
+							Character: 51,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Definition for workflow ref of undefined job",
+			args: args{
+				filePath: "./testdata/definitionWorkflows.yml",
+				position: protocol.Position{
+					Line:      18,
+					Character: 16,
+				},
+			},
+			want: []protocol.Location{},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -48,9 +48,20 @@ func DiagnosticString(content string, cache *utils.Cache, context *utils.LsConte
 }
 
 func DiagnosticYAML(yamlDocument yamlparser.YamlDocument, cache *utils.Cache, context *utils.LsContext) ([]protocol.Diagnostic, error) {
+	validateStruct := validate.Validate{
+		APIs: validate.ValidateAPIs{
+			DockerHub: dockerhub.NewAPI(),
+		},
+		Doc:         yamlDocument,
+		Diagnostics: &[]protocol.Diagnostic{},
+		Cache:       cache,
+		Context:     context,
+	}
+
 	if yamlDocument.Version != 0 && yamlDocument.Version < 2.1 {
-		// TODO: Handle error
-		return []protocol.Diagnostic{}, nil
+		// Only the version is checked, the rest of the validation is for 2.1
+		validateStruct.ValidateVersion()
+		return context.GetDiagnosticSeverities().Apply(*validateStruct.Diagnostics), nil
 	}
 
 	diag := DiagnosticType{
@@ -74,19 +85,10 @@ func DiagnosticYAML(yamlDocument yamlparser.YamlDocument, cache *utils.Cache, co
 		validator.ValidateWithJSONSchema(diag.yamlDocument.RootNode, diag.yamlDocument.Content),
 	)
 
-	validateStruct := validate.Validate{
-		APIs: validate.ValidateAPIs{
-			DockerHub: dockerhub.NewAPI(),
-		},
-		Doc:         diag.yamlDocument,
-		Diagnostics: &[]protocol.Diagnostic{},
-		Cache:       cache,
-		Context:     context,
-	}
 	validateStruct.Validate(false)
 	diag.addDiagnostics(*validateStruct.Diagnostics)
 
-	return *diag.diagnostics, nil
+	return context.GetDiagnosticSeverities().Apply(*diag.diagnostics), nil
 }
 
 func (diag *DiagnosticType) addDiagnostics(diagnostic []protocol.Diagnostic) {
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/testHelpers"
@@ -64,3 +65,133 @@ func TestFindErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestDiagnosticRules(t *testing.T) {
+	cwd, _ := os.Getwd()
+	schemaPath, _ := filepath.Abs(cwd + "/../../schema.json")
+	content := `version: 2.1
+
+jobs:
+  build:
+    machine: true
+    unknown-key: value
+    parallelism: 1
+    steps:
+      - checkout
+      - node/install
+  unused:
+    docker:
+      - image: cimg/base
+    steps:
+      - checkout
+
+workflows:
+  main:
+    jobs:
+      - build
+`
+
+	getDiagnostics := func(t *testing.T, severities utils.DiagnosticSeverities) []protocol.Diagnostic {
+		context := testHelpers.GetDefaultLsContext()
+		context.Api.Token = ""
+		context.SetOffline(true)
+		context.SetDiagnosticSeverities(severities)
+
+		diagnostics, err := DiagnosticString(content, utils.CreateCache(), context, schemaPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return diagnostics
+	}
+
+	findRule := func(diagnostics []protocol.Diagnostic, rule string) *protocol.Diagnostic {
+		for _, diagnostic := range diagnostics {
+			if utils.GetDiagnosticRule(diagnostic) == rule {
+				return &diagnostic
+			}
+		}
+		return nil
+	}
+
+	t.Run("Every diagnostic has a rule", func(t *testing.T) {
+		diagnostics := getDiagnostics(t, nil)
+
+		for _, diagnostic := range diagnostics {
+			if !strings.HasPrefix(utils.GetDiagnosticRule(diagnostic), utils.RuleNamespace) {
+				t.Errorf("diagnostic %q has no rule: %v", diagnostic.Message, diagnostic.Code)
+			}
+		}
+
+		for _, rule := range []string{utils.RuleSchema, utils.RuleDeprecatedImage, utils.RuleParallelism, utils.RuleOrbNotDeclared, utils.RuleUnusedJob} {
+			if findRule(diagnostics, rule) == nil {
+				t.Errorf("no diagnostic for rule %s", rule)
+			}
+		}
+	})
+
+	t.Run("Severities are overridden by the settings", func(t *testing.T) {
+		diagnostics := getDiagnostics(t, utils.ParseDiagnosticSeverities(map[string]interface{}{
+			"unused-job":             "off",
+			utils.RuleOrbNotDeclared: "hint",
+		}))
+
+		if findRule(diagnostics, utils.RuleUnusedJob) != nil {
+			t.Error("diagnostics of rules turned off should not be reported")
+		}
+
+		diagnostic := findRule(diagnostics, utils.RuleOrbNotDeclared)
+		if diagnostic == nil || diagnostic.Severity != protocol.DiagnosticSeverityHint {
+			t.Errorf("severity of %s should be hint: %v", utils.RuleOrbNotDeclared, diagnostic)
+		}
+	})
+}
+
+func TestVersionDiagnostics(t *testing.T) {
+	cwd, _ := os.Getwd()
+	schemaPath, _ := filepath.Abs(cwd + "/../../schema.json")
+	jobs := `jobs:
+  build:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - checkout
+
+workflows:
+  main:
+    jobs:
+      - build
+`
+
+	getMessages := func(t *testing.T, content string) []string {
+		context := testHelpers.GetDefaultLsContext()
+		context.Api.Token = ""
+		context.SetOffline(true)
+
+		diagnostics, err := DiagnosticString(content, utils.CreateCache(), context, schemaPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		messages := []string{}
+		for _, diagnostic := range diagnostics {
+			if utils.GetDiagnosticRule(diagnostic) == utils.RuleVersion || utils.GetDiagnosticRule(diagnostic) == utils.RuleSchema {
+				messages = append(messages, diagnostic.Message)
+			}
+		}
+		return messages
+	}
+
+	t.Run("Missing version is only reported once", func(t *testing.T) {
+		messages := getMessages(t, jobs)
+		if !reflect.DeepEqual(messages, []string{"Missing version, add `version: 2.1` at the top of the configuration"}) {
+			t.Errorf("unexpected diagnostics %v", messages)
+		}
+	})
+
+	t.Run("Version 2 configurations are warned about 2.1 features", func(t *testing.T) {
+		messages := getMessages(t, "version: 2\n\norbs:\n  node: circleci/node@5.1.0\n\n"+jobs)
+		if !reflect.DeepEqual(messages, []string{"Orbs are only supported from version 2.1, set `version: 2.1` to use them"}) {
+			t.Errorf("unexpected diagnostics %v", messages)
+		}
+	})
+}
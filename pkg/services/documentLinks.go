@@ -0,0 +1,78 @@
+package languageservice
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
+	yamlparser "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
+	utils "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"go.lsp.dev/protocol"
+)
+
+// Links the slugs of the orbs imported from the registry to their page. Orbs
+// that are not cached are fetched in the background, and only linked once
+// resolved
+func DocumentLinks(params protocol.DocumentLinkParams, cache *utils.Cache, context *utils.LsContext) ([]protocol.DocumentLink, error) {
+	doc, err := yamlparser.ParseFromUriWithCache(params.TextDocument.URI, cache, context)
+	if err != nil {
+		return nil, err
+	}
+
+	links := []protocol.DocumentLink{}
+	for _, orb := range doc.Orbs {
+		if orb.Url.IsLocal || orb.ValueNode == nil {
+			continue
+		}
+
+		orbInfo := cache.OrbCache.GetOrb(orb.Url.GetOrbID())
+		if orbInfo == nil {
+			go doc.GetOrFetchOrbInfo(orb, cache)
+			continue
+		}
+
+		version := orbInfo.RemoteInfo.Version
+		if version == "" {
+			version = orb.Url.Version
+		}
+
+		registryURL := getOrbRegistryURL(orb.Url.Name, version, context)
+		if registryURL == "" {
+			continue
+		}
+
+		slugRange, found := getOrbSlugRange(doc, orb)
+		if !found {
+			continue
+		}
+
+		links = append(links, protocol.DocumentLink{
+			Range:   slugRange,
+			Target:  protocol.DocumentURI(registryURL),
+			Tooltip: fmt.Sprintf("Open %s on the orb registry", ast.FormatOrbID(orb.Url.Name, version)),
+		})
+	}
+
+	sort.Slice(links, func(i, j int) bool {
+		return links[i].Range.Start.Line < links[j].Range.Start.Line
+	})
+
+	return links, nil
+}
+
+// The range of the value of an orb can include quotes and the version
+func getOrbSlugRange(doc yamlparser.YamlDocument, orb ast.Orb) (protocol.Range, bool) {
+	slug := strings.TrimSpace(orb.Url.Name)
+	index := strings.Index(doc.GetRawNodeText(orb.ValueNode), slug)
+	if slug == "" || index == -1 {
+		return protocol.Range{}, false
+	}
+
+	start := orb.ValueRange.Start
+	start.Character += uint32(index)
+	end := start
+	end.Character += uint32(len(slug))
+
+	return protocol.Range{Start: start, End: end}, true
+}
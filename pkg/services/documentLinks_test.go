@@ -0,0 +1,68 @@
+package languageservice
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/testHelpers"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+const documentLinksYaml = `version: 2.1
+
+orbs:
+  node: circleci/node@5
+  slack: "circleci/slack@4.12.0"
+  local:
+    commands:
+      hello:
+        steps:
+          - run: echo hello
+`
+
+func TestDocumentLinks(t *testing.T) {
+	cache := utils.CreateCache()
+	fileURI := uri.File("/tmp/documentLinks.yml")
+	cache.FileCache.SetFile(utils.CachedFile{
+		TextDocument: protocol.TextDocumentItem{URI: fileURI, Text: documentLinksYaml},
+	})
+	cache.OrbCache.SetOrb(&ast.OrbInfo{RemoteInfo: ast.RemoteOrbInfo{Version: "5.1.0"}}, "circleci/node@5")
+	cache.OrbCache.SetOrb(&ast.OrbInfo{RemoteInfo: ast.RemoteOrbInfo{Version: "4.12.0"}}, "circleci/slack@4.12.0")
+
+	params := protocol.DocumentLinkParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: fileURI},
+	}
+
+	links, err := DocumentLinks(params, cache, testHelpers.GetDefaultLsContext())
+	assert.Nil(t, err)
+	assert.Equal(t, []protocol.DocumentLink{
+		{
+			Range: protocol.Range{
+				Start: protocol.Position{Line: 3, Character: 8},
+				End:   protocol.Position{Line: 3, Character: 21},
+			},
+			Target:  "https://circleci.com/developer/orbs/orb/circleci/node?version=5.1.0",
+			Tooltip: "Open circleci/node@5.1.0 on the orb registry",
+		},
+		{
+			Range: protocol.Range{
+				Start: protocol.Position{Line: 4, Character: 10},
+				End:   protocol.Position{Line: 4, Character: 24},
+			},
+			Target:  "https://circleci.com/developer/orbs/orb/circleci/slack?version=4.12.0",
+			Tooltip: "Open circleci/slack@4.12.0 on the orb registry",
+		},
+	}, links)
+
+	t.Run("Should not link orbs of self-hosted instances", func(t *testing.T) {
+		context := testHelpers.GetDefaultLsContext()
+		context.Api.HostUrl = "https://circleci.example.com"
+
+		links, err := DocumentLinks(params, cache, context)
+		assert.Nil(t, err)
+		assert.Empty(t, links)
+	})
+}
@@ -24,19 +24,14 @@ func resolveCommandsSymbols(document *parser.YamlDocument) []protocol.DocumentSy
 		children = append(children, singleCommandSymbols(command))
 	}
 
-	commandsSymbols.Children = children
+	commandsSymbols.Children = sortSymbols(children)
 
 	return []protocol.DocumentSymbol{commandsSymbols}
 }
 
 func singleCommandSymbols(command ast.Command) protocol.DocumentSymbol {
-	symbol := protocol.DocumentSymbol{
-		Name:           command.Name,
-		Range:          command.Range,
-		SelectionRange: command.Range,
-		Detail:         command.Description,
-		Kind:           protocol.SymbolKind(CommandsSymbol),
-	}
+	symbol := namedSymbol(command.Range, command.NameRange, command.Name, CommandsSymbol)
+	symbol.Detail = command.Description
 
 	if len(command.Steps) > 0 {
 		stepChild := protocol.DocumentSymbol{
@@ -53,8 +48,8 @@ func singleCommandSymbols(command ast.Command) protocol.DocumentSymbol {
 	if len(command.Parameters) > 0 {
 		paramsChild := protocol.DocumentSymbol{
 			Name:           "Parameters",
-			Range:          command.StepsRange,
-			SelectionRange: command.StepsRange,
+			Range:          command.ParametersRange,
+			SelectionRange: command.ParametersRange,
 			Kind:           protocol.SymbolKind(ListSymbol),
 			Children:       parametersSymbols(command.Parameters),
 		}
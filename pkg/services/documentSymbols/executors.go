@@ -24,7 +24,7 @@ func resolveExecutorsSymbols(document *parser.YamlDocument) []protocol.DocumentS
 		children = append(children, singleExecutorSymbols(executor))
 	}
 
-	executorsSymbol.Children = children
+	executorsSymbol.Children = sortSymbols(children)
 
 	return []protocol.DocumentSymbol{executorsSymbol}
 }
@@ -56,14 +56,9 @@ func singleExecutorSymbols(executor ast.Executor) protocol.DocumentSymbol {
 		childrens = append(childrens, envsSymbols(envs))
 	}
 
-	symbol := protocol.DocumentSymbol{
-		Name:           executor.GetName(),
-		Range:          executor.GetRange(),
-		SelectionRange: executor.GetRange(),
-		Detail:         execType,
-		Kind:           protocol.SymbolKind(ExecutorsSymbol),
-		Children:       childrens,
-	}
+	symbol := namedSymbol(executor.GetRange(), executor.GetNameRange(), executor.GetName(), ExecutorsSymbol)
+	symbol.Detail = execType
+	symbol.Children = childrens
 
 	return symbol
 }
@@ -26,13 +26,13 @@ func resolveJobsSymbols(document *parser.YamlDocument) []protocol.DocumentSymbol
 		children = append(children, singleJobSymbols(job))
 	}
 
-	jobsSymbols.Children = children
+	jobsSymbols.Children = sortSymbols(children)
 
 	return []protocol.DocumentSymbol{jobsSymbols}
 }
 
 func singleJobSymbols(job ast.Job) protocol.DocumentSymbol {
-	jobSymbol := symbolFromRange(job.Range, job.Name, JobSymbol)
+	jobSymbol := namedSymbol(job.Range, job.NameRange, job.Name, JobSymbol)
 
 	if !utils.IsDefaultRange(job.ParametersRange) {
 		jobSymbol.Children = append(jobSymbol.Children, protocol.DocumentSymbol{
@@ -112,7 +112,7 @@ func parametersSymbols(parameters map[string]ast.Parameter) []protocol.DocumentS
 		symbols = append(symbols, parameterDefinitionSymbols(param))
 	}
 
-	return symbols
+	return sortSymbols(symbols)
 }
 
 func stepsSymbols(steps []ast.Step) []protocol.DocumentSymbol {
@@ -123,7 +123,7 @@ func stepsSymbols(steps []ast.Step) []protocol.DocumentSymbol {
 			Name:           step.GetName(),
 			Range:          step.GetRange(),
 			SelectionRange: step.GetRange(),
-			Kind:           protocol.SymbolKind(JobSymbol),
+			Kind:           protocol.SymbolKind(CommandsSymbol),
 		})
 	}
 
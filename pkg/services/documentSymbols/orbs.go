@@ -1,6 +1,7 @@
 package documentSymbols
 
 import (
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
 	"go.lsp.dev/protocol"
@@ -26,17 +27,25 @@ func resolveOrbSymbols(document *parser.YamlDocument) []protocol.DocumentSymbol
 				Name:           orb.Name,
 				Kind:           protocol.SymbolKind(OrbSymbol),
 				Range:          orb.Range,
-				SelectionRange: orb.Range,
+				SelectionRange: orbSelectionRange(orb),
 				Detail:         orb.Url.Version,
 			},
 		)
 	}
 
-	symbol.Children = children
+	symbol.Children = sortSymbols(children)
 
 	return []protocol.DocumentSymbol{symbol}
 }
 
+func orbSelectionRange(orb ast.Orb) protocol.Range {
+	if utils.IsDefaultRange(orb.NameRange) {
+		return orb.Range
+	}
+
+	return orb.NameRange
+}
+
 func symbolFromRange(rng protocol.Range, label string, symbol float64) protocol.DocumentSymbol {
 	return protocol.DocumentSymbol{
 		Name:           label,
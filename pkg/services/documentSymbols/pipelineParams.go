@@ -19,11 +19,12 @@ func resolvePipelineParametersSymbols(document *parser.YamlDocument) []protocol.
 	for _, param := range document.PipelineParameters {
 		children = append(children, parameterDefinitionSymbols(param))
 	}
+	children = sortSymbols(children)
 
 	return []protocol.DocumentSymbol{
 		{
 			Name:           "Pipeline Parameters",
-			Kind:           1,
+			Kind:           protocol.SymbolKind(PipelineParamSymbol),
 			Range:          document.PipelineParametersRange,
 			SelectionRange: document.PipelineParametersRange,
 			Children:       children,
@@ -43,11 +44,8 @@ func parameterDefinitionSymbols(parameter ast.Parameter) protocol.DocumentSymbol
 		detail = fmt.Sprintf("%s - %s", detail, parameter.GetDescription())
 	}
 
-	return protocol.DocumentSymbol{
-		Name:           parameter.GetName(),
-		Range:          parameter.GetRange(),
-		SelectionRange: parameter.GetRange(),
-		Detail:         detail,
-		Kind:           protocol.SymbolKind(PropertySymbol),
-	}
+	symbol := namedSymbol(parameter.GetRange(), parameter.GetNameRange(), parameter.GetName(), PropertySymbol)
+	symbol.Detail = detail
+
+	return symbol
 }
@@ -1,18 +1,21 @@
 package documentSymbols
 
 import (
+	"sort"
+
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
 	"go.lsp.dev/protocol"
 )
 
 const (
 	VersionSymbol       float64 = 2
 	OrbSymbol           float64 = 12
-	ExecutorsSymbol     float64 = 6
-	CommandsSymbol      float64 = 1
-	WorkflowsSymbol     float64 = 23
-	PipelineParamSymbol float64 = 1
-	JobSymbol           float64 = 1
+	ExecutorsSymbol     float64 = 5
+	CommandsSymbol      float64 = 6
+	WorkflowsSymbol     float64 = 3
+	PipelineParamSymbol float64 = 18
+	JobSymbol           float64 = 12
 	ListSymbol          float64 = 18
 
 	StringParameterSymbol float64 = 15
@@ -73,3 +76,25 @@ func SymbolsForDocument(document *parser.YamlDocument) []protocol.DocumentSymbol
 
 	return symbols
 }
+
+// Symbol of a named entry, such as a job, whose selection range is only its
+// name
+func namedSymbol(rng protocol.Range, nameRange protocol.Range, label string, symbol float64) protocol.DocumentSymbol {
+	res := symbolFromRange(rng, label, symbol)
+
+	if !utils.IsDefaultRange(nameRange) {
+		res.SelectionRange = nameRange
+	}
+
+	return res
+}
+
+// Entries are parsed into maps, keep the order of the document
+func sortSymbols(symbols []protocol.DocumentSymbol) []protocol.DocumentSymbol {
+	sort.SliceStable(symbols, func(i, j int) bool {
+		a, b := symbols[i].Range.Start, symbols[j].Range.Start
+		return a.Line < b.Line || (a.Line == b.Line && a.Character < b.Character)
+	})
+
+	return symbols
+}
@@ -26,13 +26,13 @@ func resolveWorkflowsSymbols(document *parser.YamlDocument) []protocol.DocumentS
 		children = append(children, singleWorkflowSymbols(workflow))
 	}
 
-	workflowsSymbols.Children = children
+	workflowsSymbols.Children = sortSymbols(children)
 
 	return []protocol.DocumentSymbol{workflowsSymbols}
 }
 
 func singleWorkflowSymbols(workflow ast.Workflow) protocol.DocumentSymbol {
-	symbol := symbolFromRange(workflow.Range, workflow.Name, WorkflowsSymbol)
+	symbol := namedSymbol(workflow.Range, workflow.NameRange, workflow.Name, WorkflowsSymbol)
 
 	if len(workflow.JobRefs) > 0 {
 		symbol.Children = append(symbol.Children, protocol.DocumentSymbol{
@@ -83,15 +83,19 @@ func workflowJobsSymbols(workflow ast.Workflow) []protocol.DocumentSymbol {
 			})
 		}
 
-		jobs = append(
-			jobs,
-			protocol.DocumentSymbol{
-				Name:           j.StepName,
-				Range:          j.JobRefRange,
-				SelectionRange: j.JobRefRange,
-				Children:       children,
-			},
-		)
+		// The name of the job ref is either its `name` parameter or the job
+		nameRange := j.StepNameRange
+		if utils.IsDefaultRange(nameRange) {
+			nameRange = j.JobNameRange
+		}
+
+		jobSymbol := namedSymbol(j.JobRefRange, nameRange, j.StepName, JobSymbol)
+		jobSymbol.Children = children
+		if j.StepName != j.JobName {
+			jobSymbol.Detail = j.JobName
+		}
+
+		jobs = append(jobs, jobSymbol)
 	}
 
 	return jobs
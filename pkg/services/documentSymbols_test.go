@@ -0,0 +1,118 @@
+package languageservice
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/testHelpers"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+const documentSymbolsYaml = `version: 2.1
+
+orbs:
+  node: circleci/node@5.0.0
+
+parameters:
+  deploy:
+    type: boolean
+    default: false
+
+commands:
+  greet:
+    steps:
+      - run: echo hello
+
+executors:
+  default:
+    docker:
+      - image: cimg/base:2023.01
+
+jobs:
+  test:
+    executor: default
+    steps:
+      - greet
+  build:
+    executor: default
+    steps:
+      - checkout
+
+workflows:
+  main:
+    jobs:
+      - build
+      - test:
+          name: unit-tests
+          requires:
+            - build
+`
+
+func TestDocumentSymbols(t *testing.T) {
+	cache := utils.CreateCache()
+	fileURI := uri.File("/tmp/symbols.yml")
+	cache.FileCache.SetFile(utils.CachedFile{
+		TextDocument: protocol.TextDocumentItem{URI: fileURI, Text: documentSymbolsYaml},
+	})
+
+	symbols, err := DocumentSymbols(protocol.DocumentSymbolParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: fileURI},
+	}, cache, testHelpers.GetDefaultLsContext())
+	assert.Nil(t, err)
+
+	byName := map[string]protocol.DocumentSymbol{}
+	for _, symbol := range symbols {
+		byName[symbol.Name] = symbol
+	}
+
+	rng := func(line, start, end uint32) protocol.Range {
+		return protocol.Range{
+			Start: protocol.Position{Line: line, Character: start},
+			End:   protocol.Position{Line: line, Character: end},
+		}
+	}
+
+	t.Run("Should list the entries in the order of the document", func(t *testing.T) {
+		jobs := byName["Jobs"].Children
+		assert.Len(t, jobs, 2)
+		assert.Equal(t, "test", jobs[0].Name)
+		assert.Equal(t, "build", jobs[1].Name)
+		assert.Equal(t, protocol.SymbolKindFunction, jobs[0].Kind)
+	})
+
+	t.Run("Should select only the name of the entries", func(t *testing.T) {
+		assert.Equal(t, rng(21, 2, 6), byName["Jobs"].Children[0].SelectionRange)
+		assert.Equal(t, rng(11, 2, 7), byName["Commands"].Children[0].SelectionRange)
+		assert.Equal(t, rng(16, 2, 9), byName["Executors"].Children[0].SelectionRange)
+		assert.Equal(t, rng(3, 2, 6), byName["Orbs"].Children[0].SelectionRange)
+		assert.Equal(t, rng(6, 2, 8), byName["Pipeline Parameters"].Children[0].SelectionRange)
+	})
+
+	t.Run("Should list the jobs of the workflows", func(t *testing.T) {
+		workflow := byName["Workflows"].Children[0]
+		assert.Equal(t, "main", workflow.Name)
+		assert.Equal(t, protocol.SymbolKindNamespace, workflow.Kind)
+		assert.Equal(t, rng(31, 2, 6), workflow.SelectionRange)
+
+		jobRefs := workflow.Children[0].Children
+		assert.Len(t, jobRefs, 2)
+		assert.Equal(t, "build", jobRefs[0].Name)
+		assert.Equal(t, "unit-tests", jobRefs[1].Name)
+		assert.Equal(t, "test", jobRefs[1].Detail)
+		assert.Equal(t, rng(35, 16, 26), jobRefs[1].SelectionRange)
+	})
+
+	t.Run("Should have selection ranges within the ranges", func(t *testing.T) {
+		var check func(symbols []protocol.DocumentSymbol)
+		check = func(symbols []protocol.DocumentSymbol) {
+			for _, symbol := range symbols {
+				assert.True(t, utils.PosInRange(symbol.Range, symbol.SelectionRange.Start), symbol.Name)
+				assert.True(t, utils.PosInRange(symbol.Range, symbol.SelectionRange.End), symbol.Name)
+				check(symbol.Children)
+			}
+		}
+		check(symbols)
+	})
+}
@@ -0,0 +1,83 @@
+package languageservice
+
+import (
+	yamlparser "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	sitter "github.com/smacker/go-tree-sitter"
+	"go.lsp.dev/protocol"
+)
+
+// Sections whose entries can be folded one by one
+var foldableEntriesSections = []string{"jobs", "commands", "workflows", "executors"}
+
+// Keys that can be folded wherever they are
+var foldableKeys = []string{"steps", "pre-steps", "post-steps", "parameters"}
+
+func FoldingRanges(params protocol.FoldingRangeParams, cache *utils.Cache, context *utils.LsContext) ([]protocol.FoldingRange, error) {
+	doc, err := yamlparser.ParseFromUriWithCache(params.TextDocument.URI, cache, context)
+	if err != nil {
+		return nil, err
+	}
+
+	ranges := []protocol.FoldingRange{}
+	getFoldingRanges(&doc, doc.RootNode, []string{}, &ranges)
+
+	return ranges, nil
+}
+
+// Walk through the mapping pairs, block or flow ones, keeping track of the
+// keys leading to them
+func getFoldingRanges(doc *yamlparser.YamlDocument, node *sitter.Node, path []string, ranges *[]protocol.FoldingRange) {
+	if node == nil {
+		return
+	}
+
+	if node.Type() == "block_mapping_pair" || node.Type() == "flow_pair" {
+		key := doc.GetNodeText(node.ChildByFieldName("key"))
+
+		if isFoldable(path, key) {
+			if rng, ok := getNodeFoldingRange(node); ok {
+				*ranges = append(*ranges, rng)
+			}
+		}
+
+		getFoldingRanges(doc, node.ChildByFieldName("value"), append(path, key), ranges)
+		return
+	}
+
+	for i := 0; i < int(node.ChildCount()); i++ {
+		getFoldingRanges(doc, node.Child(i), path, ranges)
+	}
+}
+
+func isFoldable(path []string, key string) bool {
+	if len(path) == 0 {
+		return true
+	}
+
+	if len(path) == 1 && utils.FindInArray(foldableEntriesSections, path[0]) != -1 {
+		return true
+	}
+
+	return utils.FindInArray(foldableKeys, key) != -1
+}
+
+func getNodeFoldingRange(node *sitter.Node) (protocol.FoldingRange, bool) {
+	start := node.StartPoint()
+	end := node.EndPoint()
+
+	// Block scalars end at the beginning of the line following them
+	if end.Column == 0 && end.Row > start.Row {
+		end.Row--
+	}
+
+	if end.Row <= start.Row {
+		return protocol.FoldingRange{}, false
+	}
+
+	return protocol.FoldingRange{
+		StartLine: start.Row,
+		EndLine:   end.Row,
+		Kind:      protocol.RegionFoldingRange,
+	}, true
+}
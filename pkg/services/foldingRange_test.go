@@ -0,0 +1,78 @@
+package languageservice
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/testHelpers"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+const foldingRangeYaml = `version: 2.1
+
+executors:
+  default: &default-executor
+    docker:
+      - image: cimg/base:2023.01
+
+commands:
+  greet: { steps: [{ run: echo hello }] }
+
+jobs:
+  build:
+    executor: default
+    parameters:
+      version:
+        type: string
+    steps:
+      - checkout
+      - run:
+          command: |
+            make build
+            make test
+
+  test: {
+    executor: default,
+    steps: [checkout]
+    }
+
+workflows:
+  main:
+    jobs: [build, test]
+`
+
+func TestFoldingRanges(t *testing.T) {
+	cache := utils.CreateCache()
+	fileURI := uri.File("/tmp/folding.yml")
+	cache.FileCache.SetFile(utils.CachedFile{
+		TextDocument: protocol.TextDocumentItem{URI: fileURI, Text: foldingRangeYaml},
+	})
+
+	ranges, err := FoldingRanges(protocol.FoldingRangeParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: fileURI},
+		},
+	}, cache, testHelpers.GetDefaultLsContext())
+	assert.Nil(t, err)
+
+	lines := [][2]uint32{}
+	for _, rng := range ranges {
+		assert.Equal(t, protocol.RegionFoldingRange, rng.Kind)
+		lines = append(lines, [2]uint32{rng.StartLine, rng.EndLine})
+	}
+
+	assert.Equal(t, [][2]uint32{
+		{2, 5},   // executors
+		{3, 5},   // default executor
+		{7, 8},   // commands
+		{10, 26}, // jobs
+		{11, 21}, // build
+		{13, 15}, // build parameters
+		{16, 21}, // build steps
+		{23, 26}, // test, in flow style
+		{28, 30}, // workflows
+		{29, 30}, // main workflow
+	}, lines)
+}
@@ -0,0 +1,421 @@
+package languageservice
+
+import (
+	"sort"
+	"strings"
+
+	yamlparser "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	sitter "github.com/smacker/go-tree-sitter"
+	"go.lsp.dev/protocol"
+)
+
+const formattingIndentation = 2
+
+// Conventional order of the top-level keys, the other keys stay right after
+// the one they follow
+var topLevelKeysOrder = []string{"version", "setup", "orbs", "parameters", "executors", "commands", "jobs", "workflows"}
+
+// Indents the mappings and the sequences with two spaces and orders the
+// top-level keys. Scalars, block scalars included, are shifted along with
+// their key so their content is not modified
+func Formatting(params protocol.DocumentFormattingParams, cache *utils.Cache, context *utils.LsContext) ([]protocol.TextEdit, error) {
+	doc, err := yamlparser.ParseFromUriWithCache(params.TextDocument.URI, cache, context)
+	if err != nil {
+		return nil, err
+	}
+
+	formatter, rootMapping, ok := newDocumentFormatter(&doc)
+	if !ok {
+		return []protocol.TextEdit{}, nil
+	}
+
+	lines := formatter.lines
+	formatter.indentBlock(rootMapping, 0)
+	formatted := formatter.reindentLines()
+
+	if !context.DisableFormatterKeyOrdering {
+		if reordered, ok := formatter.orderTopLevelKeys(rootMapping, formatted); ok {
+			return getReplacementEdits(lines, reordered), nil
+		}
+	}
+
+	return getIndentationEdits(lines, formatted), nil
+}
+
+// Indents the items of the selection, expanded to whole mapping pairs and
+// sequence items. They are placed relatively to the items around them, which
+// are left untouched, and the top-level keys are not ordered
+func RangeFormatting(params protocol.DocumentRangeFormattingParams, cache *utils.Cache, context *utils.LsContext) ([]protocol.TextEdit, error) {
+	doc, err := yamlparser.ParseFromUriWithCache(params.TextDocument.URI, cache, context)
+	if err != nil {
+		return nil, err
+	}
+
+	formatter, rootMapping, ok := newDocumentFormatter(&doc)
+	if !ok {
+		return []protocol.TextEdit{}, nil
+	}
+
+	startLine, endLine := params.Range.Start.Line, params.Range.End.Line
+	if params.Range.End.Character == 0 && endLine > startLine {
+		endLine--
+	}
+	formatter.firstRow, formatter.lastRow = formatter.expandToItems(rootMapping, startLine, endLine)
+
+	formatter.indentBlock(rootMapping, 0)
+	return getIndentationEdits(formatter.lines, formatter.reindentLines()), nil
+}
+
+type documentFormatter struct {
+	doc   *yamlparser.YamlDocument
+	lines []string
+
+	// Lines to format, the items starting outside of them keep their column
+	firstRow, lastRow uint32
+
+	// Number of spaces to add to, or remove from, each line
+	deltas []int
+	// Lines starting a mapping pair or a sequence item
+	itemStarts   []bool
+	commentLines []bool
+}
+
+func newDocumentFormatter(doc *yamlparser.YamlDocument) (*documentFormatter, *sitter.Node, bool) {
+	// Guessing the structure of an invalid document could break it further
+	rootMapping := yamlparser.GetBlockMappingNode(doc.RootNode)
+	if rootMapping == nil || doc.RootNode.HasError() {
+		return nil, nil, false
+	}
+
+	lines := strings.Split(string(doc.Content), "\n")
+	formatter := &documentFormatter{
+		doc:          doc,
+		lines:        lines,
+		firstRow:     0,
+		lastRow:      uint32(len(lines) - 1),
+		deltas:       make([]int, len(lines)),
+		itemStarts:   make([]bool, len(lines)),
+		commentLines: make([]bool, len(lines)),
+	}
+	formatter.findCommentLines(doc.RootNode)
+
+	return formatter, rootMapping, true
+}
+
+// Places the items of a block mapping or sequence at the given column, the
+// whole item being shifted by the same amount before its own children are
+// placed
+func (formatter *documentFormatter) indentBlock(block *sitter.Node, column int) {
+	// Items of the block that are not formatted can not be moved, the
+	// formatted ones have to stay aligned with them
+	for i := 0; i < int(block.NamedChildCount()); i++ {
+		item := block.NamedChild(i)
+		if formatter.startsLine(item.StartPoint()) && !formatter.isFormatted(item.StartPoint().Row) {
+			column = int(block.StartPoint().Column) + formatter.deltas[block.StartPoint().Row]
+			break
+		}
+	}
+
+	for i := 0; i < int(block.NamedChildCount()); i++ {
+		item := block.NamedChild(i)
+		if item.Type() != "block_mapping_pair" && item.Type() != "block_sequence_item" {
+			continue
+		}
+
+		start := item.StartPoint()
+		delta := formatter.deltas[start.Row]
+		if formatter.startsLine(start) && formatter.isFormatted(start.Row) {
+			delta = column - int(start.Column)
+			formatter.itemStarts[start.Row] = true
+			for row := start.Row; row <= getNodeLastRow(item); row++ {
+				formatter.deltas[row] = delta
+			}
+		}
+
+		child := getItemBlock(item)
+		if child == nil {
+			continue
+		}
+
+		childColumn := int(start.Column) + delta + formattingIndentation
+		// Mapping written right after the dash of a sequence item
+		if child.StartPoint().Row == start.Row {
+			childColumn = int(child.StartPoint().Column) + delta
+		}
+		formatter.indentBlock(child, childColumn)
+	}
+}
+
+func (formatter *documentFormatter) findCommentLines(node *sitter.Node) {
+	if node.Type() == "comment" && formatter.startsLine(node.StartPoint()) {
+		formatter.commentLines[node.StartPoint().Row] = true
+	}
+
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		formatter.findCommentLines(node.NamedChild(i))
+	}
+}
+
+// Comments are aligned on the item they precede when they were aligned
+// with it, and otherwise follow the line above them
+func (formatter *documentFormatter) reindentLines() []string {
+	formatted := make([]string, len(formatter.lines))
+	lastDelta := 0
+
+	for row, line := range formatter.lines {
+		formatted[row] = line
+		if !formatter.isFormatted(uint32(row)) {
+			continue
+		}
+
+		delta := formatter.deltas[row]
+
+		if formatter.commentLines[row] {
+			delta = lastDelta
+			if next, ok := formatter.getNextItemRow(row); ok && getIndentation(formatter.lines[next]) == getIndentation(line) {
+				delta = formatter.deltas[next]
+			}
+		} else if strings.TrimSpace(line) != "" {
+			lastDelta = delta
+		}
+
+		if strings.TrimSpace(line) != "" && delta != 0 {
+			indentation := max(0, getIndentation(line)+delta)
+			formatted[row] = strings.Repeat(" ", indentation) + strings.TrimLeft(line, " ")
+		}
+	}
+
+	return formatted
+}
+
+// Moves the top-level keys, along with the comments right above them, in the
+// conventional order. The blank lines between the keys stay where they are,
+// to keep the grouping of the file
+func (formatter *documentFormatter) orderTopLevelKeys(rootMapping *sitter.Node, lines []string) ([]string, bool) {
+	// Moving an anchor after its aliases would make the document invalid
+	if hasAlias(rootMapping) {
+		return nil, false
+	}
+
+	type chunk struct {
+		start, end uint32
+		rank       int
+	}
+
+	chunks := []chunk{}
+	rank := -1
+	previousEnd := -1
+	for i := 0; i < int(rootMapping.NamedChildCount()); i++ {
+		pair := rootMapping.NamedChild(i)
+		if pair.Type() != "block_mapping_pair" {
+			continue
+		}
+
+		key := formatter.doc.GetNodeText(pair.ChildByFieldName("key"))
+		if index := utils.FindInArray(topLevelKeysOrder, key); index != -1 {
+			rank = index
+		}
+
+		start := pair.StartPoint().Row
+		for row := int(start) - 1; row > previousEnd && (formatter.commentLines[row] || strings.TrimSpace(lines[row]) == ""); row-- {
+			if formatter.commentLines[row] {
+				start = uint32(row)
+			}
+		}
+		// Comments at the top level after the pair introduce the next key
+		end := getNodeLastRow(pair)
+		for end > pair.StartPoint().Row && (strings.TrimSpace(lines[end]) == "" || formatter.commentLines[end] && getIndentation(lines[end]) == 0) {
+			end--
+		}
+
+		chunks = append(chunks, chunk{start: start, end: end, rank: rank})
+		previousEnd = int(end)
+	}
+
+	if len(chunks) < 2 {
+		return nil, false
+	}
+
+	// Lines between two keys must be blank, they are kept untouched
+	for i := 1; i < len(chunks); i++ {
+		for row := chunks[i-1].end + 1; row < chunks[i].start; row++ {
+			if strings.TrimSpace(lines[row]) != "" {
+				return nil, false
+			}
+		}
+	}
+
+	ordered := append([]chunk{}, chunks...)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].rank < ordered[j].rank })
+	if sort.SliceIsSorted(chunks, func(i, j int) bool { return chunks[i].rank < chunks[j].rank }) {
+		return nil, false
+	}
+
+	reordered := append([]string{}, lines[:chunks[0].start]...)
+	for i, chunk := range ordered {
+		if i > 0 {
+			reordered = append(reordered, lines[chunks[i-1].end+1:chunks[i].start]...)
+		}
+		reordered = append(reordered, lines[chunk.start:chunk.end+1]...)
+	}
+	reordered = append(reordered, lines[chunks[len(chunks)-1].end+1:]...)
+
+	return reordered, true
+}
+
+// Starts the lines to format at the deepest item containing the first one,
+// and ends them after the last line of the items they contain
+func (formatter *documentFormatter) expandToItems(rootMapping *sitter.Node, firstRow uint32, lastRow uint32) (uint32, uint32) {
+	items := [][2]uint32{}
+	formatter.findItems(rootMapping, &items)
+
+	selectionStart := firstRow
+	for _, item := range items {
+		if item[0] <= selectionStart && selectionStart <= item[1] {
+			firstRow = item[0]
+		}
+	}
+
+	// Items are sorted by starting line, the ones ending after the lines can
+	// only be found after the ones they contain
+	for _, item := range items {
+		if firstRow <= item[0] && item[0] <= lastRow {
+			lastRow = max(lastRow, item[1])
+		}
+	}
+
+	return firstRow, min(lastRow, uint32(len(formatter.lines)-1))
+}
+
+// First and last lines of the mapping pairs and sequence items in the order
+// of the document
+func (formatter *documentFormatter) findItems(node *sitter.Node, items *[][2]uint32) {
+	if (node.Type() == "block_mapping_pair" || node.Type() == "block_sequence_item") && formatter.startsLine(node.StartPoint()) {
+		*items = append(*items, [2]uint32{node.StartPoint().Row, getNodeLastRow(node)})
+	}
+
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		formatter.findItems(node.NamedChild(i), items)
+	}
+}
+
+func (formatter *documentFormatter) isFormatted(row uint32) bool {
+	return formatter.firstRow <= row && row <= formatter.lastRow
+}
+
+func (formatter *documentFormatter) startsLine(point sitter.Point) bool {
+	line := formatter.lines[point.Row]
+	return int(point.Column) <= len(line) && strings.TrimSpace(line[:point.Column]) == ""
+}
+
+func (formatter *documentFormatter) getNextItemRow(row int) (int, bool) {
+	for next := row + 1; next < len(formatter.lines); next++ {
+		if formatter.itemStarts[next] {
+			return next, true
+		}
+		if !formatter.commentLines[next] && strings.TrimSpace(formatter.lines[next]) != "" {
+			return 0, false
+		}
+	}
+
+	return 0, false
+}
+
+// The block mapping or sequence of a mapping pair or of a sequence item
+func getItemBlock(item *sitter.Node) *sitter.Node {
+	value := item.ChildByFieldName("value")
+	if item.Type() == "block_sequence_item" {
+		value = yamlparser.GetChildOfType(item, "block_node")
+	}
+	if value == nil || value.Type() != "block_node" {
+		return nil
+	}
+
+	if mapping := yamlparser.GetChildOfType(value, "block_mapping"); mapping != nil {
+		return mapping
+	}
+	return yamlparser.GetChildOfType(value, "block_sequence")
+}
+
+func hasAlias(node *sitter.Node) bool {
+	if node.Type() == "alias" {
+		return true
+	}
+
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if hasAlias(node.NamedChild(i)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Nodes such as block scalars end at the beginning of the line following
+// their content
+func getNodeLastRow(node *sitter.Node) uint32 {
+	end := node.EndPoint()
+	if end.Column == 0 && end.Row > node.StartPoint().Row {
+		return end.Row - 1
+	}
+	return end.Row
+}
+
+func getIndentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// Only replaces the indentation of the lines that changed
+func getIndentationEdits(lines []string, formatted []string) []protocol.TextEdit {
+	edits := []protocol.TextEdit{}
+
+	for row := range lines {
+		if lines[row] == formatted[row] {
+			continue
+		}
+
+		edits = append(edits, protocol.TextEdit{
+			Range: protocol.Range{
+				Start: protocol.Position{Line: uint32(row), Character: 0},
+				End:   protocol.Position{Line: uint32(row), Character: uint32(getIndentation(lines[row]))},
+			},
+			NewText: strings.Repeat(" ", getIndentation(formatted[row])),
+		})
+	}
+
+	return edits
+}
+
+// Replaces the lines between the unchanged beginning and end of the document
+func getReplacementEdits(lines []string, formatted []string) []protocol.TextEdit {
+	prefix := 0
+	for prefix < len(lines) && prefix < len(formatted) && lines[prefix] == formatted[prefix] {
+		prefix++
+	}
+	if prefix == len(lines) && prefix == len(formatted) {
+		return []protocol.TextEdit{}
+	}
+
+	suffix := 0
+	for suffix < len(lines)-prefix && suffix < len(formatted)-prefix &&
+		lines[len(lines)-1-suffix] == formatted[len(formatted)-1-suffix] {
+		suffix++
+	}
+
+	newText := strings.Join(formatted[prefix:len(formatted)-suffix], "\n")
+	end := protocol.Position{Line: uint32(len(lines) - suffix), Character: 0}
+	if suffix > 0 {
+		newText += "\n"
+	} else {
+		end = protocol.Position{Line: uint32(len(lines) - 1), Character: uint32(len(lines[len(lines)-1]))}
+	}
+
+	return []protocol.TextEdit{{
+		Range: protocol.Range{
+			Start: protocol.Position{Line: uint32(prefix), Character: 0},
+			End:   end,
+		},
+		NewText: newText,
+	}}
+}
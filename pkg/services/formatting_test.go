@@ -0,0 +1,219 @@
+package languageservice
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/testHelpers"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+func TestFormatting(t *testing.T) {
+	fileURI := uri.File("/tmp/formatting.yml")
+
+	format := func(t *testing.T, content string, context *utils.LsContext) (string, []protocol.TextEdit) {
+		cache := utils.CreateCache()
+		cache.FileCache.SetFile(utils.CachedFile{
+			TextDocument: protocol.TextDocumentItem{URI: fileURI, Text: content},
+		})
+
+		edits, err := Formatting(protocol.DocumentFormattingParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: fileURI},
+		}, cache, context)
+		assert.Nil(t, err)
+		return applyTextEdits(content, edits), edits
+	}
+
+	t.Run("Should indent with two spaces and keep the block scalars intact", func(t *testing.T) {
+		content := `version: 2.1
+
+jobs:
+    build:
+        docker:
+        - image: cimg/base:2023.01
+        steps:
+            # Get the code
+            - checkout
+            -   run:
+                    name: Test
+                    command: |
+                        make test
+                          --verbose
+            - run: echo "done"
+`
+		formatted, edits := format(t, content, testHelpers.GetDefaultLsContext())
+
+		assert.Equal(t, `version: 2.1
+
+jobs:
+  build:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      # Get the code
+      - checkout
+      -   run:
+            name: Test
+            command: |
+                make test
+                  --verbose
+      - run: echo "done"
+`, formatted)
+		assert.Len(t, edits, 12)
+	})
+
+	t.Run("Should order the top-level keys with their comments", func(t *testing.T) {
+		content := `workflows:
+  main:
+    jobs:
+      - build
+
+# Jobs of the project
+jobs:
+  build:
+    machine: true
+    steps:
+      - checkout
+references:
+  image: cimg/base:2023.01
+
+version: 2.1
+`
+		formatted, edits := format(t, content, testHelpers.GetDefaultLsContext())
+
+		assert.Equal(t, `version: 2.1
+
+# Jobs of the project
+jobs:
+  build:
+    machine: true
+    steps:
+      - checkout
+references:
+  image: cimg/base:2023.01
+
+workflows:
+  main:
+    jobs:
+      - build
+`, formatted)
+		assert.Len(t, edits, 1)
+	})
+
+	t.Run("Should keep the order of the keys when disabled", func(t *testing.T) {
+		content := "jobs:\n    build:\n        machine: true\nversion: 2.1\n"
+		context := testHelpers.GetDefaultLsContext()
+		context.DisableFormatterKeyOrdering = true
+
+		formatted, _ := format(t, content, context)
+		assert.Equal(t, "jobs:\n  build:\n    machine: true\nversion: 2.1\n", formatted)
+	})
+
+	t.Run("Should not order the keys of documents using aliases", func(t *testing.T) {
+		content := "jobs:\n  build:\n    docker: *image\nreferences:\n  image: &image\n    - image: cimg/base:2023.01\nversion: 2.1\n"
+		formatted, edits := format(t, content, testHelpers.GetDefaultLsContext())
+		assert.Equal(t, content, formatted)
+		assert.Empty(t, edits)
+	})
+
+	t.Run("Should not modify formatted or invalid documents", func(t *testing.T) {
+		for _, content := range []string{
+			"version: 2.1\n\njobs:\n  build:\n    machine: true\n",
+			"version: 2.1\njobs:\n    build: [\n",
+		} {
+			_, edits := format(t, content, testHelpers.GetDefaultLsContext())
+			assert.Empty(t, edits)
+		}
+	})
+}
+
+func TestRangeFormatting(t *testing.T) {
+	fileURI := uri.File("/tmp/rangeFormatting.yml")
+	content := `version: 2.1
+
+jobs:
+    build:
+        machine: true
+        steps:
+              - checkout
+              - run:
+                      name: Test
+                      command: make test
+    deploy:
+        machine: true
+workflows:
+    main:
+        jobs:
+            - build
+`
+
+	format := func(t *testing.T, rng protocol.Range) (string, []protocol.TextEdit) {
+		cache := utils.CreateCache()
+		cache.FileCache.SetFile(utils.CachedFile{
+			TextDocument: protocol.TextDocumentItem{URI: fileURI, Text: content},
+		})
+
+		edits, err := RangeFormatting(protocol.DocumentRangeFormattingParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: fileURI},
+			Range:        rng,
+		}, cache, testHelpers.GetDefaultLsContext())
+		assert.Nil(t, err)
+		return applyTextEdits(content, edits), edits
+	}
+
+	t.Run("Should format the selected items relatively to their parent", func(t *testing.T) {
+		formatted, edits := format(t, protocol.Range{
+			Start: protocol.Position{Line: 6, Character: 16},
+			End:   protocol.Position{Line: 8, Character: 2},
+		})
+
+		assert.Equal(t, `version: 2.1
+
+jobs:
+    build:
+        machine: true
+        steps:
+          - checkout
+          - run:
+              name: Test
+              command: make test
+    deploy:
+        machine: true
+workflows:
+    main:
+        jobs:
+            - build
+`, formatted)
+
+		for _, edit := range edits {
+			assert.True(t, edit.Range.Start.Line >= 6 && edit.Range.End.Line <= 9)
+		}
+	})
+
+	t.Run("Should expand the selection to the whole item and keep it aligned with its siblings", func(t *testing.T) {
+		formatted, _ := format(t, protocol.Range{
+			Start: protocol.Position{Line: 3, Character: 0},
+			End:   protocol.Position{Line: 4, Character: 0},
+		})
+
+		assert.Equal(t, `version: 2.1
+
+jobs:
+    build:
+      machine: true
+      steps:
+        - checkout
+        - run:
+            name: Test
+            command: make test
+    deploy:
+        machine: true
+workflows:
+    main:
+        jobs:
+            - build
+`, formatted)
+	})
+}
@@ -2,8 +2,12 @@ package languageservice
 
 import (
 	"fmt"
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
 
 	yamlparser "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/services/hover"
 	utils "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
 	sitter "github.com/smacker/go-tree-sitter"
 	"go.lsp.dev/protocol"
@@ -24,9 +28,87 @@ func Hover(params protocol.HoverParams, cache *utils.Cache, context *utils.LsCon
 		}, nil
 	}
 
+	if name := getOrbEntityNameAtPosition(doc, params.Position); name != "" {
+		if value := hover.HoverOrbEntity(doc, name, cache); value != "" {
+			return protocol.Hover{
+				Contents: protocol.MarkupContent{
+					Kind:  protocol.Markdown,
+					Value: value,
+				},
+			}, nil
+		}
+	}
+
+	if value := hover.HoverBuiltInStep(doc, params.Position); value != "" {
+		return protocol.Hover{
+			Contents: protocol.MarkupContent{
+				Kind:  protocol.Markdown,
+				Value: value,
+			},
+		}, nil
+	}
+
+	if value := hover.HoverEnvironment(doc, params.Position, cache, context); value != "" {
+		return protocol.Hover{
+			Contents: protocol.MarkupContent{
+				Kind:  protocol.Markdown,
+				Value: value,
+			},
+		}, nil
+	}
+
 	return protocol.Hover{}, fmt.Errorf("No hover")
 }
 
+// Returns the name of the orb job or command referenced at the given position,
+// either as a workflow job or as a step of a job or command
+func getOrbEntityNameAtPosition(doc yamlparser.YamlDocument, pos protocol.Position) string {
+	for _, workflow := range doc.Workflows {
+		for _, jobRef := range workflow.JobRefs {
+			if utils.PosInRange(jobRef.JobNameRange, pos) && strings.Contains(jobRef.JobName, "/") {
+				return jobRef.JobName
+			}
+		}
+	}
+
+	for _, job := range doc.Jobs {
+		if name := getOrbStepNameAtPosition(job.Steps, pos); name != "" {
+			return name
+		}
+	}
+
+	for _, command := range doc.Commands {
+		if name := getOrbStepNameAtPosition(command.Steps, pos); name != "" {
+			return name
+		}
+	}
+
+	return ""
+}
+
+func getOrbStepNameAtPosition(steps []ast.Step, pos protocol.Position) string {
+	for _, step := range steps {
+		namedStep, ok := step.(ast.NamedStep)
+		if !ok || !strings.Contains(namedStep.Name, "/") {
+			continue
+		}
+
+		// The range of the step includes its parameters, only its name is hovered
+		nameRange := protocol.Range{
+			Start: namedStep.Range.Start,
+			End: protocol.Position{
+				Line:      namedStep.Range.Start.Line,
+				Character: namedStep.Range.Start.Character + uint32(len(namedStep.Name)),
+			},
+		}
+		if utils.PosInRange(nameRange, pos) {
+			return namedStep.Name
+		}
+	}
+
+	return ""
+}
+
 func GetPathFromVisitedNodes(visitedNodes []*sitter.Node, doc yamlparser.YamlDocument) []string {
 	var path []string
 	if len(visitedNodes) == 0 {
@@ -0,0 +1,81 @@
+package hover
+
+import (
+	"fmt"
+	"strings"
+
+	yamlparser "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"go.lsp.dev/protocol"
+)
+
+// Render the documentation of the built-in step whose name is at the given
+// position, such as `checkout` or `save_cache`, from the configuration
+// schema. Returns an empty string when there is none
+func HoverBuiltInStep(doc yamlparser.YamlDocument, pos protocol.Position) string {
+	_, visitedNodes, err := utils.NodeAtPos(doc.RootNode, pos)
+	if err != nil {
+		return ""
+	}
+
+	path := []string{}
+	for _, node := range visitedNodes {
+		switch node.Type() {
+		case "block_mapping_pair", "flow_pair":
+			keyNode, _ := doc.GetKeyValueNodes(node)
+			if keyNode == nil {
+				return ""
+			}
+
+			path = append(path, doc.GetNodeText(keyNode))
+			if utils.PosInRange(doc.NodeToRange(keyNode), pos) {
+				return builtInStepDocumentation(utils.GetBuiltInStepAtPath(path))
+			}
+
+		case "block_sequence_item", "flow_sequence":
+			path = append(path, "-")
+		}
+	}
+
+	// Steps written without keys, such as `- checkout`
+	if len(path) > 0 && path[len(path)-1] == "-" {
+		name := doc.GetNodeText(visitedNodes[len(visitedNodes)-1])
+		return builtInStepDocumentation(utils.GetBuiltInStepAtPath(append(path, name)))
+	}
+
+	return ""
+}
+
+func builtInStepDocumentation(step *utils.ConfigKey) string {
+	if step == nil {
+		return ""
+	}
+
+	res := fmt.Sprintf("`%s` - Built-in step\n\n", step.Name)
+
+	if step.Description != "" {
+		res += step.Description + "\n\n"
+	}
+
+	for _, example := range step.Examples {
+		res += "```yaml\n" + example + "\n```\n\n"
+	}
+
+	if len(step.Keys) == 0 {
+		return res
+	}
+
+	res += "Keys:\n\n"
+	for _, key := range step.Keys {
+		res += fmt.Sprintf("- `%s` (%s)", key.Name, key.Kind)
+		if key.Description != "" {
+			res += ": " + key.Description
+		}
+		if len(key.Conflicts) > 0 {
+			res += fmt.Sprintf(", can not be used with `%s`", strings.Join(key.Conflicts, "`, `"))
+		}
+		res += "\n"
+	}
+
+	return res
+}
@@ -0,0 +1,154 @@
+package hover
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
+	yamlparser "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"go.lsp.dev/protocol"
+)
+
+type environmentSource struct {
+	name      string
+	variables map[string]string
+
+	// Values of the contexts and of the project are secrets, only the names
+	// of their variables are known
+	secret bool
+}
+
+// Render the environment a `run` step runs with, or the one of its job when
+// hovering the `environment` key of the job. The sources are listed from the
+// highest precedence: the step, the job, the executor, the contexts and the
+// project. Returns an empty string when nothing is hovered or nothing is set
+func HoverEnvironment(doc yamlparser.YamlDocument, pos protocol.Position, cache *utils.Cache, context *utils.LsContext) string {
+	for _, job := range doc.Jobs {
+		if utils.PosInRange(job.EnvironmentRange, pos) {
+			return environmentDocumentation("job `"+job.Name+"`", getEnvironmentSources(doc, job, nil, cache, context))
+		}
+
+		for _, step := range job.Steps {
+			run, ok := step.(ast.Run)
+			if ok && utils.PosInRange(run.Range, pos) {
+				return environmentDocumentation("this step", getEnvironmentSources(doc, job, &run, cache, context))
+			}
+		}
+	}
+
+	return ""
+}
+
+func getEnvironmentSources(doc yamlparser.YamlDocument, job ast.Job, run *ast.Run, cache *utils.Cache, context *utils.LsContext) []environmentSource {
+	sources := []environmentSource{}
+
+	if run != nil {
+		sources = append(sources, environmentSource{name: "step", variables: run.Environment})
+	}
+
+	sources = append(sources, environmentSource{name: "job `" + job.Name + "`", variables: job.Environment})
+
+	if executor, ok := doc.Executors[job.Executor]; ok {
+		variables := map[string]string{}
+		// The keys of named executors are parsed without their values
+		for _, key := range executor.GetEnvs().Keys {
+			variables[key] = ""
+		}
+		if docker, ok := executor.(ast.DockerExecutor); ok && len(docker.Image) > 0 {
+			for key, value := range docker.Image[0].Environment {
+				variables[key] = value
+			}
+		}
+		sources = append(sources, environmentSource{name: "executor `" + job.Executor + "`", variables: variables})
+	} else if len(job.Docker.Image) > 0 {
+		sources = append(sources, environmentSource{name: "primary container", variables: job.Docker.Image[0].Environment})
+	}
+
+	cachedFile := cache.FileCache.GetFile(doc.URI)
+	if cachedFile == nil {
+		return sources
+	}
+
+	if job.Contexts != nil {
+		fromContexts := map[string]map[string]string{}
+		for _, env := range utils.GetAllContextEnvVariables(context, cache, cachedFile.Project.OrganizationName, *job.Contexts) {
+			if fromContexts[env.AssociatedContext] == nil {
+				fromContexts[env.AssociatedContext] = map[string]string{}
+			}
+			fromContexts[env.AssociatedContext][env.Name] = ""
+		}
+
+		// Contexts are applied in their order in the workflows, the last one
+		// having the highest precedence
+		for i := len(*job.Contexts) - 1; i >= 0; i-- {
+			name := (*job.Contexts)[i]
+			if variables, ok := fromContexts[name]; ok {
+				sources = append(sources, environmentSource{name: "context " + name, variables: variables, secret: true})
+				delete(fromContexts, name)
+			}
+		}
+	}
+
+	if cachedFile.Project.Slug != "" {
+		variables := map[string]string{}
+		for _, env := range cache.FileCache.GetProjectEnvVariables(doc.URI) {
+			variables[env] = ""
+		}
+		sources = append(sources, environmentSource{name: "project " + cachedFile.Project.Name, variables: variables, secret: true})
+	}
+
+	return sources
+}
+
+func environmentDocumentation(subject string, sources []environmentSource) string {
+	type effectiveVariable struct {
+		source    environmentSource
+		value     string
+		overrides []string
+	}
+
+	names := []string{}
+	variables := map[string]*effectiveVariable{}
+	for _, source := range sources {
+		keys := make([]string, 0, len(source.variables))
+		for key := range source.variables {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if variable, ok := variables[key]; ok {
+				variable.overrides = append(variable.overrides, source.name)
+				continue
+			}
+
+			names = append(names, key)
+			variables[key] = &effectiveVariable{source: source, value: source.variables[key]}
+		}
+	}
+
+	if len(names) == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("**Environment of %s**\n\nFrom the highest precedence:\n\n", subject))
+
+	for _, name := range names {
+		variable := variables[name]
+		if variable.source.secret || variable.value == "" {
+			builder.WriteString(fmt.Sprintf("- `%s` (from %s)", name, variable.source.name))
+		} else {
+			builder.WriteString(fmt.Sprintf("- `%s`: `%s` (from %s)", name, variable.value, variable.source.name))
+		}
+
+		if len(variable.overrides) > 0 {
+			builder.WriteString(", overrides " + strings.Join(variable.overrides, ", "))
+		}
+		builder.WriteString("\n")
+	}
+
+	return builder.String()
+}
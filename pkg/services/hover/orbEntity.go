@@ -0,0 +1,126 @@
+package hover
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
+	yamlparser "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+)
+
+// Render the documentation of a job or command provided by an orb, such as
+// `node/test`. Returns an empty string when the name is not an orb reference
+func HoverOrbEntity(doc yamlparser.YamlDocument, name string, cache *utils.Cache) string {
+	entity, msg := LookupOrbEntity(doc, name, cache)
+	if entity == nil {
+		return msg
+	}
+
+	return orbEntityDefinition(name, entity.Kind, entity.Description, entity.Parameters, entity.OrbInfo)
+}
+
+type OrbEntity struct {
+	Kind        string
+	Description string
+	Parameters  map[string]ast.Parameter
+	OrbInfo     *ast.OrbInfo
+}
+
+// Look up a job or command provided by an orb, such as `node/test`. When it
+// is not available, the returned message tells why, it is empty when the name
+// is not an orb reference
+func LookupOrbEntity(doc yamlparser.YamlDocument, name string, cache *utils.Cache) (*OrbEntity, string) {
+	orbName, ok := doc.CouldBeOrbReference(name)
+	if !ok {
+		return nil, ""
+	}
+	entityName := strings.SplitN(name, "/", 2)[1]
+
+	orbInfo, ok := doc.LocalOrbInfo[orbName]
+	if !ok {
+		orbID, isDeclared := doc.GetOrbIDFromReference(name)
+		if !isDeclared {
+			return nil, fmt.Sprintf("`%s` - The orb `%s` is not declared in the `orbs` section", name, orbName)
+		}
+
+		orbInfo = cache.OrbCache.GetOrb(orbID)
+		if orbInfo == nil {
+			// Fetching can take a while, do not block the request on it: the
+			// orb will be available on the next one
+			go doc.GetOrFetchOrbInfo(doc.Orbs[orbName], cache)
+			return nil, fmt.Sprintf("`%s` - Resolving orb `%s`…", name, orbID)
+		}
+	}
+
+	if job, ok := orbInfo.Jobs[entityName]; ok {
+		return &OrbEntity{"Orb job", job.Description, job.Parameters, orbInfo}, ""
+	}
+
+	if command, ok := orbInfo.Commands[entityName]; ok {
+		return &OrbEntity{"Orb command", command.Description, command.Parameters, orbInfo}, ""
+	}
+
+	return nil, ""
+}
+
+func orbEntityDefinition(name string, kind string, description string, parameters map[string]ast.Parameter, orbInfo *ast.OrbInfo) string {
+	res := fmt.Sprintf("`%s` - %s", name, kind)
+
+	if orbInfo.IsLocal {
+		res += " (local orb)"
+	} else if orbInfo.RemoteInfo.ID != "" {
+		res += fmt.Sprintf(" from `%s`", orbInfo.RemoteInfo.ID)
+	}
+	res += "\n\n"
+
+	if description != "" {
+		res += strings.TrimSpace(description) + "\n\n"
+	}
+
+	if len(parameters) == 0 {
+		return res
+	}
+
+	names := make([]string, 0, len(parameters))
+	for paramName := range parameters {
+		names = append(names, paramName)
+	}
+	sort.Strings(names)
+
+	res += "Parameters:\n\n"
+	for _, paramName := range names {
+		param := parameters[paramName]
+		res += fmt.Sprintf("- `%s` (%s)", paramName, param.GetType())
+
+		if defaultValue, ok := ParameterDefault(param); ok {
+			res += fmt.Sprintf(", default: `%s`", defaultValue)
+		}
+		if paramDescription := param.GetDescription(); paramDescription != "" {
+			res += ": " + paramDescription
+		}
+		res += "\n"
+	}
+
+	return res
+}
+
+func ParameterDefault(param ast.Parameter) (string, bool) {
+	switch p := param.(type) {
+	case ast.StringParameter:
+		return p.Default, p.HasDefault
+	case ast.BooleanParameter:
+		return fmt.Sprint(p.Default), p.HasDefault
+	case ast.IntegerParameter:
+		return fmt.Sprint(p.Default), p.HasDefault
+	case ast.EnumParameter:
+		return p.Default, p.HasDefault
+	case ast.ExecutorParameter:
+		return p.Default, p.HasDefault
+	case ast.EnvVariableParameter:
+		return p.Default, p.HasDefault
+	}
+
+	return "", false
+}
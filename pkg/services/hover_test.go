@@ -0,0 +1,264 @@
+package languageservice
+
+import (
+	"path"
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/services/hover"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/testHelpers"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+func TestHoverOrbEntity(t *testing.T) {
+	cache := utils.CreateCache()
+	context := testHelpers.GetDefaultLsContext()
+
+	parsedOrb, err := parser.ParseFromURI(uri.File(path.Join("./testdata/orb.yaml")), context)
+	if err != nil {
+		panic(err)
+	}
+
+	cache.OrbCache.SetOrb(&ast.OrbInfo{
+		OrbParsedAttributes: parsedOrb.ToOrbParsedAttributes(),
+		RemoteInfo: ast.RemoteOrbInfo{
+			ID:       "superorb/superfunc@1.2.3",
+			FilePath: uri.File(path.Join("./testdata/orb.yaml")).Filename(),
+		},
+	}, "superorb/superfunc@1.2.3")
+
+	content := `version: 2.1
+
+orbs:
+  superfunc: superorb/superfunc@1.2.3
+  aliased: superorb/superfunc@1.2.3
+
+workflows:
+  test-build:
+    jobs:
+      - superfunc/supermethod
+      - undeclared/job
+      - aliased/supermethod
+`
+	doc, err := parser.ParseFromContent([]byte(content), context, uri.File(""), protocol.Position{})
+	assert.Nil(t, err)
+
+	testCases := []struct {
+		Name     string
+		Position protocol.Position
+		Expected string
+	}{
+		{
+			Name:     "Should render the orb job documentation",
+			Position: protocol.Position{Line: 9, Character: 12},
+			Expected: "`superfunc/supermethod` - Orb job from `superorb/superfunc@1.2.3`\n\n" +
+				"Describes a welcome message, common environment variables, and documentation links used to get started with CircleCI.\n\n",
+		},
+		{
+			Name:     "Should tell when the orb is not declared",
+			Position: protocol.Position{Line: 10, Character: 12},
+			Expected: "`undeclared/job` - The orb `undeclared` is not declared in the `orbs` section",
+		},
+		{
+			Name:     "Should follow the alias of the orb",
+			Position: protocol.Position{Line: 11, Character: 12},
+			Expected: "`aliased/supermethod` - Orb job from `superorb/superfunc@1.2.3`\n\n" +
+				"Describes a welcome message, common environment variables, and documentation links used to get started with CircleCI.\n\n",
+		},
+		{
+			Name:     "Should not hover outside of orb references",
+			Position: protocol.Position{Line: 7, Character: 4},
+			Expected: "",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			name := getOrbEntityNameAtPosition(doc, tt.Position)
+			assert.Equal(t, tt.Expected, hover.HoverOrbEntity(doc, name, cache))
+		})
+	}
+}
+
+func TestHoverEnvironment(t *testing.T) {
+	cache := utils.CreateCache()
+	context := testHelpers.GetDefaultLsContext()
+	fileURI := uri.File("/project/.circleci/config.yml")
+
+	content := `version: 2.1
+
+executors:
+  node:
+    docker:
+      - image: cimg/node:20.5
+        environment:
+          NODE_ENV: production
+          CI_TIMEOUT: "10"
+
+jobs:
+  build:
+    executor: node
+    environment:
+      NODE_ENV: test
+    steps:
+      - run:
+          command: npm test
+          environment:
+            CI_TIMEOUT: "30"
+      - checkout
+
+workflows:
+  main:
+    jobs:
+      - build:
+          context: secrets
+`
+	cache.FileCache.SetFile(utils.CachedFile{
+		TextDocument: protocol.TextDocumentItem{URI: fileURI, Text: content},
+		Project: utils.Project{
+			Slug:             "gh/acme/app",
+			Name:             "app",
+			OrganizationName: "acme",
+		},
+		EnvVariables: []string{"NPM_TOKEN"},
+	})
+	cache.ContextCache.SetOrganizationContext("acme", &utils.Context{Name: "secrets"})
+	cache.ContextCache.AddEnvVariableToOrganizationContext("acme", "secrets", "AWS_SECRET_ACCESS_KEY")
+	cache.ContextCache.AddEnvVariableToOrganizationContext("acme", "secrets", "NPM_TOKEN")
+
+	doc, err := parser.ParseFromContent([]byte(content), context, fileURI, protocol.Position{})
+	assert.Nil(t, err)
+
+	testCases := []struct {
+		Name     string
+		Position protocol.Position
+		Expected string
+	}{
+		{
+			Name:     "Should render the environment of a run step",
+			Position: protocol.Position{Line: 16, Character: 10},
+			Expected: "**Environment of this step**\n\nFrom the highest precedence:\n\n" +
+				"- `CI_TIMEOUT`: `30` (from step), overrides executor `node`\n" +
+				"- `NODE_ENV`: `test` (from job `build`), overrides executor `node`\n" +
+				"- `AWS_SECRET_ACCESS_KEY` (from context secrets)\n" +
+				"- `NPM_TOKEN` (from context secrets), overrides project app\n",
+		},
+		{
+			Name:     "Should render the environment of a job",
+			Position: protocol.Position{Line: 13, Character: 6},
+			Expected: "**Environment of job `build`**\n\nFrom the highest precedence:\n\n" +
+				"- `NODE_ENV`: `test` (from job `build`), overrides executor `node`\n" +
+				"- `CI_TIMEOUT`: `10` (from executor `node`)\n" +
+				"- `AWS_SECRET_ACCESS_KEY` (from context secrets)\n" +
+				"- `NPM_TOKEN` (from context secrets), overrides project app\n",
+		},
+		{
+			Name:     "Should not hover other steps",
+			Position: protocol.Position{Line: 21, Character: 10},
+			Expected: "",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Equal(t, tt.Expected, hover.HoverEnvironment(doc, tt.Position, cache, context))
+		})
+	}
+}
+
+func TestHoverBuiltInStep(t *testing.T) {
+	context := testHelpers.GetDefaultLsContext()
+	content := `version: 2.1
+
+commands:
+  setup:
+    steps:
+      - checkout
+      - restore_cache:
+          keys:
+            - deps-
+
+jobs:
+  build:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - setup
+      - run: make test
+      - when:
+          condition: true
+          steps:
+            - persist_to_workspace:
+                root: .
+                paths: [checkout]
+`
+	doc, err := parser.ParseFromContent([]byte(content), context, uri.File(""), protocol.Position{})
+	assert.Nil(t, err)
+
+	testCases := []struct {
+		Name     string
+		Position protocol.Position
+		Expected string
+	}{
+		{
+			Name:     "Should render a step written without keys",
+			Position: protocol.Position{Line: 5, Character: 10},
+			Expected: "`checkout` - Built-in step\n\n" +
+				utils.GetBuiltInStep("checkout").Description + "\n\n" +
+				"```yaml\n- checkout\n```\n\n" +
+				"```yaml\n- checkout:\n    path: ~/project\n```\n\n" +
+				"Keys:\n\n" +
+				"- `path` (string): Directory the code is checked out to, relative to the working directory\n",
+		},
+		{
+			Name:     "Should render the conflicting keys",
+			Position: protocol.Position{Line: 6, Character: 10},
+			Expected: "`restore_cache` - Built-in step\n\n" +
+				utils.GetBuiltInStep("restore_cache").Description + "\n\n" +
+				"Keys:\n\n" +
+				"- `key` (string): Key of the cache to restore, can not be used with `keys`\n" +
+				"- `keys` (list): Keys of the caches to restore, the first one found is restored, can not be used with `key`\n" +
+				"- `name` (string): Title of the step shown in the CircleCI UI\n",
+		},
+		{
+			Name:     "Should not render the keys of the steps",
+			Position: protocol.Position{Line: 7, Character: 11},
+			Expected: "",
+		},
+		{
+			Name:     "Should not render commands",
+			Position: protocol.Position{Line: 15, Character: 9},
+			Expected: "",
+		},
+		{
+			Name:     "Should render the steps of conditions",
+			Position: protocol.Position{Line: 20, Character: 16},
+			Expected: "`persist_to_workspace` - Built-in step\n\n" +
+				utils.GetBuiltInStep("persist_to_workspace").Description + "\n\n" +
+				"Keys:\n\n" +
+				"- `root` (string): Directory the paths are relative to\n" +
+				"- `paths` (list): Paths of the directories and files to persist, relative to the root\n",
+		},
+		{
+			Name:     "Should not render values named like steps",
+			Position: protocol.Position{Line: 22, Character: 25},
+			Expected: "",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Equal(t, tt.Expected, hover.HoverBuiltInStep(doc, tt.Position))
+		})
+	}
+
+	run := hover.HoverBuiltInStep(doc, protocol.Position{Line: 16, Character: 9})
+	assert.Contains(t, run, "`run` - Built-in step")
+	assert.Contains(t, run, "```yaml\n# The command is also the name of the step\n- run: make test\n```")
+	assert.Contains(t, run, "```yaml\n- run:\n    name: Test\n    command: make test\n```")
+	assert.Contains(t, run, "- `command` (string): Command run through the shell\n")
+}
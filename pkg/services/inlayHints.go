@@ -0,0 +1,128 @@
+package languageservice
+
+import (
+	"sort"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
+	yamlparser "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/services/hover"
+	utils "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"go.lsp.dev/protocol"
+)
+
+// Inlay hints have been introduced in LSP 3.17 and are not part of the
+// protocol package
+const MethodTextDocumentInlayHint = "textDocument/inlayHint"
+
+type InlayHintKind uint32
+
+const InlayHintKindParameter InlayHintKind = 2
+
+type InlayHintParams struct {
+	TextDocument protocol.TextDocumentIdentifier `json:"textDocument"`
+	Range        protocol.Range                  `json:"range"`
+}
+
+type InlayHint struct {
+	Position    protocol.Position `json:"position"`
+	Label       string            `json:"label"`
+	Kind        InlayHintKind     `json:"kind,omitempty"`
+	PaddingLeft bool              `json:"paddingLeft,omitempty"`
+}
+
+// Shows the default value of the parameters omitted when invoking a command
+// or a job, next to its name
+func InlayHints(params InlayHintParams, cache *utils.Cache, context *utils.LsContext) ([]InlayHint, error) {
+	if context.DisableInlayHints {
+		return []InlayHint{}, nil
+	}
+
+	doc, err := yamlparser.ParseFromUriWithCache(params.TextDocument.URI, cache, context)
+	if err != nil {
+		return nil, err
+	}
+
+	hints := []InlayHint{}
+
+	for _, step := range getAllNamedSteps(doc) {
+		if !utils.PosInRange(params.Range, step.Range.Start) {
+			continue
+		}
+
+		definedParams := getInvokedParameters(doc, step.Name, cache, false)
+		hints = append(hints, getDefaultValueHints(doc, step.Range, definedParams, step.Parameters, nil)...)
+	}
+
+	for _, workflow := range doc.Workflows {
+		for _, jobRef := range workflow.JobRefs {
+			if !utils.PosInRange(params.Range, jobRef.JobNameRange.Start) {
+				continue
+			}
+
+			definedParams := getInvokedParameters(doc, jobRef.JobName, cache, true)
+			hints = append(hints, getDefaultValueHints(doc, jobRef.JobNameRange, definedParams, jobRef.Parameters, jobRef.MatrixParams)...)
+		}
+	}
+
+	sort.SliceStable(hints, func(i, j int) bool {
+		if hints[i].Position.Line == hints[j].Position.Line {
+			return hints[i].Position.Character < hints[j].Position.Character
+		}
+		return hints[i].Position.Line < hints[j].Position.Line
+	})
+
+	return hints, nil
+}
+
+// Parameters of the local job or command, or of the orb one, invoked with the
+// given name
+func getInvokedParameters(doc yamlparser.YamlDocument, name string, cache *utils.Cache, isJob bool) map[string]ast.Parameter {
+	if isJob {
+		if job, ok := doc.Jobs[name]; ok {
+			return job.Parameters
+		}
+	} else if command, ok := doc.Commands[name]; ok {
+		return command.Parameters
+	}
+
+	if entity, _ := hover.LookupOrbEntity(doc, name, cache); entity != nil {
+		return entity.Parameters
+	}
+
+	return nil
+}
+
+func getDefaultValueHints(doc yamlparser.YamlDocument, nameRange protocol.Range, definedParams map[string]ast.Parameter, values map[string]ast.ParameterValue, matrixValues map[string][]ast.ParameterValue) []InlayHint {
+	names := []string{}
+	for name, param := range definedParams {
+		_, isSet := values[name]
+		_, isInMatrix := matrixValues[name]
+		if _, hasDefault := hover.ParameterDefault(param); hasDefault && !isSet && !isInMatrix {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	// Right after the name, and the colon when the invocation has parameters
+	position := nameRange.End
+	if index := utils.PosToIndex(position, doc.Content); index < len(doc.Content) && doc.Content[index] == ':' {
+		position.Character++
+	}
+
+	hints := []InlayHint{}
+	for _, name := range names {
+		defaultValue, _ := hover.ParameterDefault(definedParams[name])
+		if defaultValue == "" {
+			defaultValue = `""`
+		}
+
+		hints = append(hints, InlayHint{
+			Position:    position,
+			Label:       name + " = " + defaultValue,
+			Kind:        InlayHintKindParameter,
+			PaddingLeft: true,
+		})
+	}
+
+	return hints
+}
@@ -0,0 +1,110 @@
+package languageservice
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/testHelpers"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+const inlayHintsYaml = `version: 2.1
+
+commands:
+  greet:
+    parameters:
+      to:
+        type: string
+        default: world
+      loud:
+        type: boolean
+        default: false
+      prefix:
+        type: string
+    steps:
+      - run: echo << parameters.prefix >> << parameters.to >>
+
+jobs:
+  build:
+    parameters:
+      version:
+        type: string
+        default: ""
+      os:
+        type: enum
+        enum: [linux, macos]
+        default: linux
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - greet
+      - greet:
+          to: you
+
+workflows:
+  main:
+    jobs:
+      - build:
+          matrix:
+            parameters:
+              os: [linux, macos]
+`
+
+func TestInlayHints(t *testing.T) {
+	cache := utils.CreateCache()
+	fileURI := uri.File("/tmp/inlayHints.yml")
+	cache.FileCache.SetFile(utils.CachedFile{
+		TextDocument: protocol.TextDocumentItem{URI: fileURI, Text: inlayHintsYaml},
+	})
+
+	params := InlayHintParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: fileURI},
+		Range: protocol.Range{
+			Start: protocol.Position{Line: 0, Character: 0},
+			End:   protocol.Position{Line: 52, Character: 0},
+		},
+	}
+
+	hint := func(line uint32, char uint32, label string) InlayHint {
+		return InlayHint{
+			Position:    protocol.Position{Line: line, Character: char},
+			Label:       label,
+			Kind:        InlayHintKindParameter,
+			PaddingLeft: true,
+		}
+	}
+
+	t.Run("Should show the defaults of the omitted parameters", func(t *testing.T) {
+		hints, err := InlayHints(params, cache, testHelpers.GetDefaultLsContext())
+		assert.Nil(t, err)
+		assert.Equal(t, []InlayHint{
+			hint(29, 13, "loud = false"),
+			hint(29, 13, "to = world"),
+			hint(30, 14, "loud = false"),
+			hint(36, 14, `version = ""`),
+		}, hints)
+	})
+
+	t.Run("Should only show the hints of the requested range", func(t *testing.T) {
+		rangeParams := params
+		rangeParams.Range = protocol.Range{
+			Start: protocol.Position{Line: 30, Character: 0},
+			End:   protocol.Position{Line: 32, Character: 0},
+		}
+
+		hints, err := InlayHints(rangeParams, cache, testHelpers.GetDefaultLsContext())
+		assert.Nil(t, err)
+		assert.Equal(t, []InlayHint{hint(30, 14, "loud = false")}, hints)
+	})
+
+	t.Run("Should not show hints when disabled", func(t *testing.T) {
+		context := testHelpers.GetDefaultLsContext()
+		context.DisableInlayHints = true
+
+		hints, err := InlayHints(params, cache, context)
+		assert.Nil(t, err)
+		assert.Empty(t, hints)
+	})
+}
@@ -0,0 +1,126 @@
+package languageservice
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
+	yamlparser "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
+	utils "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"go.lsp.dev/protocol"
+)
+
+var orbReference = regexp.MustCompile(`^([A-Za-z0-9_-]+)/([A-Za-z0-9_.-]+)`)
+
+// Fixes a job or a step such as `node/install` referencing an orb that is not
+// imported, by importing the orb of the `circleci` namespace with the same
+// name, as long as it has such a job or command. The orb is fetched to offer
+// its latest version, and its volatile one as an alternative
+func addMissingOrbActions(doc yamlparser.YamlDocument, diagnostic protocol.Diagnostic, cache *utils.Cache, context *utils.LsContext) []protocol.CodeAction {
+	if utils.GetDiagnosticRule(diagnostic) != utils.RuleOrbNotDeclared {
+		return []protocol.CodeAction{}
+	}
+
+	lines := strings.Split(string(doc.Content), "\n")
+	match := getOrbReferenceAt(lines, diagnostic.Range.Start)
+	if match == nil {
+		return []protocol.CodeAction{}
+	}
+
+	alias, entityName := match[1], match[2]
+	if _, ok := doc.Orbs[alias]; ok {
+		return []protocol.CodeAction{}
+	}
+
+	orbName := "circleci/" + alias
+	orbInfo, err := yamlparser.GetOrbInfo(ast.FormatOrbID(orbName, "volatile"), cache, context)
+	if err != nil || orbInfo == nil {
+		return []protocol.CodeAction{}
+	}
+
+	_, isCommand := orbInfo.Commands[entityName]
+	_, isJob := orbInfo.Jobs[entityName]
+	if !isCommand && !isJob {
+		return []protocol.CodeAction{}
+	}
+
+	versions := []string{}
+	if orbInfo.RemoteInfo.Version != "" {
+		versions = append(versions, orbInfo.RemoteInfo.Version)
+	}
+	versions = append(versions, "volatile")
+
+	actions := []protocol.CodeAction{}
+	for i, version := range versions {
+		orbID := ast.FormatOrbID(orbName, version)
+		actions = append(actions, protocol.CodeAction{
+			Title:       fmt.Sprintf("Import orb %s", orbID),
+			Kind:        protocol.QuickFix,
+			Diagnostics: []protocol.Diagnostic{diagnostic},
+			IsPreferred: i == 0,
+			Edit: &protocol.WorkspaceEdit{
+				Changes: map[protocol.DocumentURI][]protocol.TextEdit{
+					doc.URI: {getOrbInsertionEdit(doc, lines, alias, orbID)},
+				},
+			},
+		})
+	}
+
+	return actions
+}
+
+// Adds the orb after the last imported one, or in a new `orbs` section right
+// after the version
+func getOrbInsertionEdit(doc yamlparser.YamlDocument, lines []string, alias string, orbID string) protocol.TextEdit {
+	if len(doc.Orbs) > 0 {
+		var last ast.Orb
+		for _, orb := range doc.Orbs {
+			if orb.Range.End.Line >= last.Range.End.Line {
+				last = orb
+			}
+		}
+
+		line := last.Range.End.Line
+		end := protocol.Position{Line: line, Character: uint32(len(lines[line]))}
+		indent := strings.Repeat(" ", int(last.NameRange.Start.Character))
+		return protocol.TextEdit{
+			Range:   protocol.Range{Start: end, End: end},
+			NewText: fmt.Sprintf("\n%s%s: %s", indent, alias, orbID),
+		}
+	}
+
+	orb := fmt.Sprintf("  %s: %s", alias, orbID)
+
+	// Empty `orbs` section
+	if !utils.IsDefaultRange(doc.OrbsRange) {
+		line := doc.OrbsRange.Start.Line
+		end := protocol.Position{Line: line, Character: uint32(len(lines[line]))}
+		return protocol.TextEdit{
+			Range:   protocol.Range{Start: end, End: end},
+			NewText: "\n" + orb,
+		}
+	}
+
+	if utils.IsDefaultRange(doc.VersionRange) {
+		return protocol.TextEdit{
+			Range:   protocol.Range{},
+			NewText: "orbs:\n" + orb + "\n\n",
+		}
+	}
+
+	start := protocol.Position{Line: doc.VersionRange.End.Line + 1, Character: 0}
+	return protocol.TextEdit{
+		Range:   protocol.Range{Start: start, End: start},
+		NewText: "\norbs:\n" + orb + "\n",
+	}
+}
+
+// Orb alias and entity name of the reference starting at the given position
+func getOrbReferenceAt(lines []string, pos protocol.Position) []string {
+	if int(pos.Line) >= len(lines) || int(pos.Character) > len(lines[pos.Line]) {
+		return nil
+	}
+
+	return orbReference.FindStringSubmatch(lines[pos.Line][pos.Character:])
+}
@@ -0,0 +1,99 @@
+package languageservice
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
+	yamlparser "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
+	utils "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"go.lsp.dev/protocol"
+	"golang.org/x/mod/semver"
+)
+
+// Command returning the edit pinning the floating orbs of all the opened
+// files
+const PinAllOrbsCommand = "pinAllOrbs"
+
+// Offers to pin the orbs of the selection referenced with a floating
+// version, such as @1 or @volatile, to the version they resolved to
+func pinOrbActions(doc yamlparser.YamlDocument, selection protocol.Range, cache *utils.Cache) []protocol.CodeAction {
+	actions := []protocol.CodeAction{}
+
+	for _, orb := range doc.Orbs {
+		if selection.Start.Line < orb.Range.Start.Line || selection.Start.Line > orb.Range.End.Line {
+			continue
+		}
+
+		edit, ok := getOrbPinEdit(orb, cache)
+		if !ok {
+			continue
+		}
+
+		actions = append(actions, protocol.CodeAction{
+			Title: fmt.Sprintf("Pin to @%s", edit.NewText),
+			Kind:  protocol.RefactorRewrite,
+			Edit: &protocol.WorkspaceEdit{
+				Changes: map[protocol.DocumentURI][]protocol.TextEdit{
+					doc.URI: {edit},
+				},
+			},
+		})
+	}
+
+	return actions
+}
+
+// Pins the floating orbs of every file of the cache at once
+func PinAllOrbs(cache *utils.Cache, context *utils.LsContext) protocol.WorkspaceEdit {
+	changes := map[protocol.DocumentURI][]protocol.TextEdit{}
+
+	for fileURI := range cache.FileCache.GetFiles() {
+		doc, err := yamlparser.ParseFromUriWithCache(fileURI, cache, context)
+		if err != nil {
+			continue
+		}
+
+		edits := []protocol.TextEdit{}
+		for _, orb := range doc.Orbs {
+			if edit, ok := getOrbPinEdit(orb, cache); ok {
+				edits = append(edits, edit)
+			}
+		}
+
+		if len(edits) > 0 {
+			changes[fileURI] = edits
+		}
+	}
+
+	return protocol.WorkspaceEdit{Changes: changes}
+}
+
+// Rewrites a floating version of the orb to the exact version it resolved
+// to. Nothing is fetched: the orb is only pinned once it is in the cache
+func getOrbPinEdit(orb ast.Orb, cache *utils.Cache) (protocol.TextEdit, bool) {
+	if orb.Url.IsLocal || !isFloatingOrbVersion(orb.Url.Version) {
+		return protocol.TextEdit{}, false
+	}
+
+	orbInfo := cache.OrbCache.GetOrb(orb.Url.GetOrbID())
+	if orbInfo == nil {
+		return protocol.TextEdit{}, false
+	}
+
+	resolved := orbInfo.RemoteInfo.Version
+	if !semver.IsValid("v"+resolved) || isFloatingOrbVersion(resolved) {
+		return protocol.TextEdit{}, false
+	}
+
+	return protocol.TextEdit{
+		Range:   orb.VersionRange,
+		NewText: resolved,
+	}, true
+}
+
+// Volatile and partial versions, such as 1 or 1.2, follow the releases of
+// the orb
+func isFloatingOrbVersion(version string) bool {
+	return version == "volatile" || (semver.IsValid("v"+version) && strings.Count(version, ".") < 2)
+}
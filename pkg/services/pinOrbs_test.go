@@ -0,0 +1,104 @@
+package languageservice
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/testHelpers"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// Orbs missing from the cache are never found
+type unreachableOrbFetcher struct{}
+
+func (unreachableOrbFetcher) FetchOrb(orbID string) (*ast.OrbInfo, error) {
+	return nil, errors.New("registry unreachable")
+}
+
+func TestPinOrbs(t *testing.T) {
+	fileURI := uri.File("/tmp/pinOrbs.yml")
+	otherURI := uri.File("/tmp/pinOrbs-other.yml")
+	content := "version: 2.1\n\norbs:\n  node: circleci/node@5\n  go: circleci/go@volatile\n  slack: circleci/slack@4.12\n  aws: circleci/aws-cli@4.1.0\n  python: circleci/python@2\n  ruby: circleci/ruby@volatile\n"
+
+	createCache := func() *utils.Cache {
+		cache := utils.CreateCache(utils.WithOrbFetcher(unreachableOrbFetcher{}))
+		cache.FileCache.SetFile(utils.CachedFile{
+			TextDocument: protocol.TextDocumentItem{URI: fileURI, Text: content},
+		})
+		cache.OrbCache.SetOrb(&ast.OrbInfo{
+			RemoteInfo: ast.RemoteOrbInfo{Version: "5.2.0", LatestVersion: "5.2.0"},
+		}, "circleci/node@5")
+		cache.OrbCache.SetOrb(&ast.OrbInfo{
+			RemoteInfo: ast.RemoteOrbInfo{Version: "1.9.0", LatestVersion: "1.9.0"},
+		}, "circleci/go@volatile")
+		cache.OrbCache.SetOrb(&ast.OrbInfo{
+			RemoteInfo: ast.RemoteOrbInfo{Version: "4.12.5", LatestVersion: "4.12.5"},
+		}, "circleci/slack@4.12")
+		cache.OrbCache.SetOrb(&ast.OrbInfo{
+			RemoteInfo: ast.RemoteOrbInfo{Version: "4.1.0", LatestVersion: "4.1.0"},
+		}, "circleci/aws-cli@4.1.0")
+		// Resolution unknown
+		cache.OrbCache.SetOrb(&ast.OrbInfo{}, "circleci/ruby@volatile")
+		return cache
+	}
+
+	getActions := func(t *testing.T, line uint32) []protocol.CodeAction {
+		position := protocol.Position{Line: line, Character: 4}
+		actions, err := CodeActions(protocol.CodeActionParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: fileURI},
+			Range:        protocol.Range{Start: position, End: position},
+		}, createCache(), testHelpers.GetDefaultLsContext())
+		assert.Nil(t, err)
+		return actions
+	}
+
+	t.Run("Should pin partial versions", func(t *testing.T) {
+		actions := getActions(t, 3)
+		assert.Len(t, actions, 1)
+		assert.Equal(t, "Pin to @5.2.0", actions[0].Title)
+		assert.Equal(t,
+			"version: 2.1\n\norbs:\n  node: circleci/node@5.2.0\n  go: circleci/go@volatile\n  slack: circleci/slack@4.12\n  aws: circleci/aws-cli@4.1.0\n  python: circleci/python@2\n  ruby: circleci/ruby@volatile\n",
+			applyTextEdits(content, actions[0].Edit.Changes[fileURI]))
+
+		actions = getActions(t, 5)
+		assert.Len(t, actions, 1)
+		assert.Equal(t, "Pin to @4.12.5", actions[0].Title)
+	})
+
+	t.Run("Should pin volatile versions", func(t *testing.T) {
+		actions := getActions(t, 4)
+		assert.Len(t, actions, 1)
+		assert.Equal(t, "Pin to @1.9.0", actions[0].Title)
+		assert.Equal(t,
+			"version: 2.1\n\norbs:\n  node: circleci/node@5\n  go: circleci/go@1.9.0\n  slack: circleci/slack@4.12\n  aws: circleci/aws-cli@4.1.0\n  python: circleci/python@2\n  ruby: circleci/ruby@volatile\n",
+			applyTextEdits(content, actions[0].Edit.Changes[fileURI]))
+	})
+
+	t.Run("Should not offer anything without a concrete resolution", func(t *testing.T) {
+		assert.Empty(t, getActions(t, 6))
+		assert.Empty(t, getActions(t, 7))
+		assert.Empty(t, getActions(t, 8))
+	})
+
+	t.Run("Should pin the orbs of every cached file", func(t *testing.T) {
+		cache := createCache()
+		otherContent := "version: 2.1\n\norbs:\n  go: circleci/go@volatile\n"
+		cache.FileCache.SetFile(utils.CachedFile{
+			TextDocument: protocol.TextDocumentItem{URI: otherURI, Text: otherContent},
+		})
+
+		edit := PinAllOrbs(cache, testHelpers.GetDefaultLsContext())
+
+		assert.Len(t, edit.Changes, 2)
+		assert.Equal(t,
+			"version: 2.1\n\norbs:\n  node: circleci/node@5.2.0\n  go: circleci/go@1.9.0\n  slack: circleci/slack@4.12.5\n  aws: circleci/aws-cli@4.1.0\n  python: circleci/python@2\n  ruby: circleci/ruby@volatile\n",
+			applyTextEdits(content, edit.Changes[fileURI]))
+		assert.Equal(t,
+			"version: 2.1\n\norbs:\n  go: circleci/go@1.9.0\n",
+			applyTextEdits(otherContent, edit.Changes[otherURI]))
+	})
+}
@@ -114,7 +114,20 @@ func (ref ReferenceHandler) GetReferences() ([]protocol.Location, error) {
 	ref.getStepsOfJobs()
 	ref.getStepsOfCommands()
 
-	return ref.getReferenceFromSteps(cmdName, isOrb)
+	locations, err := ref.getReferenceFromSteps(cmdName, isOrb)
+
+	if command, ok := ref.Doc.Commands[cmdName]; ok && utils.PosInRange(ref.Doc.CommandsRange, ref.Params.Position) {
+		locations = append(locations, ref.getReferencesInOtherFiles(cmdName, false)...)
+
+		if ref.Params.Context.IncludeDeclaration {
+			locations = append(locations, protocol.Location{
+				URI:   ref.Params.TextDocument.URI,
+				Range: command.NameRange,
+			})
+		}
+	}
+
+	return locations, err
 }
 
 type StepRangeAndName struct {
@@ -229,9 +242,82 @@ func (ref ReferenceHandler) getExecutorReferences() ([]protocol.Location, string
 		}
 	}
 
+	if executorName == "" {
+		return locations, executorName
+	}
+
+	locations = append(locations, ref.getReferencesInOtherFiles(executorName, true)...)
+
+	if ref.Params.Context.IncludeDeclaration {
+		locations = append(locations, protocol.Location{
+			URI:   ref.Params.TextDocument.URI,
+			Range: executor.GetNameRange(),
+		})
+	}
+
 	return locations, executorName
 }
 
+// When the current document is the source of an orb, search the usages of
+// one of its commands or executors in the other opened files importing it
+func (ref ReferenceHandler) getReferencesInOtherFiles(name string, isExecutor bool) []protocol.Location {
+	locations := []protocol.Location{}
+	currentFilePath := ref.Params.TextDocument.URI.Filename()
+
+	for fileURI, file := range ref.Cache.FileCache.GetFiles() {
+		if fileURI == ref.Params.TextDocument.URI {
+			continue
+		}
+
+		doc, err := yamlparser.ParseFromContent([]byte(file.TextDocument.Text), ref.Doc.Context, fileURI, protocol.Position{})
+		if err != nil {
+			continue
+		}
+
+		for orbName, orb := range doc.Orbs {
+			// Only looking in the cache, finding references should not fetch orbs
+			orbInfo := ref.Cache.OrbCache.GetOrb(orb.Url.GetOrbID())
+			if orbInfo == nil || orbInfo.IsLocal || orbInfo.RemoteInfo.FilePath != currentFilePath {
+				continue
+			}
+
+			locations = append(locations, getReferencesInDocument(doc, orbName+"/"+name, isExecutor)...)
+		}
+	}
+
+	return locations
+}
+
+func getReferencesInDocument(doc yamlparser.YamlDocument, name string, isExecutor bool) []protocol.Location {
+	locations := []protocol.Location{}
+
+	if isExecutor {
+		for _, job := range doc.Jobs {
+			if job.Executor == name {
+				locations = append(locations, protocol.Location{URI: doc.URI, Range: job.ExecutorRange})
+			}
+		}
+
+		return locations
+	}
+
+	steps := []StepRangeAndName{}
+	for _, job := range doc.Jobs {
+		steps = append(steps, getStepsOfCommandOrJob(job.Steps)...)
+	}
+	for _, command := range doc.Commands {
+		steps = append(steps, getStepsOfCommandOrJob(command.Steps)...)
+	}
+
+	for _, step := range steps {
+		if step.Name == name {
+			locations = append(locations, protocol.Location{URI: doc.URI, Range: step.Range})
+		}
+	}
+
+	return locations
+}
+
 func (ref ReferenceHandler) getParamReferences(cmdName string) ([]protocol.Location, error) {
 	var params map[string]ast.Parameter
 	var rng protocol.Range
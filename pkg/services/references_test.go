@@ -7,8 +7,11 @@ import (
 	"sort"
 	"testing"
 
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/testHelpers"
 	utils "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"github.com/stretchr/testify/assert"
 	"go.lsp.dev/protocol"
 	"go.lsp.dev/uri"
 )
@@ -309,3 +312,68 @@ func sortLocationItem(items []protocol.Location) {
 		return items[i].Range.Start.Line < items[j].Range.Start.Line
 	})
 }
+
+func TestReferencesFromOrbSource(t *testing.T) {
+	cache := utils.CreateCache()
+	context := testHelpers.GetDefaultLsContext()
+
+	orbURI := uri.File("./testdata/orb.yaml")
+	configURI := uri.File("./testdata/referencesOrb.yml")
+
+	for _, fileURI := range []protocol.URI{orbURI, configURI} {
+		content, _ := os.ReadFile(fileURI.Filename())
+		cache.FileCache.SetFile(utils.CachedFile{
+			TextDocument: protocol.TextDocumentItem{
+				URI:  fileURI,
+				Text: string(content),
+			},
+		})
+	}
+
+	parsedOrb, err := parser.ParseFromURI(orbURI, context)
+	if err != nil {
+		panic(err)
+	}
+
+	cache.OrbCache.SetOrb(&ast.OrbInfo{
+		OrbParsedAttributes: parsedOrb.ToOrbParsedAttributes(),
+		RemoteInfo: ast.RemoteOrbInfo{
+			FilePath: orbURI.Filename(),
+		},
+	}, "superorb/superfunc@1.2.3")
+
+	params := protocol.ReferenceParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: orbURI},
+			Position:     protocol.Position{Line: 3, Character: 6},
+		},
+		Context: protocol.ReferenceContext{IncludeDeclaration: true},
+	}
+
+	got, err := References(params, cache, context)
+	assert.Nil(t, err)
+
+	assert.ElementsMatch(t, []protocol.Location{
+		{
+			URI: orbURI,
+			Range: protocol.Range{
+				Start: protocol.Position{Line: 12, Character: 8},
+				End:   protocol.Position{Line: 12, Character: 25},
+			},
+		},
+		{
+			URI: configURI,
+			Range: protocol.Range{
+				Start: protocol.Position{Line: 7, Character: 8},
+				End:   protocol.Position{Line: 7, Character: 34},
+			},
+		},
+		{
+			URI: orbURI,
+			Range: protocol.Range{
+				Start: protocol.Position{Line: 3, Character: 4},
+				End:   protocol.Position{Line: 3, Character: 11},
+			},
+		},
+	}, got)
+}
@@ -0,0 +1,293 @@
+package languageservice
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
+	yamlparser "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
+	utils "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"go.lsp.dev/protocol"
+)
+
+var validParameterName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func PrepareRename(params protocol.PrepareRenameParams, cache *utils.Cache, context *utils.LsContext) (*protocol.Range, error) {
+	doc, err := yamlparser.ParseFromUriWithCache(params.TextDocument.URI, cache, context)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, rng, found := getCommandNameAtPosition(doc, params.Position); found {
+		return &rng, nil
+	}
+
+	scope, found := getParameterScopeAtPosition(doc, params.Position)
+	if !found {
+		return nil, nil
+	}
+
+	_, rng, found := scope.getParameterAtPosition(doc, params.Position)
+	if !found {
+		return nil, nil
+	}
+
+	return &rng, nil
+}
+
+func Rename(params protocol.RenameParams, cache *utils.Cache, context *utils.LsContext) (*protocol.WorkspaceEdit, error) {
+	doc, err := yamlparser.ParseFromUriWithCache(params.TextDocument.URI, cache, context)
+	if err != nil {
+		return nil, err
+	}
+
+	if !validParameterName.MatchString(params.NewName) {
+		return nil, fmt.Errorf("invalid name: %s", params.NewName)
+	}
+
+	ranges := []protocol.Range{}
+	if commandName, _, found := getCommandNameAtPosition(doc, params.Position); found {
+		if _, exists := doc.Commands[params.NewName]; exists {
+			return nil, fmt.Errorf("command %s already exists", params.NewName)
+		}
+		ranges = getCommandNameRanges(doc, commandName)
+	} else {
+		scope, found := getParameterScopeAtPosition(doc, params.Position)
+		if !found {
+			return nil, fmt.Errorf("nothing to rename")
+		}
+
+		paramName, _, found := scope.getParameterAtPosition(doc, params.Position)
+		if !found {
+			return nil, fmt.Errorf("nothing to rename")
+		}
+		if _, exists := scope.parameters[params.NewName]; exists {
+			return nil, fmt.Errorf("parameter %s already exists", params.NewName)
+		}
+		ranges = scope.getParameterRanges(doc, paramName)
+	}
+
+	edits := []protocol.TextEdit{}
+	for _, rng := range ranges {
+		edits = append(edits, protocol.TextEdit{Range: rng, NewText: params.NewName})
+	}
+
+	return &protocol.WorkspaceEdit{
+		Changes: map[protocol.DocumentURI][]protocol.TextEdit{
+			params.TextDocument.URI: edits,
+		},
+	}, nil
+}
+
+// Returns the name of the command of the document either defined or called at
+// the given position, along with the range of the name
+func getCommandNameAtPosition(doc yamlparser.YamlDocument, pos protocol.Position) (string, protocol.Range, bool) {
+	for name, command := range doc.Commands {
+		if utils.PosInRange(command.NameRange, pos) {
+			return name, command.NameRange, true
+		}
+	}
+
+	for _, step := range getAllNamedSteps(doc) {
+		if _, ok := doc.Commands[step.Name]; !ok {
+			continue
+		}
+
+		if rng := getStepNameRange(step); utils.PosInRange(rng, pos) {
+			return step.Name, rng, true
+		}
+	}
+
+	return "", protocol.Range{}, false
+}
+
+func getCommandNameRanges(doc yamlparser.YamlDocument, name string) []protocol.Range {
+	ranges := []protocol.Range{doc.Commands[name].NameRange}
+
+	for _, step := range getAllNamedSteps(doc) {
+		if step.Name == name {
+			ranges = append(ranges, getStepNameRange(step))
+		}
+	}
+
+	return ranges
+}
+
+// The range of a step includes its parameters
+func getStepNameRange(step ast.NamedStep) protocol.Range {
+	return protocol.Range{
+		Start: step.Range.Start,
+		End: protocol.Position{
+			Line:      step.Range.Start.Line,
+			Character: step.Range.Start.Character + uint32(len(step.Name)),
+		},
+	}
+}
+
+type parameterScopeKind int
+
+const (
+	jobParameterScope parameterScopeKind = iota
+	commandParameterScope
+	executorParameterScope
+)
+
+// The job, command or executor defining the parameters that can be referenced
+// with << parameters.name >> within its range
+type parameterScope struct {
+	kind       parameterScopeKind
+	name       string
+	rng        protocol.Range
+	parameters map[string]ast.Parameter
+}
+
+func getParameterScopeAtPosition(doc yamlparser.YamlDocument, pos protocol.Position) (parameterScope, bool) {
+	for _, job := range doc.Jobs {
+		if utils.PosInRange(job.Range, pos) {
+			return parameterScope{jobParameterScope, job.Name, job.Range, job.Parameters}, true
+		}
+	}
+
+	for _, command := range doc.Commands {
+		if utils.PosInRange(command.Range, pos) {
+			return parameterScope{commandParameterScope, command.Name, command.Range, command.Parameters}, true
+		}
+	}
+
+	for _, executor := range doc.Executors {
+		if utils.PosInRange(executor.GetRange(), pos) {
+			return parameterScope{executorParameterScope, executor.GetName(), executor.GetRange(), executor.GetParameters()}, true
+		}
+	}
+
+	return parameterScope{}, false
+}
+
+// Returns the name of the parameter either defined or referenced at the given
+// position, along with the range of the name
+func (scope parameterScope) getParameterAtPosition(doc yamlparser.YamlDocument, pos protocol.Position) (string, protocol.Range, bool) {
+	for name, param := range scope.parameters {
+		if utils.PosInRange(param.GetNameRange(), pos) {
+			return name, param.GetNameRange(), true
+		}
+	}
+
+	name, isPipelineParam := utils.GetParamNameUsedAtPos(doc.Content, pos)
+	if _, ok := scope.parameters[name]; !ok || isPipelineParam {
+		return "", protocol.Range{}, false
+	}
+
+	for _, rng := range getInterpolationRanges(doc.Content, "parameters", name, scope.rng) {
+		if utils.PosInRange(rng, pos) {
+			return name, rng, true
+		}
+	}
+
+	return "", protocol.Range{}, false
+}
+
+// Returns the ranges of the definition of the parameter, of its references in
+// the scope, and of the keys setting it where the scope is used
+func (scope parameterScope) getParameterRanges(doc yamlparser.YamlDocument, paramName string) []protocol.Range {
+	ranges := []protocol.Range{}
+
+	if param, ok := scope.parameters[paramName]; ok {
+		ranges = append(ranges, param.GetNameRange())
+	}
+
+	ranges = append(ranges, getInterpolationRanges(doc.Content, "parameters", paramName, scope.rng)...)
+
+	switch scope.kind {
+	case jobParameterScope:
+		for _, workflow := range doc.Workflows {
+			for _, jobRef := range workflow.JobRefs {
+				if jobRef.JobName != scope.name {
+					continue
+				}
+
+				if value, ok := jobRef.Parameters[paramName]; ok {
+					ranges = append(ranges, getParameterValueKeyRange(value))
+				}
+
+				if values, ok := jobRef.MatrixParams[paramName]; ok && len(values) > 0 {
+					ranges = append(ranges, getParameterValueKeyRange(values[0]))
+					ranges = append(ranges, getInterpolationRanges(doc.Content, "matrix", paramName, jobRef.JobRefRange)...)
+				}
+			}
+		}
+
+	case commandParameterScope:
+		for _, step := range getAllNamedSteps(doc) {
+			if value, ok := step.Parameters[paramName]; ok && step.Name == scope.name {
+				ranges = append(ranges, getParameterValueKeyRange(value))
+			}
+		}
+
+	case executorParameterScope:
+		for _, job := range doc.Jobs {
+			if value, ok := job.ExecutorParameters[paramName]; ok && job.Executor == scope.name {
+				ranges = append(ranges, getParameterValueKeyRange(value))
+			}
+		}
+	}
+
+	return ranges
+}
+
+func getAllNamedSteps(doc yamlparser.YamlDocument) []ast.NamedStep {
+	steps := []ast.Step{}
+
+	for _, job := range doc.Jobs {
+		steps = append(steps, job.Steps...)
+	}
+	for _, command := range doc.Commands {
+		steps = append(steps, command.Steps...)
+	}
+	for _, workflow := range doc.Workflows {
+		for _, jobRef := range workflow.JobRefs {
+			steps = append(steps, jobRef.PreSteps...)
+			steps = append(steps, jobRef.PostSteps...)
+		}
+	}
+
+	namedSteps := []ast.NamedStep{}
+	for _, step := range steps {
+		if namedStep, ok := step.(ast.NamedStep); ok {
+			namedSteps = append(namedSteps, namedStep)
+		}
+	}
+
+	return namedSteps
+}
+
+// The range of a parameter value starts with the key setting it
+func getParameterValueKeyRange(value ast.ParameterValue) protocol.Range {
+	return protocol.Range{
+		Start: value.Range.Start,
+		End: protocol.Position{
+			Line:      value.Range.Start.Line,
+			Character: value.Range.Start.Character + uint32(len(value.Name)),
+		},
+	}
+}
+
+// Returns the ranges of the name in every << prefix.name >> found in the range
+func getInterpolationRanges(content []byte, prefix string, name string, rng protocol.Range) []protocol.Range {
+	startIndex := utils.PosToIndex(rng.Start, content)
+	endIndex := utils.PosToIndex(rng.End, content)
+	if startIndex < 0 || endIndex > len(content) || startIndex > endIndex {
+		return []protocol.Range{}
+	}
+
+	regex := regexp.MustCompile(fmt.Sprintf(`<<\s*%s\.(%s)\s*>>`, regexp.QuoteMeta(prefix), regexp.QuoteMeta(name)))
+
+	ranges := []protocol.Range{}
+	for _, match := range regex.FindAllSubmatchIndex(content[startIndex:endIndex], -1) {
+		ranges = append(ranges, protocol.Range{
+			Start: utils.IndexToPos(startIndex+match[2], content),
+			End:   utils.IndexToPos(startIndex+match[3], content),
+		})
+	}
+
+	return ranges
+}
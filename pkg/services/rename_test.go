@@ -0,0 +1,155 @@
+package languageservice
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/testHelpers"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+const renameYaml = `version: 2.1
+
+commands:
+  greet:
+    parameters:
+      who:
+        type: string
+    steps:
+      - run: echo << parameters.who >>
+  other:
+    parameters:
+      who:
+        type: string
+    steps:
+      - run: echo << parameters.who >>
+
+jobs:
+  build:
+    parameters:
+      who:
+        type: string
+      version:
+        type: string
+    docker:
+      - image: cimg/base:<< parameters.version >>
+    steps:
+      - greet:
+          who: << parameters.who >>
+
+workflows:
+  main:
+    jobs:
+      - build:
+          name: build-<< matrix.version >>
+          who: me
+          matrix:
+            parameters:
+              version: ["1", "2"]
+`
+
+func TestRename(t *testing.T) {
+	cache := utils.CreateCache()
+	context := testHelpers.GetDefaultLsContext()
+	fileURI := uri.File("/tmp/rename.yml")
+
+	cache.FileCache.SetFile(utils.CachedFile{
+		TextDocument: protocol.TextDocumentItem{
+			URI:  fileURI,
+			Text: renameYaml,
+		},
+	})
+
+	rename := func(pos protocol.Position, newName string) ([]protocol.Range, error) {
+		edit, err := Rename(protocol.RenameParams{
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: fileURI},
+				Position:     pos,
+			},
+			NewName: newName,
+		}, cache, context)
+		if err != nil {
+			return nil, err
+		}
+
+		ranges := []protocol.Range{}
+		for _, textEdit := range edit.Changes[fileURI] {
+			assert.Equal(t, newName, textEdit.NewText)
+			ranges = append(ranges, textEdit.Range)
+		}
+		sort.Slice(ranges, func(i, j int) bool {
+			return ranges[i].Start.Line < ranges[j].Start.Line
+		})
+		return ranges, nil
+	}
+
+	rng := func(line, start, end uint32) protocol.Range {
+		return protocol.Range{
+			Start: protocol.Position{Line: line, Character: start},
+			End:   protocol.Position{Line: line, Character: end},
+		}
+	}
+
+	t.Run("Should rename a command parameter only within the command and its usages", func(t *testing.T) {
+		ranges, err := rename(protocol.Position{Line: 5, Character: 7}, "name")
+		assert.Nil(t, err)
+		assert.Equal(t, []protocol.Range{
+			rng(5, 6, 9),
+			rng(8, 32, 35),
+			rng(27, 10, 13),
+		}, ranges)
+	})
+
+	t.Run("Should rename a job parameter from one of its references", func(t *testing.T) {
+		ranges, err := rename(protocol.Position{Line: 24, Character: 40}, "tag")
+		assert.Nil(t, err)
+		assert.Equal(t, []protocol.Range{
+			rng(21, 6, 13),
+			rng(24, 39, 46),
+			rng(33, 32, 39),
+			rng(37, 14, 21),
+		}, ranges)
+
+		_, err = rename(protocol.Position{Line: 24, Character: 40}, "who")
+		assert.EqualError(t, err, "parameter who already exists")
+	})
+
+	t.Run("Should refuse invalid names", func(t *testing.T) {
+		_, err := rename(protocol.Position{Line: 5, Character: 7}, "not valid")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Should rename a command and its calls", func(t *testing.T) {
+		ranges, err := rename(protocol.Position{Line: 26, Character: 9}, "welcome")
+		assert.Nil(t, err)
+		assert.Equal(t, []protocol.Range{
+			rng(3, 2, 7),
+			rng(26, 8, 13),
+		}, ranges)
+
+		_, err = rename(protocol.Position{Line: 3, Character: 3}, "other")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Should only prepare renaming on parameters and commands", func(t *testing.T) {
+		prepare := func(pos protocol.Position) *protocol.Range {
+			res, err := PrepareRename(protocol.PrepareRenameParams{
+				TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+					TextDocument: protocol.TextDocumentIdentifier{URI: fileURI},
+					Position:     pos,
+				},
+			}, cache, context)
+			assert.Nil(t, err)
+			return res
+		}
+
+		expected := rng(14, 32, 35)
+		assert.Equal(t, &expected, prepare(protocol.Position{Line: 14, Character: 33}))
+		expected = rng(3, 2, 7)
+		assert.Equal(t, &expected, prepare(protocol.Position{Line: 3, Character: 3}))
+		assert.Nil(t, prepare(protocol.Position{Line: 0, Character: 3}))
+	})
+}
@@ -0,0 +1,189 @@
+package languageservice
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// Version of SARIF, the Static Analysis Results Interchange Format, read by
+// the code scanning dashboards
+const SARIFVersion = "2.1.0"
+
+const SARIFSchema = "https://docs.oasis-open.org/sarif/sarif/v2.1.0/errata01/os/schemas/sarif-schema-2.1.0.json"
+
+// Base of the relative URIs of the files, the dashboards resolving it to the
+// root of the repository
+const SARIFSourceRoot = "SRCROOT"
+
+type SARIFLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+type SARIFRun struct {
+	Tool               SARIFTool                        `json:"tool"`
+	OriginalURIBaseIDs map[string]SARIFArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	Results            []SARIFResult                    `json:"results"`
+}
+
+type SARIFTool struct {
+	Driver SARIFDriver `json:"driver"`
+}
+
+type SARIFDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []SARIFRule `json:"rules"`
+}
+
+type SARIFRule struct {
+	ID      string `json:"id"`
+	HelpURI string `json:"helpUri"`
+}
+
+type SARIFResult struct {
+	RuleID string `json:"ruleId,omitempty"`
+
+	// Index of the rule in the rules of the driver, -1 when the diagnostic
+	// has no rule
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   SARIFMessage    `json:"message"`
+	Locations []SARIFLocation `json:"locations"`
+}
+
+type SARIFMessage struct {
+	Text string `json:"text"`
+}
+
+type SARIFLocation struct {
+	PhysicalLocation SARIFPhysicalLocation `json:"physicalLocation"`
+}
+
+type SARIFPhysicalLocation struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+	Region           SARIFRegion           `json:"region"`
+}
+
+type SARIFArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+// Lines and columns are 1-based, the end column being right after the last
+// character of the region
+type SARIFRegion struct {
+	StartLine   uint32 `json:"startLine"`
+	StartColumn uint32 `json:"startColumn"`
+	EndLine     uint32 `json:"endLine"`
+	EndColumn   uint32 `json:"endColumn"`
+}
+
+var sarifLevels = map[protocol.DiagnosticSeverity]string{
+	protocol.DiagnosticSeverityError:       "error",
+	protocol.DiagnosticSeverityWarning:     "warning",
+	protocol.DiagnosticSeverityInformation: "note",
+	protocol.DiagnosticSeverityHint:        "note",
+}
+
+// Converts the diagnostics of the files to a SARIF log. The files in the root
+// directory are given relative to it, for the dashboards to find them in the
+// repository. The rules are the ones of the diagnostics, along with the page
+// documenting them
+func GetSARIFLog(files map[protocol.URI][]protocol.Diagnostic, root string) SARIFLog {
+	run := SARIFRun{
+		Tool: SARIFTool{
+			Driver: SARIFDriver{
+				Name:           "circleci-yaml-language-server",
+				Version:        utils.ServerVersion,
+				InformationURI: "https://github.com/CircleCI-Public/circleci-yaml-language-server",
+				Rules:          []SARIFRule{},
+			},
+		},
+		Results: []SARIFResult{},
+	}
+
+	if root != "" {
+		run.OriginalURIBaseIDs = map[string]SARIFArtifactLocation{
+			SARIFSourceRoot: {URI: strings.TrimSuffix(string(uri.File(root)), "/") + "/"},
+		}
+	}
+
+	fileURIs := make([]protocol.URI, 0, len(files))
+	for fileURI := range files {
+		fileURIs = append(fileURIs, fileURI)
+	}
+	sort.Slice(fileURIs, func(i, j int) bool { return fileURIs[i] < fileURIs[j] })
+
+	ruleIndexes := map[string]int{}
+	for _, fileURI := range fileURIs {
+		location := getSARIFArtifactLocation(fileURI, root)
+
+		for _, diagnostic := range files[fileURI] {
+			result := SARIFResult{
+				RuleID:    utils.GetDiagnosticRule(diagnostic),
+				RuleIndex: -1,
+				Level:     sarifLevels[diagnostic.Severity],
+				Message:   SARIFMessage{Text: diagnostic.Message},
+				Locations: []SARIFLocation{{
+					PhysicalLocation: SARIFPhysicalLocation{
+						ArtifactLocation: location,
+						Region: SARIFRegion{
+							StartLine:   diagnostic.Range.Start.Line + 1,
+							StartColumn: diagnostic.Range.Start.Character + 1,
+							EndLine:     diagnostic.Range.End.Line + 1,
+							EndColumn:   diagnostic.Range.End.Character + 1,
+						},
+					},
+				}},
+			}
+			if result.Level == "" {
+				result.Level = "error"
+			}
+
+			if result.RuleID != "" {
+				index, ok := ruleIndexes[result.RuleID]
+				if !ok {
+					index = len(run.Tool.Driver.Rules)
+					ruleIndexes[result.RuleID] = index
+					run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, SARIFRule{
+						ID:      result.RuleID,
+						HelpURI: utils.GetRuleDocumentation(result.RuleID),
+					})
+				}
+				result.RuleIndex = index
+			}
+
+			run.Results = append(run.Results, result)
+		}
+	}
+
+	return SARIFLog{
+		Schema:  SARIFSchema,
+		Version: SARIFVersion,
+		Runs:    []SARIFRun{run},
+	}
+}
+
+func getSARIFArtifactLocation(fileURI protocol.URI, root string) SARIFArtifactLocation {
+	if root == "" {
+		return SARIFArtifactLocation{URI: string(fileURI)}
+	}
+
+	relative, err := filepath.Rel(root, fileURI.Filename())
+	if err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return SARIFArtifactLocation{URI: string(fileURI)}
+	}
+
+	// The path is made a relative URI reference, its characters escaped the
+	// way they are in the file URIs
+	escaped := strings.TrimPrefix(string(uri.File("/"+filepath.ToSlash(relative))), "file:///")
+	return SARIFArtifactLocation{URI: escaped, URIBaseID: SARIFSourceRoot}
+}
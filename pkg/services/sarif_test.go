@@ -0,0 +1,101 @@
+package languageservice
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/xeipuuv/gojsonschema"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+func TestGetSARIFLog(t *testing.T) {
+
+	files := map[protocol.URI][]protocol.Diagnostic{
+		uri.File("/project/.circleci/config.yml"): {
+			utils.WithDiagnosticRule(utils.RuleUnknownJob, utils.CreateErrorDiagnosticFromRange(protocol.Range{
+				Start: protocol.Position{Line: 14, Character: 6},
+				End:   protocol.Position{Line: 14, Character: 12},
+			}, "Cannot find declaration for job test")),
+			utils.WithDiagnosticRule(utils.RuleOrbVersion, utils.CreateWarningDiagnosticFromRange(protocol.Range{
+				Start: protocol.Position{Line: 3, Character: 8},
+				End:   protocol.Position{Line: 3, Character: 28},
+			}, "New version available")),
+			utils.WithDiagnosticRule(utils.RuleUnknownJob, utils.CreateErrorDiagnosticFromRange(protocol.Range{
+				Start: protocol.Position{Line: 15, Character: 6},
+				End:   protocol.Position{Line: 15, Character: 14},
+			}, "Cannot find declaration for job deploy")),
+		},
+		uri.File("/elsewhere/no rule.yml"): {
+			utils.CreateHintDiagnosticFromRange(protocol.Range{}, "Hint without rule"),
+		},
+	}
+
+	log := GetSARIFLog(files, "/project")
+
+	t.Run("Should be valid SARIF", func(t *testing.T) {
+		// The official schema, the one referenced by the log
+		cwd, _ := os.Getwd()
+		schemaPath, _ := filepath.Abs(cwd + "/testdata/sarif-schema-2.1.0.json")
+		schema, err := gojsonschema.NewSchema(gojsonschema.NewReferenceLoader(string(uri.File(schemaPath))))
+		if !assert.Nil(t, err, "%s is needed, it is downloaded by `task test:sarif-schema`", schemaPath) {
+			return
+		}
+
+		result, err := schema.Validate(gojsonschema.NewGoLoader(log))
+		assert.Nil(t, err)
+		assert.Empty(t, result.Errors())
+
+		// The log is written as JSON by the command line
+		output, err := json.Marshal(log)
+		assert.Nil(t, err)
+		result, err = schema.Validate(gojsonschema.NewBytesLoader(output))
+		assert.Nil(t, err)
+		assert.Empty(t, result.Errors())
+	})
+
+	t.Run("Should describe each rule once", func(t *testing.T) {
+		assert.Equal(t, []SARIFRule{
+			{ID: utils.RuleUnknownJob, HelpURI: utils.RulesDocumentation},
+			{ID: utils.RuleOrbVersion, HelpURI: "https://circleci.com/docs/orb-intro/"},
+		}, log.Runs[0].Tool.Driver.Rules)
+	})
+
+	t.Run("Should convert the diagnostics to results", func(t *testing.T) {
+		results := log.Runs[0].Results
+		assert.Len(t, results, 4)
+
+		assert.Equal(t, SARIFResult{
+			RuleID:    utils.RuleUnknownJob,
+			RuleIndex: 0,
+			Level:     "error",
+			Message:   SARIFMessage{Text: "Cannot find declaration for job test"},
+			Locations: []SARIFLocation{{
+				PhysicalLocation: SARIFPhysicalLocation{
+					ArtifactLocation: SARIFArtifactLocation{URI: ".circleci/config.yml", URIBaseID: SARIFSourceRoot},
+					Region:           SARIFRegion{StartLine: 15, StartColumn: 7, EndLine: 15, EndColumn: 13},
+				},
+			}},
+		}, results[1])
+		assert.Equal(t, "warning", results[2].Level)
+		assert.Equal(t, 1, results[2].RuleIndex)
+
+		// Outside of the root, the files are given by their absolute URI
+		assert.Equal(t, SARIFResult{
+			RuleIndex: -1,
+			Level:     "note",
+			Message:   SARIFMessage{Text: "Hint without rule"},
+			Locations: []SARIFLocation{{
+				PhysicalLocation: SARIFPhysicalLocation{
+					ArtifactLocation: SARIFArtifactLocation{URI: "file:///elsewhere/no%20rule.yml"},
+					Region:           SARIFRegion{StartLine: 1, StartColumn: 1, EndLine: 1, EndColumn: 1},
+				},
+			}},
+		}, results[0])
+		assert.Equal(t, "file:///project/", log.Runs[0].OriginalURIBaseIDs[SARIFSourceRoot].URI)
+	})
+}
@@ -26,7 +26,9 @@ type SemanticTokenStruct struct {
 	tokens          *[]Tokens
 }
 
-var PARAM_REGEX, _ = regexp.Compile(`<<\s*(parameters|pipeline.parameters)\.([A-z0-9-_]*)\s*>>`)
+// Matches the innermost interpolations, so that in nested ones such as
+// << parameters.<< pipeline.parameters.name >> >> only the inner one is kept
+var PARAM_REGEX, _ = regexp.Compile(`<<\s*(parameters|pipeline|matrix|env)\.([A-Za-z0-9_.-]+)\s*>>`)
 
 func SemanticTokens(params protocol.SemanticTokensParams, cache *utils.Cache, context *utils.LsContext) protocol.SemanticTokens {
 	doc, err := parser.ParseFromUriWithCache(params.TextDocument.URI, cache, context)
@@ -125,8 +127,15 @@ func (sem SemanticTokenStruct) highlightBuiltInKeywords(keyNode *sitter.Node) {
 	}
 }
 
+// Each interpolation is split into the delimiters (operator), the namespace
+// (namespace) and the identifier (variable)
 func (sem SemanticTokenStruct) highlightParameters(valueNode *sitter.Node) {
-	sem.highlightWithRegex(valueNode, PARAM_REGEX)
+	sem.forEachMatch(valueNode, PARAM_REGEX, func(match []int, toPos func(int) protocol.Position) {
+		sem.addToken(toPos(match[0]), 2, 5, 0)
+		sem.addToken(toPos(match[2]), uint32(match[3]-match[2]), 1, 0)
+		sem.addToken(toPos(match[4]), uint32(match[5]-match[4]), 6, 0)
+		sem.addToken(toPos(match[1]-2), 2, 5, 0)
+	})
 }
 
 func (sem SemanticTokenStruct) highlightCacheKeys(valueNode *sitter.Node) {
@@ -167,6 +176,20 @@ func (sem SemanticTokenStruct) highlightOrbs(valueNode *sitter.Node) {
 }
 
 func (sem SemanticTokenStruct) highlightWithRegex(valueNode *sitter.Node, regex *regexp.Regexp) {
+	sem.forEachMatch(valueNode, regex, func(match []int, toPos func(int) protocol.Position) {
+		length := match[1] - match[0]
+
+		if length < 0 {
+			return
+		}
+
+		sem.addToken(toPos(match[0]), uint32(length), 0, 0)
+	})
+}
+
+// Calls fn with the submatch indexes of every match of the regex in the scalar
+// node, toPos converting an index of the node content to a document position
+func (sem SemanticTokenStruct) forEachMatch(valueNode *sitter.Node, regex *regexp.Regexp, fn func(match []int, toPos func(int) protocol.Position)) {
 	child := parser.GetFirstChild(valueNode)
 	isFlowNode := valueNode.Type() == "flow_node"
 	isBlockScalar := valueNode.Type() == "block_node" && child != nil && child.Type() == "block_scalar"
@@ -175,24 +198,21 @@ func (sem SemanticTokenStruct) highlightWithRegex(valueNode *sitter.Node, regex
 		return
 	}
 
-	content := sem.doc.GetRawNodeText(valueNode)
-	params := regex.FindAllIndex([]byte(content), -1)
-
-	for _, param := range params {
-		length := param[1] - param[0]
+	content := []byte(sem.doc.GetRawNodeText(valueNode))
+	toPos := func(index int) protocol.Position {
+		pos := utils.IndexToPos(index, content)
 
-		if length < 0 {
-			continue
+		// Only the first line of a flow node is shifted by its column
+		if isFlowNode && pos.Line == 0 {
+			pos.Character += valueNode.StartPoint().Column
 		}
 
-		startPos := utils.IndexToPos(param[0], []byte(content))
-		startPos.Line += valueNode.StartPoint().Row
-
-		if isFlowNode {
-			startPos.Character += valueNode.StartPoint().Column
-		}
+		pos.Line += valueNode.StartPoint().Row
+		return pos
+	}
 
-		sem.addToken(startPos, uint32(length), 0, 0)
+	for _, match := range regex.FindAllSubmatchIndex(content, -1) {
+		fn(match, toPos)
 	}
 }
 
@@ -0,0 +1,68 @@
+package languageservice
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/testHelpers"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+const semanticTokensYaml = `version: 2.1
+
+jobs:
+  build:
+    docker:
+      - image: cimg/base:<< pipeline.parameters.tag >>
+    parameters:
+      target:
+        type: string
+    steps:
+      - run: echo << parameters.target >> << pipeline.git.branch >>
+      - run:
+          command: |
+            make << matrix.os >>-<< env.ARCH >>
+      - run: echo << parameters.<< pipeline.parameters.name >> >>
+`
+
+func TestSemanticTokensInterpolations(t *testing.T) {
+	cache := utils.CreateCache()
+	fileURI := uri.File("/tmp/semantics.yml")
+	cache.FileCache.SetFile(utils.CachedFile{
+		TextDocument: protocol.TextDocumentItem{URI: fileURI, Text: semanticTokensYaml},
+	})
+
+	res := SemanticTokens(protocol.SemanticTokensParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: fileURI},
+	}, cache, testHelpers.GetDefaultLsContext())
+
+	// Decode the relative positions and only keep the interpolation tokens
+	type token struct{ line, char, length, tokenType uint32 }
+	tokens := []token{}
+	line, char := uint32(0), uint32(0)
+	for i := 0; i+4 < len(res.Data); i += 5 {
+		if res.Data[i] != 0 {
+			char = 0
+		}
+		line += res.Data[i]
+		char += res.Data[i+1]
+
+		if tokenType := res.Data[i+3]; tokenType == 5 || tokenType == 6 || (tokenType == 1 && line > 3) {
+			tokens = append(tokens, token{line, char, res.Data[i+2], tokenType})
+		}
+	}
+
+	assert.Equal(t, []token{
+		{5, 25, 2, 5}, {5, 28, 8, 1}, {5, 37, 14, 6}, {5, 52, 2, 5},
+
+		{10, 18, 2, 5}, {10, 21, 10, 1}, {10, 32, 6, 6}, {10, 39, 2, 5},
+		{10, 42, 2, 5}, {10, 45, 8, 1}, {10, 54, 10, 6}, {10, 65, 2, 5},
+
+		{13, 17, 2, 5}, {13, 20, 6, 1}, {13, 27, 2, 6}, {13, 30, 2, 5},
+		{13, 33, 2, 5}, {13, 36, 3, 1}, {13, 40, 4, 6}, {13, 45, 2, 5},
+
+		{14, 32, 2, 5}, {14, 35, 8, 1}, {14, 44, 15, 6}, {14, 60, 2, 5},
+	}, tokens)
+}
@@ -0,0 +1,116 @@
+package languageservice
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	yamlparser "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/services/hover"
+	utils "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"go.lsp.dev/protocol"
+)
+
+// Lists the parameters of the orb job or command whose parameters are being
+// written at the given position
+func SignatureHelp(params protocol.SignatureHelpParams, cache *utils.Cache, context *utils.LsContext) (*protocol.SignatureHelp, error) {
+	doc, err := yamlparser.ParseFromUriWithCache(params.TextDocument.URI, cache, context)
+	if err != nil {
+		return nil, err
+	}
+
+	name, found := getOrbInvocationAtPosition(doc, params.Position)
+	if !found {
+		return nil, nil
+	}
+
+	entity, _ := hover.LookupOrbEntity(doc, name, cache)
+	if entity == nil {
+		return nil, nil
+	}
+
+	paramNames := make([]string, 0, len(entity.Parameters))
+	for paramName := range entity.Parameters {
+		paramNames = append(paramNames, paramName)
+	}
+	sort.Strings(paramNames)
+
+	labels := []string{}
+	parameters := []protocol.ParameterInformation{}
+	for _, paramName := range paramNames {
+		param := entity.Parameters[paramName]
+		label := fmt.Sprintf("%s: %s", paramName, param.GetType())
+		if defaultValue, ok := hover.ParameterDefault(param); ok {
+			label += " = " + defaultValue
+		}
+
+		labels = append(labels, label)
+		parameters = append(parameters, protocol.ParameterInformation{
+			Label:         label,
+			Documentation: param.GetDescription(),
+		})
+	}
+
+	activeParameter := uint32(0)
+	if key := getKeyAtLine(doc, params.Position); key != "" {
+		for i, paramName := range paramNames {
+			if paramName == key {
+				activeParameter = uint32(i)
+				break
+			}
+		}
+	}
+
+	return &protocol.SignatureHelp{
+		Signatures: []protocol.SignatureInformation{
+			{
+				Label:           fmt.Sprintf("%s(%s)", name, strings.Join(labels, ", ")),
+				Documentation:   strings.TrimSpace(entity.Description),
+				Parameters:      parameters,
+				ActiveParameter: activeParameter,
+			},
+		},
+		ActiveParameter: activeParameter,
+	}, nil
+}
+
+// Returns the name of the orb job or command whose parameters include the
+// given position, either as a step or as a workflow job
+func getOrbInvocationAtPosition(doc yamlparser.YamlDocument, pos protocol.Position) (string, bool) {
+	// Steps come first, as they can be pre or post steps of a workflow job
+	for _, step := range getAllNamedSteps(doc) {
+		if strings.Contains(step.Name, "/") && utils.PosInRange(step.ParametersRange, pos) {
+			return step.Name, true
+		}
+	}
+
+	for _, workflow := range doc.Workflows {
+		for _, jobRef := range workflow.JobRefs {
+			if !strings.Contains(jobRef.JobName, "/") || !utils.PosInRange(jobRef.JobRefRange, pos) {
+				continue
+			}
+
+			if !utils.PosInRange(jobRef.JobNameRange, pos) {
+				return jobRef.JobName, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// Returns the key written on the line of the position, if any
+func getKeyAtLine(doc yamlparser.YamlDocument, pos protocol.Position) string {
+	lines := strings.Split(string(doc.Content), "\n")
+	if int(pos.Line) >= len(lines) {
+		return ""
+	}
+
+	line := strings.TrimLeft(lines[pos.Line], " -{,")
+	key, _, found := strings.Cut(line, ":")
+	if !found {
+		return ""
+	}
+
+	return strings.TrimSpace(key)
+}
@@ -0,0 +1,117 @@
+package languageservice
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/testHelpers"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+const signatureHelpYaml = `version: 2.1
+
+orbs:
+  tools: company/tools@1.0.0
+
+jobs:
+  build:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - tools/notify:
+          message: done
+          channel: builds
+
+workflows:
+  main:
+    jobs:
+      - tools/test:
+          retries: 2
+`
+
+func TestSignatureHelp(t *testing.T) {
+	cache := utils.CreateCache()
+	fileURI := uri.File("/tmp/signature.yml")
+	cache.FileCache.SetFile(utils.CachedFile{
+		TextDocument: protocol.TextDocumentItem{URI: fileURI, Text: signatureHelpYaml},
+	})
+	cache.OrbCache.SetOrb(&ast.OrbInfo{
+		OrbParsedAttributes: ast.OrbParsedAttributes{
+			Commands: map[string]ast.Command{
+				"notify": {
+					Name:        "notify",
+					Description: "Send a notification\n",
+					Parameters: map[string]ast.Parameter{
+						"message": ast.StringParameter{
+							BaseParameter: ast.BaseParameter{Name: "message", Description: "Text to send"},
+						},
+						"channel": ast.StringParameter{
+							BaseParameter: ast.BaseParameter{Name: "channel", HasDefault: true},
+							Default:       "general",
+						},
+					},
+				},
+			},
+			Jobs: map[string]ast.Job{
+				"test": {
+					Name: "test",
+					Parameters: map[string]ast.Parameter{
+						"retries": ast.IntegerParameter{
+							BaseParameter: ast.BaseParameter{Name: "retries"},
+						},
+					},
+				},
+			},
+		},
+	}, "company/tools@1.0.0")
+
+	signatureHelp := func(pos protocol.Position) *protocol.SignatureHelp {
+		res, err := SignatureHelp(protocol.SignatureHelpParams{
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: fileURI},
+				Position:     pos,
+			},
+		}, cache, testHelpers.GetDefaultLsContext())
+		assert.Nil(t, err)
+		return res
+	}
+
+	t.Run("Should list the parameters of an orb command", func(t *testing.T) {
+		res := signatureHelp(protocol.Position{Line: 11, Character: 14})
+		assert.Equal(t, &protocol.SignatureHelp{
+			Signatures: []protocol.SignatureInformation{
+				{
+					Label:         "tools/notify(channel: string = general, message: string)",
+					Documentation: "Send a notification",
+					Parameters: []protocol.ParameterInformation{
+						{Label: "channel: string = general", Documentation: ""},
+						{Label: "message: string", Documentation: "Text to send"},
+					},
+					ActiveParameter: 1,
+				},
+			},
+			ActiveParameter: 1,
+		}, res)
+	})
+
+	t.Run("Should highlight the parameter of the key at the cursor", func(t *testing.T) {
+		res := signatureHelp(protocol.Position{Line: 12, Character: 12})
+		assert.Equal(t, uint32(0), res.ActiveParameter)
+	})
+
+	t.Run("Should list the parameters of an orb job", func(t *testing.T) {
+		res := signatureHelp(protocol.Position{Line: 18, Character: 14})
+		assert.Equal(t, "tools/test(retries: integer)", res.Signatures[0].Label)
+	})
+
+	t.Run("Should not help on the name of the orb command", func(t *testing.T) {
+		assert.Nil(t, signatureHelp(protocol.Position{Line: 10, Character: 10}))
+	})
+
+	t.Run("Should not help outside of orb invocations", func(t *testing.T) {
+		assert.Nil(t, signatureHelp(protocol.Position{Line: 8, Character: 10}))
+	})
+}
@@ -0,0 +1,97 @@
+package languageservice
+
+import (
+	"fmt"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
+	yamlparser "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/parser"
+	utils "github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"go.lsp.dev/protocol"
+	"golang.org/x/mod/semver"
+)
+
+// Command returning the edit upgrading the orbs of all the opened files
+const UpgradeAllOrbsCommand = "upgradeAllOrbs"
+
+// Offers to upgrade the orbs of the selection that are not on their latest
+// version
+func upgradeOrbActions(doc yamlparser.YamlDocument, selection protocol.Range, cache *utils.Cache, context *utils.LsContext) []protocol.CodeAction {
+	actions := []protocol.CodeAction{}
+
+	for _, orb := range doc.Orbs {
+		if selection.Start.Line < orb.Range.Start.Line || selection.Start.Line > orb.Range.End.Line {
+			continue
+		}
+
+		edit, ok := getOrbUpgradeEdit(orb, cache, context)
+		if !ok {
+			continue
+		}
+
+		actions = append(actions, protocol.CodeAction{
+			Title: fmt.Sprintf("Upgrade to @%s", edit.NewText),
+			Kind:  protocol.RefactorRewrite,
+			Edit: &protocol.WorkspaceEdit{
+				Changes: map[protocol.DocumentURI][]protocol.TextEdit{
+					doc.URI: {edit},
+				},
+			},
+		})
+	}
+
+	return actions
+}
+
+// Upgrades the orbs of every file of the cache at once
+func UpgradeAllOrbs(cache *utils.Cache, context *utils.LsContext) protocol.WorkspaceEdit {
+	changes := map[protocol.DocumentURI][]protocol.TextEdit{}
+
+	for fileURI := range cache.FileCache.GetFiles() {
+		doc, err := yamlparser.ParseFromUriWithCache(fileURI, cache, context)
+		if err != nil {
+			continue
+		}
+
+		edits := []protocol.TextEdit{}
+		for _, orb := range doc.Orbs {
+			if edit, ok := getOrbUpgradeEdit(orb, cache, context); ok {
+				edits = append(edits, edit)
+			}
+		}
+
+		if len(edits) > 0 {
+			changes[fileURI] = edits
+		}
+	}
+
+	return protocol.WorkspaceEdit{Changes: changes}
+}
+
+// Rewrites the version of the orb to its latest one, fetched from the
+// registry when not cached. Local orbs and volatile or parameterized
+// versions are left untouched
+func getOrbUpgradeEdit(orb ast.Orb, cache *utils.Cache, context *utils.LsContext) (protocol.TextEdit, bool) {
+	if orb.Url.IsLocal || !semver.IsValid("v"+orb.Url.Version) {
+		return protocol.TextEdit{}, false
+	}
+
+	orbInfo, err := yamlparser.GetOrbInfo(orb.Url.GetOrbID(), cache, context)
+	if err != nil || orbInfo == nil {
+		return protocol.TextEdit{}, false
+	}
+
+	latest := orbInfo.RemoteInfo.LatestVersion
+	current := orbInfo.RemoteInfo.Version
+	if current == "" {
+		current = orb.Url.Version
+	}
+
+	if latest == "" || latest == orb.Url.Version || semver.Compare("v"+current, "v"+latest) >= 0 {
+		return protocol.TextEdit{}, false
+	}
+
+	return protocol.TextEdit{
+		Range:   orb.VersionRange,
+		NewText: latest,
+	}, true
+}
@@ -0,0 +1,76 @@
+package languageservice
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/testHelpers"
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+func TestUpgradeOrbs(t *testing.T) {
+	fileURI := uri.File("/tmp/upgradeOrbs.yml")
+	otherURI := uri.File("/tmp/upgradeOrbs-other.yml")
+	content := "version: 2.1\n\norbs:\n  node: circleci/node@5.0.1\n  slack: circleci/slack@4.12.5\n  go: circleci/go@volatile\n"
+
+	createCache := func() *utils.Cache {
+		cache := utils.CreateCache()
+		cache.FileCache.SetFile(utils.CachedFile{
+			TextDocument: protocol.TextDocumentItem{URI: fileURI, Text: content},
+		})
+		cache.OrbCache.SetOrb(&ast.OrbInfo{
+			RemoteInfo: ast.RemoteOrbInfo{Version: "5.0.1", LatestVersion: "5.2.0"},
+		}, "circleci/node@5.0.1")
+		cache.OrbCache.SetOrb(&ast.OrbInfo{
+			RemoteInfo: ast.RemoteOrbInfo{Version: "4.12.5", LatestVersion: "4.12.5"},
+		}, "circleci/slack@4.12.5")
+		return cache
+	}
+
+	getActions := func(t *testing.T, line uint32) []protocol.CodeAction {
+		position := protocol.Position{Line: line, Character: 4}
+		actions, err := CodeActions(protocol.CodeActionParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: fileURI},
+			Range:        protocol.Range{Start: position, End: position},
+		}, createCache(), testHelpers.GetDefaultLsContext())
+		assert.Nil(t, err)
+		return actions
+	}
+
+	t.Run("Should upgrade an outdated orb to its latest version", func(t *testing.T) {
+		actions := getActions(t, 3)
+
+		assert.Len(t, actions, 1)
+		assert.Equal(t, "Upgrade to @5.2.0", actions[0].Title)
+		assert.Equal(t,
+			"version: 2.1\n\norbs:\n  node: circleci/node@5.2.0\n  slack: circleci/slack@4.12.5\n  go: circleci/go@volatile\n",
+			applyTextEdits(content, actions[0].Edit.Changes[fileURI]))
+	})
+
+	t.Run("Should not offer anything for orbs on their latest version", func(t *testing.T) {
+		assert.Empty(t, getActions(t, 4))
+		assert.Empty(t, getActions(t, 5))
+		assert.Empty(t, getActions(t, 0))
+	})
+
+	t.Run("Should upgrade the orbs of every cached file", func(t *testing.T) {
+		cache := createCache()
+		otherContent := "version: 2.1\n\norbs:\n  node: circleci/node@5.0.1\n"
+		cache.FileCache.SetFile(utils.CachedFile{
+			TextDocument: protocol.TextDocumentItem{URI: otherURI, Text: otherContent},
+		})
+
+		edit := UpgradeAllOrbs(cache, testHelpers.GetDefaultLsContext())
+
+		assert.Len(t, edit.Changes, 2)
+		assert.Equal(t,
+			"version: 2.1\n\norbs:\n  node: circleci/node@5.2.0\n  slack: circleci/slack@4.12.5\n  go: circleci/go@volatile\n",
+			applyTextEdits(content, edit.Changes[fileURI]))
+		assert.Equal(t,
+			"version: 2.1\n\norbs:\n  node: circleci/node@5.2.0\n",
+			applyTextEdits(otherContent, edit.Changes[otherURI]))
+	})
+}
@@ -1,16 +1,28 @@
 package utils
 
 import (
+	"container/list"
+	"errors"
 	"fmt"
+	"log/slog"
+	"math"
+	"net/url"
 	"os"
 	"path"
+	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
 	"github.com/adrg/xdg"
 	"go.lsp.dev/protocol"
 )
 
+// Operations spanning several caches must acquire their locks in the order
+// of the fields below, use withLocks to do so
 type Cache struct {
 	FileCache          FileCache
 	OrbCache           OrbCache
@@ -22,12 +34,35 @@ type Cache struct {
 
 type DockerCache struct {
 	cacheMutex  *sync.Mutex
+	counters    *cacheCounters
 	dockerCache map[string]*CachedDockerImage
+
+	// Time after which an image that was not found is checked again
+	negativeTTL time.Duration
+
+	// Maximum number of images kept, the least recently used ones are evicted
+	// first. Zero means unbounded
+	maxEntries int
+	recency    *list.List
+	elements   map[string]*list.Element
+
+	pending *pendingCalls[*CachedDockerImage]
+	logger  *slog.Logger
 }
 
 type CachedDockerImage struct {
-	Checked bool
-	Exists  bool
+	Checked   bool
+	Exists    bool
+	CheckedAt time.Time
+
+	// Set when the image could not be checked, in which case Exists is
+	// meaningless
+	Err error
+}
+
+// Positive results are kept for the whole session, only negative ones expire
+func (img *CachedDockerImage) isExpired(now time.Time, negativeTTL time.Duration) bool {
+	return !img.Exists && negativeTTL > 0 && now.Sub(img.CheckedAt) > negativeTTL
 }
 
 type CachedDockerTags struct {
@@ -47,21 +82,87 @@ type CachedFile struct {
 	TextDocument protocol.TextDocumentItem
 	Project      Project
 	EnvVariables []string
+
+	// Whether all the environment variables of the project have been
+	// fetched
+	envVariablesResolved bool
 }
 
 type FileCache struct {
-	cacheMutex *sync.Mutex
+	cacheMutex *sync.RWMutex
+	counters   *cacheCounters
 	fileCache  map[protocol.URI]*CachedFile
+
+	// Remote orbs referenced by the open files, and the number of files
+	// referencing each orb ID. The sources of the referenced orbs are kept on
+	// disk, see RemoveOrbFiles
+	fileOrbs      map[protocol.URI][]string
+	orbReferences map[string]int
+
+	// Fetches of the environment variables of the projects in progress, by
+	// file
+	envVariablesCalls *pendingCalls[struct{}]
 }
 
 type OrbCache struct {
-	cacheMutex *sync.Mutex
-	orbsCache  map[string]*ast.OrbInfo
+	cacheMutex  *sync.RWMutex
+	counters    *cacheCounters
+	listeners   *changeListeners[string]
+	orbsCache   map[string]*CachedOrb
+	maxAge      time.Duration
+	stopSweeper chan struct{}
+
+	// Directory in which orbs are persisted across sessions, empty when
+	// persistence is disabled
+	persistenceDir string
+
+	// Set when the sources of the remote orbs are not written to disk, the
+	// orbs then only live for the session
+	inMemory bool
+
+	// Source of the remote orbs missing from the cache, nil when the public
+	// registry is used
+	fetcher OrbFetcher
+	pending *pendingCalls[*ast.OrbInfo]
+
+	// Orbs the open files reference, that expiring does not remove, nil when
+	// no file cache is attached
+	referencedOrbs func() map[string]bool
+}
+
+// Retrieves a remote orb from its ID, such as circleci/node@5.0.0. The result
+// is stored in the orb cache by the caller
+type OrbFetcher interface {
+	FetchOrb(orbID string) (*ast.OrbInfo, error)
+}
+
+type CachedOrb struct {
+	Orb *ast.OrbInfo
+
+	// When the orb was stored and how long it is valid for, a zero TTL means
+	// the entry never expires
+	StoredAt time.Time
+	TTL      time.Duration
+}
+
+func (orb *CachedOrb) isExpired(now time.Time) bool {
+	return orb.TTL > 0 && now.Sub(orb.StoredAt) > orb.TTL
 }
 
 type ContextCache struct {
-	cacheMutex   *sync.Mutex
+	cacheMutex   *sync.RWMutex
+	counters     *cacheCounters
+	listeners    *changeListeners[ContextChange]
 	contextCache map[string]map[string]*Context
+
+	// Organizations whose contexts have been fetched, mapped to the host they
+	// were fetched from
+	resolvedOrganizations map[string]string
+
+	// Organizations whose contexts have been requested, whether they are
+	// resolved or not, mapped to the host they were requested from
+	requestedOrganizations map[string]string
+	pending                *pendingCalls[struct{}]
 }
 
 type ResourceClassCache struct {
@@ -69,21 +170,209 @@ type ResourceClassCache struct {
 	resourceClassCache map[protocol.URI]*[]string
 }
 
-func (c *Cache) init() {
-	c.FileCache.fileCache = make(map[protocol.URI]*CachedFile)
-	c.FileCache.cacheMutex = &sync.Mutex{}
+type cacheCounters struct {
+	hits   atomic.Int64
+	misses atomic.Int64
+
+	// The lookups recorded with their key are logged at the debug level
+	name   string
+	logger *slog.Logger
+}
+
+func newCacheCounters(name string, logger *slog.Logger) *cacheCounters {
+	return &cacheCounters{name: name, logger: logger}
+}
+
+func (c *cacheCounters) record(hit bool) {
+	if hit {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+}
+
+func (c *cacheCounters) recordKey(key string, hit bool) {
+	c.record(hit)
+
+	if c.logger == nil {
+		return
+	}
+	if hit {
+		c.logger.Debug("Cache hit", "cache", c.name, "key", key)
+	} else {
+		c.logger.Debug("Cache miss", "cache", c.name, "key", key)
+	}
+}
+
+func (c *cacheCounters) reset() {
+	c.hits.Store(0)
+	c.misses.Store(0)
+}
+
+func (c *cacheCounters) stats(entries int) CacheStats {
+	return CacheStats{
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		Entries: entries,
+	}
+}
+
+type CacheStats struct {
+	Hits    int64 `json:"hits"`
+	Misses  int64 `json:"misses"`
+	Entries int   `json:"entries"`
+}
+
+type Stats struct {
+	FileCache    CacheStats `json:"fileCache"`
+	OrbCache     CacheStats `json:"orbCache"`
+	DockerCache  CacheStats `json:"dockerCache"`
+	ContextCache CacheStats `json:"contextCache"`
+}
+
+// Description of a cached orb, as returned by the orb cache dump
+type OrbCacheEntry struct {
+	ID            string    `json:"id"`
+	Version       string    `json:"version"`
+	LatestVersion string    `json:"latestVersion"`
+	FilePath      string    `json:"filePath"`
+	StoredAt      time.Time `json:"storedAt"`
+	Expired       bool      `json:"expired"`
+
+	Jobs      []string `json:"jobs"`
+	Commands  []string `json:"commands"`
+	Executors []string `json:"executors"`
+}
+
+// Default time after which a cached orb is considered stale and has to be
+// fetched again
+const DefaultOrbCacheTTL = 30 * time.Minute
+
+// Default time after which a Docker image that was not found, or could not
+// be checked, is checked again
+const DefaultDockerNegativeTTL = 5 * time.Minute
+
+type CacheOptions struct {
+	OrbTTL            time.Duration
+	OrbPersistenceDir string
+	InMemoryOrbs      bool
+	DockerNegativeTTL time.Duration
+	DockerMaxEntries  int
+	OrbFetcher        OrbFetcher
+	Logger            *slog.Logger
+	OrbSweeper        bool
+}
+
+type CacheOption func(*CacheOptions)
+
+// Set the time after which an orb entry expires, zero disables expiry
+func WithOrbTTL(ttl time.Duration) CacheOption {
+	return func(options *CacheOptions) {
+		options.OrbTTL = ttl
+	}
+}
+
+// Persist the remote orbs whose source is stored in the given directory so
+// they can be reloaded with LoadPersistedOrbs by another session
+func WithOrbPersistenceDir(dir string) CacheOption {
+	return func(options *CacheOptions) {
+		options.OrbPersistenceDir = dir
+	}
+}
+
+// Keep the remote orbs in memory only: their sources are not written to the
+// orb cache directory and nothing is persisted, for when the directory is
+// not writable
+func WithInMemoryOrbs() CacheOption {
+	return func(options *CacheOptions) {
+		options.InMemoryOrbs = true
+		options.OrbPersistenceDir = ""
+	}
+}
+
+// Set the time after which a negative Docker image result expires, zero
+// keeps them for the whole session
+func WithDockerNegativeTTL(ttl time.Duration) CacheOption {
+	return func(options *CacheOptions) {
+		options.DockerNegativeTTL = ttl
+	}
+}
 
-	c.OrbCache.orbsCache = make(map[string]*ast.OrbInfo)
-	c.OrbCache.cacheMutex = &sync.Mutex{}
+// Bound the number of Docker images kept in the cache, zero means unbounded
+func WithDockerCacheLimit(maxEntries int) CacheOption {
+	return func(options *CacheOptions) {
+		options.DockerMaxEntries = maxEntries
+	}
+}
+
+// Fetch the remote orbs with the given fetcher instead of the public
+// registry, to use a private host or a fake in tests
+func WithOrbFetcher(fetcher OrbFetcher) CacheOption {
+	return func(options *CacheOptions) {
+		options.OrbFetcher = fetcher
+	}
+}
+
+// Evict the expired orbs in the background, every orb TTL. Meant for long
+// lived servers, which must stop it with StopSweeper once done with the cache
+func WithOrbSweeper() CacheOption {
+	return func(options *CacheOptions) {
+		options.OrbSweeper = true
+	}
+}
+
+// Log the lookups of the orbs, the Docker images and the contexts, and the
+// checks of the images
+func WithLogger(logger *slog.Logger) CacheOption {
+	return func(options *CacheOptions) {
+		options.Logger = logger
+	}
+}
+
+func (c *Cache) init(options CacheOptions) {
+	logger := options.Logger
+	if logger == nil {
+		logger = DiscardLogger
+	}
+
+	c.FileCache.fileCache = make(map[protocol.URI]*CachedFile)
+	c.FileCache.cacheMutex = &sync.RWMutex{}
+	c.FileCache.counters = &cacheCounters{}
+	c.FileCache.fileOrbs = make(map[protocol.URI][]string)
+	c.FileCache.orbReferences = make(map[string]int)
+	c.FileCache.envVariablesCalls = newPendingCalls[struct{}]()
+
+	c.OrbCache.orbsCache = make(map[string]*CachedOrb)
+	c.OrbCache.cacheMutex = &sync.RWMutex{}
+	c.OrbCache.counters = newCacheCounters("orb", logger)
+	c.OrbCache.listeners = newChangeListeners[string]()
+	c.OrbCache.maxAge = options.OrbTTL
+	c.OrbCache.persistenceDir = options.OrbPersistenceDir
+	c.OrbCache.inMemory = options.InMemoryOrbs
+	c.OrbCache.fetcher = options.OrbFetcher
+	c.OrbCache.pending = newPendingCalls[*ast.OrbInfo]()
+	c.OrbCache.referencedOrbs = c.FileCache.getReferencedOrbs
 
 	c.DockerCache.cacheMutex = &sync.Mutex{}
+	c.DockerCache.counters = newCacheCounters("docker", logger)
 	c.DockerCache.dockerCache = make(map[string]*CachedDockerImage)
+	c.DockerCache.negativeTTL = options.DockerNegativeTTL
+	c.DockerCache.maxEntries = options.DockerMaxEntries
+	c.DockerCache.recency = list.New()
+	c.DockerCache.elements = make(map[string]*list.Element)
+	c.DockerCache.pending = newPendingCalls[*CachedDockerImage]()
+	c.DockerCache.logger = logger
 
 	c.DockerTagsCache.cacheMutex = &sync.Mutex{}
 	c.DockerTagsCache.tagsCache = make(map[string]CachedDockerTags)
 
-	c.ContextCache.cacheMutex = &sync.Mutex{}
+	c.ContextCache.cacheMutex = &sync.RWMutex{}
+	c.ContextCache.counters = newCacheCounters("context", logger)
+	c.ContextCache.listeners = newChangeListeners[ContextChange]()
 	c.ContextCache.contextCache = make(map[string]map[string]*Context)
+	c.ContextCache.resolvedOrganizations = make(map[string]string)
+	c.ContextCache.requestedOrganizations = make(map[string]string)
+	c.ContextCache.pending = newPendingCalls[struct{}]()
 
 	c.ResourceClassCache.cacheMutex = &sync.Mutex{}
 	c.ResourceClassCache.resourceClassCache = make(map[protocol.URI]*[]string)
@@ -98,121 +387,778 @@ func (c *FileCache) SetFile(cachedFile CachedFile) CachedFile {
 	return cachedFile
 }
 
-func (c *FileCache) GetFile(uri protocol.URI) *CachedFile {
+// Insert all the text documents under a single lock acquisition, files that
+// are already cached keep their project information
+// Returns the number of documents inserted
+func (c *FileCache) SetFiles(textDocuments []*protocol.TextDocumentItem) int {
 	c.cacheMutex.Lock()
 	defer c.cacheMutex.Unlock()
-	return c.fileCache[uri]
+
+	inserted := 0
+	for _, textDocument := range textDocuments {
+		if textDocument == nil {
+			continue
+		}
+
+		if file, ok := c.fileCache[textDocument.URI]; ok {
+			// Replace the entry rather than modifying it, readers may still
+			// hold the previous one
+			updated := *file
+			updated.TextDocument = *textDocument
+			c.fileCache[textDocument.URI] = &updated
+		} else {
+			c.fileCache[textDocument.URI] = &CachedFile{TextDocument: *textDocument}
+		}
+		inserted++
+	}
+
+	return inserted
 }
 
+func (c *FileCache) GetFile(uri protocol.URI) *CachedFile {
+	c.cacheMutex.RLock()
+	defer c.cacheMutex.RUnlock()
+
+	file, ok := c.fileCache[uri]
+	c.counters.record(ok)
+
+	return file
+}
+
+// Same as GetFile but only returns the text document, along with whether the
+// file is cached. The document is a copy, it is not affected by later changes
+func (c *FileCache) TryGetFile(uri protocol.URI) (*protocol.TextDocumentItem, bool) {
+	c.cacheMutex.RLock()
+	defer c.cacheMutex.RUnlock()
+
+	file, ok := c.fileCache[uri]
+	c.counters.record(ok)
+	if !ok {
+		return nil, false
+	}
+
+	textDocument := file.TextDocument
+	return &textDocument, true
+}
+
+// Returns a snapshot of the cached files, the map can be safely iterated
+// while the cache is being modified. The map is a shallow copy, the
+// *CachedFile values are still shared with the cache
 func (c *FileCache) GetFiles() map[protocol.URI]*CachedFile {
-	c.cacheMutex.Lock()
-	defer c.cacheMutex.Unlock()
-	return c.fileCache
+	c.cacheMutex.RLock()
+	defer c.cacheMutex.RUnlock()
+
+	files := make(map[protocol.URI]*CachedFile, len(c.fileCache))
+	for uri, file := range c.fileCache {
+		files[uri] = file
+	}
+
+	return files
 }
 
 func (c *FileCache) RemoveFile(uri protocol.URI) {
 	c.cacheMutex.Lock()
 	defer c.cacheMutex.Unlock()
 	delete(c.fileCache, uri)
+	c.setFileOrbs(uri, nil)
+}
+
+// Set the remote orbs the file references, replacing the ones it referenced
+// before. Closed files reference no orb
+func (c *FileCache) SetFileOrbs(uri protocol.URI, orbIDs []string) {
+	c.cacheMutex.Lock()
+	defer c.cacheMutex.Unlock()
+	c.setFileOrbs(uri, orbIDs)
+}
+
+// The lock must be held
+func (c *FileCache) setFileOrbs(uri protocol.URI, orbIDs []string) {
+	for _, orbID := range c.fileOrbs[uri] {
+		c.orbReferences[orbID]--
+		if c.orbReferences[orbID] <= 0 {
+			delete(c.orbReferences, orbID)
+		}
+	}
+	delete(c.fileOrbs, uri)
+
+	seen := map[string]bool{}
+	for _, orbID := range orbIDs {
+		if seen[orbID] {
+			continue
+		}
+		seen[orbID] = true
+		c.fileOrbs[uri] = append(c.fileOrbs[uri], orbID)
+		c.orbReferences[orbID]++
+	}
+}
+
+// Number of open files referencing the orb
+func (c *FileCache) OrbReferences(orbID string) int {
+	c.cacheMutex.RLock()
+	defer c.cacheMutex.RUnlock()
+	return c.orbReferences[orbID]
+}
+
+func (c *FileCache) getReferencedOrbs() map[string]bool {
+	c.cacheMutex.RLock()
+	defer c.cacheMutex.RUnlock()
+
+	referenced := make(map[string]bool, len(c.orbReferences))
+	for orbID := range c.orbReferences {
+		referenced[orbID] = true
+	}
+	return referenced
 }
 
 func (c *FileCache) AddEnvVariableToProjectLinkedToFile(uri protocol.URI, envVariable string) {
 	c.cacheMutex.Lock()
 	defer c.cacheMutex.Unlock()
-	project := c.fileCache[uri]
 
-	if FindInArray(project.EnvVariables, envVariable) < 0 {
-		project.EnvVariables = append(project.EnvVariables, envVariable)
+	file, ok := c.fileCache[uri]
+	if !ok || FindInArray(file.EnvVariables, envVariable) >= 0 {
+		return
 	}
-	c.fileCache[uri] = project
+
+	// Replace the entry rather than modifying it, readers may still hold the
+	// previous one. The variables are copied as well, appending could reuse
+	// their backing array
+	updated := *file
+	updated.EnvVariables = append(append(make([]string, 0, len(file.EnvVariables)+1), file.EnvVariables...), envVariable)
+	c.fileCache[uri] = &updated
+}
+
+// Returns a copy of the environment variables of the project linked to the
+// file
+func (c *FileCache) GetProjectEnvVariables(uri protocol.URI) []string {
+	c.cacheMutex.RLock()
+	defer c.cacheMutex.RUnlock()
+
+	file, ok := c.fileCache[uri]
+	if !ok {
+		return []string{}
+	}
+
+	return append([]string{}, file.EnvVariables...)
 }
 
 func (c *FileCache) AddProjectSlugToFile(uri protocol.URI, project Project) {
 	c.cacheMutex.Lock()
 	defer c.cacheMutex.Unlock()
-	file := c.fileCache[uri]
 
-	file.Project = project
+	file, ok := c.fileCache[uri]
+	if !ok {
+		return
+	}
+
+	// Replace the entry rather than modifying it, readers may still hold the
+	// previous one
+	updated := *file
+	updated.Project = project
+	updated.envVariablesResolved = false
+	c.fileCache[uri] = &updated
+}
+
+// Mark all the environment variables of the project linked to the file as
+// fetched: a variable missing from the file does not exist in the project
+func (c *FileCache) SetProjectEnvVariablesResolved(uri protocol.URI) {
+	c.cacheMutex.Lock()
+	defer c.cacheMutex.Unlock()
+
+	if file, ok := c.fileCache[uri]; ok {
+		updated := *file
+		updated.envVariablesResolved = true
+		c.fileCache[uri] = &updated
+	}
+}
+
+func (c *FileCache) IsProjectEnvVariablesResolved(uri protocol.URI) bool {
+	c.cacheMutex.RLock()
+	defer c.cacheMutex.RUnlock()
+
+	file, ok := c.fileCache[uri]
+	return ok && file.envVariablesResolved
+}
+
+// Forget the environment variables of the project linked to the file, before
+// fetching them again
+func (c *FileCache) ResetProjectEnvVariables(uri protocol.URI) {
+	c.cacheMutex.Lock()
+	defer c.cacheMutex.Unlock()
+
+	if file, ok := c.fileCache[uri]; ok {
+		updated := *file
+		updated.EnvVariables = []string{}
+		updated.envVariablesResolved = false
+		c.fileCache[uri] = &updated
+	}
+}
 
-	c.fileCache[uri] = file
+// Unlink the projects fetched from the given host from their files, along
+// with their environment variables
+func (c *FileCache) RemoveProjectsOfHost(host string) {
+	c.cacheMutex.Lock()
+	defer c.cacheMutex.Unlock()
+
+	for uri, file := range c.fileCache {
+		if file.Project.Host != host {
+			continue
+		}
+
+		// Replace the entry rather than modifying it, readers may still hold
+		// the previous one
+		updated := *file
+		updated.Project = Project{}
+		updated.EnvVariables = []string{}
+		updated.envVariablesResolved = false
+		c.fileCache[uri] = &updated
+	}
+}
+
+// Unlink every project from its file, along with its environment variables,
+// the files themselves are kept
+func (c *FileCache) RemoveProjects() {
+	c.cacheMutex.Lock()
+	defer c.cacheMutex.Unlock()
+
+	for uri, file := range c.fileCache {
+		updated := *file
+		updated.Project = Project{}
+		updated.EnvVariables = []string{}
+		updated.envVariablesResolved = false
+		c.fileCache[uri] = &updated
+	}
+}
+
+// Remove every file, along with the projects linked to them
+func (c *FileCache) Clear() {
+	c.cacheMutex.Lock()
+	defer c.cacheMutex.Unlock()
+	c.removeAll()
+}
+
+// The lock must be held
+func (c *FileCache) removeAll() {
+	c.fileCache = make(map[protocol.URI]*CachedFile)
+	c.fileOrbs = make(map[protocol.URI][]string)
+	c.orbReferences = make(map[string]int)
+}
+
+// Projects are not cached on their own but linked to the files of their
+// repository, lookups go through the files so removing a file or a host is
+// enough to keep them consistent
+
+// Returns the project with the given slug linked to any cached file
+func (c *FileCache) GetProjectBySlug(slug string) (Project, bool) {
+	c.cacheMutex.RLock()
+	defer c.cacheMutex.RUnlock()
+
+	for _, file := range c.fileCache {
+		if slug != "" && file.Project.Slug == slug {
+			c.counters.record(true)
+			return file.Project, true
+		}
+	}
+
+	c.counters.record(false)
+	return Project{}, false
+}
+
+// Returns the projects with the given name linked to the cached files, sorted
+// by slug. Projects of different organizations can share a name, in which
+// case all of them are returned and the caller has to choose using their
+// organization
+func (c *FileCache) GetProjectsByName(name string) []Project {
+	c.cacheMutex.RLock()
+	defer c.cacheMutex.RUnlock()
+
+	bySlug := map[string]Project{}
+	for _, file := range c.fileCache {
+		if name != "" && file.Project.Name == name {
+			bySlug[file.Project.Slug] = file.Project
+		}
+	}
+	c.counters.record(len(bySlug) > 0)
+
+	projects := make([]Project, 0, len(bySlug))
+	for _, project := range bySlug {
+		projects = append(projects, project)
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].Slug < projects[j].Slug })
+
+	return projects
 }
 
 func (c *FileCache) UpdateTextDocument(uri protocol.URI, textDocument protocol.TextDocumentItem) {
 	c.cacheMutex.Lock()
 	defer c.cacheMutex.Unlock()
-	file := c.fileCache[uri]
-	file.TextDocument = textDocument
 
-	c.fileCache[uri] = file
+	file, ok := c.fileCache[uri]
+	if !ok {
+		return
+	}
+
+	updated := *file
+	updated.TextDocument = textDocument
+	c.fileCache[uri] = &updated
 }
 
-// ORBS
+// The range of the change events is not a pointer in the protocol package,
+// full replacements can not be told apart from insertions at the start of the
+// document. Full replacements are given this range instead, see
+// methods.DidChange
+var FullDocumentRange = protocol.Range{
+	End: protocol.Position{Line: math.MaxUint32, Character: math.MaxUint32},
+}
 
-func (c *OrbCache) HasOrb(orbID string) bool {
+// Applies the change events to the text of the cached document, in order,
+// under a single lock acquisition and sets its version
+// Returns the updated document, false when the document is not cached
+func (c *FileCache) ApplyChanges(uri protocol.URI, changes []protocol.TextDocumentContentChangeEvent, version int32) (protocol.TextDocumentItem, bool) {
 	c.cacheMutex.Lock()
 	defer c.cacheMutex.Unlock()
 
-	_, ok := c.orbsCache[orbID]
+	file, ok := c.fileCache[uri]
+	if !ok {
+		return protocol.TextDocumentItem{}, false
+	}
+
+	text := file.TextDocument.Text
+	for _, change := range changes {
+		if change.Range == FullDocumentRange {
+			text = change.Text
+			continue
+		}
 
-	return ok
+		start := positionToOffset(change.Range.Start, text)
+		end := max(start, positionToOffset(change.Range.End, text))
+		text = text[:start] + change.Text + text[end:]
+	}
+
+	// Replace the entry rather than modifying it, readers may still hold the
+	// previous one
+	updated := *file
+	updated.TextDocument.Text = text
+	updated.TextDocument.Version = version
+	c.fileCache[uri] = &updated
+
+	return updated.TextDocument, true
+}
+
+// Byte offset of the position in the text. Characters are counted in UTF-16
+// code units, as the clients do, a position inside a character snaps to its
+// start. Positions past the end of their line or of the document are clamped
+func positionToOffset(pos protocol.Position, text string) int {
+	offset := 0
+	for line := uint32(0); line < pos.Line; line++ {
+		next := strings.IndexByte(text[offset:], '\n')
+		if next == -1 {
+			return len(text)
+		}
+		offset += next + 1
+	}
+
+	lineLength := strings.IndexByte(text[offset:], '\n')
+	if lineLength == -1 {
+		lineLength = len(text) - offset
+	}
+
+	line := text[offset : offset+lineLength]
+	units := 0
+	for i, r := range line {
+		// Characters out of the Basic Multilingual Plane are surrogate pairs
+		if r >= 0x10000 {
+			units += 2
+		} else {
+			units++
+		}
+		if units > int(pos.Character) {
+			return offset + i
+		}
+	}
+
+	return offset + lineLength
+}
+
+func (c *FileCache) stats() CacheStats {
+	c.cacheMutex.RLock()
+	defer c.cacheMutex.RUnlock()
+	return c.counters.stats(len(c.fileCache))
+}
+
+// ORBS
+
+// Fetcher the orbs missing from the cache are retrieved with, nil when none
+// was set with WithOrbFetcher
+func (c *OrbCache) Fetcher() OrbFetcher {
+	return c.fetcher
+}
+
+// Whether the sources of the remote orbs must not be written to disk, see
+// WithInMemoryOrbs
+func (c *OrbCache) IsInMemory() bool {
+	return c.inMemory
+}
+
+func (c *OrbCache) HasOrb(orbID string) bool {
+	c.cacheMutex.RLock()
+	defer c.cacheMutex.RUnlock()
+
+	cachedOrb, ok := c.orbsCache[orbID]
+	hit := ok && !cachedOrb.isExpired(time.Now())
+	c.counters.recordKey(orbID, hit)
+
+	return hit
 }
 
 func (c *OrbCache) SetOrb(orb *ast.OrbInfo, orbID string) ast.OrbInfo {
+	return c.SetOrbWithTTL(orb, orbID, c.maxAge)
+}
+
+// Same as SetOrb but the entry expires after the given TTL instead of the
+// cache default
+func (c *OrbCache) SetOrbWithTTL(orb *ast.OrbInfo, orbID string, ttl time.Duration) ast.OrbInfo {
+	cachedOrb := &CachedOrb{
+		Orb:      orb,
+		StoredAt: time.Now(),
+		TTL:      ttl,
+	}
+
 	c.cacheMutex.Lock()
-	defer c.cacheMutex.Unlock()
-	c.orbsCache[orbID] = orb
+	c.orbsCache[orbID] = cachedOrb
+	c.cacheMutex.Unlock()
+
+	// Persisting is best effort, the orb will simply be fetched again on the
+	// next session if it fails
+	c.persistOrb(orbID, cachedOrb)
+	c.listeners.notify(orbID)
+
 	return *orb
 }
 
 func (c *OrbCache) UpdateOrbParsedAttributes(orbID string, parsedOrbAttributes ast.OrbParsedAttributes) ast.OrbParsedAttributes {
 	c.cacheMutex.Lock()
-	defer c.cacheMutex.Unlock()
-	c.orbsCache[orbID].OrbParsedAttributes = parsedOrbAttributes
+	cachedOrb, ok := c.orbsCache[orbID]
+	if !ok {
+		// Evicted in the meantime
+		c.cacheMutex.Unlock()
+		return parsedOrbAttributes
+	}
+	cachedOrb.Orb.OrbParsedAttributes = parsedOrbAttributes
+	c.cacheMutex.Unlock()
+
+	c.listeners.notify(orbID)
 	return parsedOrbAttributes
 }
 
 func (c *OrbCache) GetOrb(orbID string) *ast.OrbInfo {
-	c.cacheMutex.Lock()
-	defer c.cacheMutex.Unlock()
-	return c.orbsCache[orbID]
+	c.cacheMutex.RLock()
+	defer c.cacheMutex.RUnlock()
+
+	cachedOrb, ok := c.orbsCache[orbID]
+	if !ok || cachedOrb.isExpired(time.Now()) {
+		c.counters.recordKey(orbID, false)
+		return nil
+	}
+
+	c.counters.recordKey(orbID, true)
+	return cachedOrb.Orb
+}
+
+// Returns the cached orb or else stores the one computed by create. Concurrent
+// calls for the same orb wait for a single computation, so that an orb is
+// only fetched once. Nothing is stored when create fails
+func (c *OrbCache) GetOrSet(orbID string, create func() (*ast.OrbInfo, error)) (*ast.OrbInfo, error) {
+	if orb := c.GetOrb(orbID); orb != nil {
+		return orb, nil
+	}
+
+	return c.pending.do(orbID, func() (*ast.OrbInfo, error) {
+		// Stored by a computation that ended since the first lookup
+		if orb := c.GetOrb(orbID); orb != nil {
+			return orb, nil
+		}
+
+		orb, err := create()
+		if err != nil {
+			return orb, err
+		}
+
+		c.SetOrb(orb, orbID)
+		return orb, nil
+	})
+}
+
+// Same as GetOrb along with whether the orb is cached, expired orbs are
+// reported as absent
+func (c *OrbCache) TryGetOrb(orbID string) (*ast.OrbInfo, bool) {
+	c.cacheMutex.RLock()
+	defer c.cacheMutex.RUnlock()
+
+	cachedOrb, ok := c.orbsCache[orbID]
+	hit := ok && !cachedOrb.isExpired(time.Now())
+	c.counters.recordKey(orbID, hit)
+	if !hit {
+		return nil, false
+	}
+
+	return cachedOrb.Orb, true
+}
+
+// Returns the IDs of the orbs currently cached, expired ones excluded
+func (c *OrbCache) OrbIDs() []string {
+	c.cacheMutex.RLock()
+	defer c.cacheMutex.RUnlock()
+
+	now := time.Now()
+	orbIDs := make([]string, 0, len(c.orbsCache))
+	for orbID, cachedOrb := range c.orbsCache {
+		if !cachedOrb.isExpired(now) {
+			orbIDs = append(orbIDs, orbID)
+		}
+	}
+
+	return orbIDs
+}
+
+// Calls fn for each orb of the cache, expired ones excluded, until it returns
+// false. The cache is locked during the iteration, fn must not use it
+func (c *OrbCache) Range(fn func(orbID string, orb *ast.OrbInfo) bool) {
+	c.cacheMutex.RLock()
+	defer c.cacheMutex.RUnlock()
+
+	now := time.Now()
+	for orbID, cachedOrb := range c.orbsCache {
+		if cachedOrb.isExpired(now) {
+			continue
+		}
+
+		if !fn(orbID, cachedOrb.Orb) {
+			return
+		}
+	}
+}
+
+// Returns a snapshot of the cached orbs, expired ones excluded. The map is a
+// shallow copy, the *ast.OrbInfo values are still shared with the cache
+func (c *OrbCache) GetAllOrbs() map[string]*ast.OrbInfo {
+	orbs := map[string]*ast.OrbInfo{}
+	c.Range(func(orbID string, orb *ast.OrbInfo) bool {
+		orbs[orbID] = orb
+		return true
+	})
+
+	return orbs
+}
+
+// Describes every orb of the cache, expired ones included, sorted by ID
+func (c *OrbCache) Snapshot() []OrbCacheEntry {
+	c.cacheMutex.RLock()
+	defer c.cacheMutex.RUnlock()
+
+	now := time.Now()
+	entries := make([]OrbCacheEntry, 0, len(c.orbsCache))
+	for orbID, cachedOrb := range c.orbsCache {
+		entry := OrbCacheEntry{
+			ID:        orbID,
+			StoredAt:  cachedOrb.StoredAt,
+			Expired:   cachedOrb.isExpired(now),
+			Jobs:      []string{},
+			Commands:  []string{},
+			Executors: []string{},
+		}
+
+		if orb := cachedOrb.Orb; orb != nil {
+			entry.Version = orb.RemoteInfo.Version
+			entry.LatestVersion = orb.RemoteInfo.LatestVersion
+			entry.FilePath = orb.RemoteInfo.FilePath
+			entry.Jobs = sortedKeys(orb.Jobs)
+			entry.Commands = sortedKeys(orb.Commands)
+			entry.Executors = sortedKeys(orb.Executors)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ID < entries[j].ID
+	})
+
+	return entries
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
 }
 
 func (c *OrbCache) RemoveOrb(orbID string) {
 	c.cacheMutex.Lock()
-	defer c.cacheMutex.Unlock()
 	delete(c.orbsCache, orbID)
+	c.cacheMutex.Unlock()
+
+	c.listeners.notify(orbID)
+}
+
+// Removes the orb from the cache along with its source file on disk, so that
+// it is fetched again instead of being read from the FS cache
+func (c *OrbCache) RemoveOrbWithFile(orbID string) {
+	c.cacheMutex.Lock()
+	if cachedOrb, ok := c.orbsCache[orbID]; ok {
+		removeOrbFile(cachedOrb.Orb)
+		delete(c.orbsCache, orbID)
+	}
+	c.cacheMutex.Unlock()
+
+	// The source of an expired orb may still be on disk
+	removeOrbFile(&ast.OrbInfo{RemoteInfo: ast.RemoteOrbInfo{FilePath: GetOrbCacheFSPath(orbID)}})
+
+	c.listeners.notify(orbID)
 }
 
 func (c *OrbCache) RemoveOrbs() {
 	c.cacheMutex.Lock()
-	defer c.cacheMutex.Unlock()
+	removed := make([]string, 0, len(c.orbsCache))
 	for k := range c.orbsCache {
 		delete(c.orbsCache, k)
+		removed = append(removed, k)
+	}
+	c.cacheMutex.Unlock()
+
+	c.listeners.notify(removed...)
+}
+
+func (c *OrbCache) stats() CacheStats {
+	c.cacheMutex.RLock()
+	defer c.cacheMutex.RUnlock()
+	return c.counters.stats(len(c.orbsCache))
+}
+
+// Remove every expired orb from the cache, along with its source file on
+// disk. The orbs that open files reference are kept, the files sharing their
+// source as well
+func (c *OrbCache) RemoveExpiredOrbs() {
+	// Collected before locking the orbs, RemoveOrbFiles locks the files first
+	referenced := map[string]bool{}
+	if c.referencedOrbs != nil {
+		referenced = c.referencedOrbs()
+	}
+
+	c.cacheMutex.Lock()
+
+	referencedFiles := map[string]bool{}
+	for orbID := range referenced {
+		if cachedOrb, ok := c.orbsCache[orbID]; ok && cachedOrb.Orb.RemoteInfo.FilePath != "" {
+			referencedFiles[cachedOrb.Orb.RemoteInfo.FilePath] = true
+		}
+	}
+
+	now := time.Now()
+	removed := []string{}
+	for orbID, cachedOrb := range c.orbsCache {
+		if !cachedOrb.isExpired(now) || referenced[orbID] {
+			continue
+		}
+		if referencedFiles[cachedOrb.Orb.RemoteInfo.FilePath] {
+			delete(c.orbsCache, orbID)
+			removed = append(removed, orbID)
+			continue
+		}
+
+		removeOrbFile(cachedOrb.Orb)
+		delete(c.orbsCache, orbID)
+		removed = append(removed, orbID)
 	}
+	c.cacheMutex.Unlock()
+
+	c.listeners.notify(removed...)
+}
+
+func (c *OrbCache) startSweeper(interval time.Duration) {
+	stop := make(chan struct{})
+	c.stopSweeper = stop
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				c.RemoveExpiredOrbs()
+			case <-stop:
+				return
+			}
+		}
+	}()
 }
 
+// Stop the background goroutine evicting expired orbs
+func (c *OrbCache) StopSweeper() {
+	if c.stopSweeper != nil {
+		close(c.stopSweeper)
+		c.stopSweeper = nil
+	}
+}
+
+// Remove the source files of the orbs from disk, except the ones of the orbs
+// that open files still reference. Another workspace sharing the persisted
+// cache would otherwise have to fetch them again
 func (c *Cache) RemoveOrbFiles() {
-	c.OrbCache.cacheMutex.Lock()
-	defer c.OrbCache.cacheMutex.Unlock()
-	c.FileCache.cacheMutex.Lock()
-	defer c.FileCache.cacheMutex.Unlock()
+	c.withLocks(func() {
+		// An orb can be cached under several IDs sharing its file, such as
+		// circleci/node@5 and the version it resolved to
+		referencedFiles := map[string]bool{}
+		for orbID := range c.FileCache.orbReferences {
+			if cachedOrb, ok := c.OrbCache.orbsCache[orbID]; ok {
+				referencedFiles[cachedOrb.Orb.RemoteInfo.FilePath] = true
+			}
+		}
 
-	for _, orb := range c.OrbCache.orbsCache {
-		if _, err := os.Stat(orb.RemoteInfo.FilePath); err == nil {
-			os.Remove(orb.RemoteInfo.FilePath)
+		for orbID, cachedOrb := range c.OrbCache.orbsCache {
+			if c.FileCache.orbReferences[orbID] > 0 || referencedFiles[cachedOrb.Orb.RemoteInfo.FilePath] {
+				continue
+			}
+			removeOrbFile(cachedOrb.Orb)
 		}
+	}, fileCacheLock, orbCacheLock)
+}
+
+func removeOrbFile(orb *ast.OrbInfo) {
+	if _, err := os.Stat(orb.RemoteInfo.FilePath); err == nil {
+		os.Remove(orb.RemoteInfo.FilePath)
+		os.Remove(getPersistedOrbFSPath(orb.RemoteInfo.FilePath))
 	}
 }
 
 // Docker images cache
 
 func (c *DockerCache) Add(name string, exists bool) *CachedDockerImage {
+	return c.AddWithError(name, exists, nil)
+}
+
+// Same as Add but records the error that happened while checking the image,
+// such results are treated like negative ones and checked again later
+func (c *DockerCache) AddWithError(name string, exists bool, err error) *CachedDockerImage {
 	c.cacheMutex.Lock()
 	defer c.cacheMutex.Unlock()
 
 	c.dockerCache[name] = &CachedDockerImage{
-		Checked: true,
-		Exists:  exists,
+		Checked:   true,
+		Exists:    exists && err == nil,
+		CheckedAt: time.Now(),
+		Err:       err,
+	}
+	c.touch(name)
+
+	if c.maxEntries > 0 {
+		for c.recency.Len() > c.maxEntries {
+			c.removeEntry(c.recency.Back().Value.(string))
+		}
 	}
 
 	return c.dockerCache[name]
@@ -222,14 +1168,104 @@ func (c *DockerCache) Get(name string) *CachedDockerImage {
 	c.cacheMutex.Lock()
 	defer c.cacheMutex.Unlock()
 
-	return c.dockerCache[name]
+	image, ok := c.dockerCache[name]
+	if ok && image.isExpired(time.Now(), c.negativeTTL) {
+		image, ok = nil, false
+	}
+	c.counters.recordKey(name, ok)
+
+	if ok {
+		c.touch(name)
+	}
+
+	return image
+}
+
+// Returns the cached image or else records the result of check. Concurrent
+// calls for the same image wait for a single check
+func (c *DockerCache) GetOrSet(name string, check func() (bool, error)) *CachedDockerImage {
+	if image := c.Get(name); image != nil {
+		return image
+	}
+
+	image, _ := c.pending.do(name, func() (*CachedDockerImage, error) {
+		if image := c.Get(name); image != nil {
+			return image, nil
+		}
+
+		start := time.Now()
+		exists, err := check()
+		if err != nil {
+			c.logger.Warn("Docker image could not be checked", "image", name, "error", err)
+		} else {
+			c.logger.Debug("Docker image checked", "image", name, "exists", exists, "duration", time.Since(start))
+		}
+
+		return c.AddWithError(name, exists, err), nil
+	})
+	return image
 }
 
 func (c *DockerCache) Remove(name string) {
 	c.cacheMutex.Lock()
 	defer c.cacheMutex.Unlock()
 
+	c.removeEntry(name)
+}
+
+// Removes the images whose check failed with the given error, so that they
+// are checked again, e.g. the ones recorded while offline
+func (c *DockerCache) RemoveWithError(target error) {
+	c.cacheMutex.Lock()
+	defer c.cacheMutex.Unlock()
+
+	for name, image := range c.dockerCache {
+		if image.Err != nil && errors.Is(image.Err, target) {
+			c.removeEntry(name)
+		}
+	}
+}
+
+// Mark the image as the most recently used, the lock must be held
+func (c *DockerCache) touch(name string) {
+	if c.maxEntries <= 0 {
+		return
+	}
+
+	if element, ok := c.elements[name]; ok {
+		c.recency.MoveToFront(element)
+		return
+	}
+
+	c.elements[name] = c.recency.PushFront(name)
+}
+
+func (c *DockerCache) removeEntry(name string) {
 	delete(c.dockerCache, name)
+
+	if element, ok := c.elements[name]; ok {
+		c.recency.Remove(element)
+		delete(c.elements, name)
+	}
+}
+
+func (c *DockerCache) Clear() {
+	c.cacheMutex.Lock()
+	defer c.cacheMutex.Unlock()
+	c.removeAll()
+}
+
+// The lock must be held
+func (c *DockerCache) removeAll() {
+	c.dockerCache = make(map[string]*CachedDockerImage)
+	c.recency.Init()
+	c.elements = make(map[string]*list.Element)
+}
+
+func (c *DockerCache) stats() CacheStats {
+	c.cacheMutex.Lock()
+	defer c.cacheMutex.Unlock()
+	return c.counters.stats(len(c.dockerCache))
 }
 
 // Docker tags cache
@@ -252,16 +1288,36 @@ func (c *DockerTagsCache) Get(namespace, image string) *CachedDockerTags {
 	return &tags
 }
 
+// The lock must be held
+func (c *DockerTagsCache) removeAll() {
+	c.tagsCache = make(map[string]CachedDockerTags)
+}
+
 // Cache
 
-func CreateCache() *Cache {
+func CreateCache(opts ...CacheOption) *Cache {
+	options := CacheOptions{
+		OrbTTL:            DefaultOrbCacheTTL,
+		DockerNegativeTTL: DefaultDockerNegativeTTL,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	cache := Cache{}
-	cache.init()
+	cache.init(options)
+
+	if options.OrbSweeper && options.OrbTTL > 0 {
+		cache.OrbCache.startSweeper(options.OrbTTL)
+	}
+
 	return &cache
 }
 
+// Path of the file caching the source of the orb. Each orb ID maps to a
+// single file of the cache directory, see getOrbCacheFileName
 func GetOrbCacheFSPath(orbYaml string) string {
-	file := path.Join("cci", "orbs", ".circleci", orbYaml+".yml")
+	file := path.Join("cci", "orbs", ".circleci", getOrbCacheFileName(orbYaml))
 	filePath, err := xdg.CacheFile(file)
 
 	if err != nil {
@@ -271,51 +1327,333 @@ func GetOrbCacheFSPath(orbYaml string) string {
 	return filePath
 }
 
-func (cache *Cache) ClearHostData() {
-	cache.RemoveOrbFiles()
-	cache.OrbCache.RemoveOrbs()
+// File name of an orb ID such as ns/orb@1.2.3: the characters other than
+// letters, digits, `-`, `_` and `.` are percent-encoded, `%` included. The
+// mapping is deterministic and without collisions, and the names never
+// contain a separator, so that an orb ID can not point outside of the
+// directory. GetOrbIDFromCacheFSPath reverses it
+func getOrbCacheFileName(orbID string) string {
+	var builder strings.Builder
+	for _, b := range []byte(orbID) {
+		switch {
+		case 'a' <= b && b <= 'z', 'A' <= b && b <= 'Z', '0' <= b && b <= '9', b == '-', b == '_', b == '.':
+			builder.WriteByte(b)
+		default:
+			builder.WriteString(fmt.Sprintf("%%%02X", b))
+		}
+	}
+
+	return builder.String() + ".yml"
+}
+
+// ID of the orb whose source is cached at the given path, as written by
+// GetOrbCacheFSPath. Returns false for the files outside of the cache
+func GetOrbIDFromCacheFSPath(filePath string) (string, bool) {
+	if filepath.Dir(filePath) != filepath.Clean(GetOrbCacheFSDir()) || filepath.Ext(filePath) != ".yml" {
+		return "", false
+	}
+
+	orbID, err := url.PathUnescape(strings.TrimSuffix(filepath.Base(filePath), ".yml"))
+	if err != nil {
+		return "", false
+	}
+
+	return orbID, true
+}
+
+func GetOrbCacheFSDir() string {
+	return path.Dir(GetOrbCacheFSPath("orb"))
+}
+
+// Check that files can be created in the directory, creating it if needed.
+// Read-only file systems and sandboxes only fail when writing
+func CheckDirWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return err
+	}
+	file.Close()
+
+	return os.Remove(file.Name())
+}
+
+func (cache *Cache) Stats() Stats {
+	return Stats{
+		FileCache:    cache.FileCache.stats(),
+		OrbCache:     cache.OrbCache.stats(),
+		DockerCache:  cache.DockerCache.stats(),
+		ContextCache: cache.ContextCache.stats(),
+	}
+}
+
+func (cache *Cache) ResetStats() {
+	cache.FileCache.counters.reset()
+	cache.OrbCache.counters.reset()
+	cache.DockerCache.counters.reset()
+	cache.ContextCache.counters.reset()
+}
+
+// Remove every orb, used when the whole host data is not valid anymore, for
+// example when switching from a self-hosted instance to cloud
+func (cache *Cache) ClearAllHostData() {
+	removed := []string{}
+
+	cache.withLocks(func() {
+		for orbID, cachedOrb := range cache.OrbCache.orbsCache {
+			removeOrbFile(cachedOrb.Orb)
+			delete(cache.OrbCache.orbsCache, orbID)
+			removed = append(removed, orbID)
+		}
+	}, fileCacheLock, orbCacheLock)
+
+	cache.OrbCache.listeners.notify(removed...)
+}
+
+// Remove the contexts and projects that were fetched from the given host,
+// entries of other hosts are left intact
+func (cache *Cache) ClearHostDataFor(host string) {
+	cache.ContextCache.RemoveContextsOfHost(host)
+	cache.FileCache.RemoveProjectsOfHost(host)
+}
+
+// Empty every cache at once, along with the orb files and the statistics.
+// All the locks are held meanwhile: readers either see the whole content or
+// nothing of it
+func (cache *Cache) Clear() {
+	removedOrbs := []string{}
+	removedContexts := []ContextChange{}
+
+	cache.withLocks(func() {
+		cache.FileCache.removeAll()
+
+		for orbID, cachedOrb := range cache.OrbCache.orbsCache {
+			removeOrbFile(cachedOrb.Orb)
+			removedOrbs = append(removedOrbs, orbID)
+		}
+		cache.OrbCache.orbsCache = make(map[string]*CachedOrb)
+
+		cache.DockerCache.removeAll()
+		cache.DockerTagsCache.removeAll()
+		cache.ResourceClassCache.resourceClassCache = make(map[protocol.URI]*[]string)
+		removedContexts = cache.ContextCache.removeAll()
+
+		cache.ResetStats()
+	}, fileCacheLock, orbCacheLock, dockerCacheLock, dockerTagsCacheLock, resourceClassCacheLock, contextCacheLock)
+
+	cache.OrbCache.listeners.notify(removedOrbs...)
+	cache.ContextCache.listeners.notify(removedContexts...)
 }
 
 // Context cache
 
 func (c *ContextCache) SetOrganizationContext(organizationId string, ctx *Context) *Context {
 	c.cacheMutex.Lock()
-	defer c.cacheMutex.Unlock()
 	if c.contextCache[organizationId] == nil {
 		c.contextCache[organizationId] = make(map[string]*Context)
 	}
 	c.contextCache[organizationId][ctx.Name] = ctx
+	c.cacheMutex.Unlock()
+
+	c.listeners.notify(ContextChange{OrganizationId: organizationId, Name: ctx.Name})
 	return ctx
 }
 
 func (c *ContextCache) GetOrganizationContext(organizationId string, name string) *Context {
-	c.cacheMutex.Lock()
-	defer c.cacheMutex.Unlock()
-	return c.contextCache[organizationId][name]
+	c.cacheMutex.RLock()
+	defer c.cacheMutex.RUnlock()
+
+	ctx, ok := c.contextCache[organizationId][name]
+	c.counters.recordKey(organizationId+"/"+name, ok)
+
+	return ctx
+}
+
+// Same as GetOrganizationContext along with whether the context is cached
+func (c *ContextCache) TryGetContext(organizationId string, name string) (*Context, bool) {
+	c.cacheMutex.RLock()
+	defer c.cacheMutex.RUnlock()
+
+	ctx, ok := c.contextCache[organizationId][name]
+	c.counters.recordKey(organizationId+"/"+name, ok)
+
+	return ctx, ok
 }
 
 func (c *ContextCache) RemoveOrganizationContext(organizationId string, name string) {
 	c.cacheMutex.Lock()
-	defer c.cacheMutex.Unlock()
 	org := c.contextCache[organizationId]
 	delete(org, name)
+	c.cacheMutex.Unlock()
+
+	c.listeners.notify(ContextChange{OrganizationId: organizationId, Name: name})
 }
 
+// Names of the environment variables of a cached context, nil when the context
+// is not cached
+func (c *ContextCache) GetContextEnvVariables(organizationId string, name string) []string {
+	c.cacheMutex.RLock()
+	defer c.cacheMutex.RUnlock()
+
+	ctx, ok := c.contextCache[organizationId][name]
+	c.counters.recordKey(organizationId+"/"+name, ok)
+	if !ok {
+		return nil
+	}
+
+	return ctx.EnvVariables()
+}
+
+// Adds a variable to a cached context, read back with
+// GetContextEnvVariables or (*Context).EnvVariables
 func (c *ContextCache) AddEnvVariableToOrganizationContext(organizationId string, name string, envVariable string) {
 	c.cacheMutex.Lock()
-	defer c.cacheMutex.Unlock()
 	ctx := c.contextCache[organizationId][name]
 
 	if FindInArray(ctx.envVariables, envVariable) < 0 {
 		ctx.envVariables = append(ctx.envVariables, envVariable)
 	}
 	c.contextCache[organizationId][name] = ctx
+	c.cacheMutex.Unlock()
+
+	c.listeners.notify(ContextChange{OrganizationId: organizationId, Name: name})
 }
 
-func (c *ContextCache) GetAllContextOfOrganization(organizationId string) map[string]*Context {
+func (c *ContextCache) RemoveContextsOfHost(host string) {
+	c.cacheMutex.Lock()
+	removed := []ContextChange{}
+	for organizationId, contexts := range c.contextCache {
+		for name, ctx := range contexts {
+			if ctx.Host != host {
+				continue
+			}
+
+			delete(contexts, name)
+			removed = append(removed, ContextChange{OrganizationId: organizationId, Name: name})
+		}
+	}
+	for organizationId, resolvedHost := range c.resolvedOrganizations {
+		if resolvedHost == host {
+			delete(c.resolvedOrganizations, organizationId)
+		}
+	}
+	for organizationId, requestedHost := range c.requestedOrganizations {
+		if requestedHost == host {
+			delete(c.requestedOrganizations, organizationId)
+		}
+	}
+	c.cacheMutex.Unlock()
+
+	c.listeners.notify(removed...)
+}
+
+// Mark all the contexts of the organization as fetched: a context missing
+// from the cache does not exist
+func (c *ContextCache) SetOrganizationResolved(organizationId string, host string) {
+	c.cacheMutex.Lock()
+	defer c.cacheMutex.Unlock()
+
+	c.resolvedOrganizations[organizationId] = host
+}
+
+func (c *ContextCache) IsOrganizationResolved(organizationId string) bool {
+	c.cacheMutex.RLock()
+	defer c.cacheMutex.RUnlock()
+
+	_, ok := c.resolvedOrganizations[organizationId]
+	return ok
+}
+
+// Record that the contexts of the organization have been requested. An
+// organization with more contexts than a page is never resolved, it is only
+// requested once all the same
+func (c *ContextCache) SetOrganizationRequested(organizationId string, host string) {
 	c.cacheMutex.Lock()
 	defer c.cacheMutex.Unlock()
-	return c.contextCache[organizationId]
+
+	c.requestedOrganizations[organizationId] = host
+}
+
+func (c *ContextCache) IsOrganizationRequested(organizationId string) bool {
+	c.cacheMutex.RLock()
+	defer c.cacheMutex.RUnlock()
+
+	_, ok := c.requestedOrganizations[organizationId]
+	return ok
+}
+
+// Returns a snapshot of the contexts of the organization, the map can be
+// safely iterated while the cache is being modified
+func (c *ContextCache) GetAllContextOfOrganization(organizationId string) map[string]*Context {
+	c.cacheMutex.RLock()
+	defer c.cacheMutex.RUnlock()
+
+	contexts, ok := c.contextCache[organizationId]
+	c.counters.recordKey(organizationId, ok)
+
+	snapshot := make(map[string]*Context, len(contexts))
+	for name, ctx := range contexts {
+		snapshot[name] = ctx
+	}
+
+	return snapshot
+}
+
+// Names of the cached contexts of the organization, sorted
+func (c *ContextCache) ContextNames(organizationId string) []string {
+	c.cacheMutex.RLock()
+	defer c.cacheMutex.RUnlock()
+
+	contexts, ok := c.contextCache[organizationId]
+	c.counters.recordKey(organizationId, ok)
+
+	names := make([]string, 0, len(contexts))
+	for name := range contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Remove every context, organizations have to be fetched again
+func (c *ContextCache) Clear() {
+	c.cacheMutex.Lock()
+	removed := c.removeAll()
+	c.cacheMutex.Unlock()
+
+	c.listeners.notify(removed...)
+}
+
+// The lock must be held, the listeners are to be notified of the returned
+// changes once it is released
+func (c *ContextCache) removeAll() []ContextChange {
+	removed := []ContextChange{}
+	for organizationId, contexts := range c.contextCache {
+		for name := range contexts {
+			removed = append(removed, ContextChange{OrganizationId: organizationId, Name: name})
+		}
+	}
+
+	c.contextCache = make(map[string]map[string]*Context)
+	c.resolvedOrganizations = make(map[string]string)
+	c.requestedOrganizations = make(map[string]string)
+	return removed
+}
+
+func (c *ContextCache) stats() CacheStats {
+	c.cacheMutex.RLock()
+	defer c.cacheMutex.RUnlock()
+
+	entries := 0
+	for _, contexts := range c.contextCache {
+		entries += len(contexts)
+	}
+
+	return c.counters.stats(entries)
 }
 
 // Resource class
@@ -0,0 +1,49 @@
+package utils
+
+import "sync"
+
+// Computations of cache entries in progress by key. A computation started
+// while another one is in progress for the same key waits for it and shares
+// its result instead of running again
+type pendingCalls[T any] struct {
+	mutex *sync.Mutex
+	calls map[string]*pendingCall[T]
+}
+
+type pendingCall[T any] struct {
+	done  chan struct{}
+	value T
+	err   error
+}
+
+func newPendingCalls[T any]() *pendingCalls[T] {
+	return &pendingCalls[T]{
+		mutex: &sync.Mutex{},
+		calls: make(map[string]*pendingCall[T]),
+	}
+}
+
+// Run compute for the key unless it is already running, in which case its
+// result is awaited. Must not be called while holding the cache lock
+func (p *pendingCalls[T]) do(key string, compute func() (T, error)) (T, error) {
+	p.mutex.Lock()
+	if call, ok := p.calls[key]; ok {
+		p.mutex.Unlock()
+		<-call.done
+		return call.value, call.err
+	}
+
+	call := &pendingCall[T]{done: make(chan struct{})}
+	p.calls[key] = call
+	p.mutex.Unlock()
+
+	defer func() {
+		p.mutex.Lock()
+		delete(p.calls, key)
+		p.mutex.Unlock()
+		close(call.done)
+	}()
+
+	call.value, call.err = compute()
+	return call.value, call.err
+}
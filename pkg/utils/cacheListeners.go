@@ -0,0 +1,67 @@
+package utils
+
+import "sync"
+
+// Set of callbacks invoked when a cache is modified
+type changeListeners[T any] struct {
+	mutex     *sync.Mutex
+	nextId    int
+	callbacks map[int]func(T)
+}
+
+func newChangeListeners[T any]() *changeListeners[T] {
+	return &changeListeners[T]{
+		mutex:     &sync.Mutex{},
+		callbacks: make(map[int]func(T)),
+	}
+}
+
+// Register a callback and return the function unregistering it
+func (l *changeListeners[T]) add(callback func(T)) func() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	id := l.nextId
+	l.nextId++
+	l.callbacks[id] = callback
+
+	return func() {
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+		delete(l.callbacks, id)
+	}
+}
+
+// Invoke every callback with each of the given changes. Must not be called
+// while holding the cache lock, so that a callback can use the cache
+func (l *changeListeners[T]) notify(changes ...T) {
+	l.mutex.Lock()
+	callbacks := make([]func(T), 0, len(l.callbacks))
+	for _, callback := range l.callbacks {
+		callbacks = append(callbacks, callback)
+	}
+	l.mutex.Unlock()
+
+	for _, change := range changes {
+		for _, callback := range callbacks {
+			callback(change)
+		}
+	}
+}
+
+type ContextChange struct {
+	OrganizationId string
+	Name           string
+}
+
+// Register a callback called with the ID of the orb every time an orb is
+// added, updated or removed. Returns the function unregistering it
+func (c *OrbCache) OnChange(callback func(orbID string)) func() {
+	return c.listeners.add(callback)
+}
+
+// Register a callback called every time a context is added, updated or
+// removed. Returns the function unregistering it
+func (c *ContextCache) OnChange(callback func(change ContextChange)) func() {
+	return c.listeners.add(callback)
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"sort"
+	"sync"
+)
+
+// Identifies the lock of one of the caches, the values follow the order in
+// which the locks must be acquired
+type cacheLock int
+
+const (
+	fileCacheLock cacheLock = iota
+	orbCacheLock
+	dockerCacheLock
+	dockerTagsCacheLock
+	resourceClassCacheLock
+	contextCacheLock
+)
+
+func (cache *Cache) locker(lock cacheLock) sync.Locker {
+	switch lock {
+	case fileCacheLock:
+		return cache.FileCache.cacheMutex
+	case orbCacheLock:
+		return cache.OrbCache.cacheMutex
+	case dockerCacheLock:
+		return cache.DockerCache.cacheMutex
+	case dockerTagsCacheLock:
+		return cache.DockerTagsCache.cacheMutex
+	case resourceClassCacheLock:
+		return cache.ResourceClassCache.cacheMutex
+	default:
+		return cache.ContextCache.cacheMutex
+	}
+}
+
+// Run fn while holding the given locks. The locks are always acquired in the
+// same order, whatever the order they are given in, which avoids deadlocks
+// between operations spanning several caches
+func (cache *Cache) withLocks(fn func(), locks ...cacheLock) {
+	ordered := make([]cacheLock, len(locks))
+	copy(ordered, locks)
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i] < ordered[j] })
+
+	for i, lock := range ordered {
+		if i > 0 && ordered[i-1] == lock {
+			continue
+		}
+
+		locker := cache.locker(lock)
+		locker.Lock()
+		defer locker.Unlock()
+	}
+
+	fn()
+}
@@ -0,0 +1,1103 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+)
+
+func TestOrbCacheTTL(t *testing.T) {
+	cache := CreateCache(WithOrbTTL(10 * time.Millisecond))
+
+	cache.OrbCache.SetOrb(&ast.OrbInfo{}, "circleci/node@1")
+	cache.OrbCache.SetOrbWithTTL(&ast.OrbInfo{}, "circleci/go@1", time.Hour)
+
+	assert.True(t, cache.OrbCache.HasOrb("circleci/node@1"))
+	assert.NotNil(t, cache.OrbCache.GetOrb("circleci/node@1"))
+
+	time.Sleep(20 * time.Millisecond)
+
+	assert.False(t, cache.OrbCache.HasOrb("circleci/node@1"))
+	assert.Nil(t, cache.OrbCache.GetOrb("circleci/node@1"))
+	assert.NotNil(t, cache.OrbCache.GetOrb("circleci/go@1"))
+}
+
+func TestOrbCacheSweeper(t *testing.T) {
+	filePath := path.Join(t.TempDir(), "node@1.yml")
+	assert.NoError(t, os.WriteFile(filePath, []byte("version: 2.1"), 0644))
+
+	// Expired orbs are only evicted on demand by default
+	cache := CreateCache(WithOrbTTL(time.Millisecond))
+	assert.Nil(t, cache.OrbCache.stopSweeper)
+
+	cache = CreateCache(WithOrbTTL(time.Millisecond), WithOrbSweeper())
+	cache.OrbCache.SetOrb(&ast.OrbInfo{
+		RemoteInfo: ast.RemoteOrbInfo{FilePath: filePath},
+	}, "circleci/node@1")
+
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(filePath)
+		return os.IsNotExist(err)
+	}, time.Second, time.Millisecond)
+
+	cache.OrbCache.StopSweeper()
+	assert.Nil(t, cache.OrbCache.stopSweeper)
+}
+
+func TestOrbCacheWithoutTTL(t *testing.T) {
+	cache := CreateCache(WithOrbTTL(0))
+
+	cache.OrbCache.SetOrb(&ast.OrbInfo{}, "circleci/node@1")
+	time.Sleep(5 * time.Millisecond)
+
+	assert.NotNil(t, cache.OrbCache.GetOrb("circleci/node@1"))
+}
+
+func TestRemoveExpiredOrbs(t *testing.T) {
+	cache := CreateCache(WithOrbTTL(0))
+	filePath := path.Join(t.TempDir(), "node@1.yml")
+	assert.NoError(t, os.WriteFile(filePath, []byte("version: 2.1"), 0644))
+
+	cache.OrbCache.SetOrbWithTTL(&ast.OrbInfo{
+		RemoteInfo: ast.RemoteOrbInfo{FilePath: filePath},
+	}, "circleci/node@1", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	cache.OrbCache.RemoveExpiredOrbs()
+
+	_, err := os.Stat(filePath)
+	assert.True(t, os.IsNotExist(err))
+	assert.False(t, cache.OrbCache.HasOrb("circleci/node@1"))
+}
+
+func TestRemoveExpiredOrbsKeepsReferencedOrbs(t *testing.T) {
+	cache := CreateCache(WithOrbTTL(0))
+	dir := t.TempDir()
+	referencedPath := path.Join(dir, "node@5.yml")
+	unusedPath := path.Join(dir, "go@1.yml")
+	for _, filePath := range []string{referencedPath, unusedPath} {
+		assert.NoError(t, os.WriteFile(filePath, []byte("version: 2.1"), 0644))
+	}
+
+	// circleci/node@5.1.0 is the version circleci/node@5 resolved to
+	for orbID, filePath := range map[string]string{
+		"circleci/node@5":     referencedPath,
+		"circleci/node@5.1.0": referencedPath,
+		"circleci/go@1":       unusedPath,
+	} {
+		cache.OrbCache.SetOrbWithTTL(&ast.OrbInfo{
+			RemoteInfo: ast.RemoteOrbInfo{FilePath: filePath},
+		}, orbID, time.Millisecond)
+	}
+	cache.FileCache.SetFileOrbs("file:///project/.circleci/config.yml", []string{"circleci/node@5"})
+	time.Sleep(5 * time.Millisecond)
+
+	cache.OrbCache.RemoveExpiredOrbs()
+
+	_, err := os.Stat(referencedPath)
+	assert.NoError(t, err)
+	_, err = os.Stat(unusedPath)
+	assert.True(t, os.IsNotExist(err))
+	orbIDs := []string{}
+	for _, entry := range cache.OrbCache.Snapshot() {
+		orbIDs = append(orbIDs, entry.ID)
+	}
+	assert.Equal(t, []string{"circleci/node@5"}, orbIDs)
+
+	// The orb can be evicted while its source is parsed
+	cache.FileCache.SetFileOrbs("file:///project/.circleci/config.yml", nil)
+	cache.OrbCache.RemoveExpiredOrbs()
+	assert.NotPanics(t, func() {
+		cache.OrbCache.UpdateOrbParsedAttributes("circleci/node@5", ast.OrbParsedAttributes{})
+	})
+}
+
+func BenchmarkConcurrentCacheReads(b *testing.B) {
+	cache := CreateCache(WithOrbTTL(0))
+	uri := protocol.URI("file:///home/circleci/project/.circleci/config.yml")
+	cache.FileCache.SetFile(CachedFile{
+		TextDocument: protocol.TextDocumentItem{URI: uri},
+	})
+	cache.OrbCache.SetOrb(&ast.OrbInfo{}, "circleci/node@1")
+
+	// RunParallel starts parallelism * GOMAXPROCS goroutines, aim for 8 readers
+	b.SetParallelism(max(1, 8/runtime.GOMAXPROCS(0)))
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			cache.FileCache.GetFile(uri)
+			cache.OrbCache.GetOrb("circleci/node@1")
+		}
+	})
+}
+
+func TestGetFilesReturnsSnapshot(t *testing.T) {
+	cache := CreateCache(WithOrbTTL(0))
+	uri := protocol.URI("file:///config.yml")
+	cache.FileCache.SetFile(CachedFile{
+		TextDocument: protocol.TextDocumentItem{URI: uri},
+	})
+
+	files := cache.FileCache.GetFiles()
+	cache.FileCache.RemoveFile(uri)
+
+	assert.Len(t, files, 1)
+	assert.Len(t, cache.FileCache.GetFiles(), 0)
+}
+
+func TestApplyChanges(t *testing.T) {
+	uri := protocol.URI("file:///config.yml")
+	text := "version: 2.1\njobs:\n  build:\n    steps:\n      - checkout\n"
+	rng := func(startLine, startChar, endLine, endChar uint32) protocol.Range {
+		return protocol.Range{
+			Start: protocol.Position{Line: startLine, Character: startChar},
+			End:   protocol.Position{Line: endLine, Character: endChar},
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		changes  []protocol.TextDocumentContentChangeEvent
+		expected string
+	}{
+		{
+			name: "Multiple ranges applied in order",
+			changes: []protocol.TextDocumentContentChangeEvent{
+				{Range: rng(2, 2, 2, 7), Text: "test"},
+				{Range: rng(4, 16, 4, 16), Text: "\n      - run: make test"},
+				{Range: rng(0, 0, 0, 0), Text: "# config\n"},
+			},
+			expected: "# config\nversion: 2.1\njobs:\n  test:\n    steps:\n      - checkout\n      - run: make test\n",
+		},
+		{
+			name: "Range spanning several lines",
+			changes: []protocol.TextDocumentContentChangeEvent{
+				{Range: rng(1, 0, 5, 0), Text: ""},
+			},
+			expected: "version: 2.1\n",
+		},
+		{
+			name: "Range past the end of the document",
+			changes: []protocol.TextDocumentContentChangeEvent{
+				{Range: rng(4, 6, 10, 0), Text: "- run: make\n"},
+			},
+			expected: "version: 2.1\njobs:\n  build:\n    steps:\n      - run: make\n",
+		},
+		{
+			name: "Full replacement followed by a range",
+			changes: []protocol.TextDocumentContentChangeEvent{
+				{Range: FullDocumentRange, Text: "version: 2\n"},
+				{Range: rng(0, 10, 0, 10), Text: ".1"},
+			},
+			expected: "version: 2.1\n",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := CreateCache(WithOrbTTL(0))
+			cache.FileCache.SetFile(CachedFile{
+				TextDocument: protocol.TextDocumentItem{URI: uri, Text: text, Version: 1},
+			})
+			previous := cache.FileCache.GetFile(uri)
+
+			textDocument, ok := cache.FileCache.ApplyChanges(uri, tt.changes, 2)
+
+			assert.True(t, ok)
+			assert.Equal(t, tt.expected, textDocument.Text)
+			assert.Equal(t, int32(2), textDocument.Version)
+			assert.Equal(t, textDocument, cache.FileCache.GetFile(uri).TextDocument)
+			assert.Equal(t, text, previous.TextDocument.Text)
+		})
+	}
+
+	t.Run("Document not cached", func(t *testing.T) {
+		cache := CreateCache(WithOrbTTL(0))
+		_, ok := cache.FileCache.ApplyChanges(uri, []protocol.TextDocumentContentChangeEvent{{Text: "version: 2.1"}}, 1)
+		assert.False(t, ok)
+	})
+}
+
+func TestApplyChangesUTF16Columns(t *testing.T) {
+	uri := protocol.URI("file:///config.yml")
+	// 🚀 is two UTF-16 code units and four bytes, é and à one unit and two bytes
+	text := "jobs:\n  build:\n    description: \"🚀 déjà vu\"\n"
+	rng := func(line, startChar, endChar uint32) protocol.Range {
+		return protocol.Range{
+			Start: protocol.Position{Line: line, Character: startChar},
+			End:   protocol.Position{Line: line, Character: endChar},
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		change   protocol.TextDocumentContentChangeEvent
+		expected string
+	}{
+		{
+			name:     "Range after multi-byte characters",
+			change:   protocol.TextDocumentContentChangeEvent{Range: rng(2, 21, 25), Text: "deja"},
+			expected: "jobs:\n  build:\n    description: \"🚀 deja vu\"\n",
+		},
+		{
+			name:     "Insertion after a surrogate pair",
+			change:   protocol.TextDocumentContentChangeEvent{Range: rng(2, 20, 20), Text: "!"},
+			expected: "jobs:\n  build:\n    description: \"🚀! déjà vu\"\n",
+		},
+		{
+			name:     "Position inside a surrogate pair",
+			change:   protocol.TextDocumentContentChangeEvent{Range: rng(2, 19, 19), Text: "x"},
+			expected: "jobs:\n  build:\n    description: \"x🚀 déjà vu\"\n",
+		},
+		{
+			name:     "Position past the end of the line",
+			change:   protocol.TextDocumentContentChangeEvent{Range: rng(2, 40, 40), Text: " # ok"},
+			expected: "jobs:\n  build:\n    description: \"🚀 déjà vu\" # ok\n",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := CreateCache(WithOrbTTL(0))
+			cache.FileCache.SetFile(CachedFile{
+				TextDocument: protocol.TextDocumentItem{URI: uri, Text: text, Version: 1},
+			})
+
+			textDocument, ok := cache.FileCache.ApplyChanges(uri, []protocol.TextDocumentContentChangeEvent{tt.change}, 2)
+
+			assert.True(t, ok)
+			assert.Equal(t, tt.expected, textDocument.Text)
+			assert.True(t, utf8.ValidString(textDocument.Text))
+		})
+	}
+}
+
+func TestTryGet(t *testing.T) {
+	cache := CreateCache(WithOrbTTL(0))
+	uri := protocol.URI("file:///config.yml")
+	cache.FileCache.SetFile(CachedFile{
+		TextDocument: protocol.TextDocumentItem{URI: uri, Text: "version: 2.1"},
+	})
+	cache.OrbCache.SetOrb(&ast.OrbInfo{}, "circleci/node@1")
+	cache.OrbCache.SetOrbWithTTL(&ast.OrbInfo{}, "circleci/go@1", time.Nanosecond)
+	cache.ContextCache.SetOrganizationContext("org-id", &Context{Name: "deploy"})
+	time.Sleep(time.Millisecond)
+
+	textDocument, ok := cache.FileCache.TryGetFile(uri)
+	assert.True(t, ok)
+	assert.Equal(t, "version: 2.1", textDocument.Text)
+	_, ok = cache.FileCache.TryGetFile("file:///other.yml")
+	assert.False(t, ok)
+
+	_, ok = cache.OrbCache.TryGetOrb("circleci/node@1")
+	assert.True(t, ok)
+	_, ok = cache.OrbCache.TryGetOrb("circleci/go@1")
+	assert.False(t, ok)
+	_, ok = cache.OrbCache.TryGetOrb("circleci/python@1")
+	assert.False(t, ok)
+
+	ctx, ok := cache.ContextCache.TryGetContext("org-id", "deploy")
+	assert.True(t, ok)
+	assert.Equal(t, "deploy", ctx.Name)
+	_, ok = cache.ContextCache.TryGetContext("org-id", "release")
+	assert.False(t, ok)
+}
+
+func TestCacheStats(t *testing.T) {
+	cache := CreateCache(WithOrbTTL(0))
+	cache.OrbCache.SetOrb(&ast.OrbInfo{}, "circleci/node@1")
+	cache.DockerCache.Add("cimg/go:1.21", true)
+
+	cache.OrbCache.GetOrb("circleci/node@1")
+	cache.OrbCache.HasOrb("circleci/go@1")
+	cache.DockerCache.Get("cimg/go:1.21")
+	cache.FileCache.GetFile("file:///missing.yml")
+
+	stats := cache.Stats()
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 1, Entries: 1}, stats.OrbCache)
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 0, Entries: 1}, stats.DockerCache)
+	assert.Equal(t, CacheStats{Hits: 0, Misses: 1, Entries: 0}, stats.FileCache)
+
+	cache.ResetStats()
+	stats = cache.Stats()
+	assert.Equal(t, CacheStats{Entries: 1}, stats.OrbCache)
+	assert.Equal(t, CacheStats{}, stats.FileCache)
+}
+
+func TestOrbCacheRange(t *testing.T) {
+	cache := CreateCache(WithOrbTTL(0))
+	cache.OrbCache.SetOrb(&ast.OrbInfo{}, "circleci/node@1")
+	cache.OrbCache.SetOrb(&ast.OrbInfo{}, "circleci/go@1")
+	cache.OrbCache.SetOrbWithTTL(&ast.OrbInfo{}, "circleci/python@1", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+
+	visited := []string{}
+	cache.OrbCache.Range(func(orbID string, orb *ast.OrbInfo) bool {
+		visited = append(visited, orbID)
+		return true
+	})
+	assert.ElementsMatch(t, []string{"circleci/node@1", "circleci/go@1"}, visited)
+
+	calls := 0
+	cache.OrbCache.Range(func(orbID string, orb *ast.OrbInfo) bool {
+		calls++
+		return false
+	})
+	assert.Equal(t, 1, calls)
+
+	orbs := cache.OrbCache.GetAllOrbs()
+	assert.Len(t, orbs, 2)
+	delete(orbs, "circleci/node@1")
+	orbs["circleci/ruby@1"] = &ast.OrbInfo{}
+	assert.True(t, cache.OrbCache.HasOrb("circleci/node@1"))
+	assert.False(t, cache.OrbCache.HasOrb("circleci/ruby@1"))
+}
+
+func TestOrbCacheSnapshot(t *testing.T) {
+	cache := CreateCache(WithOrbTTL(0))
+	cache.OrbCache.SetOrb(&ast.OrbInfo{
+		OrbParsedAttributes: ast.OrbParsedAttributes{
+			Jobs:     map[string]ast.Job{"test": {}, "install": {}},
+			Commands: map[string]ast.Command{"install-packages": {}},
+		},
+		RemoteInfo: ast.RemoteOrbInfo{
+			Version:       "5.0.0",
+			LatestVersion: "5.1.0",
+			FilePath:      "/tmp/circleci/node@5.0.0.yml",
+		},
+	}, "circleci/node@5.0.0")
+	cache.OrbCache.SetOrbWithTTL(&ast.OrbInfo{}, "circleci/go@1", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+
+	entries := cache.OrbCache.Snapshot()
+	assert.Len(t, entries, 2)
+
+	assert.Equal(t, "circleci/go@1", entries[0].ID)
+	assert.True(t, entries[0].Expired)
+	assert.Equal(t, []string{}, entries[0].Jobs)
+
+	assert.Equal(t, "circleci/node@5.0.0", entries[1].ID)
+	assert.False(t, entries[1].Expired)
+	assert.Equal(t, "5.0.0", entries[1].Version)
+	assert.Equal(t, "5.1.0", entries[1].LatestVersion)
+	assert.Equal(t, "/tmp/circleci/node@5.0.0.yml", entries[1].FilePath)
+	assert.Equal(t, []string{"install", "test"}, entries[1].Jobs)
+	assert.Equal(t, []string{"install-packages"}, entries[1].Commands)
+	assert.Equal(t, []string{}, entries[1].Executors)
+}
+
+func TestRemoveOrbWithFile(t *testing.T) {
+	cache := CreateCache(WithOrbTTL(0))
+	filePath := path.Join(t.TempDir(), "node@5.yml")
+	assert.NoError(t, os.WriteFile(filePath, []byte("version: 2.1"), 0644))
+
+	cache.OrbCache.SetOrb(&ast.OrbInfo{RemoteInfo: ast.RemoteOrbInfo{FilePath: filePath}}, "circleci/node@5")
+	cache.OrbCache.SetOrb(&ast.OrbInfo{}, "circleci/go@1")
+
+	cache.OrbCache.RemoveOrbWithFile("circleci/node@5")
+
+	assert.Nil(t, cache.OrbCache.GetOrb("circleci/node@5"))
+	assert.NotNil(t, cache.OrbCache.GetOrb("circleci/go@1"))
+	_, err := os.Stat(filePath)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestLoadPersistedOrbs(t *testing.T) {
+	dir := t.TempDir()
+	filePath := path.Join(dir, "circleci", "node@1.0.0.yml")
+	assert.NoError(t, os.MkdirAll(path.Dir(filePath), 0755))
+	assert.NoError(t, os.WriteFile(filePath, []byte("version: 2.1"), 0644))
+
+	removedFilePath := path.Join(dir, "circleci", "go@1.0.0.yml")
+	assert.NoError(t, os.WriteFile(removedFilePath, []byte("version: 2.1"), 0644))
+
+	previous := CreateCache(WithOrbTTL(0), WithOrbPersistenceDir(dir))
+	previous.OrbCache.SetOrb(&ast.OrbInfo{
+		Description: "Node orb",
+		RemoteInfo:  ast.RemoteOrbInfo{FilePath: filePath, Version: "1.0.0"},
+	}, "circleci/node@1.0.0")
+	previous.OrbCache.SetOrb(&ast.OrbInfo{
+		RemoteInfo: ast.RemoteOrbInfo{FilePath: removedFilePath, Version: "1.0.0"},
+	}, "circleci/go@1.0.0")
+	assert.NoError(t, os.Remove(removedFilePath))
+
+	cache := CreateCache(WithOrbTTL(0), WithOrbPersistenceDir(dir))
+	loaded := cache.LoadPersistedOrbs(func(source []byte, filePath string) (ast.OrbParsedAttributes, error) {
+		return ast.OrbParsedAttributes{Name: string(source)}, nil
+	})
+
+	assert.Equal(t, 1, loaded)
+	orb := cache.OrbCache.GetOrb("circleci/node@1.0.0")
+	assert.NotNil(t, orb)
+	assert.Equal(t, "Node orb", orb.Description)
+	assert.Equal(t, "version: 2.1", orb.Name)
+	assert.Nil(t, cache.OrbCache.GetOrb("circleci/go@1.0.0"))
+}
+
+func TestCheckDirWritable(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, CheckDirWritable(path.Join(dir, "cci", "orbs")))
+
+	entries, err := os.ReadDir(path.Join(dir, "cci", "orbs"))
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	// A directory can not be created under a file, this is also the case for
+	// the root user, unlike the permissions of a directory
+	file := path.Join(dir, "file")
+	assert.NoError(t, os.WriteFile(file, []byte{}, 0644))
+	assert.Error(t, CheckDirWritable(path.Join(file, "orbs")))
+
+	if runtime.GOOS != "windows" && os.Geteuid() != 0 {
+		readOnly := path.Join(dir, "read-only")
+		assert.NoError(t, os.Mkdir(readOnly, 0555))
+		assert.Error(t, CheckDirWritable(readOnly))
+	}
+}
+
+func TestInMemoryOrbs(t *testing.T) {
+	dir := t.TempDir()
+	filePath := path.Join(dir, "node@1.0.0.yml")
+	assert.NoError(t, os.WriteFile(filePath, []byte("version: 2.1"), 0644))
+
+	previous := CreateCache(WithOrbTTL(0), WithOrbPersistenceDir(dir))
+	previous.OrbCache.SetOrb(&ast.OrbInfo{
+		RemoteInfo: ast.RemoteOrbInfo{FilePath: filePath, Version: "1.0.0"},
+	}, "circleci/node@1.0.0")
+
+	cache := CreateCache(WithOrbTTL(0), WithOrbPersistenceDir(dir), WithInMemoryOrbs())
+	assert.True(t, cache.OrbCache.IsInMemory())
+	assert.False(t, previous.OrbCache.IsInMemory())
+
+	// The orbs persisted by another session are not loaded
+	loaded := cache.LoadPersistedOrbs(func(source []byte, filePath string) (ast.OrbParsedAttributes, error) {
+		return ast.OrbParsedAttributes{}, nil
+	})
+	assert.Equal(t, 0, loaded)
+	assert.Nil(t, cache.OrbCache.GetOrb("circleci/node@1.0.0"))
+
+	// Nor are the orbs of the session persisted
+	goFilePath := path.Join(dir, "go@1.0.0.yml")
+	cache.OrbCache.SetOrb(&ast.OrbInfo{
+		RemoteInfo: ast.RemoteOrbInfo{FilePath: goFilePath, Version: "1.0.0"},
+	}, "circleci/go@1.0.0")
+	assert.NotNil(t, cache.OrbCache.GetOrb("circleci/go@1.0.0"))
+	_, err := os.Stat(getPersistedOrbFSPath(goFilePath))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestLoadPersistedOrbsIgnoresOtherFormatVersions(t *testing.T) {
+	dir := t.TempDir()
+	filePath := path.Join(dir, "node@1.0.0.yml")
+	assert.NoError(t, os.WriteFile(filePath, []byte("version: 2.1"), 0644))
+	assert.NoError(t, os.WriteFile(
+		path.Join(dir, "node@1.0.0.json"),
+		[]byte(`{"formatVersion": 0, "orbId": "circleci/node@1.0.0", "remoteInfo": {"FilePath": "`+filePath+`"}}`),
+		0644,
+	))
+
+	cache := CreateCache(WithOrbTTL(0), WithOrbPersistenceDir(dir))
+	loaded := cache.LoadPersistedOrbs(func(source []byte, filePath string) (ast.OrbParsedAttributes, error) {
+		return ast.OrbParsedAttributes{}, nil
+	})
+
+	assert.Equal(t, 0, loaded)
+}
+
+func TestDockerCacheNegativeTTL(t *testing.T) {
+	cache := CreateCache(WithOrbTTL(0), WithDockerNegativeTTL(10*time.Millisecond))
+
+	cache.DockerCache.Add("cimg/go:1.21", true)
+	cache.DockerCache.Add("cimg/unknown:1.0", false)
+	cache.DockerCache.AddWithError("cimg/node:20.0", true, errors.New("registry unreachable"))
+
+	assert.NotNil(t, cache.DockerCache.Get("cimg/unknown:1.0"))
+	failed := cache.DockerCache.Get("cimg/node:20.0")
+	assert.False(t, failed.Exists)
+	assert.Error(t, failed.Err)
+
+	time.Sleep(20 * time.Millisecond)
+
+	assert.True(t, cache.DockerCache.Get("cimg/go:1.21").Exists)
+	assert.Nil(t, cache.DockerCache.Get("cimg/unknown:1.0"))
+	assert.Nil(t, cache.DockerCache.Get("cimg/node:20.0"))
+}
+
+func TestDockerCacheLRU(t *testing.T) {
+	cache := CreateCache(WithOrbTTL(0), WithDockerCacheLimit(2))
+
+	cache.DockerCache.Add("cimg/go:1.21", true)
+	cache.DockerCache.Add("cimg/node:20.0", true)
+	cache.DockerCache.Get("cimg/go:1.21")
+	cache.DockerCache.Add("cimg/rust:1.70", true)
+
+	assert.NotNil(t, cache.DockerCache.Get("cimg/go:1.21"))
+	assert.Nil(t, cache.DockerCache.Get("cimg/node:20.0"))
+	assert.NotNil(t, cache.DockerCache.Get("cimg/rust:1.70"))
+
+	cache.DockerCache.Remove("cimg/go:1.21")
+	cache.DockerCache.Add("cimg/python:3.11", true)
+	assert.NotNil(t, cache.DockerCache.Get("cimg/rust:1.70"))
+	assert.NotNil(t, cache.DockerCache.Get("cimg/python:3.11"))
+}
+
+func TestOrbCacheGetOrSet(t *testing.T) {
+	cache := CreateCache(WithOrbTTL(0))
+
+	created := atomic.Int32{}
+	create := func() (*ast.OrbInfo, error) {
+		created.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		return &ast.OrbInfo{Description: "fetched"}, nil
+	}
+
+	orbs := make([]*ast.OrbInfo, 20)
+	wg := sync.WaitGroup{}
+	for i := range orbs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			orb, err := cache.OrbCache.GetOrSet("circleci/node@5.0.0", create)
+			assert.NoError(t, err)
+			orbs[i] = orb
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), created.Load())
+	for _, orb := range orbs {
+		assert.Same(t, orbs[0], orb)
+	}
+	assert.Same(t, orbs[0], cache.OrbCache.GetOrb("circleci/node@5.0.0"))
+
+	// Failures are not stored, the next lookup computes the orb again
+	_, err := cache.OrbCache.GetOrSet("circleci/go@1.0.0", func() (*ast.OrbInfo, error) {
+		return nil, errors.New("could not find orb")
+	})
+	assert.Error(t, err)
+	assert.False(t, cache.OrbCache.HasOrb("circleci/go@1.0.0"))
+
+	orb, err := cache.OrbCache.GetOrSet("circleci/go@1.0.0", create)
+	assert.NoError(t, err)
+	assert.Equal(t, "fetched", orb.Description)
+	assert.Equal(t, int32(2), created.Load())
+}
+
+func TestDockerCacheGetOrSet(t *testing.T) {
+	cache := CreateCache(WithOrbTTL(0))
+
+	checked := atomic.Int32{}
+	check := func() (bool, error) {
+		checked.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		return true, nil
+	}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			image := cache.DockerCache.GetOrSet("cimg/go:1.21", check)
+			assert.True(t, image.Exists)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), checked.Load())
+
+	failed := cache.DockerCache.GetOrSet("cimg/node:20.0", func() (bool, error) {
+		return true, errors.New("registry unreachable")
+	})
+	assert.False(t, failed.Exists)
+	assert.Error(t, failed.Err)
+}
+
+func TestDockerCacheUnbounded(t *testing.T) {
+	cache := CreateCache(WithOrbTTL(0))
+
+	for i := 0; i < 100; i++ {
+		cache.DockerCache.Add(fmt.Sprintf("cimg/go:1.%d", i), true)
+	}
+
+	assert.Equal(t, 100, cache.Stats().DockerCache.Entries)
+}
+
+func TestContextCacheSnapshot(t *testing.T) {
+	cache := CreateCache(WithOrbTTL(0))
+	cache.ContextCache.SetOrganizationContext("org", &Context{Name: "deploy"})
+	cache.ContextCache.SetOrganizationContext("org", &Context{Name: "release"})
+
+	contexts := cache.ContextCache.GetAllContextOfOrganization("org")
+	cache.ContextCache.RemoveOrganizationContext("org", "deploy")
+
+	assert.Len(t, contexts, 2)
+	assert.Equal(t, []string{"release"}, cache.ContextCache.ContextNames("org"))
+	assert.Empty(t, cache.ContextCache.ContextNames("unknown-org"))
+}
+
+func TestOrbCacheKeepsVersionsApart(t *testing.T) {
+	cache := CreateCache(WithOrbTTL(0))
+	cache.OrbCache.SetOrb(&ast.OrbInfo{
+		RemoteInfo: ast.RemoteOrbInfo{Version: "1.2.3"},
+	}, ast.FormatOrbID("foo/bar", "1.2.3"))
+	cache.OrbCache.SetOrb(&ast.OrbInfo{
+		RemoteInfo: ast.RemoteOrbInfo{Version: "2.0.0"},
+	}, ast.FormatOrbID("foo/bar", "2.0.0"))
+
+	assert.Equal(t, "1.2.3", cache.OrbCache.GetOrb("foo/bar@1.2.3").RemoteInfo.Version)
+	assert.Equal(t, "2.0.0", cache.OrbCache.GetOrb("foo/bar@2.0.0").RemoteInfo.Version)
+	assert.Nil(t, cache.OrbCache.GetOrb("foo/bar@1"))
+
+	orbURL := ast.OrbURL{Name: "foo/bar", Version: "2.0.0"}
+	assert.Equal(t, "2.0.0", cache.OrbCache.GetOrb(orbURL.GetOrbID()).RemoteInfo.Version)
+}
+
+func TestOrbCacheOnChange(t *testing.T) {
+	cache := CreateCache(WithOrbTTL(0))
+	changes := []string{}
+	unregister := cache.OrbCache.OnChange(func(orbID string) {
+		// Re-entering the cache from a listener must not deadlock
+		cache.OrbCache.HasOrb(orbID)
+		changes = append(changes, orbID)
+	})
+
+	cache.OrbCache.SetOrb(&ast.OrbInfo{}, "circleci/node@1")
+	cache.OrbCache.UpdateOrbParsedAttributes("circleci/node@1", ast.OrbParsedAttributes{})
+	cache.OrbCache.RemoveOrb("circleci/node@1")
+	cache.OrbCache.SetOrb(&ast.OrbInfo{}, "circleci/go@1")
+	cache.OrbCache.RemoveOrbs()
+
+	unregister()
+	cache.OrbCache.SetOrb(&ast.OrbInfo{}, "circleci/node@1")
+
+	assert.Equal(t, []string{
+		"circleci/node@1",
+		"circleci/node@1",
+		"circleci/node@1",
+		"circleci/go@1",
+		"circleci/go@1",
+	}, changes)
+}
+
+func TestProjectLookups(t *testing.T) {
+	cache := CreateCache()
+	files := map[protocol.URI]Project{
+		"file:///app/.circleci/config.yml":      {Slug: "gh/org/app", Name: "app", OrganizationName: "org"},
+		"file:///app/.circleci/other.yml":       {Slug: "gh/org/app", Name: "app", OrganizationName: "org"},
+		"file:///fork/.circleci/config.yml":     {Slug: "gh/fork/app", Name: "app", OrganizationName: "fork", Host: "https://circleci.example.com"},
+		"file:///website/.circleci/config.yml":  {Slug: "gh/org/website", Name: "website", OrganizationName: "org"},
+		"file:///unlinked/.circleci/config.yml": {},
+	}
+	for uri, project := range files {
+		cache.FileCache.SetFile(CachedFile{TextDocument: protocol.TextDocumentItem{URI: uri}})
+		cache.FileCache.AddProjectSlugToFile(uri, project)
+	}
+
+	project, ok := cache.FileCache.GetProjectBySlug("gh/org/website")
+	assert.True(t, ok)
+	assert.Equal(t, "website", project.Name)
+
+	slugs := func(projects []Project) []string {
+		res := []string{}
+		for _, project := range projects {
+			res = append(res, project.Slug)
+		}
+		return res
+	}
+	assert.Equal(t, []string{"gh/fork/app", "gh/org/app"}, slugs(cache.FileCache.GetProjectsByName("app")))
+	assert.Empty(t, cache.FileCache.GetProjectsByName(""))
+
+	cache.FileCache.RemoveFile("file:///website/.circleci/config.yml")
+	_, ok = cache.FileCache.GetProjectBySlug("gh/org/website")
+	assert.False(t, ok)
+	assert.Empty(t, cache.FileCache.GetProjectsByName("website"))
+
+	previous := cache.FileCache.GetFile("file:///fork/.circleci/config.yml")
+	cache.FileCache.RemoveProjectsOfHost("https://circleci.example.com")
+	assert.Equal(t, "gh/fork/app", previous.Project.Slug)
+	_, ok = cache.FileCache.GetProjectBySlug("gh/fork/app")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"gh/org/app"}, slugs(cache.FileCache.GetProjectsByName("app")))
+
+	cache.FileCache.RemoveFile("file:///app/.circleci/config.yml")
+	assert.Equal(t, []string{"gh/org/app"}, slugs(cache.FileCache.GetProjectsByName("app")))
+}
+
+func TestProjectEnvVariablesResolved(t *testing.T) {
+	cache := CreateCache()
+	uri := protocol.URI("file:///project/.circleci/config.yml")
+	cache.FileCache.SetFile(CachedFile{TextDocument: protocol.TextDocumentItem{URI: uri}})
+
+	assert.False(t, cache.FileCache.IsProjectEnvVariablesResolved(uri))
+	assert.False(t, cache.FileCache.IsProjectEnvVariablesResolved("file:///unknown.yml"))
+
+	cache.FileCache.AddProjectSlugToFile(uri, Project{Slug: "gh/org/repo", Host: "https://circleci.com"})
+	cache.FileCache.AddEnvVariableToProjectLinkedToFile(uri, "TOKEN")
+	cache.FileCache.SetProjectEnvVariablesResolved(uri)
+	assert.True(t, cache.FileCache.IsProjectEnvVariablesResolved(uri))
+
+	variables := cache.FileCache.GetProjectEnvVariables(uri)
+	variables[0] = "CHANGED"
+	assert.Equal(t, []string{"TOKEN"}, cache.FileCache.GetProjectEnvVariables(uri))
+	assert.Empty(t, cache.FileCache.GetProjectEnvVariables("file:///unknown.yml"))
+
+	previous := cache.FileCache.GetFile(uri)
+	cache.FileCache.ResetProjectEnvVariables(uri)
+	assert.False(t, cache.FileCache.IsProjectEnvVariablesResolved(uri))
+	assert.Empty(t, cache.FileCache.GetFile(uri).EnvVariables)
+	assert.Equal(t, []string{"TOKEN"}, previous.EnvVariables)
+	assert.True(t, previous.envVariablesResolved)
+	assert.Equal(t, "gh/org/repo", cache.FileCache.GetFile(uri).Project.Slug)
+
+	cache.FileCache.SetProjectEnvVariablesResolved(uri)
+	cache.FileCache.AddProjectSlugToFile(uri, Project{Slug: "gh/org/other", Host: "https://circleci.com"})
+	assert.False(t, cache.FileCache.IsProjectEnvVariablesResolved(uri))
+
+	cache.FileCache.SetProjectEnvVariablesResolved(uri)
+	cache.FileCache.RemoveProjectsOfHost("https://circleci.com")
+	assert.False(t, cache.FileCache.IsProjectEnvVariablesResolved(uri))
+}
+
+func TestContextEnvVariables(t *testing.T) {
+	cache := CreateCache()
+	cache.ContextCache.SetOrganizationContext("org", &Context{Name: "deploy"})
+	cache.ContextCache.AddEnvVariableToOrganizationContext("org", "deploy", "AWS_KEY")
+	cache.ContextCache.AddEnvVariableToOrganizationContext("org", "deploy", "AWS_SECRET")
+
+	variables := cache.ContextCache.GetContextEnvVariables("org", "deploy")
+	assert.Equal(t, []string{"AWS_KEY", "AWS_SECRET"}, variables)
+
+	variables[0] = "MUTATED"
+	fromContext := cache.ContextCache.GetOrganizationContext("org", "deploy").EnvVariables()
+	fromContext[1] = "MUTATED"
+
+	assert.Equal(t, []string{"AWS_KEY", "AWS_SECRET"}, cache.ContextCache.GetContextEnvVariables("org", "deploy"))
+	assert.Nil(t, cache.ContextCache.GetContextEnvVariables("org", "unknown"))
+}
+
+func TestContextCacheOnChange(t *testing.T) {
+	cache := CreateCache(WithOrbTTL(0))
+	first, second := 0, 0
+	cache.ContextCache.OnChange(func(change ContextChange) { first++ })
+	unregister := cache.ContextCache.OnChange(func(change ContextChange) {
+		assert.Equal(t, ContextChange{OrganizationId: "org", Name: "deploy"}, change)
+		second++
+	})
+
+	cache.ContextCache.SetOrganizationContext("org", &Context{Name: "deploy"})
+	unregister()
+	cache.ContextCache.RemoveOrganizationContext("org", "deploy")
+
+	assert.Equal(t, 2, first)
+	assert.Equal(t, 1, second)
+}
+
+func TestClearHostDataFor(t *testing.T) {
+	cache := CreateCache(WithOrbTTL(0))
+	cache.ContextCache.SetOrganizationContext("org", &Context{Name: "cloud", Host: "https://circleci.com"})
+	cache.ContextCache.SetOrganizationContext("org", &Context{Name: "server", Host: "https://circleci.example.com"})
+
+	cloudFile := protocol.URI("file:///cloud/.circleci/config.yml")
+	serverFile := protocol.URI("file:///server/.circleci/config.yml")
+	cache.FileCache.SetFile(CachedFile{
+		TextDocument: protocol.TextDocumentItem{URI: cloudFile},
+		Project:      Project{Slug: "gh/org/cloud", Host: "https://circleci.com"},
+		EnvVariables: []string{"TOKEN"},
+	})
+	cache.FileCache.SetFile(CachedFile{
+		TextDocument: protocol.TextDocumentItem{URI: serverFile},
+		Project:      Project{Slug: "gh/org/server", Host: "https://circleci.example.com"},
+	})
+
+	cache.ContextCache.SetOrganizationRequested("org", "https://circleci.com")
+	cache.ContextCache.SetOrganizationRequested("other", "https://circleci.example.com")
+	assert.True(t, cache.ContextCache.IsOrganizationRequested("org"))
+	assert.False(t, cache.ContextCache.IsOrganizationResolved("org"))
+
+	cache.ClearHostDataFor("https://circleci.com")
+
+	assert.Equal(t, []string{"server"}, cache.ContextCache.ContextNames("org"))
+	assert.False(t, cache.ContextCache.IsOrganizationRequested("org"))
+	assert.True(t, cache.ContextCache.IsOrganizationRequested("other"))
+	assert.Equal(t, Project{}, cache.FileCache.GetFile(cloudFile).Project)
+	assert.Empty(t, cache.FileCache.GetFile(cloudFile).EnvVariables)
+	assert.Equal(t, "gh/org/server", cache.FileCache.GetFile(serverFile).Project.Slug)
+}
+
+func TestCacheClear(t *testing.T) {
+	cache := CreateCache(WithOrbTTL(0), WithDockerCacheLimit(10))
+	uri := protocol.URI("file:///project/.circleci/config.yml")
+	cache.FileCache.SetFile(CachedFile{
+		TextDocument: protocol.TextDocumentItem{URI: uri},
+		Project:      Project{Slug: "gh/org/repo"},
+	})
+	cache.OrbCache.SetOrb(&ast.OrbInfo{}, "circleci/node@1")
+	cache.DockerCache.Add("cimg/base:2023.01", true)
+	cache.DockerTagsCache.Add("cimg", "base", CachedDockerTags{Recommended: "2023.01"})
+	cache.ResourceClassCache.SetResourceClassForFile(uri, &[]string{"org/runner"})
+	cache.ContextCache.SetOrganizationContext("org", &Context{Name: "deploy"})
+	cache.ContextCache.SetOrganizationResolved("org", "https://circleci.com")
+	cache.ContextCache.SetOrganizationRequested("org", "https://circleci.com")
+	cache.FileCache.GetFile(uri)
+
+	removedOrbs, removedContexts := []string{}, []ContextChange{}
+	cache.OrbCache.OnChange(func(orbID string) { removedOrbs = append(removedOrbs, orbID) })
+	cache.ContextCache.OnChange(func(change ContextChange) { removedContexts = append(removedContexts, change) })
+
+	cache.Clear()
+
+	assert.Empty(t, cache.FileCache.GetFiles())
+	assert.Empty(t, cache.OrbCache.OrbIDs())
+	assert.Nil(t, cache.DockerCache.Get("cimg/base:2023.01"))
+	assert.Nil(t, cache.DockerTagsCache.Get("cimg", "base"))
+	assert.Empty(t, cache.ResourceClassCache.GetResourceClassOfFile(uri))
+	assert.Empty(t, cache.ContextCache.ContextNames("org"))
+	assert.False(t, cache.ContextCache.IsOrganizationResolved("org"))
+	assert.False(t, cache.ContextCache.IsOrganizationRequested("org"))
+	assert.Equal(t, []string{"circleci/node@1"}, removedOrbs)
+	assert.Equal(t, []ContextChange{{OrganizationId: "org", Name: "deploy"}}, removedContexts)
+
+	// The lookups made above after the clear are the only ones recorded
+	stats := cache.Stats()
+	assert.Equal(t, CacheStats{Misses: 1}, stats.DockerCache)
+	assert.Equal(t, CacheStats{}, stats.FileCache)
+
+	// The cache is still usable, including the recency list of the Docker images
+	cache.DockerCache.Add("cimg/node:20.5", true)
+	assert.True(t, cache.DockerCache.Get("cimg/node:20.5").Exists)
+}
+
+func TestSubCacheClear(t *testing.T) {
+	cache := CreateCache(WithOrbTTL(0))
+	uri := protocol.URI("file:///project/.circleci/config.yml")
+	cache.FileCache.SetFile(CachedFile{
+		TextDocument: protocol.TextDocumentItem{URI: uri},
+		Project:      Project{Slug: "gh/org/repo"},
+		EnvVariables: []string{"TOKEN"},
+	})
+	cache.DockerCache.Add("cimg/base:2023.01", true)
+	cache.ContextCache.SetOrganizationContext("org", &Context{Name: "deploy"})
+
+	previous := cache.FileCache.GetFile(uri)
+	cache.FileCache.RemoveProjects()
+	assert.Equal(t, Project{}, cache.FileCache.GetFile(uri).Project)
+	assert.Equal(t, "gh/org/repo", previous.Project.Slug)
+	assert.Empty(t, cache.FileCache.GetFile(uri).EnvVariables)
+
+	cache.DockerCache.Clear()
+	assert.Nil(t, cache.DockerCache.Get("cimg/base:2023.01"))
+	assert.Len(t, cache.FileCache.GetFiles(), 1)
+
+	cache.ContextCache.Clear()
+	assert.Empty(t, cache.ContextCache.ContextNames("org"))
+
+	cache.FileCache.Clear()
+	assert.Empty(t, cache.FileCache.GetFiles())
+}
+
+func TestConcurrentMultiCacheOperations(t *testing.T) {
+	cache := CreateCache(WithOrbTTL(0))
+	uri := protocol.URI("file:///config.yml")
+	done := make(chan struct{})
+
+	go func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(5)
+			go func() {
+				defer wg.Done()
+				cache.ClearAllHostData()
+			}()
+			go func() {
+				defer wg.Done()
+				cache.Clear()
+				cache.ContextCache.ContextNames("org")
+			}()
+			go func() {
+				defer wg.Done()
+				cache.RemoveOrbFiles()
+			}()
+			go func() {
+				defer wg.Done()
+				cache.FileCache.SetFile(CachedFile{TextDocument: protocol.TextDocumentItem{URI: uri}})
+				cache.OrbCache.SetOrb(&ast.OrbInfo{}, "circleci/node@1")
+			}()
+			go func() {
+				defer wg.Done()
+				cache.withLocks(func() {}, orbCacheLock, fileCacheLock)
+				cache.FileCache.GetFiles()
+			}()
+		}
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("deadlock between cache operations")
+	}
+}
+
+func TestSetFiles(t *testing.T) {
+	cache := CreateCache(WithOrbTTL(0))
+	existing := protocol.URI("file:///existing.yml")
+	cache.FileCache.SetFile(CachedFile{
+		TextDocument: protocol.TextDocumentItem{URI: existing},
+		Project:      Project{Slug: "gh/org/repo"},
+	})
+
+	inserted := cache.FileCache.SetFiles([]*protocol.TextDocumentItem{
+		{URI: existing, Text: "version: 2.1"},
+		{URI: "file:///new.yml"},
+		nil,
+	})
+
+	assert.Equal(t, 2, inserted)
+	assert.Len(t, cache.FileCache.GetFiles(), 2)
+	assert.Equal(t, "version: 2.1", cache.FileCache.GetFile(existing).TextDocument.Text)
+	assert.Equal(t, "gh/org/repo", cache.FileCache.GetFile(existing).Project.Slug)
+}
+
+func makeTextDocuments(n int) []*protocol.TextDocumentItem {
+	textDocuments := make([]*protocol.TextDocumentItem, n)
+	for i := range textDocuments {
+		textDocuments[i] = &protocol.TextDocumentItem{
+			URI: protocol.URI(fmt.Sprintf("file:///project-%d/.circleci/config.yml", i)),
+		}
+	}
+	return textDocuments
+}
+
+func BenchmarkSetFileIndividually(b *testing.B) {
+	textDocuments := makeTextDocuments(1000)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		cache := CreateCache(WithOrbTTL(0))
+		for _, textDocument := range textDocuments {
+			cache.FileCache.SetFile(CachedFile{TextDocument: *textDocument})
+		}
+	}
+}
+
+func BenchmarkSetFilesBatch(b *testing.B) {
+	textDocuments := makeTextDocuments(1000)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		cache := CreateCache(WithOrbTTL(0))
+		cache.FileCache.SetFiles(textDocuments)
+	}
+}
+
+func TestGetOrbCacheFSPath(t *testing.T) {
+	testCases := []struct {
+		orbID    string
+		fileName string
+	}{
+		{orbID: "circleci/node@5.1.0", fileName: "circleci%2Fnode%405.1.0.yml"},
+		{orbID: "circleci/node@volatile", fileName: "circleci%2Fnode%40volatile.yml"},
+		{orbID: "my-org/my_orb@dev:alpha", fileName: "my-org%2Fmy_orb%40dev%3Aalpha.yml"},
+		{orbID: "../../../etc/passwd", fileName: "..%2F..%2F..%2Fetc%2Fpasswd.yml"},
+		{orbID: `..\..\orb@1`, fileName: "..%5C..%5Corb%401.yml"},
+		{orbID: "..", fileName: "...yml"},
+		{orbID: "ns%2Forb", fileName: "ns%252Forb.yml"},
+	}
+
+	dir := GetOrbCacheFSDir()
+	fileNames := map[string]string{}
+	for _, tt := range testCases {
+		t.Run(tt.orbID, func(t *testing.T) {
+			filePath := GetOrbCacheFSPath(tt.orbID)
+
+			assert.Equal(t, tt.fileName, path.Base(filePath))
+			assert.Equal(t, dir, path.Dir(filePath))
+			assert.Equal(t, filePath, GetOrbCacheFSPath(tt.orbID))
+
+			orbID, ok := GetOrbIDFromCacheFSPath(filePath)
+			assert.True(t, ok)
+			assert.Equal(t, tt.orbID, orbID)
+		})
+
+		assert.NotContains(t, fileNames, tt.fileName, "%s and %s share a file", fileNames[tt.fileName], tt.orbID)
+		fileNames[tt.fileName] = tt.orbID
+	}
+
+	// The percent-encoding of the slash is not the slash itself
+	assert.NotEqual(t, GetOrbCacheFSPath("ns/orb"), GetOrbCacheFSPath("ns%2Forb"))
+}
+
+func TestGetOrbIDFromCacheFSPath(t *testing.T) {
+	dir := GetOrbCacheFSDir()
+
+	for _, filePath := range []string{
+		path.Join("/home/user/project/.circleci", "circleci%2Fnode%405.1.0.yml"),
+		path.Join(dir, "circleci%2Fnode%405.1.0.yaml"),
+		path.Join(dir, "circleci%2Gnode.yml"),
+	} {
+		_, ok := GetOrbIDFromCacheFSPath(filePath)
+		assert.False(t, ok, filePath)
+	}
+}
+
+func TestRemoveOrbFilesKeepsReferencedOrbs(t *testing.T) {
+	cache := CreateCache(WithOrbTTL(0))
+	dir := t.TempDir()
+	orbFile := func(name string) string {
+		filePath := path.Join(dir, name)
+		assert.NoError(t, os.WriteFile(filePath, []byte("version: 2.1"), 0644))
+		return filePath
+	}
+	exists := func(filePath string) bool {
+		_, err := os.Stat(filePath)
+		return err == nil
+	}
+
+	nodeFile, goFile, slackFile := orbFile("node.yml"), orbFile("go.yml"), orbFile("slack.yml")
+	node := &ast.OrbInfo{RemoteInfo: ast.RemoteOrbInfo{FilePath: nodeFile, Version: "5.1.0"}}
+	cache.OrbCache.SetOrb(node, "circleci/node@5")
+	cache.OrbCache.SetOrb(node, "circleci/node@5.1.0")
+	cache.OrbCache.SetOrb(&ast.OrbInfo{RemoteInfo: ast.RemoteOrbInfo{FilePath: goFile}}, "circleci/go@1")
+	cache.OrbCache.SetOrb(&ast.OrbInfo{RemoteInfo: ast.RemoteOrbInfo{FilePath: slackFile}}, "circleci/slack@4")
+
+	// Two roots of a workspace sharing the node orb
+	frontend := protocol.URI("file:///frontend/.circleci/config.yml")
+	backend := protocol.URI("file:///backend/.circleci/config.yml")
+	cache.FileCache.SetFileOrbs(frontend, []string{"circleci/node@5", "circleci/slack@4"})
+	cache.FileCache.SetFileOrbs(backend, []string{"circleci/node@5", "circleci/go@1", "circleci/go@1"})
+	assert.Equal(t, 2, cache.FileCache.OrbReferences("circleci/node@5"))
+	assert.Equal(t, 1, cache.FileCache.OrbReferences("circleci/go@1"))
+
+	// Closing the frontend releases the slack orb only
+	cache.FileCache.SetFileOrbs(frontend, nil)
+	cache.RemoveOrbFiles()
+	assert.Equal(t, 1, cache.FileCache.OrbReferences("circleci/node@5"))
+	assert.True(t, exists(nodeFile))
+	assert.True(t, exists(goFile))
+	assert.False(t, exists(slackFile))
+
+	// Editing the backend to remove the go orb releases it
+	cache.FileCache.SetFileOrbs(backend, []string{"circleci/node@5"})
+	cache.RemoveOrbFiles()
+	assert.True(t, exists(nodeFile))
+	assert.False(t, exists(goFile))
+
+	cache.FileCache.RemoveFile(backend)
+	assert.Equal(t, 0, cache.FileCache.OrbReferences("circleci/node@5"))
+	cache.RemoveOrbFiles()
+	assert.False(t, exists(nodeFile))
+}
@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"go.lsp.dev/protocol"
+)
+
+var (
+	versionKey = regexp.MustCompile(`(?m)^version[ \t]*:`)
+
+	// Sections of a configuration, one of them is enough to tell a
+	// configuration apart from the other YAML files having a version, such
+	// as Docker Compose files
+	configSectionKey = regexp.MustCompile(`(?m)^(jobs|workflows|orbs|commands|executors)[ \t]*:`)
+)
+
+func IsYAMLFile(uri protocol.URI) bool {
+	extension := path.Ext(uri.Filename())
+	return extension == ".yml" || extension == ".yaml"
+}
+
+// YAML files in a .circleci directory, at any depth, are configurations.
+// Elsewhere, the files having the shape of a configuration are: a top-level
+// version along with jobs, workflows, orbs, commands or executors. This is the
+// case of the configurations of dynamic config and of the local orbs
+func IsCircleCIConfig(uri protocol.URI, text string) bool {
+	if !IsYAMLFile(uri) {
+		return false
+	}
+
+	if strings.Contains(filepath.ToSlash(filepath.Dir(uri.Filename()))+"/", "/.circleci/") {
+		return true
+	}
+
+	return versionKey.MatchString(text) && configSectionKey.MatchString(text)
+}
+
+func (file CachedFile) IsCircleCIConfig() bool {
+	return IsCircleCIConfig(file.TextDocument.URI, file.TextDocument.Text)
+}
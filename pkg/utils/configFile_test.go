@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/uri"
+)
+
+func TestIsCircleCIConfig(t *testing.T) {
+	config := `version: 2.1
+
+jobs:
+  build:
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - checkout
+`
+
+	testCases := []struct {
+		name     string
+		path     string
+		text     string
+		expected bool
+	}{
+		{
+			name:     "Configuration with the .yml extension",
+			path:     "/project/.circleci/config.yml",
+			text:     config,
+			expected: true,
+		},
+		{
+			name:     "Configuration with the .yaml extension",
+			path:     "/project/.circleci/config.yaml",
+			text:     config,
+			expected: true,
+		},
+		{
+			name:     "Empty file of the .circleci directory",
+			path:     "/project/.circleci/config.yml",
+			text:     "",
+			expected: true,
+		},
+		{
+			name:     "Nested configuration of dynamic config",
+			path:     "/project/.circleci/configs/continue.yaml",
+			text:     "",
+			expected: true,
+		},
+		{
+			name:     "Configuration at a non-standard path",
+			path:     "/project/ci/generated/pipeline.yaml",
+			text:     config,
+			expected: true,
+		},
+		{
+			name: "Local orb",
+			path: "/project/orb.yml",
+			text: `version: 2.1
+description: Greetings
+
+commands:
+  greet:
+    steps:
+      - run: echo hello
+`,
+			expected: true,
+		},
+		{
+			name: "Docker Compose file",
+			path: "/project/docker-compose.yml",
+			text: `version: "3.9"
+services:
+  web:
+    image: nginx
+`,
+			expected: false,
+		},
+		{
+			name: "GitHub Actions workflow",
+			path: "/project/.github/workflows/ci.yaml",
+			text: `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+`,
+			expected: false,
+		},
+		{
+			name:     "Not a YAML file",
+			path:     "/project/.circleci/README.md",
+			text:     config,
+			expected: false,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsCircleCIConfig(uri.File(tt.path), tt.text))
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package utils
+
+import "sort"
+
+// Values described once in the JSON Schema and referenced by the keys using
+// them, the steps being recursive through the conditional steps
+var configJSONSchemaDefinitions = map[*ConfigKey]string{
+	configStepsSchema:             "step",
+	configParametersSchema.AnyKey: "parameter",
+	configExecutorSchema:          "executor",
+	configCommandSchema:           "command",
+	configJobSchema:               "job",
+	configWorkflowSchema:          "workflow",
+	configWorkflowJobSchema:       "workflowJob",
+}
+
+// Returns the configuration schema as a JSON Schema, draft-07, for the tools
+// other than the server to validate configurations the way it does. The
+// schema is permissive where the server is: unknown keys are allowed, and
+// scalars may be strings since they can be interpolations of parameters
+func GetConfigJSONSchema() map[string]any {
+	definitions := map[string]any{}
+	for key, name := range configJSONSchemaDefinitions {
+		definitions[name] = configValueToJSONSchema(key)
+	}
+
+	schema := configValueToJSONSchema(ConfigSchema)
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["title"] = "CircleCI configuration"
+	schema["definitions"] = definitions
+
+	return schema
+}
+
+// Schema of a key, or a reference to its definition when it is a shared one
+func configKeyToJSONSchema(key *ConfigKey) map[string]any {
+	if name, ok := configJSONSchemaDefinitions[key]; ok {
+		return map[string]any{"$ref": "#/definitions/" + name}
+	}
+
+	schema := configValueToJSONSchema(key)
+	if key.Description != "" {
+		schema["description"] = key.Description
+	}
+	return schema
+}
+
+func configValueToJSONSchema(key *ConfigKey) map[string]any {
+	switch key.Kind {
+	case "string":
+		// YAML reads values such as the version 2.1 as numbers
+		return map[string]any{"type": []string{"string", "number", "boolean"}}
+	case "integer":
+		return map[string]any{"type": []string{"integer", "string"}}
+	case "boolean":
+		return map[string]any{"type": []string{"boolean", "string"}}
+	case "list":
+		schema := map[string]any{"type": "array"}
+		if key.Items != nil {
+			schema["items"] = configKeyToJSONSchema(key.Items)
+		}
+		return schema
+	case "map":
+		return configMapToJSONSchema(key)
+	}
+
+	return map[string]any{}
+}
+
+func configMapToJSONSchema(key *ConfigKey) map[string]any {
+	schema := map[string]any{"type": "object"}
+
+	if len(key.Keys) > 0 {
+		properties := map[string]any{}
+		for _, child := range key.Keys {
+			properties[child.Name] = configKeyToJSONSchema(child)
+		}
+		schema["properties"] = properties
+	}
+
+	if key.AnyKey != nil {
+		schema["additionalProperties"] = configKeyToJSONSchema(key.AnyKey)
+	}
+
+	if conflicts := getConfigKeyConflicts(key); len(conflicts) > 0 {
+		schema["allOf"] = conflicts
+	}
+
+	// A step is a map having the name of the step as single key, or this
+	// name alone when the step has no parameters. The commands and the orb
+	// commands are steps as well
+	if key == configStepsSchema {
+		properties := schema["properties"].(map[string]any)
+		properties["run"] = map[string]any{
+			"anyOf": []any{map[string]any{"type": "string", "description": "Command run through the shell"}, properties["run"]},
+		}
+
+		schema["minProperties"] = 1
+		schema["maxProperties"] = 1
+		return map[string]any{"anyOf": []any{map[string]any{"type": "string"}, schema}}
+	}
+
+	// The jobs of the workflows are written by name alone when they have no
+	// parameters
+	if key.AnyKey == configWorkflowJobSchema {
+		schema["minProperties"] = 1
+		schema["maxProperties"] = 1
+		return map[string]any{"anyOf": []any{map[string]any{"type": "string"}, schema}}
+	}
+
+	return schema
+}
+
+// The conflicting keys of a map, each pair once, as schemas forbidding to
+// have both keys
+func getConfigKeyConflicts(key *ConfigKey) []any {
+	pairs := map[[2]string]bool{}
+	for _, child := range key.Keys {
+		for _, conflict := range child.Conflicts {
+			pair := [2]string{child.Name, conflict}
+			sort.Strings(pair[:])
+			pairs[pair] = true
+		}
+	}
+
+	sorted := make([][2]string, 0, len(pairs))
+	for pair := range pairs {
+		sorted = append(sorted, pair)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i][0] < sorted[j][0] || (sorted[i][0] == sorted[j][0] && sorted[i][1] < sorted[j][1])
+	})
+
+	conflicts := []any{}
+	for _, pair := range sorted {
+		conflicts = append(conflicts, map[string]any{
+			"not": map[string]any{"required": []string{pair[0], pair[1]}},
+		})
+	}
+	return conflicts
+}
@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xeipuuv/gojsonschema"
+	"gopkg.in/yaml.v3"
+)
+
+func TestGetConfigJSONSchema(t *testing.T) {
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewGoLoader(GetConfigJSONSchema()))
+	assert.Nil(t, err)
+
+	validate := func(config string) []string {
+		document := map[string]any{}
+		assert.Nil(t, yaml.Unmarshal([]byte(config), &document))
+
+		result, err := schema.Validate(gojsonschema.NewGoLoader(document))
+		assert.Nil(t, err)
+
+		// The alternatives of a value are each reported for the same field
+		fields := []string{}
+		for _, resultError := range result.Errors() {
+			if FindInArray(fields, resultError.Field()) == -1 {
+				fields = append(fields, resultError.Field())
+			}
+		}
+		return fields
+	}
+
+	t.Run("Should accept a configuration", func(t *testing.T) {
+		assert.Empty(t, validate(`version: 2.1
+
+orbs:
+  node: circleci/node@5.1.0
+
+parameters:
+  deploy:
+    type: boolean
+    default: false
+
+executors:
+  base:
+    docker:
+      - image: cimg/base:2023.01
+
+commands:
+  greet:
+    parameters:
+      to:
+        type: string
+    steps:
+      - run: echo << parameters.to >>
+
+jobs:
+  build:
+    executor: base
+    parallelism: << pipeline.parameters.parallelism >>
+    steps:
+      - checkout
+      - greet:
+          to: world
+      - when:
+          condition: << pipeline.parameters.deploy >>
+          steps:
+            - run:
+                command: make deploy
+                background: false
+
+workflows:
+  main:
+    jobs:
+      - build
+      - node/test:
+          requires: [build]
+          matrix:
+            parameters:
+              version: ["18", "20"]
+`))
+	})
+
+	t.Run("Should reject the conflicting keys and the malformed values", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{"jobs.build", "jobs.build.steps.0", "jobs.build.steps.1", "jobs.build.steps.1.run", "workflows.main.jobs"}, validate(`version: 2.1
+
+jobs:
+  build:
+    executor: base
+    docker:
+      - image: cimg/base:2023.01
+    steps:
+      - checkout: {}
+        run: echo two steps
+      - run: [echo]
+
+workflows:
+  main:
+    jobs: build
+`))
+	})
+}
@@ -0,0 +1,502 @@
+package utils
+
+import "strings"
+
+// Key of the configuration, along with the keys expected in its value. The
+// kinds of the values are the ones of the YAML values: string, integer,
+// boolean, list or map
+type ConfigKey struct {
+	Name        string
+	Description string
+	Kind        string
+
+	// Inserted after the name when completing the key, as a snippet. When
+	// empty, it is derived from the kind of the value
+	Snippet string
+
+	// Keys that can not be used along this one in the same map
+	Conflicts []string
+
+	// Keys of the value, when it is a map
+	Keys []*ConfigKey
+
+	// Value of the keys named by the user, such as the names of the jobs
+	AnyKey *ConfigKey
+
+	// Items of the value, when it is a list
+	Items *ConfigKey
+
+	// Ways of writing the key, as YAML, shown when hovering the built-in steps
+	Examples []string
+}
+
+// Returns the key expected under the given name in the value of the key
+func (key *ConfigKey) GetKey(name string) *ConfigKey {
+	if key == nil {
+		return nil
+	}
+
+	for _, child := range key.Keys {
+		if child.Name == name {
+			return child
+		}
+	}
+
+	return key.AnyKey
+}
+
+// Snippet inserted when completing the key, the cursor ending in its value
+func (key *ConfigKey) GetSnippet() string {
+	if key.Snippet != "" {
+		return key.Name + key.Snippet
+	}
+
+	switch key.Kind {
+	case "map":
+		return key.Name + ":\n\t$0"
+	case "list":
+		return key.Name + ":\n\t- $0"
+	}
+
+	return key.Name + ": $0"
+}
+
+// Whether the key can be used in a map already having the given keys
+func (key *ConfigKey) ConflictsWith(present map[string]bool) bool {
+	for _, conflict := range key.Conflicts {
+		if present[conflict] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns the key at the given path from the root of the configuration, the
+// items of the lists being designated by "-", or nil when the path is unknown
+func GetConfigKeyAtPath(path []string) *ConfigKey {
+	key := ConfigSchema
+	for _, name := range path {
+		if name == "-" {
+			key = key.Items
+		} else {
+			key = key.GetKey(name)
+		}
+
+		if key == nil {
+			return nil
+		}
+	}
+
+	return key
+}
+
+var configStepsSchema = &ConfigKey{Kind: "map"}
+
+// Returns the built-in step of the given name, such as checkout, nil for the
+// other steps
+func GetBuiltInStep(name string) *ConfigKey {
+	for _, step := range configStepsSchema.Keys {
+		if step.Name == name {
+			return step
+		}
+	}
+
+	return nil
+}
+
+// Returns the built-in step designated by the path, the last name of the
+// path being the name of the step in a list of steps, as in
+// jobs/build/steps/-/checkout. Nil when the path designates something else
+func GetBuiltInStepAtPath(path []string) *ConfigKey {
+	if len(path) < 2 || GetConfigKeyAtPath(path[:len(path)-1]) != configStepsSchema {
+		return nil
+	}
+
+	return GetBuiltInStep(path[len(path)-1])
+}
+
+var configParametersSchema = &ConfigKey{
+	Name:        "parameters",
+	Description: "Parameters that can be passed when invoking the entity, each with a type and an optional default value",
+	Kind:        "map",
+	AnyKey: &ConfigKey{
+		Kind: "map",
+		Keys: []*ConfigKey{
+			{Name: "type", Description: "Type of the parameter: string, boolean, integer, enum, executor, steps or env_var_name", Kind: "string"},
+			{Name: "description", Description: "Description of the parameter", Kind: "string"},
+			{Name: "default", Description: "Value of the parameter when it is not passed, the parameter is required without it", Kind: "string"},
+			{Name: "enum", Description: "Values allowed for an enum parameter", Kind: "list"},
+		},
+	},
+}
+
+var configEnvironmentSchema = &ConfigKey{
+	Name:        "environment",
+	Description: "Environment variables, by name",
+	Kind:        "map",
+}
+
+func newConfigStepsKey(name string, description string) *ConfigKey {
+	return &ConfigKey{Name: name, Description: description, Kind: "list", Items: configStepsSchema}
+}
+
+var configDockerSchema = &ConfigKey{
+	Name:        "docker",
+	Description: "Runs the job in Docker containers, the first image being the primary container where the steps run",
+	Kind:        "list",
+	Snippet:     ":\n\t- image: $0",
+	Conflicts:   []string{"executor", "machine", "macos", "windows"},
+	Items: &ConfigKey{
+		Kind: "map",
+		Keys: []*ConfigKey{
+			{Name: "image", Description: "Name of the image, with its tag", Kind: "string"},
+			{Name: "name", Description: "Hostname of the container, the secondary containers are reachable with it", Kind: "string"},
+			{Name: "entrypoint", Description: "Command used as the entrypoint of the container", Kind: "list"},
+			{Name: "command", Description: "Command used as the PID 1 of the container", Kind: "list"},
+			{Name: "user", Description: "User running the commands in the container", Kind: "string"},
+			configEnvironmentSchema,
+			{
+				Name:        "auth",
+				Description: "Credentials of the registry of the image",
+				Kind:        "map",
+				Keys: []*ConfigKey{
+					{Name: "username", Description: "Username on the registry", Kind: "string"},
+					{Name: "password", Description: "Password on the registry, usually an environment variable", Kind: "string"},
+				},
+			},
+			{
+				Name:        "aws_auth",
+				Description: "Credentials of the AWS ECR registry of the image",
+				Kind:        "map",
+				Keys: []*ConfigKey{
+					{Name: "aws_access_key_id", Description: "ID of the AWS access key", Kind: "string"},
+					{Name: "aws_secret_access_key", Description: "Secret of the AWS access key", Kind: "string"},
+					{Name: "oidc_role_arn", Description: "Role assumed with the OIDC token of the job", Kind: "string"},
+				},
+			},
+		},
+	},
+}
+
+var configMachineSchema = &ConfigKey{
+	Name:        "machine",
+	Description: "Runs the job in a dedicated virtual machine",
+	Kind:        "map",
+	Snippet:     ":\n\timage: $0",
+	Conflicts:   []string{"executor", "docker", "macos", "windows"},
+	Keys: []*ConfigKey{
+		{Name: "image", Description: "Image of the virtual machine", Kind: "string"},
+		{Name: "docker_layer_caching", Description: "Reuses the Docker layers built by the previous jobs", Kind: "boolean"},
+	},
+}
+
+var configMacOSSchema = &ConfigKey{
+	Name:        "macos",
+	Description: "Runs the job in a macOS virtual machine",
+	Kind:        "map",
+	Snippet:     ":\n\txcode: $0",
+	Conflicts:   []string{"executor", "docker", "machine", "windows"},
+	Keys: []*ConfigKey{
+		{Name: "xcode", Description: "Version of Xcode installed on the virtual machine", Kind: "string"},
+	},
+}
+
+var configResourceClassSchema = &ConfigKey{
+	Name:        "resource_class",
+	Description: "Amount of CPU and RAM allocated to the job",
+	Kind:        "string",
+}
+
+var configShellSchema = &ConfigKey{
+	Name:        "shell",
+	Description: "Shell used to execute the commands of the steps",
+	Kind:        "string",
+}
+
+var configWorkingDirectorySchema = &ConfigKey{
+	Name:        "working_directory",
+	Description: "Directory in which the steps are run, ~/project by default",
+	Kind:        "string",
+}
+
+var configExecutorSchema = &ConfigKey{
+	Kind: "map",
+	Keys: []*ConfigKey{
+		{Name: "description", Description: "Description of the executor", Kind: "string"},
+		configDockerSchema,
+		configMachineSchema,
+		configMacOSSchema,
+		{
+			Name:        "windows",
+			Description: "Runs the job in a Windows virtual machine",
+			Kind:        "map",
+			Conflicts:   []string{"docker", "machine", "macos"},
+		},
+		configResourceClassSchema,
+		configShellSchema,
+		configWorkingDirectorySchema,
+		configEnvironmentSchema,
+		configParametersSchema,
+	},
+}
+
+var configJobSchema = &ConfigKey{
+	Kind: "map",
+	Keys: []*ConfigKey{
+		{Name: "description", Description: "Description of the job", Kind: "string"},
+		{Name: "executor", Description: "Executor running the job, with its parameters", Kind: "string", Conflicts: []string{"docker", "machine", "macos"}},
+		configDockerSchema,
+		configMachineSchema,
+		configMacOSSchema,
+		newConfigStepsKey("steps", "Steps run by the job"),
+		configParametersSchema,
+		configResourceClassSchema,
+		configShellSchema,
+		configWorkingDirectorySchema,
+		configEnvironmentSchema,
+		{Name: "parallelism", Description: "Number of executors running the job in parallel", Kind: "integer"},
+		{Name: "circleci_ip_ranges", Description: "Runs the job from the well-defined IP ranges of CircleCI", Kind: "boolean"},
+	},
+}
+
+var configCommandSchema = &ConfigKey{
+	Kind: "map",
+	Keys: []*ConfigKey{
+		{Name: "description", Description: "Description of the command", Kind: "string"},
+		configParametersSchema,
+		newConfigStepsKey("steps", "Steps run by the command"),
+	},
+}
+
+var configFiltersSchema = &ConfigKey{
+	Name:        "filters",
+	Description: "Branches and tags the job runs for",
+	Kind:        "map",
+	Keys: []*ConfigKey{
+		{
+			Name:        "branches",
+			Description: "Branches the job runs for, by name or regular expression",
+			Kind:        "map",
+			Keys: []*ConfigKey{
+				{Name: "only", Description: "The job only runs for these branches", Kind: "list"},
+				{Name: "ignore", Description: "The job does not run for these branches", Kind: "list"},
+			},
+		},
+		{
+			Name:        "tags",
+			Description: "Tags the job runs for, by name or regular expression. Jobs do not run for tags unless set",
+			Kind:        "map",
+			Keys: []*ConfigKey{
+				{Name: "only", Description: "The job only runs for these tags", Kind: "list"},
+				{Name: "ignore", Description: "The job does not run for these tags", Kind: "list"},
+			},
+		},
+	},
+}
+
+var configWorkflowJobSchema = &ConfigKey{
+	Kind: "map",
+	Keys: []*ConfigKey{
+		{Name: "requires", Description: "Jobs that must succeed before the job runs", Kind: "list"},
+		{Name: "name", Description: "Name of the job in the workflow, for jobs run several times", Kind: "string"},
+		{Name: "context", Description: "Contexts whose environment variables are available to the job", Kind: "list"},
+		{Name: "type", Description: "Type of the job, approval to wait for a manual approval", Kind: "string", Snippet: ": approval"},
+		configFiltersSchema,
+		{
+			Name:        "matrix",
+			Description: "Runs the job once for each combination of the parameters",
+			Kind:        "map",
+			Snippet:     ":\n\tparameters:\n\t\t$0",
+			Keys: []*ConfigKey{
+				{Name: "parameters", Description: "Values of each parameter", Kind: "map"},
+				{Name: "exclude", Description: "Combinations of the parameters not to run", Kind: "list"},
+				{Name: "alias", Description: "Name the other jobs use to require all the jobs of the matrix", Kind: "string"},
+			},
+		},
+		newConfigStepsKey("pre-steps", "Steps run before the steps of the job"),
+		newConfigStepsKey("post-steps", "Steps run after the steps of the job"),
+	},
+}
+
+var configWorkflowSchema = &ConfigKey{
+	Kind: "map",
+	Keys: []*ConfigKey{
+		{
+			Name:        "jobs",
+			Description: "Jobs run by the workflow",
+			Kind:        "list",
+			Items:       &ConfigKey{Kind: "map", AnyKey: configWorkflowJobSchema},
+		},
+		{
+			Name:        "triggers",
+			Description: "Schedules running the workflow",
+			Kind:        "list",
+			Snippet:     ":\n\t- schedule:\n\t\t\tcron: $0",
+			Items: &ConfigKey{
+				Kind: "map",
+				Keys: []*ConfigKey{
+					{
+						Name:        "schedule",
+						Description: "Runs the workflow on a schedule",
+						Kind:        "map",
+						Keys: []*ConfigKey{
+							{Name: "cron", Description: "Schedule of the workflow, in the POSIX crontab syntax", Kind: "string"},
+							configFiltersSchema,
+						},
+					},
+				},
+			},
+		},
+		{Name: "when", Description: "The workflow only runs when this condition is true", Kind: "string", Conflicts: []string{"unless"}},
+		{Name: "unless", Description: "The workflow only runs when this condition is false", Kind: "string", Conflicts: []string{"when"}},
+	},
+}
+
+// Keys of the configuration from the root, each key level being described
+// once so that the completion and the validation agree on it
+var ConfigSchema = &ConfigKey{
+	Kind: "map",
+	Keys: []*ConfigKey{
+		{Name: "version", Description: "Version of the configuration, 2.1 to use orbs, commands, executors and parameters", Kind: "string"},
+		{Name: "setup", Description: "Designates the configuration as the setup of a dynamic configuration", Kind: "boolean"},
+		{Name: "orbs", Description: "Orbs used by the configuration, by name", Kind: "map"},
+		{Name: "executors", Description: "Reusable executors, by name", Kind: "map", AnyKey: configExecutorSchema},
+		{Name: "commands", Description: "Reusable commands, by name", Kind: "map", AnyKey: configCommandSchema},
+		{Name: "parameters", Description: "Parameters of the pipeline", Kind: "map", AnyKey: configParametersSchema.AnyKey},
+		{Name: "jobs", Description: "Jobs of the configuration, by name", Kind: "map", AnyKey: configJobSchema},
+		{
+			Name:        "workflows",
+			Description: "Workflows orchestrating the jobs, by name",
+			Kind:        "map",
+			Keys:        []*ConfigKey{{Name: "version", Description: "Version of the workflows, only used by the configurations of version 2", Kind: "string"}},
+			AnyKey:      configWorkflowSchema,
+		},
+	},
+}
+
+// The keys of the built-in steps. The steps are a list of maps whose key is
+// the name of the step, the commands and the orb commands taking their
+// parameters as keys
+func init() {
+	conditionalStep := func(name string) *ConfigKey {
+		return &ConfigKey{
+			Name: name,
+			Kind: "map",
+			Keys: []*ConfigKey{
+				{Name: "condition", Description: "Condition evaluated when the configuration is compiled", Kind: "string"},
+				newConfigStepsKey("steps", "Steps run depending on the condition"),
+			},
+		}
+	}
+
+	configStepsSchema.Keys = []*ConfigKey{
+		{
+			Name: "run",
+			Kind: "map",
+			Examples: []string{
+				"# The command is also the name of the step\n- run: make test",
+				"- run:\n    name: Test\n    command: make test",
+			},
+			Keys: []*ConfigKey{
+				{Name: "command", Description: "Command run through the shell", Kind: "string"},
+				{Name: "name", Description: "Title of the step shown in the CircleCI UI", Kind: "string"},
+				configShellSchema,
+				configEnvironmentSchema,
+				{Name: "background", Description: "Runs the command in the background, the next steps not waiting for it", Kind: "boolean"},
+				configWorkingDirectorySchema,
+				{Name: "no_output_timeout", Description: "Time the command can run without output, such as 10m or 1h", Kind: "string"},
+				{Name: "when", Description: "Whether the step runs depending on the status of the job: always, on_success or on_fail", Kind: "string"},
+			},
+		},
+		{
+			Name: "checkout",
+			Kind: "map",
+			Examples: []string{
+				"- checkout",
+				"- checkout:\n    path: ~/project",
+			},
+			Keys: []*ConfigKey{
+				{Name: "path", Description: "Directory the code is checked out to, relative to the working directory", Kind: "string"},
+			},
+		},
+		{
+			Name: "setup_remote_docker",
+			Kind: "map",
+			Examples: []string{
+				"- setup_remote_docker",
+				"- setup_remote_docker:\n    docker_layer_caching: true",
+			},
+			Keys: []*ConfigKey{
+				{Name: "docker_layer_caching", Description: "Reuses the Docker layers built by the previous jobs", Kind: "boolean"},
+				{Name: "version", Description: "Version of the Docker engine", Kind: "string"},
+			},
+		},
+		{
+			Name: "save_cache",
+			Kind: "map",
+			Keys: []*ConfigKey{
+				{Name: "paths", Description: "Paths of the directories and files to cache", Kind: "list"},
+				{Name: "key", Description: "Key of the cache, with templates such as {{ checksum \"file\" }}", Kind: "string"},
+				{Name: "name", Description: "Title of the step shown in the CircleCI UI", Kind: "string"},
+				{Name: "when", Description: "Whether the step runs depending on the status of the job: always, on_success or on_fail", Kind: "string"},
+			},
+		},
+		{
+			Name: "restore_cache",
+			Kind: "map",
+			Keys: []*ConfigKey{
+				{Name: "key", Description: "Key of the cache to restore", Kind: "string", Conflicts: []string{"keys"}},
+				{Name: "keys", Description: "Keys of the caches to restore, the first one found is restored", Kind: "list", Conflicts: []string{"key"}},
+				{Name: "name", Description: "Title of the step shown in the CircleCI UI", Kind: "string"},
+			},
+		},
+		{
+			Name: "store_artifacts",
+			Kind: "map",
+			Keys: []*ConfigKey{
+				{Name: "path", Description: "Directory of the artifacts", Kind: "string"},
+				{Name: "destination", Description: "Prefix of the artifacts in the artifacts API", Kind: "string"},
+			},
+		},
+		{
+			Name: "store_test_results",
+			Kind: "map",
+			Keys: []*ConfigKey{
+				{Name: "path", Description: "Directory of the test results", Kind: "string"},
+			},
+		},
+		{
+			Name: "persist_to_workspace",
+			Kind: "map",
+			Keys: []*ConfigKey{
+				{Name: "root", Description: "Directory the paths are relative to", Kind: "string"},
+				{Name: "paths", Description: "Paths of the directories and files to persist, relative to the root", Kind: "list"},
+			},
+		},
+		{
+			Name: "attach_workspace",
+			Kind: "map",
+			Keys: []*ConfigKey{
+				{Name: "at", Description: "Directory the workspace is attached to", Kind: "string"},
+			},
+		},
+		{
+			Name: "add_ssh_keys",
+			Kind: "map",
+			Examples: []string{
+				"# Adds all the SSH keys of the project\n- add_ssh_keys",
+				"- add_ssh_keys:\n    fingerprints:\n      - \"SO:ME:FI:NG:ER:PR:IN:T\"",
+			},
+			Keys: []*ConfigKey{
+				{Name: "fingerprints", Description: "Fingerprints of the SSH keys of the project to add", Kind: "list"},
+			},
+		},
+		conditionalStep("when"),
+		conditionalStep("unless"),
+	}
+
+	for _, step := range configStepsSchema.Keys {
+		step.Description = strings.TrimSpace(BuiltInStepsDescription[step.Name])
+	}
+}
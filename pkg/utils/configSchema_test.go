@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetConfigKeyAtPath(t *testing.T) {
+	run := GetConfigKeyAtPath([]string{"jobs", "build", "steps", "-", "run"})
+	assert.NotNil(t, run)
+	assert.NotNil(t, run.GetKey("command"))
+	assert.Equal(t, BuiltInStepsDescription["run"], run.Description)
+
+	// The steps of the conditional steps are steps as well
+	nested := GetConfigKeyAtPath([]string{"commands", "greet", "steps", "-", "when", "steps", "-", "save_cache"})
+	assert.NotNil(t, nested)
+	assert.Equal(t, "paths:\n\t- $0", nested.GetKey("paths").GetSnippet())
+
+	assert.Nil(t, GetConfigKeyAtPath([]string{"jobs", "build", "unknown"}))
+	assert.Nil(t, GetConfigKeyAtPath([]string{"jobs", "build", "steps", "-", "greet"}))
+}
+
+func TestConfigKeyConflicts(t *testing.T) {
+	job := GetConfigKeyAtPath([]string{"jobs", "build"})
+
+	assert.True(t, job.GetKey("docker").ConflictsWith(map[string]bool{"executor": true}))
+	assert.True(t, job.GetKey("executor").ConflictsWith(map[string]bool{"machine": true}))
+	assert.False(t, job.GetKey("resource_class").ConflictsWith(map[string]bool{"executor": true}))
+}
@@ -9,6 +9,15 @@ type Context struct {
 	Name         string
 	CreatedAt    string `json:"created_at"`
 	envVariables []string
+
+	// URL of the CircleCI instance the context was fetched from
+	Host string `json:"-"`
+}
+
+// Names of the environment variables defined in the context. The slice is a
+// copy: the variables are only added through the cache
+func (ctx *Context) EnvVariables() []string {
+	return append([]string{}, ctx.envVariables...)
 }
 
 type ContextEnvVariable struct {
@@ -24,7 +33,7 @@ func GetAllContextEnvVariables(lsContext *LsContext, cache *Cache, organizationI
 			continue
 		}
 
-		for _, envVariable := range cachedContext.envVariables {
+		for _, envVariable := range cachedContext.EnvVariables() {
 			contextEnvVariables = append(contextEnvVariables, ContextEnvVariable{
 				Name:              envVariable,
 				AssociatedContext: context,
@@ -64,7 +73,21 @@ type GetAllContextRes struct {
 	}
 }
 
+// Fetch the contexts of the organization. A single request is sent at a time
+// for an organization, concurrent calls share its result
 func GetAllContext(lsContext *LsContext, organization string, vcs string, cache *Cache) error {
+	if lsContext.IsOffline() {
+		return ErrOffline
+	}
+
+	_, err := cache.ContextCache.pending.do(organization, func() (struct{}, error) {
+		defer cache.ContextCache.SetOrganizationRequested(organization, lsContext.Api.HostUrl)
+		return struct{}{}, fetchAllContext(lsContext, organization, vcs, cache)
+	})
+	return err
+}
+
+func fetchAllContext(lsContext *LsContext, organization string, vcs string, cache *Cache) error {
 	cl := NewClient("https://circleci.com", "graphql-unstable", "", false)
 
 	query := `query($organization: String!, $vcsType: VCSType!) {
@@ -113,9 +136,17 @@ func GetAllContext(lsContext *LsContext, organization string, vcs string, cache
 			Id:           context.Node.Id,
 			Name:         context.Node.Name,
 			envVariables: resourcesToStringArray(context.Node.Resources),
+			Host:         lsContext.Api.HostUrl,
 		})
 	}
 
+	// Only the first page of contexts is fetched, the organization is not
+	// resolved when it has more of them: the contexts of the next pages would
+	// be reported as not existing
+	if !Response.Organization.Contexts.PageInfo.HasNextPage {
+		cache.ContextCache.SetOrganizationResolved(organization, lsContext.Api.HostUrl)
+	}
+
 	return nil
 }
 
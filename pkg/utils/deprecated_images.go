@@ -0,0 +1,91 @@
+package utils
+
+import "strings"
+
+type DeprecatedImage struct {
+	// Name of the image without its tag: cimg/node for Docker images,
+	// ubuntu-1604 for machine images
+	Name string
+	// Deprecated versions, matching the tags equal to them or starting with
+	// them followed by a dot. Every tag is deprecated when empty
+	Tags []string
+	// Image to use instead, with its tag
+	Replacement string
+	Reason      string
+}
+
+// Images CircleCI removed or stopped maintaining, along with their
+// replacements. Append new deprecations here
+var DeprecatedImages = []DeprecatedImage{
+	// Machine images
+	{
+		Name:        "ubuntu-1604",
+		Replacement: "ubuntu-2204:current",
+		Reason:      "Ubuntu 16.04 machine images have been removed",
+	},
+	{
+		Name:        "ubuntu-1804",
+		Replacement: "ubuntu-2204:current",
+		Reason:      "Ubuntu 18.04 machine images have been removed",
+	},
+	{
+		Name:        "circleci/classic",
+		Replacement: "ubuntu-2204:current",
+		Reason:      "The classic machine images have been removed",
+	},
+
+	// Convenience images of end-of-life languages
+	{
+		Name:        "cimg/node",
+		Tags:        []string{"10", "12", "14", "16"},
+		Replacement: "cimg/node:lts",
+		Reason:      "This Node.js version reached its end of life",
+	},
+	{
+		Name:        "cimg/python",
+		Tags:        []string{"2.7", "3.5", "3.6", "3.7"},
+		Replacement: "cimg/python:3.12",
+		Reason:      "This Python version reached its end of life",
+	},
+	{
+		Name:        "cimg/ruby",
+		Tags:        []string{"2.5", "2.6", "2.7"},
+		Replacement: "cimg/ruby:3.3",
+		Reason:      "This Ruby version reached its end of life",
+	},
+	{
+		Name:        "cimg/php",
+		Tags:        []string{"7.3", "7.4", "8.0"},
+		Replacement: "cimg/php:8.3",
+		Reason:      "This PHP version reached its end of life",
+	},
+	{
+		Name:        "cimg/go",
+		Tags:        []string{"1.16", "1.17", "1.18", "1.19", "1.20"},
+		Replacement: "cimg/go:1.22",
+		Reason:      "This Go version is no longer supported",
+	},
+}
+
+// Returns the deprecation of an image written as name:tag, if any
+func FindDeprecatedImage(image string) (DeprecatedImage, bool) {
+	name, tag, _ := strings.Cut(image, ":")
+
+	for _, deprecated := range DeprecatedImages {
+		if deprecated.Name != name {
+			continue
+		}
+
+		if len(deprecated.Tags) == 0 {
+			return deprecated, true
+		}
+
+		for _, version := range deprecated.Tags {
+			if tag == version || strings.HasPrefix(tag, version+".") {
+				return deprecated, true
+			}
+		}
+	}
+
+	return DeprecatedImage{}, false
+}
@@ -0,0 +1,197 @@
+package utils
+
+import (
+	"strings"
+
+	"go.lsp.dev/protocol"
+)
+
+// Namespace of the rules, it can be omitted in the settings
+const RuleNamespace = "circleci/"
+
+// Rules identify the kind of issue a diagnostic reports. They are set as the
+// code of the diagnostics and are stable: the settings use them to change
+// the severity of the diagnostics, and the quick fixes to find the
+// diagnostics they apply to
+const (
+	RuleYAMLSyntax   = "circleci/yaml-syntax"
+	RuleVersion      = "circleci/version"
+	RuleSchema       = "circleci/schema"
+	RuleDuplicateKey = "circleci/duplicate-key"
+	RuleEmptySection = "circleci/empty-section"
+
+	RuleUnusedAnchor   = "circleci/unused-anchor"
+	RuleUnknownAnchor  = "circleci/unknown-anchor"
+	RuleMergeKey       = "circleci/merge-key"
+	RuleUnusedCommand  = "circleci/unused-command"
+	RuleUnusedExecutor = "circleci/unused-executor"
+	RuleUnusedJob      = "circleci/unused-job"
+	RuleUnusedOrb      = "circleci/unused-orb"
+	RuleAmbiguousName  = "circleci/ambiguous-name"
+
+	RuleDeprecatedVersionKey = "circleci/deprecated-version-key"
+	RuleDeprecatedImage      = "circleci/deprecated-image"
+	RuleDeprecatedStep       = "circleci/deprecated-step"
+
+	RuleParameter          = "circleci/parameter"
+	RuleUndefinedParameter = "circleci/undefined-parameter"
+	RuleParameterDefault   = "circleci/parameter-default"
+	RuleLegacyBoolean      = "circleci/legacy-boolean"
+
+	RuleUnknownStep        = "circleci/unknown-step"
+	RuleOrbNotDeclared     = "circleci/orb-not-declared"
+	RuleInvalidStep        = "circleci/invalid-step"
+	RuleStepWhen           = "circleci/step-when"
+	RuleStepShell          = "circleci/step-shell"
+	RuleStepPath           = "circleci/step-path"
+	RuleCacheKey           = "circleci/cache-key"
+	RuleCondition          = "circleci/condition"
+	RuleMissingTestResults = "circleci/missing-test-results"
+	RuleMissingCheckout    = "circleci/missing-checkout"
+	RuleWorkspace          = "circleci/workspace"
+	RuleEnvironment        = "circleci/environment"
+	RuleHardcodedSecret    = "circleci/hardcoded-secret"
+
+	RuleUnknownExecutor = "circleci/unknown-executor"
+	RuleExecutorKeys    = "circleci/executor-keys"
+	RuleDockerImage     = "circleci/docker-image"
+	RuleDockerImageTag  = "circleci/docker-image-tag"
+	RuleMachineImage    = "circleci/machine-image"
+	RuleXcodeVersion    = "circleci/xcode-version"
+	RuleResourceClass   = "circleci/resource-class"
+
+	RuleInvalidParallelism = "circleci/invalid-parallelism"
+	RuleParallelism        = "circleci/parallelism"
+
+	RuleOrb        = "circleci/orb"
+	RuleOrbVersion = "circleci/orb-version"
+
+	RuleUnknownJob       = "circleci/unknown-job"
+	RuleJobType          = "circleci/job-type"
+	RuleUnknownRequires  = "circleci/unknown-requires"
+	RuleCircularRequires = "circleci/circular-requires"
+	RuleUnknownContext   = "circleci/unknown-context"
+	RuleFilterRegex      = "circleci/filter-regex"
+	RuleTagsFilter       = "circleci/tags-filter"
+	RuleCron             = "circleci/cron"
+	RuleSetup            = "circleci/setup"
+)
+
+// Pages of the documentation describing what the rules check
+var ruleDocumentation = map[string]string{
+	RuleVersion:            "https://circleci.com/docs/configuration-reference/#version",
+	RuleCacheKey:           "https://circleci.com/docs/caching/",
+	RuleCondition:          "https://circleci.com/docs/configuration-reference/#logic-statements",
+	RuleMissingTestResults: "https://circleci.com/docs/collect-test-data/",
+	RuleMissingCheckout:    "https://circleci.com/docs/configuration-reference/#checkout",
+	RuleWorkspace:          "https://circleci.com/docs/workspaces/",
+	RuleStepShell:          "https://circleci.com/docs/configuration-reference/#default-shell-options",
+	RuleStepPath:           "https://circleci.com/docs/artifacts/",
+	RuleInvalidParallelism: "https://circleci.com/docs/parallelism-faster-jobs/",
+	RuleParallelism:        "https://circleci.com/docs/parallelism-faster-jobs/",
+	RuleOrb:                "https://circleci.com/docs/orb-intro/",
+	RuleOrbVersion:         "https://circleci.com/docs/orb-intro/",
+	RuleOrbNotDeclared:     "https://circleci.com/docs/orb-intro/",
+	RuleUnknownContext:     "https://circleci.com/docs/contexts/",
+	RuleEnvironment:        "https://circleci.com/docs/set-environment-variable/",
+	RuleHardcodedSecret:    "https://circleci.com/docs/contexts/",
+	RuleSetup:              "https://circleci.com/docs/dynamic-config/",
+}
+
+// Page describing the rules that have no documentation of their own
+const RulesDocumentation = "https://github.com/CircleCI-Public/circleci-yaml-language-server/blob/main/DIAGNOSTICS.md"
+
+// Page of the documentation describing what the rule checks
+func GetRuleDocumentation(rule string) string {
+	if href, ok := ruleDocumentation[rule]; ok {
+		return href
+	}
+	return RulesDocumentation
+}
+
+// Sets the rule of the diagnostic as its code, along with the documentation
+// of the rule when the diagnostic does not link to a page already
+func WithDiagnosticRule(rule string, diagnostic protocol.Diagnostic) protocol.Diagnostic {
+	diagnostic.Code = rule
+
+	if href, ok := ruleDocumentation[rule]; ok && diagnostic.CodeDescription == nil {
+		diagnostic.CodeDescription = &protocol.CodeDescription{Href: protocol.URI(href)}
+	}
+
+	return diagnostic
+}
+
+// Rule set as the code of the diagnostic, if any
+func GetDiagnosticRule(diagnostic protocol.Diagnostic) string {
+	rule, _ := diagnostic.Code.(string)
+	return rule
+}
+
+// Severities given to the rules by the settings, the rules missing from it
+// keep their default severity
+type DiagnosticSeverities map[string]protocol.DiagnosticSeverity
+
+// Severity of the rules turned off, their diagnostics are not reported
+const DiagnosticSeverityOff protocol.DiagnosticSeverity = 0
+
+var diagnosticSeverityNames = map[string]protocol.DiagnosticSeverity{
+	"error":       protocol.DiagnosticSeverityError,
+	"warning":     protocol.DiagnosticSeverityWarning,
+	"info":        protocol.DiagnosticSeverityInformation,
+	"information": protocol.DiagnosticSeverityInformation,
+	"hint":        protocol.DiagnosticSeverityHint,
+	"off":         DiagnosticSeverityOff,
+}
+
+// Reads the `diagnosticSeverities` setting, an object mapping rules, with or
+// without their namespace, to one of error, warning, info, hint or off.
+// Invalid entries are ignored
+func ParseDiagnosticSeverities(setting interface{}) DiagnosticSeverities {
+	entries, ok := setting.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	severities := DiagnosticSeverities{}
+	for rule, value := range entries {
+		name, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		if !strings.HasPrefix(rule, RuleNamespace) {
+			rule = RuleNamespace + rule
+		}
+
+		if severity, ok := diagnosticSeverityNames[strings.ToLower(name)]; ok {
+			severities[rule] = severity
+		}
+	}
+
+	return severities
+}
+
+// Changes the severity of the diagnostics whose rule is configured, and
+// removes the ones of the rules turned off
+func (severities DiagnosticSeverities) Apply(diagnostics []protocol.Diagnostic) []protocol.Diagnostic {
+	if len(severities) == 0 {
+		return diagnostics
+	}
+
+	res := make([]protocol.Diagnostic, 0, len(diagnostics))
+	for _, diagnostic := range diagnostics {
+		severity, ok := severities[GetDiagnosticRule(diagnostic)]
+
+		switch {
+		case !ok:
+		case severity == DiagnosticSeverityOff:
+			continue
+		default:
+			diagnostic.Severity = severity
+		}
+
+		res = append(res, diagnostic)
+	}
+
+	return res
+}
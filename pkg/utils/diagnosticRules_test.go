@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+)
+
+func TestParseDiagnosticSeverities(t *testing.T) {
+	testCases := []struct {
+		name     string
+		setting  interface{}
+		expected DiagnosticSeverities
+	}{
+		{
+			name:     "No setting",
+			setting:  nil,
+			expected: nil,
+		},
+		{
+			name:     "Not an object",
+			setting:  "off",
+			expected: nil,
+		},
+		{
+			name: "Every severity",
+			setting: map[string]interface{}{
+				RuleUnusedJob:          "off",
+				RuleDockerImageTag:     "Error",
+				RuleParallelism:        "info",
+				RuleOrbVersion:         "information",
+				RuleUnknownContext:     "hint",
+				RuleDeprecatedImage:    "WARNING",
+				RuleMissingTestResults: "hint",
+			},
+			expected: DiagnosticSeverities{
+				RuleUnusedJob:          DiagnosticSeverityOff,
+				RuleDockerImageTag:     protocol.DiagnosticSeverityError,
+				RuleParallelism:        protocol.DiagnosticSeverityInformation,
+				RuleOrbVersion:         protocol.DiagnosticSeverityInformation,
+				RuleUnknownContext:     protocol.DiagnosticSeverityHint,
+				RuleDeprecatedImage:    protocol.DiagnosticSeverityWarning,
+				RuleMissingTestResults: protocol.DiagnosticSeverityHint,
+			},
+		},
+		{
+			name: "Rules without their namespace",
+			setting: map[string]interface{}{
+				"unused-job":      "off",
+				"circleci/schema": "warning",
+			},
+			expected: DiagnosticSeverities{
+				RuleUnusedJob: DiagnosticSeverityOff,
+				RuleSchema:    protocol.DiagnosticSeverityWarning,
+			},
+		},
+		{
+			name: "Invalid entries are ignored",
+			setting: map[string]interface{}{
+				RuleUnusedJob:   "silent",
+				RuleParallelism: 2,
+				RuleCron:        "hint",
+			},
+			expected: DiagnosticSeverities{
+				RuleCron: protocol.DiagnosticSeverityHint,
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ParseDiagnosticSeverities(tt.setting))
+		})
+	}
+}
+
+func TestDiagnosticSeveritiesApply(t *testing.T) {
+	rng := protocol.Range{}
+	diagnostics := []protocol.Diagnostic{
+		WithDiagnosticRule(RuleUnusedJob, CreateUnusedDiagnosticFromRange(rng, "Job is unused")),
+		WithDiagnosticRule(RuleDockerImageTag, CreateWarningDiagnosticFromRange(rng, "Unknown tag")),
+		WithDiagnosticRule(RuleSchema, CreateErrorDiagnosticFromRange(rng, "Invalid key")),
+		CreateErrorDiagnosticFromRange(rng, "Without rule"),
+	}
+
+	t.Run("Without severities", func(t *testing.T) {
+		var severities DiagnosticSeverities
+		assert.Equal(t, diagnostics, severities.Apply(diagnostics))
+	})
+
+	t.Run("With severities", func(t *testing.T) {
+		severities := DiagnosticSeverities{
+			RuleUnusedJob:      DiagnosticSeverityOff,
+			RuleDockerImageTag: protocol.DiagnosticSeverityError,
+		}
+
+		res := severities.Apply(diagnostics)
+
+		assert.Len(t, res, 3)
+		assert.Equal(t, "Unknown tag", res[0].Message)
+		assert.Equal(t, protocol.DiagnosticSeverityError, res[0].Severity)
+		assert.Equal(t, diagnostics[2], res[1])
+		assert.Equal(t, diagnostics[3], res[2])
+
+		// The given diagnostics are left untouched
+		assert.Equal(t, protocol.DiagnosticSeverityWarning, diagnostics[1].Severity)
+	})
+}
@@ -34,6 +34,13 @@ func CreateWarningDiagnosticFromRange(rng protocol.Range, msg string) protocol.D
 	)
 }
 
+// Warning tagged as unnecessary, so that editors fade out the unused code
+func CreateUnusedDiagnosticFromRange(rng protocol.Range, msg string) protocol.Diagnostic {
+	diagnostic := CreateWarningDiagnosticFromRange(rng, msg)
+	diagnostic.Tags = []protocol.DiagnosticTag{protocol.DiagnosticTagUnnecessary}
+	return diagnostic
+}
+
 func CreateEmptyAssignationWarning(rng protocol.Range) protocol.Diagnostic {
 	return CreateWarningDiagnosticFromRange(rng, "Empty assignation")
 }
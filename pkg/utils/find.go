@@ -8,3 +8,48 @@ func FindInArray[T comparable](array []T, elem T) int {
 	}
 	return -1
 }
+
+// Returns the candidate closest to the given string, as long as it is close
+// enough to be a likely typo: at most two edits, or a third of its
+// characters for longer strings
+func FindClosestMatch(str string, candidates []string) (string, bool) {
+	best := ""
+	bestDistance := max(2, len(str)/3) + 1
+
+	for _, candidate := range candidates {
+		if candidate == str {
+			continue
+		}
+
+		if distance := levenshteinDistance(str, candidate); distance < bestDistance {
+			best = candidate
+			bestDistance = distance
+		}
+	}
+
+	return best, best != ""
+}
+
+func levenshteinDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(rb)]
+}
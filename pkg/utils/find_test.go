@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindClosestMatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		str        string
+		candidates []string
+		want       string
+		wantFound  bool
+	}{
+		{
+			name:       "typo",
+			str:        "biuld",
+			candidates: []string{"test", "build", "deploy"},
+			want:       "build",
+			wantFound:  true,
+		},
+		{
+			name:       "too different",
+			str:        "lint",
+			candidates: []string{"test", "build", "deploy"},
+			wantFound:  false,
+		},
+		{
+			name:       "no candidates",
+			str:        "build",
+			candidates: []string{},
+			wantFound:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := FindClosestMatch(tt.str, tt.candidates)
+			assert.Equal(t, tt.wantFound, found)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -68,6 +68,9 @@ type Project struct {
 		Provider       string
 		Default_branch string `json:"default_branch"`
 	} `json:"vcs_info"`
+
+	// URL of the CircleCI instance the project was fetched from
+	Host string `json:"-"`
 }
 
 func GetProjectOrg(projectSlug string) string {
@@ -81,6 +84,10 @@ func GetProjectOrg(projectSlug string) string {
 }
 
 func GetProjectId(projectSlug string, lsContext *LsContext) (Project, error) {
+	if lsContext.IsOffline() {
+		return Project{}, ErrOffline
+	}
+
 	url := fmt.Sprintf("%s/api/v2/project/%s", lsContext.Api.HostUrl, projectSlug)
 
 	req, _ := http.NewRequest("GET", url, nil)
@@ -105,6 +112,7 @@ func GetProjectId(projectSlug string, lsContext *LsContext) (Project, error) {
 	if err != nil {
 		return Project{}, err
 	}
+	projectIdRes.Host = lsContext.Api.HostUrl
 
 	return projectIdRes, nil
 }
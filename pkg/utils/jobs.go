@@ -15,3 +15,28 @@ func HasStoreTestResultStep(step []ast.Step) bool {
 	}
 	return false
 }
+
+// Commands that can only run with the code of the project, the ones of the
+// package managers and build tools reading their manifest. Only these strong
+// signals are used to report the jobs not checking out the code
+var DefaultCheckoutCommands = []string{
+	"npm ci",
+	"npm install",
+	"npm test",
+	"npm run",
+	"yarn install",
+	"yarn test",
+	"yarn build",
+	"pnpm install",
+	"make",
+	"go build",
+	"go test",
+	"go vet",
+	"mvn",
+	"./gradlew",
+	"cargo build",
+	"cargo test",
+	"bundle install",
+	"pip install -r",
+	"pytest",
+}
@@ -0,0 +1,261 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const DefaultLogLevel = slog.LevelInfo
+
+var logLevels = map[string]slog.Level{
+	"debug":   slog.LevelDebug,
+	"info":    slog.LevelInfo,
+	"warn":    slog.LevelWarn,
+	"warning": slog.LevelWarn,
+	"error":   slog.LevelError,
+}
+
+// Reads a level of the settings, one of debug, info, warn or error
+func ParseLogLevel(value interface{}) (slog.Level, bool) {
+	name, ok := value.(string)
+	if !ok {
+		return DefaultLogLevel, false
+	}
+
+	level, ok := logLevels[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return DefaultLogLevel, false
+	}
+	return level, true
+}
+
+// Destination of the logs, such as the client or a file. The message is
+// already formatted with its attributes
+type LogSink interface {
+	WriteLog(level slog.Level, message string)
+}
+
+// Leveled structured logger of the server. The level, the sinks and the
+// secrets to redact are shared with the loggers derived with With, they can
+// be changed at any time by the settings of the client
+type Logger struct {
+	*slog.Logger
+	output *logOutput
+}
+
+type logOutput struct {
+	level slog.LevelVar
+
+	mutex   sync.RWMutex
+	sinks   map[string]LogSink
+	secrets []string
+}
+
+func NewLogger(level slog.Level) *Logger {
+	output := &logOutput{sinks: map[string]LogSink{}}
+	output.level.Set(level)
+
+	return &Logger{
+		Logger: slog.New(&logHandler{output: output}),
+		output: output,
+	}
+}
+
+func (logger *Logger) SetLevel(level slog.Level) {
+	if logger != nil {
+		logger.output.level.Set(level)
+	}
+}
+
+func (logger *Logger) GetLevel() slog.Level {
+	if logger == nil {
+		return DefaultLogLevel
+	}
+	return logger.output.level.Level()
+}
+
+// Adds or replaces the sink of the given name, a nil sink removes it
+func (logger *Logger) SetSink(name string, sink LogSink) {
+	if logger == nil {
+		return
+	}
+
+	logger.output.mutex.Lock()
+	defer logger.output.mutex.Unlock()
+
+	if sink == nil {
+		delete(logger.output.sinks, name)
+	} else {
+		logger.output.sinks[name] = sink
+	}
+}
+
+// Values replaced in every log, such as the API token: errors may quote the
+// requests they come from
+func (logger *Logger) Redact(secrets ...string) {
+	if logger == nil {
+		return
+	}
+
+	logger.output.mutex.Lock()
+	defer logger.output.mutex.Unlock()
+
+	for _, secret := range secrets {
+		// Short values would redact unrelated words
+		if len(secret) >= 8 {
+			logger.output.secrets = append(logger.output.secrets, secret)
+		}
+	}
+}
+
+// Replaces the values of the attributes whose key looks like it names a secret
+// or environment variables, these are never logged
+var redactedKeys = []string{"token", "password", "secret", "credential", "auth", "apikey", "api_key", "cookie", "env"}
+
+const redacted = "[redacted]"
+
+func isRedactedKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, redactedKey := range redactedKeys {
+		if strings.Contains(key, redactedKey) {
+			return true
+		}
+	}
+	return false
+}
+
+type logHandler struct {
+	output *logOutput
+
+	// Attributes of the logger, already formatted
+	attrs string
+	group string
+}
+
+func (handler *logHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= handler.output.level.Level()
+}
+
+func (handler *logHandler) Handle(_ context.Context, record slog.Record) error {
+	builder := strings.Builder{}
+	builder.WriteString(record.Message)
+	builder.WriteString(handler.attrs)
+	record.Attrs(func(attr slog.Attr) bool {
+		writeLogAttr(&builder, handler.group, attr)
+		return true
+	})
+
+	handler.output.mutex.RLock()
+	defer handler.output.mutex.RUnlock()
+
+	message := builder.String()
+	for _, secret := range handler.output.secrets {
+		message = strings.ReplaceAll(message, secret, redacted)
+	}
+
+	// Sorted for the sinks to always be written in the same order
+	names := make([]string, 0, len(handler.output.sinks))
+	for name := range handler.output.sinks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		handler.output.sinks[name].WriteLog(record.Level, message)
+	}
+
+	return nil
+}
+
+func (handler *logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	builder := strings.Builder{}
+	builder.WriteString(handler.attrs)
+	for _, attr := range attrs {
+		writeLogAttr(&builder, handler.group, attr)
+	}
+
+	return &logHandler{output: handler.output, attrs: builder.String(), group: handler.group}
+}
+
+func (handler *logHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return handler
+	}
+
+	return &logHandler{output: handler.output, attrs: handler.attrs, group: handler.group + name + "."}
+}
+
+// Attributes are written as ` key=value`, the values with spaces quoted
+func writeLogAttr(builder *strings.Builder, group string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		for _, groupAttr := range attr.Value.Group() {
+			writeLogAttr(builder, group+attr.Key+".", groupAttr)
+		}
+		return
+	}
+
+	value := attr.Value.String()
+	if isRedactedKey(attr.Key) {
+		value = redacted
+	}
+	if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+		value = strconv.Quote(value)
+	}
+
+	builder.WriteString(" ")
+	builder.WriteString(group + attr.Key)
+	builder.WriteString("=")
+	builder.WriteString(value)
+}
+
+type discardLogHandler struct{}
+
+func (discardLogHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardLogHandler) Handle(context.Context, slog.Record) error { return nil }
+func (handler discardLogHandler) WithAttrs([]slog.Attr) slog.Handler {
+	return handler
+}
+func (handler discardLogHandler) WithGroup(string) slog.Handler { return handler }
+
+// Logger used when there is none, such as in tests
+var DiscardLogger = slog.New(discardLogHandler{})
+
+type writerLogSink struct {
+	mutex  sync.Mutex
+	writer io.Writer
+}
+
+// Writes the logs as lines starting with their time and level
+func NewWriterLogSink(writer io.Writer) LogSink {
+	return &writerLogSink{writer: writer}
+}
+
+func (sink *writerLogSink) WriteLog(level slog.Level, message string) {
+	sink.mutex.Lock()
+	defer sink.mutex.Unlock()
+
+	fmt.Fprintf(sink.writer, "%s %s %s\n", time.Now().Format(time.RFC3339), level, message)
+}
+
+// Appends the logs to the file, which stays open for the whole session
+func OpenLogFile(path string) (LogSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWriterLogSink(file), nil
+}
@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordedLog struct {
+	level   slog.Level
+	message string
+}
+
+type recordingLogSink struct {
+	logs []recordedLog
+}
+
+func (sink *recordingLogSink) WriteLog(level slog.Level, message string) {
+	sink.logs = append(sink.logs, recordedLog{level, message})
+}
+
+func TestLoggerLevel(t *testing.T) {
+	logger := NewLogger(slog.LevelInfo)
+	sink := &recordingLogSink{}
+	logger.SetSink("test", sink)
+
+	logger.Debug("Cache hit", "key", "circleci/node@5.0.0")
+	logger.Info("New client connection")
+	logger.SetLevel(slog.LevelDebug)
+	logger.Debug("Cache hit", "key", "circleci/node@5.0.0")
+	logger.SetLevel(slog.LevelError)
+	logger.Warn("Orb could not be fetched")
+
+	assert.Equal(t, []recordedLog{
+		{slog.LevelInfo, "New client connection"},
+		{slog.LevelDebug, "Cache hit key=circleci/node@5.0.0"},
+	}, sink.logs)
+	assert.Equal(t, slog.LevelError, logger.GetLevel())
+
+	logger.SetSink("test", nil)
+	logger.Error("Request failed")
+	assert.Len(t, sink.logs, 2)
+}
+
+func TestLoggerAttributes(t *testing.T) {
+	logger := NewLogger(slog.LevelDebug)
+	sink := &recordingLogSink{}
+	logger.SetSink("test", sink)
+
+	logger.With("orb", "circleci/node@5").WithGroup("fetch").Warn(
+		"Orb could not be fetched",
+		"error", errors.New("could not find orb circleci/node@5"),
+		"attempts", 2,
+		"host", "",
+	)
+
+	assert.Equal(t, []recordedLog{
+		{slog.LevelWarn, `Orb could not be fetched orb=circleci/node@5 fetch.error="could not find orb circleci/node@5" fetch.attempts=2 fetch.host=""`},
+	}, sink.logs)
+}
+
+func TestLoggerRedaction(t *testing.T) {
+	logger := NewLogger(slog.LevelDebug)
+	sink := &recordingLogSink{}
+	logger.SetSink("test", sink)
+	logger.Redact("CCIPAT_0123456789abcdef", "short")
+
+	logger.Info("Request", "token", "abc", "dockerPassword", "hunter22", "environment", "KEY=value")
+	logger.Info("Request failed", "error", errors.New("invalid token CCIPAT_0123456789abcdef"), "image", "short")
+
+	assert.Equal(t, []recordedLog{
+		{slog.LevelInfo, "Request token=[redacted] dockerPassword=[redacted] environment=[redacted]"},
+		{slog.LevelInfo, `Request failed error="invalid token [redacted]" image=short`},
+	}, sink.logs)
+}
+
+func TestParseLogLevel(t *testing.T) {
+	testCases := []struct {
+		value interface{}
+		level slog.Level
+		ok    bool
+	}{
+		{"debug", slog.LevelDebug, true},
+		{"Warning", slog.LevelWarn, true},
+		{"error", slog.LevelError, true},
+		{"verbose", DefaultLogLevel, false},
+		{3, DefaultLogLevel, false},
+	}
+
+	for _, tt := range testCases {
+		level, ok := ParseLogLevel(tt.value)
+		assert.Equal(t, tt.level, level, tt.value)
+		assert.Equal(t, tt.ok, ok, tt.value)
+	}
+}
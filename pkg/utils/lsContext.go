@@ -2,15 +2,121 @@ package utils
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"sync/atomic"
 )
 
 type LsContext struct {
 	Api                ApiContext
 	UserIdForTelemetry string
 	IsCciExtension     bool
+
+	// Do not warn about unused commands, executors and jobs, useful for
+	// configurations defining shared definitions
+	IgnoreUnusedDefinitions bool
+
+	// Do not show the default values of omitted parameters as inlay hints
+	DisableInlayHints bool
+
+	// Do not check the existence and the tags of Docker images on their
+	// registry, for offline or air-gapped setups
+	DisableDockerImageChecks bool
+
+	// Keep the top-level keys in their order when formatting a document
+	DisableFormatterKeyOrdering bool
+
+	// Commands of the run steps that need the code of the project, such as
+	// `npm ci`, for the jobs running them without checking out the code to be
+	// reported. Nil for DefaultCheckoutCommands, see GetCheckoutCommands
+	CheckoutCommands []string
+
+	// Credentials used to check the images of private Docker registries, by
+	// registry host such as docker.io, gcr.io or
+	// <account>.dkr.ecr.<region>.amazonaws.com
+	DockerRegistryCredentials map[string]DockerRegistryCredentials
+
+	// Reports the progress of long running work, nil when the client does not
+	// support work done progress
+	Progress ProgressReporter
+
+	// Logs of the server, see GetLogger. Its level is set with the `logLevel`
+	// setting
+	Logger *Logger
+
+	// Set with the `offline` setting, it can be changed at any time by the
+	// configuration of the client, see IsOffline
+	offline atomic.Bool
+
+	// Set with the `diagnosticSeverities` setting, which can also change at
+	// any time
+	diagnosticSeverities atomic.Pointer[DiagnosticSeverities]
+}
+
+// Returned instead of reaching the network when offline
+var ErrOffline = errors.New("network lookups are disabled in offline mode")
+
+// When offline, the server never reaches the network:
+//   - remote orbs are only resolved from the ones persisted by the previous
+//     sessions, the orbs that are not are not validated, nor their version
+//     checked for updates, and their jobs, commands and executors are not
+//     reported as missing
+//   - orbs are not checked for existence and orb names are only completed
+//     from the cached orbs
+//   - Docker images and their tags are not checked on their registry, nor
+//     offered as completions
+//   - self-hosted runner namespaces are not checked
+//   - the environment variables of the projects and the contexts of the
+//     organizations are not fetched, they are neither completed nor checked
+func (ctx *LsContext) IsOffline() bool {
+	return ctx != nil && ctx.offline.Load()
+}
+
+func (ctx *LsContext) SetOffline(offline bool) {
+	ctx.offline.Store(offline)
+}
+
+func (ctx *LsContext) GetCheckoutCommands() []string {
+	if ctx == nil || ctx.CheckoutCommands == nil {
+		return DefaultCheckoutCommands
+	}
+	return ctx.CheckoutCommands
+}
+
+// Logger of the context, or a logger discarding everything when there is
+// none
+func (ctx *LsContext) GetLogger() *slog.Logger {
+	if ctx == nil || ctx.Logger == nil {
+		return DiscardLogger
+	}
+	return ctx.Logger.Logger
+}
+
+// Severities of the rules configured by the settings
+func (ctx *LsContext) GetDiagnosticSeverities() DiagnosticSeverities {
+	if ctx == nil {
+		return nil
+	}
+
+	if severities := ctx.diagnosticSeverities.Load(); severities != nil {
+		return *severities
+	}
+	return nil
+}
+
+func (ctx *LsContext) SetDiagnosticSeverities(severities DiagnosticSeverities) {
+	ctx.diagnosticSeverities.Store(&severities)
+}
+
+// For ECR, the username is AWS and the password the token given by
+// `aws ecr get-login-password`. For GCR, the username is _json_key and the
+// password the JSON key of a service account
+type DockerRegistryCredentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
 }
 
 type ApiContext struct {
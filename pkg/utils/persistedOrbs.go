@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/CircleCI-Public/circleci-yaml-language-server/pkg/ast"
+)
+
+// Bump this whenever the persisted format changes, entries written with
+// another version are ignored
+const persistedOrbFormatVersion = 1
+
+// The parsed attributes of an orb can not be serialized (they contain
+// interfaces and tree-sitter nodes), so only the metadata is persisted and
+// the attributes are rebuilt from the orb source file sitting next to it
+type persistedOrb struct {
+	FormatVersion int               `json:"formatVersion"`
+	OrbID         string            `json:"orbId"`
+	CreatedAt     string            `json:"createdAt"`
+	Description   string            `json:"description"`
+	StoredAt      time.Time         `json:"storedAt"`
+	RemoteInfo    ast.RemoteOrbInfo `json:"remoteInfo"`
+}
+
+// Parses an orb source into its attributes
+type OrbSourceParser func(source []byte, filePath string) (ast.OrbParsedAttributes, error)
+
+func getPersistedOrbFSPath(orbFilePath string) string {
+	return strings.TrimSuffix(orbFilePath, path.Ext(orbFilePath)) + ".json"
+}
+
+func (c *OrbCache) persistOrb(orbID string, cachedOrb *CachedOrb) error {
+	orb := cachedOrb.Orb
+	if c.persistenceDir == "" || c.inMemory || orb.IsLocal || !isInDir(orb.RemoteInfo.FilePath, c.persistenceDir) {
+		return nil
+	}
+
+	content, err := json.Marshal(persistedOrb{
+		FormatVersion: persistedOrbFormatVersion,
+		OrbID:         orbID,
+		CreatedAt:     orb.CreatedAt,
+		Description:   orb.Description,
+		StoredAt:      cachedOrb.StoredAt,
+		RemoteInfo:    orb.RemoteInfo,
+	})
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(getPersistedOrbFSPath(orb.RemoteInfo.FilePath), content, 0644)
+}
+
+func isInDir(filePath string, dir string) bool {
+	rel, err := filepath.Rel(dir, filePath)
+	return err == nil && !strings.HasPrefix(rel, "..")
+}
+
+// Repopulate the orb cache with the orbs persisted by a previous session in
+// the persistence directory of the cache
+// Returns the number of orbs loaded
+func (cache *Cache) LoadPersistedOrbs(parse OrbSourceParser) int {
+	dir := cache.OrbCache.persistenceDir
+	if dir == "" || cache.OrbCache.IsInMemory() {
+		return 0
+	}
+
+	loaded := 0
+
+	filepath.WalkDir(dir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || path.Ext(filePath) != ".json" {
+			return nil
+		}
+
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil
+		}
+
+		var persisted persistedOrb
+		if err := json.Unmarshal(content, &persisted); err != nil ||
+			persisted.FormatVersion != persistedOrbFormatVersion {
+			return nil
+		}
+
+		if !isInDir(persisted.RemoteInfo.FilePath, dir) {
+			return nil
+		}
+
+		source, err := os.ReadFile(persisted.RemoteInfo.FilePath)
+		if err != nil {
+			return nil
+		}
+
+		cachedOrb := &CachedOrb{
+			StoredAt: persisted.StoredAt,
+			TTL:      cache.OrbCache.maxAge,
+		}
+		if cachedOrb.isExpired(time.Now()) {
+			return nil
+		}
+
+		parsedAttributes, err := parse(source, persisted.RemoteInfo.FilePath)
+		if err != nil {
+			return nil
+		}
+
+		cachedOrb.Orb = &ast.OrbInfo{
+			OrbParsedAttributes: parsedAttributes,
+			IsLocal:             false,
+			CreatedAt:           persisted.CreatedAt,
+			Description:         persisted.Description,
+			Source:              string(source),
+			RemoteInfo:          persisted.RemoteInfo,
+		}
+
+		cache.OrbCache.cacheMutex.Lock()
+		cache.OrbCache.orbsCache[persisted.OrbID] = cachedOrb
+		cache.OrbCache.cacheMutex.Unlock()
+
+		loaded++
+		return nil
+	})
+
+	return loaded
+}
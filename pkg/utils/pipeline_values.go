@@ -0,0 +1,55 @@
+package utils
+
+import "strings"
+
+type PipelineValue struct {
+	// Name of the value without its `pipeline.` prefix
+	Name        string
+	Description string
+}
+
+// Built-in pipeline values, the pipeline parameters are declared by the
+// documents
+var PipelineValues = []PipelineValue{
+	{"id", "A globally unique id representing the pipeline"},
+	{"number", "A project unique integer id for the pipeline"},
+	{"project.git_url", "The URL where the current project is hosted"},
+	{"project.type", "The lower-case name of the VCS provider, e.g. github, bitbucket"},
+	{"git.tag", "The name of the git tag that was pushed to trigger the pipeline, empty if the pipeline was not triggered by a tag"},
+	{"git.branch", "The name of the git branch that was pushed to trigger the pipeline"},
+	{"git.revision", "The long (40-character) git SHA that is being built"},
+	{"git.base_revision", "The long (40-character) git SHA of the build prior to the one being built"},
+	{"in_setup", "True if the pipeline is in the setup phase, i.e. running a setup workflow"},
+	{"trigger_source", "The source that triggers the pipeline, e.g. webhook, api, scheduled_pipeline"},
+	{"schedule.name", "The name of the schedule if it is a scheduled pipeline, empty otherwise"},
+	{"schedule.id", "The unique id of the schedule if it is a scheduled pipeline, empty otherwise"},
+}
+
+// Pipeline values whose members depend on what triggered the pipeline, such
+// as pipeline.trigger_parameters.gitlab.branch or pipeline.event.name. Any
+// member of these namespaces is accepted
+var PipelineValueNamespaces = []string{"trigger_parameters", "event"}
+
+// Tells if the name, without its `pipeline.` prefix, is a built-in pipeline
+// value or a member of one of the namespaces
+func IsPipelineValue(name string) bool {
+	if FindInArray(GetPipelineValueNames(), name) != -1 {
+		return true
+	}
+
+	for _, namespace := range PipelineValueNamespaces {
+		if strings.HasPrefix(name, namespace+".") && len(name) > len(namespace)+1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func GetPipelineValueNames() []string {
+	names := make([]string, 0, len(PipelineValues))
+	for _, value := range PipelineValues {
+		names = append(names, value.Name)
+	}
+	return names
+}
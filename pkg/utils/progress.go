@@ -0,0 +1,28 @@
+package utils
+
+// Reports the progress of long running work to the client, such as the
+// resolution of the remote orbs
+type ProgressReporter interface {
+	Begin(title string) Progress
+}
+
+type Progress interface {
+	// The percentage goes from 0 to 100
+	Report(message string, percentage uint32)
+	End(message string)
+}
+
+type noProgress struct{}
+
+func (noProgress) Report(string, uint32) {}
+func (noProgress) End(string)            {}
+
+// Begin reporting with the reporter, nothing is reported when there is none,
+// such as when the client does not support progress
+func BeginProgress(reporter ProgressReporter, title string) Progress {
+	if reporter == nil {
+		return noProgress{}
+	}
+
+	return reporter.Begin(title)
+}
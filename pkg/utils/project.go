@@ -16,13 +16,33 @@ type ProjectEnvVariableRes struct {
 }
 
 func GetAllProjectEnvVariables(lsContext *LsContext, cache *Cache, cachedFile *CachedFile) {
-	var projectEnvVariables []string
+	if lsContext.IsOffline() {
+		return
+	}
 
-	fetchAllProjectEnvVariables(lsContext, cachedFile.Project.Slug, "", cache, &projectEnvVariables)
+	// Requested on every completion until they are fetched, a single request
+	// is sent at a time for a file
+	uri := cachedFile.TextDocument.URI
+	cache.FileCache.envVariablesCalls.do(string(uri), func() (struct{}, error) {
+		// Fetched by a call that ended since this one was started
+		if cache.FileCache.IsProjectEnvVariablesResolved(uri) {
+			return struct{}{}, nil
+		}
 
-	for _, projectEnvVariable := range projectEnvVariables {
-		cache.FileCache.AddEnvVariableToProjectLinkedToFile(cachedFile.TextDocument.URI, projectEnvVariable)
-	}
+		var projectEnvVariables []string
+
+		err := fetchAllProjectEnvVariables(lsContext, cachedFile.Project.Slug, "", cache, &projectEnvVariables)
+
+		for _, projectEnvVariable := range projectEnvVariables {
+			cache.FileCache.AddEnvVariableToProjectLinkedToFile(uri, projectEnvVariable)
+		}
+
+		// A failed page leaves the variables incomplete
+		if err == nil {
+			cache.FileCache.SetProjectEnvVariablesResolved(uri)
+		}
+		return struct{}{}, err
+	})
 }
 
 func fetchAllProjectEnvVariables(lsContext *LsContext, projectSlug string, nextPageToken string, cache *Cache, projectEnvVariables *[]string) error {
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+)
+
+func TestGetAllProjectEnvVariables(t *testing.T) {
+	requests := atomic.Int32{}
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		w.Write([]byte(`{"items": [{"name": "TOKEN"}, {"name": "KEY"}]}`))
+	}))
+	defer server.Close()
+
+	cache := CreateCache(WithOrbTTL(0))
+	uri := protocol.URI("file:///project/.circleci/config.yml")
+	cache.FileCache.SetFile(CachedFile{
+		TextDocument: protocol.TextDocumentItem{URI: uri},
+		Project:      Project{Slug: "gh/org/repo"},
+	})
+	context := &LsContext{Api: ApiContext{Token: "token", HostUrl: server.URL}}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			GetAllProjectEnvVariables(context, cache, cache.FileCache.GetFile(uri))
+		}()
+	}
+	for requests.Load() == 0 {
+		cache.FileCache.GetProjectEnvVariables(uri)
+	}
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), requests.Load())
+	assert.Equal(t, []string{"TOKEN", "KEY"}, cache.FileCache.GetProjectEnvVariables(uri))
+	assert.True(t, cache.FileCache.IsProjectEnvVariablesResolved(uri))
+
+	// Resolved variables are not fetched again
+	GetAllProjectEnvVariables(context, cache, cache.FileCache.GetFile(uri))
+	assert.Equal(t, int32(1), requests.Load())
+}